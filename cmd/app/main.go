@@ -1,8 +1,10 @@
 package main
 
 import (
+	"context"
 	"net/http"
 	"os"
+	"time"
 
 	"github.com/go-park-mail-ru/2025_2_Avrora/internal/db"
 	service "github.com/go-park-mail-ru/2025_2_Avrora/internal/delivery/grpc"
@@ -11,7 +13,9 @@ import (
 	"github.com/go-park-mail-ru/2025_2_Avrora/internal/delivery/http/middleware"
 	request_id "github.com/go-park-mail-ru/2025_2_Avrora/internal/delivery/http/middleware/request"
 	"github.com/go-park-mail-ru/2025_2_Avrora/internal/delivery/http/utils"
+	"github.com/go-park-mail-ru/2025_2_Avrora/internal/domain"
 	logger "github.com/go-park-mail-ru/2025_2_Avrora/internal/log"
+	"github.com/go-park-mail-ru/2025_2_Avrora/internal/storage"
 	"github.com/go-park-mail-ru/2025_2_Avrora/internal/usecase"
 	_ "github.com/jackc/pgx/v5/stdlib"
 	"go.uber.org/zap"
@@ -50,26 +54,81 @@ func main() {
 		log.Fatal("failed to create password hasher", zap.Error(err))
 	}
 	jwtService := utils.NewJwtGenerator(os.Getenv("JWT_SECRET"))
+	// Private file and calendar feed links are signed with keys of their own
+	urlSigner := utils.NewURLSigner(string(secretEnv("IMAGE_URL_SECRET", "image-url-signing")))
+	feedSigner := utils.NewURLSigner(string(secretEnv("CALENDAR_URL_SECRET", "calendar-feed-signing")))
+	// Proxy headers are believed only from these; unset means none
+	trustedProxies, err := middleware.ParseTrustedProxies(os.Getenv("TRUSTED_PROXIES"))
+	if err != nil {
+		log.Fatal("invalid TRUSTED_PROXIES", zap.Error(err))
+	}
 
 	// Repositories
 	offerRepo := db.NewOfferRepository(dbConn.GetDB(), repoLogger)
 	profileRepo := db.NewProfileRepository(dbConn.GetDB(), repoLogger)
 	complexRepo := db.NewHousingComplexRepository(dbConn.GetDB(), repoLogger)
+	imageRepo := db.NewImageRepository(dbConn.GetDB(), repoLogger)
+	offerPhotoRepo := db.NewOfferPhotoRepository(dbConn.GetDB(), repoLogger)
+	complexPhotoRepo := db.NewComplexPhotoRepository(dbConn.GetDB(), repoLogger)
+	moderationRepo := db.NewModerationRepository(dbConn.GetDB(), repoLogger)
+	offerDraftRepo := db.NewOfferDraftRepository(dbConn.GetDB(), repoLogger)
+	changeLogRepo := db.NewChangeLogRepository(dbConn.GetDB(), repoLogger)
+	marketRepo := db.NewMarketRepository(dbConn.GetDB(), repoLogger)
+	offerStatsRepo := db.NewOfferStatsRepository(dbConn.GetDB(), repoLogger)
+	developerRepo := db.NewDeveloperRepository(dbConn.GetDB(), repoLogger)
+	regionRepo := db.NewRegionRepository(dbConn.GetDB(), repoLogger)
+	shortTermRepo := db.NewShortTermRepository(dbConn.GetDB(), repoLogger)
+	viewingRepo := db.NewViewingRepository(dbConn.GetDB(), repoLogger)
+	notificationRepo := db.NewNotificationRepository(dbConn.GetDB(), repoLogger)
 
 	// Usecases
-	offerUC := usecase.NewOfferUsecase(offerRepo, usecaseLogger)
-	profileUC := usecase.NewProfileUsecase(profileRepo, hasher, usecaseLogger)
-	complexUC := usecase.NewHousingComplexUsecase(complexRepo, usecaseLogger)
+	offerTTL := durationEnv("OFFER_TTL", 30*24*time.Hour)
+	imageUC := usecase.NewImageUsecase(imageRepo, storage.NewLocalStorage(imageStorageDir()), durationEnv("IMAGE_UPLOAD_TTL", 24*time.Hour), usecaseLogger)
+	moderationUC := usecase.NewModerationUsecase(moderationRepo, offerRepo, usecase.DefaultModerationRules(moderationRepo), offerTTL, usecaseLogger)
+	marketUC := usecase.NewMarketUsecase(marketRepo, usecaseLogger)
+	offerStatsUC := usecase.NewOfferStatsUsecase(offerStatsRepo, offerRepo, secretEnv("VIEWER_KEY_SECRET", "offer-viewer-key"), usecaseLogger)
+	offerUC := usecase.NewOfferUsecase(offerRepo, offerDraftRepo, imageUC, moderationUC, marketUC, offerStatsUC, profileRepo, offerTTL, usecaseLogger)
+	profileUC := usecase.NewProfileUsecase(profileRepo, hasher, imageUC, usecaseLogger)
+	regionUC := usecase.NewRegionUsecase(regionRepo, offerRepo, usecaseLogger)
+	developerUC := usecase.NewDeveloperUsecase(developerRepo, profileRepo, imageUC, usecaseLogger)
+	complexUC := usecase.NewHousingComplexUsecase(complexRepo, offerRepo, developerUC, imageUC, usecaseLogger)
+	offerPhotoUC := usecase.NewOfferPhotoUsecase(offerPhotoRepo, offerRepo, moderationUC, imageUC, usecaseLogger)
+	complexPhotoUC := usecase.NewComplexPhotoUsecase(complexPhotoRepo, complexRepo, developerUC, imageUC, usecaseLogger)
+	changeLogUC := usecase.NewChangeLogUsecase(changeLogRepo, usecaseLogger)
+	shortTermUC := usecase.NewShortTermUsecase(shortTermRepo, offerRepo, usecaseLogger)
+	notificationUC := usecase.NewNotificationUsecase(notificationRepo, usecaseLogger)
+	viewingUC := usecase.NewViewingUsecase(viewingRepo, offerRepo, notificationUC, usecaseLogger)
 
 	// Handlers
 	offerHandler := handlers.NewOfferHandler(offerUC, httpLogger)
 	profileHandler := handlers.NewProfileHandler(profileUC, httpLogger)
 	complexHandler := handlers.NewComplexHandler(complexUC, httpLogger)
+	offerPhotoHandler := handlers.NewPhotoHandler(offerPhotoUC, "/api/v1/offers/photos", httpLogger)
+	complexPhotoHandler := handlers.NewPhotoHandler(complexPhotoUC, "/api/v1/complexes/photos", httpLogger)
+	moderationHandler := handlers.NewModerationHandler(moderationUC, httpLogger)
+	changeLogHandler := handlers.NewChangeLogHandler(changeLogUC, httpLogger)
+	marketHandler := handlers.NewMarketHandler(marketUC, httpLogger)
+	offerStatsHandler := handlers.NewOfferStatsHandler(offerStatsUC, httpLogger)
+	developerHandler := handlers.NewDeveloperHandler(developerUC, httpLogger)
+	regionHandler := handlers.NewRegionHandler(regionUC, httpLogger)
+	shortTermHandler := handlers.NewShortTermHandler(shortTermUC, httpLogger)
+	notificationHandler := handlers.NewNotificationHandler(notificationUC, httpLogger)
 
 	// Auth middleware helper
 	authMW := func(h http.HandlerFunc) http.HandlerFunc {
 		return middleware.AuthMiddleware(appLogger, jwtService)(h).ServeHTTP
 	}
+	optionalAuthMW := func(h http.HandlerFunc) http.HandlerFunc {
+		return middleware.OptionalAuthMiddleware(appLogger, jwtService)(h).ServeHTTP
+	}
+	moderatorMW := func(h http.HandlerFunc) http.HandlerFunc {
+		roleMW := middleware.RoleMiddleware(appLogger, profileRepo, domain.UserRoleModerator, domain.UserRoleAdmin)
+		return authMW(roleMW(h).ServeHTTP)
+	}
+	adminMW := func(h http.HandlerFunc) http.HandlerFunc {
+		roleMW := middleware.RoleMiddleware(appLogger, profileRepo, domain.UserRoleAdmin)
+		return authMW(roleMW(h).ServeHTTP)
+	}
 
 	// GRPC Clients
 	authClient, err := service.NewAuthClient(":50051", grpcLogger)
@@ -91,7 +150,8 @@ func main() {
 	authHandler := handlers.NewAuthHandler(authClient, httpLogger)
 
 	// Image handler with the proper gRPC client
-	imageHandler := handlers.NewImageHandler(fileServerClient, httpLogger, "http://localhost:8080")
+	imageHandler := handlers.NewImageHandler(fileServerClient, imageUC, urlSigner, httpLogger, "http://localhost:8080")
+	viewingHandler := handlers.NewViewingHandler(viewingUC, feedSigner, httpLogger, "http://localhost:8080")
 
 	// ┌───────────────┐
 	// │ Public routes │
@@ -107,14 +167,78 @@ func main() {
 	// Image routes
 	mux.HandleFunc("/api/v1/image/upload", authMW(imageHandler.UploadImage))
 	mux.Handle("/api/v1/image/", imageHandler.ImageServer())
+	mux.HandleFunc("/api/v1/image/private/upload", authMW(imageHandler.UploadPrivateFile))
+	mux.HandleFunc("/api/v1/image/private/sign/", authMW(imageHandler.SignPrivateURL))
+	mux.HandleFunc("/api/v1/image/private/", optionalAuthMW(imageHandler.GetPrivateFile))
 
 	// Offers
 	mux.HandleFunc("/api/v1/offers", offerHandler.GetOffers)
 	mux.HandleFunc("/api/v1/offers/create", authMW(offerHandler.CreateOffer))
-	mux.HandleFunc("/api/v1/offers/", offerHandler.GetOffer)
+	mux.HandleFunc("/api/v1/offers/attributes", offerHandler.ListOfferAttributes)
+	mux.HandleFunc("/api/v1/offers/", optionalAuthMW(offerHandler.GetOffer))
 	mux.HandleFunc("/api/v1/offers/delete/", authMW(offerHandler.DeleteOffer))
 	mux.HandleFunc("/api/v1/offers/update/", authMW(offerHandler.UpdateOffer))
 	mux.HandleFunc("/api/v1/offers/pricehistory/", offerHandler.GetOfferPriceHistory)
+	mux.HandleFunc("/api/v1/offers/similar/", offerHandler.GetSimilarOffers)
+	mux.HandleFunc("/api/v1/offers/status/", authMW(offerHandler.ChangeOfferStatus))
+	mux.HandleFunc("/api/v1/offers/renew/", authMW(offerHandler.RenewOffer))
+	mux.HandleFunc("/api/v1/offers/statushistory/", authMW(offerHandler.GetOfferStatusHistory))
+	mux.HandleFunc("/api/v1/offers/moderation/", authMW(moderationHandler.GetOfferModeration))
+	mux.HandleFunc("/api/v1/offers/stats/", authMW(offerStatsHandler.GetOfferStats))
+	mux.HandleFunc("/api/v1/offers/contact/", authMW(offerStatsHandler.RevealContact))
+
+	// Short-term rent
+	mux.HandleFunc("/api/v1/offers/calendar/", shortTermHandler.GetCalendar)
+	mux.HandleFunc("/api/v1/offers/calendar/block/", authMW(shortTermHandler.BlockDates))
+	mux.HandleFunc("/api/v1/offers/shortterm/update/", authMW(shortTermHandler.SaveTerms))
+	mux.HandleFunc("/api/v1/offers/shortterm/delete/", authMW(shortTermHandler.DeleteTerms))
+	mux.HandleFunc("/api/v1/offers/bookings/", authMW(shortTermHandler.ListOfferBookings))
+	mux.HandleFunc("/api/v1/offers/bookings/create/", authMW(shortTermHandler.CreateBooking))
+	mux.HandleFunc("/api/v1/offers/bookings/cancel/", authMW(shortTermHandler.CancelBooking))
+	mux.HandleFunc("/api/v1/bookings/my", authMW(shortTermHandler.ListMyBookings))
+
+	// Viewings
+	mux.HandleFunc("/api/v1/offers/viewings/slots/", viewingHandler.ListSlots)
+	mux.HandleFunc("/api/v1/offers/viewings/slots/create/", authMW(viewingHandler.CreateSlot))
+	mux.HandleFunc("/api/v1/offers/viewings/slots/delete/", authMW(viewingHandler.DeleteSlot))
+	mux.HandleFunc("/api/v1/offers/viewings/request/", authMW(viewingHandler.RequestViewing))
+	mux.HandleFunc("/api/v1/viewings/my", authMW(viewingHandler.ListViewings))
+	mux.HandleFunc("/api/v1/viewings/", authMW(viewingHandler.GetViewing))
+	mux.HandleFunc("/api/v1/viewings/confirm/", authMW(viewingHandler.ConfirmViewing))
+	mux.HandleFunc("/api/v1/viewings/reschedule/", authMW(viewingHandler.RescheduleViewing))
+	mux.HandleFunc("/api/v1/viewings/cancel/", authMW(viewingHandler.CancelViewing))
+	mux.HandleFunc("/api/v1/viewings/ics/", authMW(viewingHandler.GetViewingICS))
+	mux.HandleFunc("/api/v1/viewings/feed/link", authMW(viewingHandler.GetFeedLink))
+	mux.HandleFunc("/api/v1/viewings/feed.ics", viewingHandler.GetFeed)
+
+	// Notifications
+	mux.HandleFunc("/api/v1/notifications/list", authMW(notificationHandler.ListNotifications))
+	mux.HandleFunc("/api/v1/notifications/read/", authMW(notificationHandler.MarkRead))
+	mux.HandleFunc("/api/v1/notifications/readall", authMW(notificationHandler.MarkAllRead))
+
+	// Favorites
+	mux.HandleFunc("/api/v1/favorites", authMW(offerStatsHandler.ListFavorites))
+	mux.HandleFunc("/api/v1/favorites/add/", authMW(offerStatsHandler.AddFavorite))
+	mux.HandleFunc("/api/v1/favorites/remove/", authMW(offerStatsHandler.RemoveFavorite))
+
+	// Market analytics
+	mux.HandleFunc("/api/v1/prices/trend", offerHandler.GetPriceTrend)
+	mux.HandleFunc("/api/v1/market/stats", marketHandler.GetStats)
+
+	// Offer drafts
+	mux.HandleFunc("/api/v1/offers/drafts", authMW(offerHandler.ListDrafts))
+	mux.HandleFunc("/api/v1/offers/drafts/create", authMW(offerHandler.CreateDraft))
+	mux.HandleFunc("/api/v1/offers/drafts/", authMW(offerHandler.GetDraft))
+	mux.HandleFunc("/api/v1/offers/drafts/update/", authMW(offerHandler.SaveDraft))
+	mux.HandleFunc("/api/v1/offers/drafts/delete/", authMW(offerHandler.DeleteDraft))
+	mux.HandleFunc("/api/v1/offers/drafts/publish/", authMW(offerHandler.PublishDraft))
+
+	// Offer photos
+	mux.HandleFunc("/api/v1/offers/photos/", offerPhotoHandler.ListPhotos)
+	mux.HandleFunc("/api/v1/offers/photos/add/", authMW(offerPhotoHandler.AddPhoto))
+	mux.HandleFunc("/api/v1/offers/photos/update/", authMW(offerPhotoHandler.UpdatePhoto))
+	mux.HandleFunc("/api/v1/offers/photos/delete/", authMW(offerPhotoHandler.DeletePhoto))
+	mux.HandleFunc("/api/v1/offers/photos/reorder/", authMW(offerPhotoHandler.ReorderPhotos))
 
 	// Profile
 	mux.HandleFunc("/api/v1/profile/", authMW(profileHandler.GetProfile))
@@ -129,13 +253,113 @@ func main() {
 	mux.HandleFunc("/api/v1/complexes/", complexHandler.GetComplexByID)
 	mux.HandleFunc("/api/v1/complexes/update/", authMW(complexHandler.UpdateComplex))
 	mux.HandleFunc("/api/v1/complexes/delete/", authMW(complexHandler.DeleteComplex))
+	mux.HandleFunc("/api/v1/complexes/offers/", complexHandler.ListComplexOffers)
+
+	// Complex buildings, layouts and amenities
+	mux.HandleFunc("/api/v1/complexes/buildings/add/", authMW(complexHandler.AddBuilding))
+	mux.HandleFunc("/api/v1/complexes/buildings/update/", authMW(complexHandler.UpdateBuilding))
+	mux.HandleFunc("/api/v1/complexes/buildings/delete/", authMW(complexHandler.DeleteBuilding))
+	mux.HandleFunc("/api/v1/complexes/layouts/add/", authMW(complexHandler.AddLayout))
+	mux.HandleFunc("/api/v1/complexes/layouts/update/", authMW(complexHandler.UpdateLayout))
+	mux.HandleFunc("/api/v1/complexes/layouts/delete/", authMW(complexHandler.DeleteLayout))
+	mux.HandleFunc("/api/v1/complexes/amenities/", authMW(complexHandler.SetAmenities))
+
+	// Complex photos
+	mux.HandleFunc("/api/v1/complexes/photos/", complexPhotoHandler.ListPhotos)
+	mux.HandleFunc("/api/v1/complexes/photos/add/", authMW(complexPhotoHandler.AddPhoto))
+	mux.HandleFunc("/api/v1/complexes/photos/update/", authMW(complexPhotoHandler.UpdatePhoto))
+	mux.HandleFunc("/api/v1/complexes/photos/delete/", authMW(complexPhotoHandler.DeletePhoto))
+	mux.HandleFunc("/api/v1/complexes/photos/reorder/", authMW(complexPhotoHandler.ReorderPhotos))
+
+	// Developers
+	mux.HandleFunc("/api/v1/developers/list", developerHandler.ListDevelopers)
+	mux.HandleFunc("/api/v1/developers/", developerHandler.GetDeveloper)
+	mux.HandleFunc("/api/v1/developers/create", adminMW(developerHandler.CreateDeveloper))
+	mux.HandleFunc("/api/v1/developers/update/", authMW(developerHandler.UpdateDeveloper))
+	mux.HandleFunc("/api/v1/developers/members/add/", adminMW(developerHandler.AddMember))
+	mux.HandleFunc("/api/v1/developers/members/remove/", adminMW(developerHandler.RemoveMember))
+
+	// Regions and metro
+	mux.HandleFunc("/api/v1/regions", regionHandler.ListRegions)
+	mux.HandleFunc("/api/v1/regions/", regionHandler.GetRegion)
+	mux.HandleFunc("/api/v1/regions/offers/", regionHandler.ListRegionOffers)
+	mux.HandleFunc("/api/v1/regions/create", adminMW(regionHandler.CreateRegion))
+	mux.HandleFunc("/api/v1/regions/update/", adminMW(regionHandler.UpdateRegion))
+	mux.HandleFunc("/api/v1/regions/delete/", adminMW(regionHandler.DeleteRegion))
+	mux.HandleFunc("/api/v1/metro/lines", regionHandler.ListMetroLines)
+	mux.HandleFunc("/api/v1/metro/lines/create", adminMW(regionHandler.CreateMetroLine))
+	mux.HandleFunc("/api/v1/metro/lines/update/", adminMW(regionHandler.UpdateMetroLine))
+	mux.HandleFunc("/api/v1/metro/lines/delete/", adminMW(regionHandler.DeleteMetroLine))
+	mux.HandleFunc("/api/v1/metro/stations", regionHandler.ListMetroStations)
+	mux.HandleFunc("/api/v1/metro/stations/create", adminMW(regionHandler.CreateMetroStation))
+	mux.HandleFunc("/api/v1/metro/stations/update/", adminMW(regionHandler.UpdateMetroStation))
+	mux.HandleFunc("/api/v1/metro/stations/delete/", adminMW(regionHandler.DeleteMetroStation))
+
+	// Moderation
+	mux.HandleFunc("/api/v1/moderation/duplicates", moderatorMW(moderationHandler.ListDuplicateClusters))
+	mux.HandleFunc("/api/v1/moderation/queue", moderatorMW(moderationHandler.ListQueue))
+	mux.HandleFunc("/api/v1/moderation/approve/", moderatorMW(moderationHandler.ApproveOffer))
+	mux.HandleFunc("/api/v1/moderation/reject/", moderatorMW(moderationHandler.RejectOffer))
+	mux.HandleFunc("/api/v1/moderation/audit/", moderatorMW(moderationHandler.GetAudit))
+
+	// Admin
+	mux.HandleFunc("/api/v1/admin/changes", adminMW(changeLogHandler.ListChanges))
+
+	// Background jobs
+	go runEvery(context.Background(), 10*time.Minute, imageUC.ExpireUploads, appLogger)
+	go runEvery(context.Background(), time.Hour, offerUC.ExpireOffers, appLogger)
+	go runEvery(context.Background(), durationEnv("MARKET_STATS_REFRESH", time.Hour), marketUC.RefreshStats, appLogger)
+	go runEvery(context.Background(), time.Hour, offerStatsUC.PurgeViewers, appLogger)
 
 	// Middleware setup
 	var handler http.Handler = mux
 	handler = middleware.CorsMiddleware(handler, corsOrigin)
 	handler = request_id.RequestIDMiddleware(handler)
 	handler = middleware.LoggerMiddleware(appLogger)(handler)
+	handler = middleware.ClientIPMiddleware(trustedProxies)(handler)
 
 	appLogger.Logger.Info("starting server", zap.String("port", port))
 	appLogger.Logger.Fatal("server stopped", zap.Error(http.ListenAndServe(":"+port, handler)))
-}
\ No newline at end of file
+}
+
+func imageStorageDir() string {
+	if dir := os.Getenv("FILESERVER_STORAGE_DIR"); dir != "" {
+		return dir
+	}
+	return "./image"
+}
+
+// secretEnv is the secret in key, or one derived for purpose from the JWT
+// secret when key is unset
+func secretEnv(key, purpose string) []byte {
+	if secret := os.Getenv(key); secret != "" {
+		return []byte(secret)
+	}
+	return utils.DeriveSecret(os.Getenv("JWT_SECRET"), purpose)
+}
+
+func durationEnv(key string, fallback time.Duration) time.Duration {
+	if v := os.Getenv(key); v != "" {
+		if d, err := time.ParseDuration(v); err == nil {
+			return d
+		}
+	}
+	return fallback
+}
+
+// runEvery calls job right away and then once per interval
+func runEvery(ctx context.Context, interval time.Duration, job func(context.Context) error, log *logger.Logger) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		if err := job(ctx); err != nil {
+			log.Error(ctx, "background job failed", zap.Error(err))
+		}
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
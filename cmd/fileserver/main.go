@@ -39,6 +39,11 @@ func main() {
 		storageDir = "./image"
 	}
 
+	privateDir := os.Getenv("FILESERVER_PRIVATE_DIR")
+	if privateDir == "" {
+		privateDir = "./private_image"
+	}
+
 	baseURL := os.Getenv("FILESERVER_BASE_URL")
 	if baseURL == "" {
 		baseURL = "/api/v1/image"
@@ -46,7 +51,7 @@ func main() {
 
 	// Create gRPC server
 	grpcServer := grpc.NewServer()
-	service.RegisterFileServerServer(grpcServer, grpcLogger)
+	service.RegisterFileServerServer(grpcServer, storageDir, privateDir, baseURL, grpcLogger)
 
 	// Start listening
 	lis, err := net.Listen("tcp", ":"+port)
@@ -63,6 +68,7 @@ func main() {
 		grpcLogger.Logger.Info("fileserver gRPC server starting", 
 			zap.String("port", port),
 			zap.String("storage_dir", storageDir),
+			zap.String("private_dir", privateDir),
 			zap.String("base_url", baseURL))
 		
 		if err := grpcServer.Serve(lis); err != nil {
@@ -0,0 +1,69 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"flag"
+	"os"
+	"time"
+
+	"github.com/go-park-mail-ru/2025_2_Avrora/internal/db"
+	"github.com/go-park-mail-ru/2025_2_Avrora/internal/delivery/http/utils"
+	logger "github.com/go-park-mail-ru/2025_2_Avrora/internal/log"
+	"github.com/go-park-mail-ru/2025_2_Avrora/internal/storage"
+	"github.com/go-park-mail-ru/2025_2_Avrora/internal/usecase"
+	_ "github.com/jackc/pgx/v5/stdlib"
+	"go.uber.org/zap"
+)
+
+// Removes image blobs nobody references any more. Meant to be run by cron:
+//
+//	go run ./cmd/imagegc -grace 72h -dry-run
+func main() {
+	grace := flag.Duration("grace", 72*time.Hour, "how long a blob must stay unreferenced before removal")
+	dryRun := flag.Bool("dry-run", false, "only print what would be removed")
+	flag.Parse()
+
+	utils.LoadEnv()
+
+	log, err := zap.NewProduction()
+	if err != nil {
+		panic("failed to create logger")
+	}
+	defer log.Sync()
+
+	appLogger := logger.New(log).With(zap.String("service", "imagegc"))
+
+	storageDir := os.Getenv("FILESERVER_STORAGE_DIR")
+	if storageDir == "" {
+		storageDir = "./image"
+	}
+
+	dbConn, err := db.New(utils.GetPostgresDSN())
+	if err != nil {
+		log.Fatal("failed to connect to database", zap.Error(err))
+	}
+	defer dbConn.Close()
+
+	imageRepo := db.NewImageRepository(dbConn.GetDB(), appLogger)
+	imageUC := usecase.NewImageUsecase(imageRepo, storage.NewLocalStorage(storageDir), 0, appLogger)
+
+	ctx := context.Background()
+	if !*dryRun {
+		// Blobs of expired uploads become eligible for collection
+		if err := imageUC.ExpireUploads(ctx); err != nil {
+			log.Fatal("failed to expire uploads", zap.Error(err))
+		}
+	}
+
+	report, err := imageUC.CollectGarbage(ctx, *grace, *dryRun)
+	if err != nil {
+		log.Fatal("image gc failed", zap.Error(err))
+	}
+
+	enc := json.NewEncoder(os.Stdout)
+	enc.SetIndent("", "  ")
+	if err := enc.Encode(report); err != nil {
+		log.Fatal("failed to write report", zap.Error(err))
+	}
+}
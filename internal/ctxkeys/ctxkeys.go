@@ -0,0 +1,24 @@
+// Package ctxkeys holds the request-scoped values shared between the
+// delivery layer that sets them and the layers below that read them.
+package ctxkeys
+
+import "context"
+
+type contextKey string
+
+const (
+	UserIDKey    contextKey = "userID"
+	RequestIDKey contextKey = "request_id"
+)
+
+// UserID returns the authenticated user of the request, if any
+func UserID(ctx context.Context) (string, bool) {
+	userID, ok := ctx.Value(UserIDKey).(string)
+	return userID, ok
+}
+
+// RequestID returns the id of the request, if any
+func RequestID(ctx context.Context) (string, bool) {
+	requestID, ok := ctx.Value(RequestIDKey).(string)
+	return requestID, ok
+}
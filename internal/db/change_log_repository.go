@@ -0,0 +1,72 @@
+package db
+
+import (
+	"context"
+
+	"github.com/go-park-mail-ru/2025_2_Avrora/internal/ctxkeys"
+	"github.com/go-park-mail-ru/2025_2_Avrora/internal/domain"
+	"github.com/go-park-mail-ru/2025_2_Avrora/internal/log"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+	"go.uber.org/zap"
+)
+
+const (
+	// Read by the log_row_changes trigger; local settings end with the transaction
+	setAuditContextQuery = `
+		SELECT set_config('app.actor_id', $1, true), set_config('app.request_id', $2, true)`
+
+	listChangeLogQuery = `
+		SELECT id, entity_type, entity_id, action, actor_id, request_id, changes, created_at
+		FROM change_log
+		WHERE ($1::change_entity_enum IS NULL OR entity_type = $1)
+		  AND ($2::UUID IS NULL OR entity_id = $2)
+		  AND ($3::UUID IS NULL OR actor_id = $3)
+		  AND ($4::TEXT IS NULL OR request_id = $4)
+		ORDER BY created_at DESC, id
+		LIMIT $5 OFFSET $6`
+)
+
+// auditedTx runs fn in a transaction tagged with the user and request id from
+// ctx, so that the change log knows who made the writes
+func auditedTx(ctx context.Context, db *pgxpool.Pool, fn func(tx pgx.Tx) error) error {
+	actorID, _ := ctxkeys.UserID(ctx)
+	requestID, _ := ctxkeys.RequestID(ctx)
+
+	return pgx.BeginFunc(ctx, db, func(tx pgx.Tx) error {
+		if _, err := tx.Exec(ctx, setAuditContextQuery, actorID, requestID); err != nil {
+			return err
+		}
+		return fn(tx)
+	})
+}
+
+type ChangeLogRepository struct {
+	db  *pgxpool.Pool
+	log *log.Logger
+}
+
+func NewChangeLogRepository(db *pgxpool.Pool, log *log.Logger) *ChangeLogRepository {
+	return &ChangeLogRepository{db: db, log: log}
+}
+
+// List returns matching entries, newest first
+func (r *ChangeLogRepository) List(ctx context.Context, f *domain.ChangeLogFilter, limit, offset int) ([]domain.ChangeLogEntry, error) {
+	rows, err := r.db.Query(ctx, listChangeLogQuery, f.EntityType, f.EntityID, f.ActorID, f.RequestID, limit, offset)
+	if err != nil {
+		r.log.Error(ctx, "failed to list change log", zap.Error(err))
+		return nil, err
+	}
+	defer rows.Close()
+
+	var entries []domain.ChangeLogEntry
+	for rows.Next() {
+		var e domain.ChangeLogEntry
+		if err := rows.Scan(&e.ID, &e.EntityType, &e.EntityID, &e.Action, &e.ActorID, &e.RequestID, &e.Changes, &e.CreatedAt); err != nil {
+			r.log.Error(ctx, "failed to scan change log entry", zap.Error(err))
+			return nil, err
+		}
+		entries = append(entries, e)
+	}
+	return entries, rows.Err()
+}
@@ -0,0 +1,343 @@
+package db
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+
+	"github.com/go-park-mail-ru/2025_2_Avrora/internal/domain"
+	"github.com/jackc/pgx/v5"
+	"go.uber.org/zap"
+)
+
+const (
+	listComplexBuildingsQuery = `
+		SELECT id, complex_id, name, phase, floors, stage, handover_year, handover_quarter, created_at, updated_at
+		FROM complex_building
+		WHERE complex_id = $1
+		ORDER BY phase, name`
+
+	getComplexBuildingQuery = `
+		SELECT id, complex_id, name, phase, floors, stage, handover_year, handover_quarter, created_at, updated_at
+		FROM complex_building
+		WHERE complex_id = $1 AND id = $2`
+
+	addComplexBuildingQuery = `
+		INSERT INTO complex_building (complex_id, name, phase, floors, stage, handover_year, handover_quarter)
+		VALUES ($1, $2, $3, $4, $5, $6, $7)
+		RETURNING id, created_at, updated_at`
+
+	updateComplexBuildingQuery = `
+		UPDATE complex_building SET
+			name = $3, phase = $4, floors = $5, stage = $6, handover_year = $7, handover_quarter = $8
+		WHERE complex_id = $1 AND id = $2
+		RETURNING created_at, updated_at`
+
+	deleteComplexBuildingQuery = `DELETE FROM complex_building WHERE complex_id = $1 AND id = $2`
+
+	listComplexLayoutsQuery = `
+		SELECT id, complex_id, building_id, rooms, area, price_min, price_max, image_url, created_at, updated_at
+		FROM complex_layout
+		WHERE complex_id = $1
+		ORDER BY rooms, area, price_min NULLS LAST`
+
+	getComplexLayoutQuery = `
+		SELECT id, complex_id, building_id, rooms, area, price_min, price_max, image_url, created_at, updated_at
+		FROM complex_layout
+		WHERE complex_id = $1 AND id = $2`
+
+	addComplexLayoutQuery = `
+		INSERT INTO complex_layout (complex_id, building_id, rooms, area, price_min, price_max, image_url)
+		VALUES ($1, $2, $3, $4, $5, $6, $7)
+		RETURNING id, created_at, updated_at`
+
+	updateComplexLayoutQuery = `
+		UPDATE complex_layout SET
+			building_id = $3, rooms = $4, area = $5, price_min = $6, price_max = $7, image_url = $8
+		WHERE complex_id = $1 AND id = $2
+		RETURNING created_at, updated_at`
+
+	deleteComplexLayoutQuery = `DELETE FROM complex_layout WHERE complex_id = $1 AND id = $2`
+
+	listComplexAmenitiesQuery = `
+		SELECT amenity FROM complex_amenity WHERE complex_id = $1 ORDER BY amenity`
+
+	deleteMissingAmenitiesQuery = `
+		DELETE FROM complex_amenity
+		WHERE complex_id = $1 AND NOT (amenity = ANY($2::complex_amenity_enum[]))`
+
+	insertAmenitiesQuery = `
+		INSERT INTO complex_amenity (complex_id, amenity)
+		SELECT $1, UNNEST($2::complex_amenity_enum[])
+		ON CONFLICT DO NOTHING`
+
+	// Totals per offer type first, then per rooms group; flats and houses
+	// only, other property types have no place among the layouts
+	complexOfferStatsQuery = `
+		SELECT offer_type, LEAST(rooms, $2) AS rooms_group, COUNT(*),
+		       MIN(price), MAX(price), ROUND(AVG(price / area))::FLOAT8
+		FROM offer
+		WHERE housing_complex_id = $1 AND status = 'active'
+		  AND property_type IN ('apartment', 'house')
+		GROUP BY GROUPING SETS ((offer_type), (offer_type, rooms_group))
+		ORDER BY offer_type, rooms_group NULLS FIRST`
+)
+
+// loadDetails fills the buildings, layouts and amenities of the complex
+func (r *HousingComplexRepository) loadDetails(ctx context.Context, c *domain.HousingComplex) error {
+	var err error
+	if c.Buildings, err = r.ListBuildings(ctx, c.ID); err != nil {
+		return err
+	}
+	if c.Layouts, err = r.ListLayouts(ctx, c.ID); err != nil {
+		return err
+	}
+
+	rows, err := r.db.Query(ctx, listComplexAmenitiesQuery, c.ID)
+	if err != nil {
+		r.log.Error(ctx, "failed to list complex amenities", zap.String("complex_id", c.ID), zap.Error(err))
+		return err
+	}
+	defer rows.Close()
+
+	c.Amenities = nil
+	for rows.Next() {
+		var a domain.ComplexAmenity
+		if err := rows.Scan(&a); err != nil {
+			r.log.Error(ctx, "failed to scan complex amenity", zap.Error(err))
+			return err
+		}
+		c.Amenities = append(c.Amenities, a)
+	}
+	return rows.Err()
+}
+
+func scanComplexBuilding(row pgx.Row, b *domain.ComplexBuilding) error {
+	return row.Scan(
+		&b.ID,
+		&b.ComplexID,
+		&b.Name,
+		&b.Phase,
+		&b.Floors,
+		&b.Stage,
+		&b.HandoverYear,
+		&b.HandoverQuarter,
+		&b.CreatedAt,
+		&b.UpdatedAt,
+	)
+}
+
+// ListBuildings returns the buildings of the complex by phase
+func (r *HousingComplexRepository) ListBuildings(ctx context.Context, complexID string) ([]domain.ComplexBuilding, error) {
+	rows, err := r.db.Query(ctx, listComplexBuildingsQuery, complexID)
+	if err != nil {
+		r.log.Error(ctx, "failed to list complex buildings", zap.String("complex_id", complexID), zap.Error(err))
+		return nil, err
+	}
+	defer rows.Close()
+
+	var buildings []domain.ComplexBuilding
+	for rows.Next() {
+		var b domain.ComplexBuilding
+		if err := scanComplexBuilding(rows, &b); err != nil {
+			r.log.Error(ctx, "failed to scan complex building", zap.Error(err))
+			return nil, err
+		}
+		buildings = append(buildings, b)
+	}
+	return buildings, rows.Err()
+}
+
+func (r *HousingComplexRepository) GetBuilding(ctx context.Context, complexID, buildingID string) (*domain.ComplexBuilding, error) {
+	var b domain.ComplexBuilding
+	if err := scanComplexBuilding(r.db.QueryRow(ctx, getComplexBuildingQuery, complexID, buildingID), &b); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, domain.ErrBuildingNotFound
+		}
+		r.log.Error(ctx, "failed to get complex building", zap.String("building_id", buildingID), zap.Error(err))
+		return nil, err
+	}
+	return &b, nil
+}
+
+func (r *HousingComplexRepository) AddBuilding(ctx context.Context, b *domain.ComplexBuilding) error {
+	err := r.db.QueryRow(ctx, addComplexBuildingQuery,
+		b.ComplexID, b.Name, b.Phase, b.Floors, b.Stage, b.HandoverYear, b.HandoverQuarter,
+	).Scan(&b.ID, &b.CreatedAt, &b.UpdatedAt)
+	if err != nil {
+		r.log.Error(ctx, "failed to add complex building", zap.String("complex_id", b.ComplexID), zap.Error(err))
+		return err
+	}
+	return nil
+}
+
+// UpdateBuilding replaces every field of the building
+func (r *HousingComplexRepository) UpdateBuilding(ctx context.Context, b *domain.ComplexBuilding) error {
+	err := r.db.QueryRow(ctx, updateComplexBuildingQuery,
+		b.ComplexID, b.ID, b.Name, b.Phase, b.Floors, b.Stage, b.HandoverYear, b.HandoverQuarter,
+	).Scan(&b.CreatedAt, &b.UpdatedAt)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return domain.ErrBuildingNotFound
+		}
+		r.log.Error(ctx, "failed to update complex building", zap.String("building_id", b.ID), zap.Error(err))
+		return err
+	}
+	return nil
+}
+
+// DeleteBuilding removes the building; its layouts stay as offered in all buildings
+func (r *HousingComplexRepository) DeleteBuilding(ctx context.Context, complexID, buildingID string) error {
+	tag, err := r.db.Exec(ctx, deleteComplexBuildingQuery, complexID, buildingID)
+	if err != nil {
+		r.log.Error(ctx, "failed to delete complex building", zap.String("building_id", buildingID), zap.Error(err))
+		return err
+	}
+	if tag.RowsAffected() == 0 {
+		return domain.ErrBuildingNotFound
+	}
+	return nil
+}
+
+func scanComplexLayout(row pgx.Row, l *domain.ComplexLayout) error {
+	return row.Scan(
+		&l.ID,
+		&l.ComplexID,
+		&l.BuildingID,
+		&l.Rooms,
+		&l.Area,
+		&l.PriceMin,
+		&l.PriceMax,
+		&l.ImageURL,
+		&l.CreatedAt,
+		&l.UpdatedAt,
+	)
+}
+
+// ListLayouts returns the layouts of the complex by rooms and area
+func (r *HousingComplexRepository) ListLayouts(ctx context.Context, complexID string) ([]domain.ComplexLayout, error) {
+	rows, err := r.db.Query(ctx, listComplexLayoutsQuery, complexID)
+	if err != nil {
+		r.log.Error(ctx, "failed to list complex layouts", zap.String("complex_id", complexID), zap.Error(err))
+		return nil, err
+	}
+	defer rows.Close()
+
+	var layouts []domain.ComplexLayout
+	for rows.Next() {
+		var l domain.ComplexLayout
+		if err := scanComplexLayout(rows, &l); err != nil {
+			r.log.Error(ctx, "failed to scan complex layout", zap.Error(err))
+			return nil, err
+		}
+		layouts = append(layouts, l)
+	}
+	return layouts, rows.Err()
+}
+
+func (r *HousingComplexRepository) GetLayout(ctx context.Context, complexID, layoutID string) (*domain.ComplexLayout, error) {
+	var l domain.ComplexLayout
+	if err := scanComplexLayout(r.db.QueryRow(ctx, getComplexLayoutQuery, complexID, layoutID), &l); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, domain.ErrLayoutNotFound
+		}
+		r.log.Error(ctx, "failed to get complex layout", zap.String("layout_id", layoutID), zap.Error(err))
+		return nil, err
+	}
+	return &l, nil
+}
+
+func (r *HousingComplexRepository) AddLayout(ctx context.Context, userID string, l *domain.ComplexLayout) error {
+	return auditedTx(ctx, r.db, func(tx pgx.Tx) error {
+		err := tx.QueryRow(ctx, addComplexLayoutQuery,
+			l.ComplexID, l.BuildingID, l.Rooms, l.Area, l.PriceMin, l.PriceMax, l.ImageURL,
+		).Scan(&l.ID, &l.CreatedAt, &l.UpdatedAt)
+		if err != nil {
+			r.log.Error(ctx, "failed to add complex layout", zap.String("complex_id", l.ComplexID), zap.Error(err))
+			return err
+		}
+		return r.attachLayoutPlan(ctx, tx, userID, l)
+	})
+}
+
+// attachLayoutPlan attaches the upload of the floor plan, if any
+func (r *HousingComplexRepository) attachLayoutPlan(ctx context.Context, tx pgx.Tx, userID string, l *domain.ComplexLayout) error {
+	if l.ImageURL == nil {
+		return nil
+	}
+	if err := attachUploads(ctx, tx, userID, []string{*l.ImageURL}); err != nil {
+		r.log.Error(ctx, "failed to attach layout plan", zap.String("layout_id", l.ID), zap.Error(err))
+		return err
+	}
+	return nil
+}
+
+// UpdateLayout replaces every field of the layout
+func (r *HousingComplexRepository) UpdateLayout(ctx context.Context, userID string, l *domain.ComplexLayout) error {
+	return auditedTx(ctx, r.db, func(tx pgx.Tx) error {
+		err := tx.QueryRow(ctx, updateComplexLayoutQuery,
+			l.ComplexID, l.ID, l.BuildingID, l.Rooms, l.Area, l.PriceMin, l.PriceMax, l.ImageURL,
+		).Scan(&l.CreatedAt, &l.UpdatedAt)
+		if err != nil {
+			if errors.Is(err, sql.ErrNoRows) {
+				return domain.ErrLayoutNotFound
+			}
+			r.log.Error(ctx, "failed to update complex layout", zap.String("layout_id", l.ID), zap.Error(err))
+			return err
+		}
+		return r.attachLayoutPlan(ctx, tx, userID, l)
+	})
+}
+
+func (r *HousingComplexRepository) DeleteLayout(ctx context.Context, complexID, layoutID string) error {
+	tag, err := r.db.Exec(ctx, deleteComplexLayoutQuery, complexID, layoutID)
+	if err != nil {
+		r.log.Error(ctx, "failed to delete complex layout", zap.String("layout_id", layoutID), zap.Error(err))
+		return err
+	}
+	if tag.RowsAffected() == 0 {
+		return domain.ErrLayoutNotFound
+	}
+	return nil
+}
+
+// SetAmenities replaces the amenities of the complex
+func (r *HousingComplexRepository) SetAmenities(ctx context.Context, complexID string, amenities []domain.ComplexAmenity) error {
+	values := make([]string, len(amenities))
+	for i, a := range amenities {
+		values[i] = string(a)
+	}
+
+	return auditedTx(ctx, r.db, func(tx pgx.Tx) error {
+		if _, err := tx.Exec(ctx, deleteMissingAmenitiesQuery, complexID, values); err != nil {
+			r.log.Error(ctx, "failed to remove complex amenities", zap.String("complex_id", complexID), zap.Error(err))
+			return err
+		}
+		if _, err := tx.Exec(ctx, insertAmenitiesQuery, complexID, values); err != nil {
+			r.log.Error(ctx, "failed to add complex amenities", zap.String("complex_id", complexID), zap.Error(err))
+			return err
+		}
+		return nil
+	})
+}
+
+// OfferStats aggregates the active offers of the complex
+func (r *HousingComplexRepository) OfferStats(ctx context.Context, complexID string) ([]domain.ComplexOfferStats, error) {
+	rows, err := r.db.Query(ctx, complexOfferStatsQuery, complexID, domain.MaxRoomsGroup)
+	if err != nil {
+		r.log.Error(ctx, "failed to get complex offer stats", zap.String("complex_id", complexID), zap.Error(err))
+		return nil, err
+	}
+	defer rows.Close()
+
+	var stats []domain.ComplexOfferStats
+	for rows.Next() {
+		var s domain.ComplexOfferStats
+		if err := rows.Scan(&s.OfferType, &s.Rooms, &s.Offers, &s.PriceMin, &s.PriceMax, &s.AvgPricePerSqm); err != nil {
+			r.log.Error(ctx, "failed to scan complex offer stats", zap.Error(err))
+			return nil, err
+		}
+		stats = append(stats, s)
+	}
+	return stats, rows.Err()
+}
@@ -4,13 +4,14 @@ import (
 	"context"
 	"database/sql"
 	"errors"
+	"fmt"
+	"strings"
 	"time"
 
 	"github.com/go-park-mail-ru/2025_2_Avrora/internal/domain"
 	"github.com/go-park-mail-ru/2025_2_Avrora/internal/log"
 	"github.com/jackc/pgx/v5"
 	"github.com/jackc/pgx/v5/pgxpool"
-	"github.com/lib/pq"
 	"go.uber.org/zap"
 )
 
@@ -20,18 +21,52 @@ const (
 	// GetByID: full complex + later load photos
 	getComplexByIDQuery = `
 		SELECT 
-			id, name, description, year_built, location_id, developer,
-			address, starting_price, created_at, updated_at
-		FROM housing_complex
-		WHERE id = $1`
+			hc.id, hc.name, hc.description, hc.year_built, hc.location_id, hc.developer_id, d.name,
+			hc.address, complex_starting_price(hc.id), hc.created_at, hc.updated_at, hc.version
+		FROM housing_complex hc
+		LEFT JOIN developer d ON d.id = hc.developer_id
+		WHERE hc.id = $1`
 
-	// List: optimized for feed (with metro, 1 image, total count)
+	// Shared by the feed and its count. Starting price, completion and the
+	// distance to the $9, $10 point are derived per complex; NULL filters match all.
+	complexFeedFromQuery = `
+		FROM housing_complex hc
+		JOIN location l ON l.id = hc.location_id
+		LEFT JOIN developer dev ON dev.id = hc.developer_id
+		CROSS JOIN LATERAL (SELECT complex_starting_price(hc.id) AS starting_price) sp
+		CROSS JOIN LATERAL (
+			SELECT 2 * 6371 * ASIN(SQRT(
+				POWER(SIN(RADIANS(l.latitude::float8 - $9::float8) / 2), 2)
+				+ COS(RADIANS($9::float8)) * COS(RADIANS(l.latitude::float8))
+				* POWER(SIN(RADIANS(l.longitude::float8 - $10::float8) / 2), 2)
+			)) AS km -- NULL without a point or coordinates
+		) d
+		WHERE ($1::UUID IS NULL OR l.region_id IN (
+				WITH RECURSIVE sub_region AS (
+					SELECT id FROM region WHERE id = $1
+					UNION
+					SELECT r.id FROM region r JOIN sub_region s ON r.parent_id = s.id
+				)
+				SELECT id FROM sub_region))
+		  AND ($2::UUID IS NULL OR EXISTS (
+				SELECT 1 FROM location_metro lm
+				WHERE lm.location_id = hc.location_id AND lm.metro_station_id = $2))
+		  AND ($3::TEXT IS NULL OR dev.name ILIKE '%' || $3 || '%')
+		  AND ($4::INT IS NULL OR hc.year_built >= $4)
+		  AND ($5::INT IS NULL OR hc.year_built <= $5)
+		  AND ($6::BIGINT IS NULL OR sp.starting_price >= $6)
+		  AND ($7::BIGINT IS NULL OR sp.starting_price <= $7)
+		  AND ($8::BOOLEAN IS NULL OR complex_completed(hc.id, hc.year_built) = $8)
+		  AND ($11::UUID IS NULL OR hc.developer_id = $11)`
+
+	countComplexesInFeedQuery = `SELECT COUNT(*)` + complexFeedFromQuery
+
+	// List: optimized for feed (with metro, cover image)
 	listComplexesInFeedQuery = `
-		WITH total AS (SELECT COUNT(*) AS total_count FROM housing_complex)
 		SELECT
 			hc.id,
 			hc.name,
-			hc.starting_price,
+			sp.starting_price,
 			hc.address,
 			COALESCE(
 				(SELECT ms.name
@@ -46,40 +81,34 @@ const (
 				(SELECT cp.url
 				 FROM complex_photo cp
 				 WHERE cp.complex_id = hc.id
-				 ORDER BY cp.created_at ASC
+				 ORDER BY cp.is_cover DESC, cp.position ASC, cp.created_at ASC
 				 LIMIT 1),
 				''
 			) AS image_url,
+			ROUND(d.km::NUMERIC, 1)::FLOAT8,
 			hc.created_at,
-			hc.updated_at,
-			total.total_count
-		FROM housing_complex hc
-		CROSS JOIN total
-		ORDER BY hc.created_at DESC
-		LIMIT $1 OFFSET $2`
+			hc.updated_at` + complexFeedFromQuery + `
+		ORDER BY
+			CASE WHEN $12::TEXT = 'price_asc' THEN sp.starting_price END ASC NULLS LAST,
+			CASE WHEN $12 = 'price_desc' THEN sp.starting_price END DESC NULLS LAST,
+			CASE WHEN $12 = 'distance' THEN d.km END ASC NULLS LAST,
+			hc.created_at DESC, hc.id
+		LIMIT $13 OFFSET $14`
 
 	createComplexQuery = `
 		INSERT INTO housing_complex (
-			name, description, year_built, location_id,
-			developer, address, starting_price
+			id, name, description, year_built, location_id,
+			developer_id, address
 		) VALUES (
 			$1, $2, $3, $4, $5,
 			$6, $7
 		)`
 
-	createComplexPhotosQuery = `
-		INSERT INTO complex_photo (complex_id, url, created_at, updated_at)
-		SELECT $1, url, $2, $2
-		FROM UNNEST($3::TEXT[]) AS url`
+	// %s is filled with the patched column assignments. A NULL version
+	// skips the check (If-Match: *).
+	updateComplexQueryTmpl = `UPDATE housing_complex SET %s WHERE id = $1 AND ($2::INT IS NULL OR version = $2)`
 
-	updateComplexQuery = `
-		UPDATE housing_complex SET
-			name = $2, description = $3, year_built = $4, location_id = $5,
-			developer = $6, address = $7, starting_price = $8, updated_at = $9
-		WHERE id = $1`
-
-	deleteComplexPhotosQuery = "DELETE FROM complex_photo WHERE complex_id = $1"
-	deleteComplexQuery       = "DELETE FROM housing_complex WHERE id = $1"
+	deleteComplexQuery = "DELETE FROM housing_complex WHERE id = $1 AND ($2::INT IS NULL OR version = $2)"
 )
 
 type HousingComplexRepository struct {
@@ -91,33 +120,55 @@ func NewHousingComplexRepository(db *pgxpool.Pool, log *log.Logger) *HousingComp
 	return &HousingComplexRepository{db: db, log: log}
 }
 
+// complexPatchColumns are the complex columns that may be changed, by JSON name
+var complexPatchColumns = map[string]string{
+	"name":         "name",
+	"description":  "description",
+	"year_built":   "year_built",
+	"location_id":  "location_id",
+	"developer_id": "developer_id",
+	"address":      "address",
+}
+
 // scanComplex scans a row into domain.HousingComplex (without photos)
 func scanComplex(row pgx.Row) (*domain.HousingComplex, error) {
 	var c domain.HousingComplex
 	var yearBuilt *int
 	var startingPrice *int64
+	var description, developer, address *string
 
 	err := row.Scan(
 		&c.ID,
 		&c.Name,
-		&c.Description,
+		&description,
 		&yearBuilt,
 		&c.LocationID,
-		&c.Developer,
-		&c.Address,
+		&c.DeveloperID,
+		&developer,
+		&address,
 		&startingPrice,
 		&c.CreatedAt,
 		&c.UpdatedAt,
+		&c.Version,
 	)
 	if err != nil {
 		return nil, err
 	}
 	c.YearBuilt = yearBuilt
 	c.StartingPrice = startingPrice
+	if description != nil {
+		c.Description = *description
+	}
+	if developer != nil {
+		c.Developer = *developer
+	}
+	if address != nil {
+		c.Address = *address
+	}
 	return &c, nil
 }
 
-// GetByID returns full complex with all photos
+// GetByID returns full complex with its buildings, layouts, amenities and all photos
 func (r *HousingComplexRepository) GetByID(ctx context.Context, id string) (*domain.HousingComplex, error) {
 	complex, err := scanComplex(r.db.QueryRow(ctx, getComplexByIDQuery, id))
 	if err != nil {
@@ -128,9 +179,13 @@ func (r *HousingComplexRepository) GetByID(ctx context.Context, id string) (*dom
 		return nil, err
 	}
 
+	if err := r.loadDetails(ctx, complex); err != nil {
+		return nil, err
+	}
+
 	// Load all photos
 	rows, err := r.db.Query(ctx,
-		"SELECT url FROM complex_photo WHERE complex_id = $1 ORDER BY created_at",
+		"SELECT url FROM complex_photo WHERE complex_id = $1 ORDER BY position, created_at",
 		id)
 	if err != nil {
 		r.log.Warn(ctx, "failed to load photos", zap.String("id", id), zap.Error(err))
@@ -151,23 +206,38 @@ func (r *HousingComplexRepository) GetByID(ctx context.Context, id string) (*dom
 	return complex, nil
 }
 
-// List returns complexes in feed format with pagination metadata
-func (r *HousingComplexRepository) List(ctx context.Context, page, limit int) (*domain.ComplexesInFeed, error) {
+// List returns the complexes matching the filter in feed format with the
+// total number of matches
+func (r *HousingComplexRepository) List(ctx context.Context, f *domain.ComplexFilter, page, limit int) (*domain.ComplexesInFeed, error) {
 	offset := (page - 1) * limit
 
-	rows, err := r.db.Query(ctx, listComplexesInFeedQuery, limit, offset)
+	var completed *bool
+	if f.Completion != "" {
+		v := f.Completion == domain.ComplexCompleted
+		completed = &v
+	}
+	args := []any{
+		f.RegionID, f.MetroStationID, f.Developer,
+		f.YearBuiltMin, f.YearBuiltMax, f.PriceMin, f.PriceMax,
+		completed, f.Lat, f.Lon, f.DeveloperID,
+	}
+
+	var total int
+	if err := r.db.QueryRow(ctx, countComplexesInFeedQuery, args...).Scan(&total); err != nil {
+		r.log.Error(ctx, "failed to count complexes in feed", zap.Error(err))
+		return nil, err
+	}
+
+	rows, err := r.db.Query(ctx, listComplexesInFeedQuery, append(args, string(f.Sort), limit, offset)...)
 	if err != nil {
 		r.log.Error(ctx, "failed to list complexes in feed", zap.Error(err))
 		return nil, err
 	}
 	defer rows.Close()
 
-	var complexes []domain.ComplexInFeed
-	var totalCount int
-
+	complexes := []domain.ComplexInFeed{}
 	for rows.Next() {
 		var c domain.ComplexInFeed
-		var total int
 		err := rows.Scan(
 			&c.ID,
 			&c.Name,
@@ -175,17 +245,14 @@ func (r *HousingComplexRepository) List(ctx context.Context, page, limit int) (*
 			&c.Address,
 			&c.Metro,
 			&c.ImageURL,
+			&c.DistanceKm,
 			&c.CreatedAt,
 			&c.UpdatedAt,
-			&total,
 		)
 		if err != nil {
 			r.log.Error(ctx, "failed to scan complex in feed", zap.Error(err))
 			return nil, err
 		}
-		if totalCount == 0 {
-			totalCount = total
-		}
 		complexes = append(complexes, c)
 	}
 
@@ -197,83 +264,96 @@ func (r *HousingComplexRepository) List(ctx context.Context, page, limit int) (*
 	result := &domain.ComplexesInFeed{
 		Complexes: complexes,
 	}
-	result.Meta.Total = totalCount
+	result.Meta.Total = total
 	result.Meta.Offset = offset
 
 	return result, nil
 }
 
-// Create inserts a new housing complex and its photos (in app-layer transaction)
-func (r *HousingComplexRepository) Create(ctx context.Context, c *domain.HousingComplex) error {
+// Create inserts a new housing complex and its photos in one transaction
+func (r *HousingComplexRepository) Create(ctx context.Context, userID string, c *domain.HousingComplex) error {
 	now := time.Now().UTC()
 	c.CreatedAt = now
 	c.UpdatedAt = now
 
-	_, err := r.db.Exec(ctx, createComplexQuery,
-		c.Name,
-		c.Description,
-		c.YearBuilt,
-		c.LocationID,
-		c.Developer,
-		c.Address,
-		c.StartingPrice,
-	)
-	if err != nil {
-		r.log.Error(ctx, "failed to create housing complex", zap.Error(err))
-		return err
-	}
-
-	// Insert photos in one query
-	if len(c.ImageURLs) > 0 {
-		_, err = r.db.Exec(ctx, createComplexPhotosQuery, c.ID, now, pq.StringArray(c.ImageURLs))
+	return auditedTx(ctx, r.db, func(tx pgx.Tx) error {
+		_, err := tx.Exec(ctx, createComplexQuery,
+			c.ID,
+			c.Name,
+			c.Description,
+			c.YearBuilt,
+			c.LocationID,
+			c.DeveloperID,
+			c.Address,
+		)
 		if err != nil {
-			r.log.Warn(ctx, "failed to insert photos", zap.String("complex_id", c.ID), zap.Error(err))
-			// Note: You may want to roll back complex creation — handle in service layer with tx
+			r.log.Error(ctx, "failed to create housing complex", zap.Error(err))
+			return err
 		}
-	}
 
-	r.log.Info(ctx, "created housing complex", zap.String("id", c.ID))
-	return nil
+		if err := syncPhotos(ctx, tx, complexPhotoTable, c.ID, c.ImageURLs, now); err != nil {
+			r.log.Error(ctx, "failed to insert complex photos", zap.String("complex_id", c.ID), zap.Error(err))
+			return err
+		}
+		if err := attachUploads(ctx, tx, userID, c.ImageURLs); err != nil {
+			r.log.Error(ctx, "failed to attach complex images", zap.String("complex_id", c.ID), zap.Error(err))
+			return err
+		}
+
+		r.log.Info(ctx, "created housing complex", zap.String("id", c.ID))
+		return nil
+	})
 }
 
-// Update modifies complex and replaces all photos
-func (r *HousingComplexRepository) Update(ctx context.Context, c *domain.HousingComplex) error {
-	c.UpdatedAt = time.Now().UTC()
-
-	_, err := r.db.Exec(ctx, updateComplexQuery,
-		c.ID,
-		c.Name,
-		c.Description,
-		c.YearBuilt,
-		c.LocationID,
-		c.Developer,
-		c.Address,
-		c.StartingPrice,
-		c.UpdatedAt,
-	)
-	if err != nil {
-		r.log.Error(ctx, "failed to update housing complex", zap.String("id", c.ID), zap.Error(err))
-		return err
-	}
+// Update writes only the fields present in the patch; null clears a column.
+// "image_urls" replaces the photo list, keeping captions and cover of
+// retained photos. With a version the write only applies while the complex
+// is still at it.
+func (r *HousingComplexRepository) Update(ctx context.Context, userID, id string, patch domain.Patch, version *int) error {
+	now := time.Now().UTC()
+	sets, args := patchAssignments(patch, complexPatchColumns, 3)
+	sets = append(sets, fmt.Sprintf("updated_at = $%d", len(args)+3))
+	query := fmt.Sprintf(updateComplexQueryTmpl, strings.Join(sets, ", "))
 
-	// Replace photos
-	_, _ = r.db.Exec(ctx, deleteComplexPhotosQuery, c.ID)
-	if len(c.ImageURLs) > 0 {
-		now := time.Now().UTC()
-		_, _ = r.db.Exec(ctx, createComplexPhotosQuery, c.ID, now, pq.StringArray(c.ImageURLs))
-	}
+	return auditedTx(ctx, r.db, func(tx pgx.Tx) error {
+		tag, err := tx.Exec(ctx, query, append([]any{id, version}, append(args, now)...)...)
+		if err != nil {
+			r.log.Error(ctx, "failed to update housing complex", zap.String("id", id), zap.Error(err))
+			return err
+		}
+		if tag.RowsAffected() == 0 {
+			return notFoundOrStale(version, domain.ErrComplexNotFound)
+		}
+
+		if patch.Has("image_urls") {
+			urls, _ := patch["image_urls"].([]string)
+			if err := syncPhotos(ctx, tx, complexPhotoTable, id, urls, now); err != nil {
+				r.log.Error(ctx, "failed to sync complex photos", zap.String("complex_id", id), zap.Error(err))
+				return err
+			}
+			if err := attachUploads(ctx, tx, userID, urls); err != nil {
+				r.log.Error(ctx, "failed to attach complex images", zap.String("complex_id", id), zap.Error(err))
+				return err
+			}
+		}
 
-	r.log.Info(ctx, "updated housing complex", zap.String("id", c.ID))
-	return nil
+		r.log.Info(ctx, "updated housing complex", zap.String("id", id))
+		return nil
+	})
 }
 
 // Delete removes complex (photos auto-deleted via CASCADE)
-func (r *HousingComplexRepository) Delete(ctx context.Context, id string) error {
-	_, err := r.db.Exec(ctx, deleteComplexQuery, id)
-	if err != nil {
-		r.log.Error(ctx, "failed to delete housing complex", zap.String("id", id), zap.Error(err))
-		return err
-	}
-	r.log.Info(ctx, "deleted housing complex", zap.String("id", id))
-	return nil
+func (r *HousingComplexRepository) Delete(ctx context.Context, id string, version *int) error {
+	return auditedTx(ctx, r.db, func(tx pgx.Tx) error {
+		tag, err := tx.Exec(ctx, deleteComplexQuery, id, version)
+		if err != nil {
+			r.log.Error(ctx, "failed to delete housing complex", zap.String("id", id), zap.Error(err))
+			return err
+		}
+		if tag.RowsAffected() == 0 {
+			return notFoundOrStale(version, domain.ErrComplexNotFound)
+		}
+		r.log.Info(ctx, "deleted housing complex", zap.String("id", id))
+		return nil
+	})
 }
\ No newline at end of file
@@ -0,0 +1,238 @@
+package db
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+
+	"github.com/go-park-mail-ru/2025_2_Avrora/internal/domain"
+	"github.com/go-park-mail-ru/2025_2_Avrora/internal/log"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+	"go.uber.org/zap"
+)
+
+const (
+	// Developer columns with the number of complexes and delivered ones
+	selectDeveloperQuery = `
+		SELECT
+			d.id, d.name, d.description, d.logo_url, d.website, d.phone, d.founded_year,
+			COUNT(hc.id),
+			COUNT(hc.id) FILTER (WHERE complex_completed(hc.id, hc.year_built)),
+			d.created_at, d.updated_at
+		FROM developer d
+		LEFT JOIN housing_complex hc ON hc.developer_id = d.id`
+
+	getDeveloperQuery = selectDeveloperQuery + `
+		WHERE d.id = $1
+		GROUP BY d.id`
+
+	listDevelopersQuery = selectDeveloperQuery + `
+		WHERE ($1::TEXT IS NULL OR d.name ILIKE '%' || $1 || '%')
+		GROUP BY d.id
+		ORDER BY d.name, d.id
+		LIMIT $2 OFFSET $3`
+
+	// Names are unique up to case; a clash inserts nothing
+	createDeveloperQuery = `
+		INSERT INTO developer (name, description, logo_url, website, phone, founded_year)
+		VALUES ($1, $2, $3, $4, $5, $6)
+		ON CONFLICT DO NOTHING
+		RETURNING id, created_at, updated_at`
+
+	developerNameTakenQuery = `
+		SELECT EXISTS (SELECT 1 FROM developer WHERE LOWER(name) = LOWER($2) AND id <> $1)`
+
+	updateDeveloperQuery = `
+		UPDATE developer SET
+			name = $2, description = $3, logo_url = $4, website = $5, phone = $6, founded_year = $7
+		WHERE id = $1
+		RETURNING updated_at`
+
+	getMemberDeveloperQuery = `SELECT developer_id FROM developer_member WHERE user_id = $1`
+
+	// A user acts for one developer, so linking moves them
+	addDeveloperMemberQuery = `
+		INSERT INTO developer_member (developer_id, user_id)
+		VALUES ($1, $2)
+		ON CONFLICT (user_id) DO UPDATE SET developer_id = EXCLUDED.developer_id, created_at = NOW()`
+
+	// Staff keep their role
+	grantDeveloperRoleQuery = `
+		UPDATE users SET role = 'developer'
+		WHERE id = $1 AND role NOT IN ('moderator', 'admin')`
+
+	removeDeveloperMemberQuery = `DELETE FROM developer_member WHERE developer_id = $1 AND user_id = $2`
+
+	revokeDeveloperRoleQuery = `UPDATE users SET role = 'user' WHERE id = $1 AND role = 'developer'`
+)
+
+type DeveloperRepository struct {
+	db  *pgxpool.Pool
+	log *log.Logger
+}
+
+func NewDeveloperRepository(db *pgxpool.Pool, log *log.Logger) *DeveloperRepository {
+	return &DeveloperRepository{db: db, log: log}
+}
+
+func scanDeveloper(row pgx.Row, d *domain.Developer) error {
+	var description *string
+	err := row.Scan(
+		&d.ID,
+		&d.Name,
+		&description,
+		&d.LogoURL,
+		&d.Website,
+		&d.Phone,
+		&d.FoundedYear,
+		&d.Complexes,
+		&d.Delivered,
+		&d.CreatedAt,
+		&d.UpdatedAt,
+	)
+	if description != nil {
+		d.Description = *description
+	}
+	return err
+}
+
+func (r *DeveloperRepository) GetByID(ctx context.Context, id string) (*domain.Developer, error) {
+	var d domain.Developer
+	if err := scanDeveloper(r.db.QueryRow(ctx, getDeveloperQuery, id), &d); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, domain.ErrDeveloperNotFound
+		}
+		r.log.Error(ctx, "failed to get developer", zap.String("id", id), zap.Error(err))
+		return nil, err
+	}
+	return &d, nil
+}
+
+// List returns developers by name; query narrows them to names containing it
+func (r *DeveloperRepository) List(ctx context.Context, query *string, limit, offset int) ([]domain.Developer, error) {
+	rows, err := r.db.Query(ctx, listDevelopersQuery, query, limit, offset)
+	if err != nil {
+		r.log.Error(ctx, "failed to list developers", zap.Error(err))
+		return nil, err
+	}
+	defer rows.Close()
+
+	var developers []domain.Developer
+	for rows.Next() {
+		var d domain.Developer
+		if err := scanDeveloper(rows, &d); err != nil {
+			r.log.Error(ctx, "failed to scan developer", zap.Error(err))
+			return nil, err
+		}
+		developers = append(developers, d)
+	}
+	return developers, rows.Err()
+}
+
+func (r *DeveloperRepository) Create(ctx context.Context, userID string, d *domain.Developer) error {
+	return auditedTx(ctx, r.db, func(tx pgx.Tx) error {
+		err := tx.QueryRow(ctx, createDeveloperQuery,
+			d.Name, d.Description, d.LogoURL, d.Website, d.Phone, d.FoundedYear,
+		).Scan(&d.ID, &d.CreatedAt, &d.UpdatedAt)
+		if err != nil {
+			if errors.Is(err, sql.ErrNoRows) {
+				return domain.ErrDeveloperExists
+			}
+			r.log.Error(ctx, "failed to create developer", zap.Error(err))
+			return err
+		}
+		if err := r.attachLogo(ctx, tx, userID, d); err != nil {
+			return err
+		}
+		r.log.Info(ctx, "created developer", zap.String("id", d.ID))
+		return nil
+	})
+}
+
+// attachLogo attaches the upload of the logo, if any
+func (r *DeveloperRepository) attachLogo(ctx context.Context, tx pgx.Tx, userID string, d *domain.Developer) error {
+	if d.LogoURL == nil {
+		return nil
+	}
+	if err := attachUploads(ctx, tx, userID, []string{*d.LogoURL}); err != nil {
+		r.log.Error(ctx, "failed to attach developer logo", zap.String("id", d.ID), zap.Error(err))
+		return err
+	}
+	return nil
+}
+
+// Update replaces the profile of the developer
+func (r *DeveloperRepository) Update(ctx context.Context, userID string, d *domain.Developer) error {
+	var taken bool
+	if err := r.db.QueryRow(ctx, developerNameTakenQuery, d.ID, d.Name).Scan(&taken); err != nil {
+		r.log.Error(ctx, "failed to check developer name", zap.String("id", d.ID), zap.Error(err))
+		return err
+	}
+	if taken {
+		return domain.ErrDeveloperExists
+	}
+
+	return auditedTx(ctx, r.db, func(tx pgx.Tx) error {
+		err := tx.QueryRow(ctx, updateDeveloperQuery,
+			d.ID, d.Name, d.Description, d.LogoURL, d.Website, d.Phone, d.FoundedYear,
+		).Scan(&d.UpdatedAt)
+		if err != nil {
+			if errors.Is(err, sql.ErrNoRows) {
+				return domain.ErrDeveloperNotFound
+			}
+			r.log.Error(ctx, "failed to update developer", zap.String("id", d.ID), zap.Error(err))
+			return err
+		}
+		return r.attachLogo(ctx, tx, userID, d)
+	})
+}
+
+// MemberDeveloperID returns the developer the user acts for
+func (r *DeveloperRepository) MemberDeveloperID(ctx context.Context, userID string) (string, error) {
+	var id string
+	if err := r.db.QueryRow(ctx, getMemberDeveloperQuery, userID).Scan(&id); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return "", domain.ErrDeveloperNotFound
+		}
+		r.log.Error(ctx, "failed to get developer of user", zap.String("user_id", userID), zap.Error(err))
+		return "", err
+	}
+	return id, nil
+}
+
+// AddMember links the user to the developer and grants the developer role
+func (r *DeveloperRepository) AddMember(ctx context.Context, developerID, userID string) error {
+	return auditedTx(ctx, r.db, func(tx pgx.Tx) error {
+		if _, err := tx.Exec(ctx, addDeveloperMemberQuery, developerID, userID); err != nil {
+			r.log.Error(ctx, "failed to add developer member", zap.String("developer_id", developerID), zap.Error(err))
+			return err
+		}
+		if _, err := tx.Exec(ctx, grantDeveloperRoleQuery, userID); err != nil {
+			r.log.Error(ctx, "failed to grant developer role", zap.String("user_id", userID), zap.Error(err))
+			return err
+		}
+		r.log.Info(ctx, "added developer member", zap.String("developer_id", developerID), zap.String("user_id", userID))
+		return nil
+	})
+}
+
+// RemoveMember unlinks the user and takes the developer role back
+func (r *DeveloperRepository) RemoveMember(ctx context.Context, developerID, userID string) error {
+	return auditedTx(ctx, r.db, func(tx pgx.Tx) error {
+		tag, err := tx.Exec(ctx, removeDeveloperMemberQuery, developerID, userID)
+		if err != nil {
+			r.log.Error(ctx, "failed to remove developer member", zap.String("developer_id", developerID), zap.Error(err))
+			return err
+		}
+		if tag.RowsAffected() == 0 {
+			return domain.ErrUserNotFound
+		}
+		if _, err := tx.Exec(ctx, revokeDeveloperRoleQuery, userID); err != nil {
+			r.log.Error(ctx, "failed to revoke developer role", zap.String("user_id", userID), zap.Error(err))
+			return err
+		}
+		r.log.Info(ctx, "removed developer member", zap.String("developer_id", developerID), zap.String("user_id", userID))
+		return nil
+	})
+}
@@ -0,0 +1,323 @@
+package db
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"time"
+
+	"github.com/go-park-mail-ru/2025_2_Avrora/internal/domain"
+	"github.com/go-park-mail-ru/2025_2_Avrora/internal/log"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+	"go.uber.org/zap"
+)
+
+const (
+	// Re-uploading a blob that is waiting for GC restarts its grace period
+	registerImageBlobQuery = `
+		INSERT INTO image_blob (hash, filename, content_type, size_bytes, phash, ref_count, unreferenced_since)
+		VALUES ($1, $2, $3, $4, $5, count_image_refs($2), NOW())
+		ON CONFLICT (hash) DO UPDATE SET
+			phash = COALESCE(image_blob.phash, EXCLUDED.phash),
+			unreferenced_since = CASE
+				WHEN image_blob.ref_count = 0 THEN NOW()
+				ELSE image_blob.unreferenced_since
+			END
+		RETURNING ref_count, created_at, updated_at`
+
+	listUnreferencedImageBlobsQuery = `
+		SELECT hash, filename, COALESCE(content_type, ''), size_bytes, ref_count,
+			unreferenced_since, created_at, updated_at
+		FROM image_blob
+		WHERE ref_count = 0
+		  AND unreferenced_since < $1
+		  AND count_image_refs(filename) = 0
+		  AND NOT EXISTS (
+			SELECT 1 FROM image_upload iu
+			WHERE iu.blob_hash = image_blob.hash AND iu.state = 'pending'
+		  )
+		ORDER BY unreferenced_since ASC`
+
+	// Re-checks references so that a blob attached or re-uploaded after listing
+	// is never removed; the row lock holds both off until the file is gone
+	lockUnreferencedImageBlobQuery = `
+		SELECT filename FROM image_blob
+		WHERE hash = $1
+		  AND ref_count = 0
+		  AND count_image_refs(filename) = 0
+		  AND NOT EXISTS (
+			SELECT 1 FROM image_upload iu
+			WHERE iu.blob_hash = image_blob.hash AND iu.state = 'pending'
+		  )
+		FOR UPDATE`
+
+	deleteImageBlobQuery = `DELETE FROM image_blob WHERE hash = $1`
+
+	createImageUploadQuery = `
+		INSERT INTO image_upload (user_id, blob_hash, filename, url, expires_at)
+		VALUES ($1, $2, $3, $4, $5)
+		RETURNING id, state, created_at, updated_at`
+
+	listUnownedImagesQuery = `
+		SELECT u.url
+		FROM UNNEST($2::TEXT[]) AS u(url)
+		WHERE NOT EXISTS (
+			SELECT 1
+			FROM image_upload iu
+			WHERE iu.user_id = $1
+			  AND iu.filename = image_filename_from_url(u.url)
+			  AND iu.state IN ('pending', 'attached')
+		)`
+
+	// Attaches the pending uploads and counts the images whose upload by the
+	// user expired before it could be attached. Images the user never
+	// uploaded, like those kept from an earlier edit by someone else, are
+	// checked by the usecase and not counted.
+	attachImageUploadsQuery = `
+		WITH wanted AS (
+			SELECT DISTINCT image_filename_from_url(url) AS filename
+			FROM UNNEST($2::TEXT[]) AS url
+		), attached AS (
+			UPDATE image_upload
+			SET state = 'attached', attached_at = NOW()
+			WHERE user_id = $1
+			  AND state = 'pending'
+			  AND filename IN (SELECT filename FROM wanted)
+			RETURNING filename
+		)
+		SELECT COUNT(*)
+		FROM wanted w
+		WHERE NOT EXISTS (SELECT 1 FROM attached a WHERE a.filename = w.filename)
+		  AND NOT EXISTS (
+			SELECT 1 FROM image_upload iu
+			WHERE iu.user_id = $1 AND iu.filename = w.filename AND iu.state = 'attached'
+		  )
+		  AND EXISTS (
+			SELECT 1 FROM image_upload iu
+			WHERE iu.user_id = $1 AND iu.filename = w.filename AND iu.state = 'expired'
+		  )`
+
+	expireImageUploadsQuery = `
+		UPDATE image_upload
+		SET state = 'expired'
+		WHERE state = 'pending' AND expires_at < $1`
+
+	createPrivateFileQuery = `
+		INSERT INTO private_file (user_id, filename, original_name, content_type, size_bytes)
+		VALUES ($1, $2, $3, $4, $5)
+		RETURNING id, created_at, updated_at`
+
+	getPrivateFileQuery = `
+		SELECT id, user_id, filename, COALESCE(original_name, ''), COALESCE(content_type, ''),
+			size_bytes, created_at, updated_at
+		FROM private_file
+		WHERE filename = $1`
+)
+
+type ImageRepository struct {
+	db  *pgxpool.Pool
+	log *log.Logger
+}
+
+func NewImageRepository(db *pgxpool.Pool, log *log.Logger) *ImageRepository {
+	return &ImageRepository{db: db, log: log}
+}
+
+// RegisterBlob records an uploaded blob; uploading the same content twice is a no-op
+func (r *ImageRepository) RegisterBlob(ctx context.Context, blob *domain.ImageBlob) error {
+	err := r.db.QueryRow(ctx, registerImageBlobQuery,
+		blob.Hash,
+		blob.Filename,
+		blob.ContentType,
+		blob.SizeBytes,
+		phashToDB(blob.PHash),
+	).Scan(&blob.RefCount, &blob.CreatedAt, &blob.UpdatedAt)
+	if err != nil {
+		r.log.Error(ctx, "failed to register image blob", zap.String("hash", blob.Hash), zap.Error(err))
+		return err
+	}
+	return nil
+}
+
+// phashToDB stores the unsigned hash bit-for-bit in a BIGINT; 0 means unknown
+func phashToDB(phash uint64) *int64 {
+	if phash == 0 {
+		return nil
+	}
+	v := int64(phash)
+	return &v
+}
+
+// ListUnreferenced returns blobs that have had no references since before olderThan
+func (r *ImageRepository) ListUnreferenced(ctx context.Context, olderThan time.Time) ([]domain.ImageBlob, error) {
+	rows, err := r.db.Query(ctx, listUnreferencedImageBlobsQuery, olderThan)
+	if err != nil {
+		r.log.Error(ctx, "failed to list unreferenced image blobs", zap.Error(err))
+		return nil, err
+	}
+	defer rows.Close()
+
+	var blobs []domain.ImageBlob
+	for rows.Next() {
+		var b domain.ImageBlob
+		if err := rows.Scan(
+			&b.Hash,
+			&b.Filename,
+			&b.ContentType,
+			&b.SizeBytes,
+			&b.RefCount,
+			&b.UnreferencedSince,
+			&b.CreatedAt,
+			&b.UpdatedAt,
+		); err != nil {
+			r.log.Error(ctx, "failed to scan image blob", zap.Error(err))
+			return nil, err
+		}
+		blobs = append(blobs, b)
+	}
+	return blobs, rows.Err()
+}
+
+// DeleteUnreferenced removes the blob row if it is still unreferenced, calling
+// remove on its file while the row is locked; a failed remove keeps the row.
+// Returns false when the blob got referenced again in the meantime.
+func (r *ImageRepository) DeleteUnreferenced(ctx context.Context, hash string, remove func(filename string) error) (bool, error) {
+	deleted := false
+	err := pgx.BeginFunc(ctx, r.db, func(tx pgx.Tx) error {
+		var filename string
+		if err := tx.QueryRow(ctx, lockUnreferencedImageBlobQuery, hash).Scan(&filename); err != nil {
+			if errors.Is(err, sql.ErrNoRows) {
+				return nil
+			}
+			r.log.Error(ctx, "failed to lock image blob", zap.String("hash", hash), zap.Error(err))
+			return err
+		}
+
+		if err := remove(filename); err != nil {
+			return err
+		}
+
+		if _, err := tx.Exec(ctx, deleteImageBlobQuery, hash); err != nil {
+			r.log.Error(ctx, "failed to delete image blob", zap.String("hash", hash), zap.Error(err))
+			return err
+		}
+		deleted = true
+		return nil
+	})
+	if err != nil {
+		return false, err
+	}
+	return deleted, nil
+}
+
+// CreateUpload records who uploaded a blob and until when it may be attached
+func (r *ImageRepository) CreateUpload(ctx context.Context, u *domain.ImageUpload) error {
+	err := r.db.QueryRow(ctx, createImageUploadQuery,
+		u.UserID,
+		u.BlobHash,
+		u.Filename,
+		u.URL,
+		u.ExpiresAt,
+	).Scan(&u.ID, &u.State, &u.CreatedAt, &u.UpdatedAt)
+	if err != nil {
+		r.log.Error(ctx, "failed to create image upload", zap.String("user_id", u.UserID), zap.Error(err))
+		return err
+	}
+	return nil
+}
+
+// ListUnowned returns the urls the user has no live (pending or attached) upload for
+func (r *ImageRepository) ListUnowned(ctx context.Context, userID string, urls []string) ([]string, error) {
+	if len(urls) == 0 {
+		return nil, nil
+	}
+
+	rows, err := r.db.Query(ctx, listUnownedImagesQuery, userID, urls)
+	if err != nil {
+		r.log.Error(ctx, "failed to check image ownership", zap.String("user_id", userID), zap.Error(err))
+		return nil, err
+	}
+	defer rows.Close()
+
+	var unowned []string
+	for rows.Next() {
+		var url string
+		if err := rows.Scan(&url); err != nil {
+			return nil, err
+		}
+		unowned = append(unowned, url)
+	}
+	return unowned, rows.Err()
+}
+
+// errNoUploader is a caller bug: attaching needs the user who uploaded
+var errNoUploader = errors.New("attach uploads: no user ID")
+
+// attachUploads marks the pending uploads of these urls by the user as
+// attached. It runs in the transaction that stores the references, so an
+// image is never referenced by a row while its upload can still expire.
+// Returns ErrImageNotOwned if an upload expired after the usecase checked it.
+func attachUploads(ctx context.Context, tx pgx.Tx, userID string, urls []string) error {
+	if len(urls) == 0 {
+		return nil
+	}
+	if userID == "" {
+		return errNoUploader
+	}
+	var expired int
+	if err := tx.QueryRow(ctx, attachImageUploadsQuery, userID, urls).Scan(&expired); err != nil {
+		return err
+	}
+	if expired > 0 {
+		return domain.ErrImageNotOwned
+	}
+	return nil
+}
+
+// ExpireUploads moves pending uploads past their deadline to the expired state
+func (r *ImageRepository) ExpireUploads(ctx context.Context, now time.Time) (int64, error) {
+	tag, err := r.db.Exec(ctx, expireImageUploadsQuery, now)
+	if err != nil {
+		r.log.Error(ctx, "failed to expire image uploads", zap.Error(err))
+		return 0, err
+	}
+	return tag.RowsAffected(), nil
+}
+
+func (r *ImageRepository) CreatePrivateFile(ctx context.Context, f *domain.PrivateFile) error {
+	err := r.db.QueryRow(ctx, createPrivateFileQuery,
+		f.UserID,
+		f.Filename,
+		f.OriginalName,
+		f.ContentType,
+		f.SizeBytes,
+	).Scan(&f.ID, &f.CreatedAt, &f.UpdatedAt)
+	if err != nil {
+		r.log.Error(ctx, "failed to create private file", zap.String("user_id", f.UserID), zap.Error(err))
+		return err
+	}
+	return nil
+}
+
+func (r *ImageRepository) GetPrivateFile(ctx context.Context, filename string) (*domain.PrivateFile, error) {
+	var f domain.PrivateFile
+	err := r.db.QueryRow(ctx, getPrivateFileQuery, filename).Scan(
+		&f.ID,
+		&f.UserID,
+		&f.Filename,
+		&f.OriginalName,
+		&f.ContentType,
+		&f.SizeBytes,
+		&f.CreatedAt,
+		&f.UpdatedAt,
+	)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, domain.ErrImageNotFound
+		}
+		r.log.Error(ctx, "failed to get private file", zap.String("filename", filename), zap.Error(err))
+		return nil, err
+	}
+	return &f, nil
+}
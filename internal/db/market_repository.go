@@ -0,0 +1,139 @@
+package db
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+
+	"github.com/go-park-mail-ru/2025_2_Avrora/internal/domain"
+	"github.com/go-park-mail-ru/2025_2_Avrora/internal/log"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+	"go.uber.org/zap"
+)
+
+const (
+	refreshMarketPriceStatsQuery      = `REFRESH MATERIALIZED VIEW CONCURRENTLY market_price_stats`
+	refreshMarketPriceStatsRoomsQuery = `REFRESH MATERIALIZED VIEW CONCURRENTLY market_price_stats_rooms`
+
+	// All rooms first, then by rooms
+	listMarketStatsQuery = `
+		SELECT scope, scope_id, offer_type, property_type, rooms, p25, median, p75, listings, refreshed_at
+		FROM (
+			SELECT scope, scope_id, offer_type, property_type, NULL::INT AS rooms,
+			       p25, median, p75, listings, refreshed_at
+			FROM market_price_stats
+			UNION ALL
+			SELECT scope, scope_id, offer_type, property_type, rooms,
+			       p25, median, p75, listings, refreshed_at
+			FROM market_price_stats_rooms
+		) s
+		WHERE s.scope = $1 AND s.scope_id = $2
+		  AND ($3::offer_type_enum IS NULL OR s.offer_type = $3)
+		  AND ($4::property_type_enum IS NULL OR s.property_type = $4)
+		ORDER BY s.offer_type, s.property_type, s.rooms NULLS FIRST`
+
+	// The narrowest scope with enough listings: the complex, the nearest
+	// metro station, then the region of the offer location. Computed live
+	// rather than from the statistics so the rated offer is not its own peer.
+	getPeerMarketStatsQuery = `
+		WITH scopes AS (
+			SELECT 'complex'::TEXT AS scope, $5::UUID AS scope_id, 0 AS rank
+			UNION ALL
+			SELECT 'metro', (
+				SELECT metro_station_id FROM location_metro
+				WHERE location_id = $6
+				ORDER BY distance_meters ASC
+				LIMIT 1), 1
+			UNION ALL
+			SELECT 'region', (SELECT region_id FROM location WHERE id = $6), 2
+		)
+		SELECT s.scope, s.scope_id, $1::offer_type_enum, $2::property_type_enum, $3::INT,
+		       percentile_cont(0.25) WITHIN GROUP (ORDER BY m.price_per_sqm),
+		       percentile_cont(0.5) WITHIN GROUP (ORDER BY m.price_per_sqm),
+		       percentile_cont(0.75) WITHIN GROUP (ORDER BY m.price_per_sqm),
+		       COUNT(*)::INT, NOW()
+		FROM scopes s
+		JOIN market_offer_scope m ON m.scope = s.scope AND m.scope_id = s.scope_id
+		WHERE m.offer_type = $1 AND m.property_type = $2 AND m.rooms = $3
+		  AND m.offer_id <> $7
+		GROUP BY s.scope, s.scope_id, s.rank
+		HAVING COUNT(*) >= $4
+		ORDER BY s.rank
+		LIMIT 1`
+)
+
+type MarketRepository struct {
+	db  *pgxpool.Pool
+	log *log.Logger
+}
+
+func NewMarketRepository(db *pgxpool.Pool, log *log.Logger) *MarketRepository {
+	return &MarketRepository{db: db, log: log}
+}
+
+// Refresh recomputes the statistics without blocking readers
+func (r *MarketRepository) Refresh(ctx context.Context) error {
+	for _, query := range []string{refreshMarketPriceStatsQuery, refreshMarketPriceStatsRoomsQuery} {
+		if _, err := r.db.Exec(ctx, query); err != nil {
+			r.log.Error(ctx, "failed to refresh market stats", zap.String("query", query), zap.Error(err))
+			return err
+		}
+	}
+	return nil
+}
+
+func (r *MarketRepository) List(ctx context.Context, q *domain.MarketStatsQuery) ([]domain.MarketStats, error) {
+	rows, err := r.db.Query(ctx, listMarketStatsQuery, q.Scope, q.ScopeID, q.OfferType, q.PropertyType)
+	if err != nil {
+		r.log.Error(ctx, "failed to list market stats",
+			zap.String("scope", string(q.Scope)), zap.String("scope_id", q.ScopeID), zap.Error(err))
+		return nil, err
+	}
+	defer rows.Close()
+
+	var stats []domain.MarketStats
+	for rows.Next() {
+		var s domain.MarketStats
+		if err := scanMarketStats(rows, &s); err != nil {
+			r.log.Error(ctx, "failed to scan market stats", zap.Error(err))
+			return nil, err
+		}
+		stats = append(stats, s)
+	}
+	return stats, rows.Err()
+}
+
+// PeerStats returns the statistics of offers like the given one, itself
+// excluded, with at least minListings of them, or nil when no scope has that
+// many
+func (r *MarketRepository) PeerStats(ctx context.Context, offer *domain.Offer, minListings int) (*domain.MarketStats, error) {
+	var s domain.MarketStats
+	err := scanMarketStats(r.db.QueryRow(ctx, getPeerMarketStatsQuery,
+		offer.OfferType, offer.PropertyType, domain.RoomsGroup(offer.Rooms), minListings,
+		offer.HousingComplexID, offer.LocationID, offer.ID,
+	), &s)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, nil
+		}
+		r.log.Error(ctx, "failed to get peer market stats", zap.String("offer_id", offer.ID), zap.Error(err))
+		return nil, err
+	}
+	return &s, nil
+}
+
+func scanMarketStats(row pgx.Row, s *domain.MarketStats) error {
+	return row.Scan(
+		&s.Scope,
+		&s.ScopeID,
+		&s.OfferType,
+		&s.PropertyType,
+		&s.Rooms,
+		&s.P25,
+		&s.Median,
+		&s.P75,
+		&s.Listings,
+		&s.RefreshedAt,
+	)
+}
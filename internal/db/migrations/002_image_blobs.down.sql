@@ -0,0 +1,11 @@
+DROP TRIGGER IF EXISTS trigger_track_profile_avatar_refs ON profile;
+DROP TRIGGER IF EXISTS trigger_track_complex_photo_refs ON complex_photo;
+DROP TRIGGER IF EXISTS trigger_track_offer_photo_refs ON offer_photo;
+
+DROP FUNCTION IF EXISTS track_avatar_image_refs();
+DROP FUNCTION IF EXISTS track_photo_image_refs();
+DROP FUNCTION IF EXISTS count_image_refs(TEXT);
+DROP FUNCTION IF EXISTS adjust_image_blob_refs(TEXT, INT);
+DROP FUNCTION IF EXISTS image_filename_from_url(TEXT);
+
+DROP TABLE IF EXISTS image_blob;
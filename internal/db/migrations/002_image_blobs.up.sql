@@ -0,0 +1,89 @@
+-- Content-addressed image storage: one row per unique file, keyed by SHA-256 of its bytes
+CREATE TABLE image_blob (
+    hash TEXT PRIMARY KEY CHECK (hash ~ '^[0-9a-f]{64}$'),
+    filename TEXT NOT NULL UNIQUE CHECK (LENGTH(filename) <= 255),
+    content_type TEXT CHECK (LENGTH(content_type) <= 255),
+    size_bytes BIGINT NOT NULL CHECK (size_bytes >= 0),
+    ref_count INT NOT NULL DEFAULT 0 CHECK (ref_count >= 0),
+    unreferenced_since TIMESTAMPTZ DEFAULT NOW(),
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    updated_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+CREATE TRIGGER set_updated_at_image_blob
+    BEFORE UPDATE ON image_blob
+    FOR EACH ROW EXECUTE FUNCTION update_updated_at_column();
+
+CREATE INDEX idx_image_blob_unreferenced
+    ON image_blob (unreferenced_since)
+    WHERE ref_count = 0;
+
+-- "http://host/api/v1/image/abc.jpg?x=1" -> "abc.jpg"
+CREATE OR REPLACE FUNCTION image_filename_from_url(image_url TEXT)
+RETURNS TEXT AS $$
+    SELECT regexp_replace(split_part(image_url, '?', 1), '^.*/', '');
+$$ LANGUAGE sql IMMUTABLE;
+
+CREATE OR REPLACE FUNCTION adjust_image_blob_refs(image_url TEXT, delta INT)
+RETURNS VOID AS $$
+    UPDATE image_blob
+    SET ref_count = GREATEST(ref_count + delta, 0),
+        unreferenced_since = CASE
+            WHEN ref_count + delta <= 0 THEN COALESCE(unreferenced_since, NOW())
+            ELSE NULL
+        END
+    WHERE image_url IS NOT NULL
+      AND filename = image_filename_from_url(image_url);
+$$ LANGUAGE sql;
+
+-- Live reference count, used by the garbage collector as a safety net
+CREATE OR REPLACE FUNCTION count_image_refs(blob_filename TEXT)
+RETURNS BIGINT AS $$
+    SELECT
+        (SELECT COUNT(*) FROM offer_photo WHERE image_filename_from_url(url) = blob_filename) +
+        (SELECT COUNT(*) FROM complex_photo WHERE image_filename_from_url(url) = blob_filename) +
+        (SELECT COUNT(*) FROM profile WHERE image_filename_from_url(avatar_url) = blob_filename);
+$$ LANGUAGE sql STABLE;
+
+-- offer_photo / complex_photo reference tracking
+CREATE OR REPLACE FUNCTION track_photo_image_refs()
+RETURNS TRIGGER AS $$
+BEGIN
+    IF TG_OP = 'INSERT' THEN
+        PERFORM adjust_image_blob_refs(NEW.url, 1);
+    ELSIF TG_OP = 'DELETE' THEN
+        PERFORM adjust_image_blob_refs(OLD.url, -1);
+    ELSIF OLD.url IS DISTINCT FROM NEW.url THEN
+        PERFORM adjust_image_blob_refs(OLD.url, -1);
+        PERFORM adjust_image_blob_refs(NEW.url, 1);
+    END IF;
+    RETURN NULL;
+END;
+$$ LANGUAGE plpgsql;
+
+CREATE TRIGGER trigger_track_offer_photo_refs
+    AFTER INSERT OR UPDATE OF url OR DELETE ON offer_photo
+    FOR EACH ROW EXECUTE FUNCTION track_photo_image_refs();
+
+CREATE TRIGGER trigger_track_complex_photo_refs
+    AFTER INSERT OR UPDATE OF url OR DELETE ON complex_photo
+    FOR EACH ROW EXECUTE FUNCTION track_photo_image_refs();
+
+-- profile.avatar_url reference tracking
+CREATE OR REPLACE FUNCTION track_avatar_image_refs()
+RETURNS TRIGGER AS $$
+BEGIN
+    IF TG_OP = 'INSERT' THEN
+        PERFORM adjust_image_blob_refs(NEW.avatar_url, 1);
+    ELSIF TG_OP = 'DELETE' THEN
+        PERFORM adjust_image_blob_refs(OLD.avatar_url, -1);
+    ELSIF OLD.avatar_url IS DISTINCT FROM NEW.avatar_url THEN
+        PERFORM adjust_image_blob_refs(OLD.avatar_url, -1);
+        PERFORM adjust_image_blob_refs(NEW.avatar_url, 1);
+    END IF;
+    RETURN NULL;
+END;
+$$ LANGUAGE plpgsql;
+
+CREATE TRIGGER trigger_track_profile_avatar_refs
+    AFTER INSERT OR UPDATE OF avatar_url OR DELETE ON profile
+    FOR EACH ROW EXECUTE FUNCTION track_avatar_image_refs();
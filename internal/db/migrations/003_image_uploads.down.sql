@@ -0,0 +1,3 @@
+DROP TRIGGER IF EXISTS set_updated_at_image_upload ON image_upload;
+DROP TABLE IF EXISTS image_upload;
+DROP TYPE IF EXISTS image_upload_state_enum;
@@ -0,0 +1,23 @@
+CREATE TYPE image_upload_state_enum AS ENUM ('pending', 'attached', 'expired');
+
+-- Who uploaded which image; an image may only be attached by its uploader
+CREATE TABLE image_upload (
+    id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+    user_id UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    blob_hash TEXT NOT NULL REFERENCES image_blob(hash) ON DELETE CASCADE,
+    filename TEXT NOT NULL CHECK (LENGTH(filename) <= 255),
+    url TEXT NOT NULL CHECK (LENGTH(url) <= 1024),
+    state image_upload_state_enum NOT NULL DEFAULT 'pending',
+    expires_at TIMESTAMPTZ NOT NULL,
+    attached_at TIMESTAMPTZ,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    updated_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+CREATE TRIGGER set_updated_at_image_upload
+    BEFORE UPDATE ON image_upload
+    FOR EACH ROW EXECUTE FUNCTION update_updated_at_column();
+
+CREATE INDEX idx_image_upload_owner ON image_upload (user_id, filename);
+CREATE INDEX idx_image_upload_pending_expiry
+    ON image_upload (expires_at)
+    WHERE state = 'pending';
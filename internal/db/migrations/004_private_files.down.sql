@@ -0,0 +1,2 @@
+DROP TRIGGER IF EXISTS set_updated_at_private_file ON private_file;
+DROP TABLE IF EXISTS private_file;
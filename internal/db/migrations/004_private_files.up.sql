@@ -0,0 +1,17 @@
+-- Private documents (ownership papers, passport scans) live in a separate
+-- bucket and are only reachable through signed, expiring URLs
+CREATE TABLE private_file (
+    id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+    user_id UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    filename TEXT NOT NULL UNIQUE CHECK (LENGTH(filename) <= 255),
+    original_name TEXT CHECK (LENGTH(original_name) <= 255),
+    content_type TEXT CHECK (LENGTH(content_type) <= 255),
+    size_bytes BIGINT NOT NULL CHECK (size_bytes >= 0),
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    updated_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+CREATE TRIGGER set_updated_at_private_file
+    BEFORE UPDATE ON private_file
+    FOR EACH ROW EXECUTE FUNCTION update_updated_at_column();
+
+CREATE INDEX idx_private_file_user ON private_file (user_id);
@@ -0,0 +1,18 @@
+DROP INDEX IF EXISTS idx_complex_photo_position;
+DROP INDEX IF EXISTS idx_offer_photo_position;
+DROP INDEX IF EXISTS uq_complex_photo_cover;
+DROP INDEX IF EXISTS uq_offer_photo_cover;
+
+ALTER TABLE complex_photo
+    DROP COLUMN IF EXISTS room_tag,
+    DROP COLUMN IF EXISTS caption,
+    DROP COLUMN IF EXISTS is_cover,
+    DROP COLUMN IF EXISTS position;
+
+ALTER TABLE offer_photo
+    DROP COLUMN IF EXISTS room_tag,
+    DROP COLUMN IF EXISTS caption,
+    DROP COLUMN IF EXISTS is_cover,
+    DROP COLUMN IF EXISTS position;
+
+DROP TYPE IF EXISTS photo_room_tag_enum;
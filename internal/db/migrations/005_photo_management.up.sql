@@ -0,0 +1,39 @@
+-- Explicit ordering, cover photo, captions and room tags for listing photos
+CREATE TYPE photo_room_tag_enum AS ENUM (
+    'living_room', 'bedroom', 'kitchen', 'bathroom', 'hallway',
+    'balcony', 'exterior', 'view', 'floor_plan', 'other'
+);
+
+ALTER TABLE offer_photo
+    ADD COLUMN position INT NOT NULL DEFAULT 0 CHECK (position >= 0),
+    ADD COLUMN is_cover BOOLEAN NOT NULL DEFAULT FALSE,
+    ADD COLUMN caption TEXT CHECK (LENGTH(caption) <= 500),
+    ADD COLUMN room_tag photo_room_tag_enum;
+
+ALTER TABLE complex_photo
+    ADD COLUMN position INT NOT NULL DEFAULT 0 CHECK (position >= 0),
+    ADD COLUMN is_cover BOOLEAN NOT NULL DEFAULT FALSE,
+    ADD COLUMN caption TEXT CHECK (LENGTH(caption) <= 500),
+    ADD COLUMN room_tag photo_room_tag_enum;
+
+-- Existing photos keep their upload order; the oldest one becomes the cover
+UPDATE offer_photo p
+SET position = ranked.rn - 1, is_cover = (ranked.rn = 1)
+FROM (
+    SELECT id, ROW_NUMBER() OVER (PARTITION BY offer_id ORDER BY created_at, id) AS rn
+    FROM offer_photo
+) ranked
+WHERE ranked.id = p.id;
+
+UPDATE complex_photo p
+SET position = ranked.rn - 1, is_cover = (ranked.rn = 1)
+FROM (
+    SELECT id, ROW_NUMBER() OVER (PARTITION BY complex_id ORDER BY created_at, id) AS rn
+    FROM complex_photo
+) ranked
+WHERE ranked.id = p.id;
+
+CREATE UNIQUE INDEX uq_offer_photo_cover ON offer_photo (offer_id) WHERE is_cover;
+CREATE UNIQUE INDEX uq_complex_photo_cover ON complex_photo (complex_id) WHERE is_cover;
+CREATE INDEX idx_offer_photo_position ON offer_photo (offer_id, position);
+CREATE INDEX idx_complex_photo_position ON complex_photo (complex_id, position);
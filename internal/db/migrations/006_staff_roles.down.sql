@@ -0,0 +1,9 @@
+-- Postgres cannot drop enum values; demote staff and rebuild the type instead
+UPDATE users SET role = 'user' WHERE role IN ('moderator', 'admin');
+
+ALTER TABLE users ALTER COLUMN role DROP DEFAULT;
+ALTER TYPE user_role_enum RENAME TO user_role_enum_old;
+CREATE TYPE user_role_enum AS ENUM ('user', 'owner', 'realtor');
+ALTER TABLE users ALTER COLUMN role TYPE user_role_enum USING role::TEXT::user_role_enum;
+ALTER TABLE users ALTER COLUMN role SET DEFAULT 'user';
+DROP TYPE user_role_enum_old;
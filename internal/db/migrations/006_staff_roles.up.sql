@@ -0,0 +1,3 @@
+-- Kept separate: a new enum value cannot be used in the transaction that adds it
+ALTER TYPE user_role_enum ADD VALUE IF NOT EXISTS 'moderator';
+ALTER TYPE user_role_enum ADD VALUE IF NOT EXISTS 'admin';
@@ -0,0 +1,9 @@
+DROP TABLE IF EXISTS offer_duplicate_flag;
+DROP TYPE IF EXISTS duplicate_flag_status_enum;
+
+DROP TRIGGER IF EXISTS fill_offer_photo_phash ON offer_photo;
+DROP FUNCTION IF EXISTS fill_offer_photo_phash();
+DROP INDEX IF EXISTS idx_offer_photo_phash;
+
+ALTER TABLE offer_photo DROP COLUMN IF EXISTS phash;
+ALTER TABLE image_blob DROP COLUMN IF EXISTS phash;
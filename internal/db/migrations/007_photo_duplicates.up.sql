@@ -0,0 +1,47 @@
+-- 64-bit perceptual hash (dHash) computed by the file server; NULL when the
+-- file could not be decoded or was uploaded before hashing existed
+ALTER TABLE image_blob ADD COLUMN phash BIGINT;
+ALTER TABLE offer_photo ADD COLUMN phash BIGINT;
+
+-- Offer photos copy the hash of their blob whichever code path inserts them
+CREATE OR REPLACE FUNCTION fill_offer_photo_phash()
+RETURNS TRIGGER AS $$
+BEGIN
+    IF TG_OP = 'INSERT' OR NEW.url IS DISTINCT FROM OLD.url THEN
+        SELECT b.phash INTO NEW.phash
+        FROM image_blob b
+        WHERE b.filename = image_filename_from_url(NEW.url);
+    END IF;
+    RETURN NEW;
+END;
+$$ LANGUAGE plpgsql;
+
+CREATE TRIGGER fill_offer_photo_phash
+    BEFORE INSERT OR UPDATE OF url ON offer_photo
+    FOR EACH ROW EXECUTE FUNCTION fill_offer_photo_phash();
+
+CREATE INDEX idx_offer_photo_phash ON offer_photo (phash) WHERE phash IS NOT NULL;
+
+CREATE TYPE duplicate_flag_status_enum AS ENUM ('open', 'dismissed', 'confirmed');
+
+-- A photo of offer_id looks like a photo of another user's matched_offer_id
+CREATE TABLE offer_duplicate_flag (
+    id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+    offer_id UUID NOT NULL REFERENCES offer(id) ON DELETE CASCADE,
+    matched_offer_id UUID NOT NULL REFERENCES offer(id) ON DELETE CASCADE,
+    photo_id UUID NOT NULL REFERENCES offer_photo(id) ON DELETE CASCADE,
+    matched_photo_id UUID NOT NULL REFERENCES offer_photo(id) ON DELETE CASCADE,
+    distance INT NOT NULL CHECK (distance BETWEEN 0 AND 64),
+    status duplicate_flag_status_enum NOT NULL DEFAULT 'open',
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    updated_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    UNIQUE (photo_id, matched_photo_id),
+    CHECK (offer_id <> matched_offer_id)
+);
+CREATE TRIGGER set_updated_at_offer_duplicate_flag
+    BEFORE UPDATE ON offer_duplicate_flag
+    FOR EACH ROW EXECUTE FUNCTION update_updated_at_column();
+
+CREATE INDEX idx_offer_duplicate_flag_open ON offer_duplicate_flag (created_at DESC) WHERE status = 'open';
+CREATE INDEX idx_offer_duplicate_flag_offer ON offer_duplicate_flag (offer_id);
+CREATE INDEX idx_offer_duplicate_flag_matched ON offer_duplicate_flag (matched_offer_id);
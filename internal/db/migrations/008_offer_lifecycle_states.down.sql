@@ -0,0 +1,10 @@
+-- Postgres cannot drop enum values; map new states onto the old ones and rebuild the type
+UPDATE offer SET status = 'archived' WHERE status IN ('draft', 'moderation', 'expired');
+UPDATE offer SET status = 'active' WHERE status = 'paused';
+
+ALTER TABLE offer ALTER COLUMN status DROP DEFAULT;
+ALTER TYPE offer_status_enum RENAME TO offer_status_enum_old;
+CREATE TYPE offer_status_enum AS ENUM ('active', 'sold', 'archived');
+ALTER TABLE offer ALTER COLUMN status TYPE offer_status_enum USING status::TEXT::offer_status_enum;
+ALTER TABLE offer ALTER COLUMN status SET DEFAULT 'active';
+DROP TYPE offer_status_enum_old;
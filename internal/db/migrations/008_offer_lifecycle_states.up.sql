@@ -0,0 +1,5 @@
+-- Kept separate: a new enum value cannot be used in the transaction that adds it
+ALTER TYPE offer_status_enum ADD VALUE IF NOT EXISTS 'draft' BEFORE 'active';
+ALTER TYPE offer_status_enum ADD VALUE IF NOT EXISTS 'moderation' BEFORE 'active';
+ALTER TYPE offer_status_enum ADD VALUE IF NOT EXISTS 'paused' AFTER 'active';
+ALTER TYPE offer_status_enum ADD VALUE IF NOT EXISTS 'expired';
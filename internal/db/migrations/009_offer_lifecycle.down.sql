@@ -0,0 +1,7 @@
+DROP TABLE IF EXISTS offer_status_history;
+DROP INDEX IF EXISTS idx_offer_expiry;
+
+ALTER TABLE offer
+    DROP COLUMN IF EXISTS expires_at,
+    DROP COLUMN IF EXISTS published_at,
+    DROP COLUMN IF EXISTS status_changed_at;
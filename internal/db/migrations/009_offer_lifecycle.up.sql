@@ -0,0 +1,32 @@
+ALTER TABLE offer
+    ADD COLUMN status_changed_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    ADD COLUMN published_at TIMESTAMPTZ,
+    ADD COLUMN expires_at TIMESTAMPTZ;
+
+-- Existing listings count as published at creation and get a fresh period.
+-- The backfill must not bump updated_at.
+ALTER TABLE offer DISABLE TRIGGER set_updated_at_offer;
+UPDATE offer SET
+    status_changed_at = updated_at,
+    published_at = created_at,
+    expires_at = CASE WHEN status = 'active' THEN NOW() + INTERVAL '30 days' END;
+ALTER TABLE offer ENABLE TRIGGER set_updated_at_offer;
+
+CREATE INDEX idx_offer_expiry ON offer (expires_at) WHERE status IN ('active', 'paused');
+
+-- Every status change with who made it and why; from_status is NULL on creation
+CREATE TABLE offer_status_history (
+    id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+    offer_id UUID NOT NULL REFERENCES offer(id) ON DELETE CASCADE,
+    from_status offer_status_enum,
+    to_status offer_status_enum NOT NULL,
+    changed_by UUID REFERENCES users(id) ON DELETE SET NULL, -- NULL for system jobs
+    reason TEXT CHECK (LENGTH(reason) <= 1000),
+    changed_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX idx_offer_status_history_offer ON offer_status_history (offer_id, changed_at);
+
+INSERT INTO offer_status_history (offer_id, from_status, to_status, changed_by, reason, changed_at)
+SELECT id, NULL, status, user_id, 'backfill', created_at
+FROM offer;
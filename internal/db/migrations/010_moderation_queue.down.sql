@@ -0,0 +1,5 @@
+DROP TABLE IF EXISTS moderation_audit;
+DROP TABLE IF EXISTS moderation_item;
+DROP TYPE IF EXISTS moderation_action_enum;
+DROP TYPE IF EXISTS moderation_state_enum;
+DROP TYPE IF EXISTS moderation_kind_enum;
@@ -0,0 +1,40 @@
+CREATE TYPE moderation_kind_enum AS ENUM ('new', 'edit');
+CREATE TYPE moderation_state_enum AS ENUM ('pending', 'approved', 'rejected');
+CREATE TYPE moderation_action_enum AS ENUM ('submitted', 'approved', 'rejected', 'auto_rejected');
+
+-- One review of an offer submission; violations hold the pre-screen result
+CREATE TABLE moderation_item (
+    id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+    offer_id UUID NOT NULL REFERENCES offer(id) ON DELETE CASCADE,
+    kind moderation_kind_enum NOT NULL,
+    state moderation_state_enum NOT NULL DEFAULT 'pending',
+    violations JSONB NOT NULL DEFAULT '[]',
+    submitted_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    decided_by UUID REFERENCES users(id) ON DELETE SET NULL, -- NULL for automatic rejections
+    decided_at TIMESTAMPTZ,
+    reason TEXT CHECK (LENGTH(reason) <= 1000),
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    updated_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+CREATE TRIGGER set_updated_at_moderation_item
+    BEFORE UPDATE ON moderation_item
+    FOR EACH ROW EXECUTE FUNCTION update_updated_at_column();
+
+-- An offer waits in the queue at most once
+CREATE UNIQUE INDEX uq_moderation_item_pending ON moderation_item (offer_id) WHERE state = 'pending';
+CREATE INDEX idx_moderation_item_queue ON moderation_item (submitted_at) WHERE state = 'pending';
+CREATE INDEX idx_moderation_item_offer ON moderation_item (offer_id, submitted_at DESC);
+
+-- Append-only trail of submissions and decisions. Kept after the offer is deleted.
+CREATE TABLE moderation_audit (
+    id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+    item_id UUID REFERENCES moderation_item(id) ON DELETE SET NULL,
+    offer_id UUID NOT NULL,
+    action moderation_action_enum NOT NULL,
+    actor_id UUID REFERENCES users(id) ON DELETE SET NULL, -- NULL for the automatic pre-screen
+    reason TEXT CHECK (LENGTH(reason) <= 1000),
+    violations JSONB NOT NULL DEFAULT '[]',
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX idx_moderation_audit_offer ON moderation_audit (offer_id, created_at);
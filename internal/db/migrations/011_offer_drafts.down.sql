@@ -0,0 +1,12 @@
+CREATE OR REPLACE FUNCTION count_image_refs(blob_filename TEXT)
+RETURNS BIGINT AS $$
+    SELECT
+        (SELECT COUNT(*) FROM offer_photo WHERE image_filename_from_url(url) = blob_filename) +
+        (SELECT COUNT(*) FROM complex_photo WHERE image_filename_from_url(url) = blob_filename) +
+        (SELECT COUNT(*) FROM profile WHERE image_filename_from_url(avatar_url) = blob_filename);
+$$ LANGUAGE sql STABLE;
+
+-- DROP TABLE fires no row triggers; delete the photos first to release their image references
+DELETE FROM offer_draft_photo;
+DROP TABLE IF EXISTS offer_draft_photo;
+DROP TABLE IF EXISTS offer_draft;
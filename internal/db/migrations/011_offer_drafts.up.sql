@@ -0,0 +1,36 @@
+-- Offers being filled in over several sittings. payload holds whatever
+-- fields are set so far; nothing is validated until publishing.
+CREATE TABLE offer_draft (
+    id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+    user_id UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    payload JSONB NOT NULL DEFAULT '{}',
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    updated_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+CREATE TRIGGER set_updated_at_offer_draft
+    BEFORE UPDATE ON offer_draft
+    FOR EACH ROW EXECUTE FUNCTION update_updated_at_column();
+
+CREATE INDEX idx_offer_draft_user ON offer_draft (user_id, updated_at DESC);
+
+-- Draft photos hold image references like offer photos do, so the garbage
+-- collector keeps them while the draft exists
+CREATE TABLE offer_draft_photo (
+    draft_id UUID NOT NULL REFERENCES offer_draft(id) ON DELETE CASCADE,
+    url TEXT NOT NULL CHECK (LENGTH(url) <= 1024),
+    position INT NOT NULL,
+    PRIMARY KEY (draft_id, url)
+);
+
+CREATE TRIGGER trigger_track_offer_draft_photo_refs
+    AFTER INSERT OR UPDATE OF url OR DELETE ON offer_draft_photo
+    FOR EACH ROW EXECUTE FUNCTION track_photo_image_refs();
+
+CREATE OR REPLACE FUNCTION count_image_refs(blob_filename TEXT)
+RETURNS BIGINT AS $$
+    SELECT
+        (SELECT COUNT(*) FROM offer_photo WHERE image_filename_from_url(url) = blob_filename) +
+        (SELECT COUNT(*) FROM complex_photo WHERE image_filename_from_url(url) = blob_filename) +
+        (SELECT COUNT(*) FROM offer_draft_photo WHERE image_filename_from_url(url) = blob_filename) +
+        (SELECT COUNT(*) FROM profile WHERE image_filename_from_url(avatar_url) = blob_filename);
+$$ LANGUAGE sql STABLE;
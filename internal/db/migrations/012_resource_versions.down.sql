@@ -0,0 +1,9 @@
+DROP TRIGGER IF EXISTS bump_version_profile ON profile;
+DROP TRIGGER IF EXISTS bump_version_housing_complex ON housing_complex;
+DROP TRIGGER IF EXISTS bump_version_offer ON offer;
+
+ALTER TABLE profile DROP COLUMN IF EXISTS version;
+ALTER TABLE housing_complex DROP COLUMN IF EXISTS version;
+ALTER TABLE offer DROP COLUMN IF EXISTS version;
+
+DROP FUNCTION IF EXISTS bump_row_version();
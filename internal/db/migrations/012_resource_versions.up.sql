@@ -0,0 +1,24 @@
+-- Row versions for optimistic concurrency: clients send the version they
+-- read back as If-Match, and writes only apply while it is still current.
+-- Every update bumps it, whichever code path makes the change.
+CREATE OR REPLACE FUNCTION bump_row_version()
+RETURNS TRIGGER AS $$
+BEGIN
+    NEW.version = OLD.version + 1;
+    RETURN NEW;
+END;
+$$ LANGUAGE plpgsql;
+
+ALTER TABLE offer ADD COLUMN version INT NOT NULL DEFAULT 1;
+ALTER TABLE housing_complex ADD COLUMN version INT NOT NULL DEFAULT 1;
+ALTER TABLE profile ADD COLUMN version INT NOT NULL DEFAULT 1;
+
+CREATE TRIGGER bump_version_offer
+    BEFORE UPDATE ON offer
+    FOR EACH ROW EXECUTE FUNCTION bump_row_version();
+CREATE TRIGGER bump_version_housing_complex
+    BEFORE UPDATE ON housing_complex
+    FOR EACH ROW EXECUTE FUNCTION bump_row_version();
+CREATE TRIGGER bump_version_profile
+    BEFORE UPDATE ON profile
+    FOR EACH ROW EXECUTE FUNCTION bump_row_version();
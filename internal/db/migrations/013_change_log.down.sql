@@ -0,0 +1,8 @@
+DROP TRIGGER IF EXISTS log_changes_profile ON profile;
+DROP TRIGGER IF EXISTS log_changes_housing_complex ON housing_complex;
+DROP TRIGGER IF EXISTS log_changes_offer ON offer;
+DROP FUNCTION IF EXISTS log_row_changes();
+
+DROP TABLE IF EXISTS change_log;
+DROP TYPE IF EXISTS change_action_enum;
+DROP TYPE IF EXISTS change_entity_enum;
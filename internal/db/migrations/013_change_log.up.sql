@@ -0,0 +1,66 @@
+CREATE TYPE change_entity_enum AS ENUM ('offer', 'housing_complex', 'profile');
+CREATE TYPE change_action_enum AS ENUM ('create', 'update', 'delete');
+
+-- Field-level history of offers, complexes and profiles. Rows are written by
+-- triggers, so every code path is covered; the application tags its
+-- transactions with app.actor_id and app.request_id to say who and why.
+CREATE TABLE change_log (
+    id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+    entity_type change_entity_enum NOT NULL,
+    -- No foreign keys: the history outlives both the entity and the actor
+    entity_id UUID NOT NULL,
+    action change_action_enum NOT NULL,
+    actor_id UUID, -- NULL for background jobs
+    request_id TEXT CHECK (LENGTH(request_id) <= 255),
+    changes JSONB NOT NULL, -- [{"field": ..., "old": ..., "new": ...}]
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX idx_change_log_entity ON change_log (entity_type, entity_id, created_at DESC);
+CREATE INDEX idx_change_log_actor ON change_log (actor_id, created_at DESC) WHERE actor_id IS NOT NULL;
+CREATE INDEX idx_change_log_request ON change_log (request_id) WHERE request_id IS NOT NULL;
+
+-- TG_ARGV[0] is the entity type, TG_ARGV[1] the column identifying the entity
+CREATE OR REPLACE FUNCTION log_row_changes()
+RETURNS TRIGGER AS $$
+DECLARE
+    old_row JSONB := CASE WHEN TG_OP <> 'INSERT' THEN to_jsonb(OLD) END;
+    new_row JSONB := CASE WHEN TG_OP <> 'DELETE' THEN to_jsonb(NEW) END;
+    diff JSONB;
+BEGIN
+    SELECT COALESCE(jsonb_agg(jsonb_build_object(
+               'field', k,
+               'old', COALESCE(old_row -> k, 'null'),
+               'new', COALESCE(new_row -> k, 'null')
+           ) ORDER BY k), '[]')
+    INTO diff
+    FROM jsonb_object_keys(COALESCE(new_row, old_row)) AS k
+    WHERE k NOT IN ('id', 'created_at', 'updated_at', 'version')
+      AND COALESCE(old_row -> k, 'null') IS DISTINCT FROM COALESCE(new_row -> k, 'null');
+
+    IF TG_OP = 'UPDATE' AND diff = '[]' THEN
+        RETURN NULL;
+    END IF;
+
+    INSERT INTO change_log (entity_type, entity_id, action, actor_id, request_id, changes)
+    VALUES (
+        TG_ARGV[0]::change_entity_enum,
+        (COALESCE(new_row, old_row) ->> TG_ARGV[1])::UUID,
+        CASE TG_OP WHEN 'INSERT' THEN 'create' WHEN 'UPDATE' THEN 'update' ELSE 'delete' END::change_action_enum,
+        NULLIF(current_setting('app.actor_id', true), '')::UUID,
+        NULLIF(current_setting('app.request_id', true), ''),
+        diff
+    );
+    RETURN NULL;
+END;
+$$ LANGUAGE plpgsql;
+
+CREATE TRIGGER log_changes_offer
+    AFTER INSERT OR UPDATE OR DELETE ON offer
+    FOR EACH ROW EXECUTE FUNCTION log_row_changes('offer', 'id');
+CREATE TRIGGER log_changes_housing_complex
+    AFTER INSERT OR UPDATE OR DELETE ON housing_complex
+    FOR EACH ROW EXECUTE FUNCTION log_row_changes('housing_complex', 'id');
+CREATE TRIGGER log_changes_profile
+    AFTER INSERT OR UPDATE OR DELETE ON profile
+    FOR EACH ROW EXECUTE FUNCTION log_row_changes('profile', 'user_id');
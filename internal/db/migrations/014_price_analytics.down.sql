@@ -0,0 +1,27 @@
+CREATE OR REPLACE FUNCTION log_offer_price_change()
+RETURNS TRIGGER AS $$
+BEGIN
+    IF OLD.price IS DISTINCT FROM NEW.price THEN
+        INSERT INTO offer_price_history (
+            offer_id,
+            old_price,
+            new_price,
+            changed_at,
+            reason,
+            changed_by
+        ) VALUES (
+            NEW.id,
+            OLD.price,
+            NEW.price,
+            NEW.updated_at,
+            'price_updated',
+            NEW.user_id
+        );
+    END IF;
+    RETURN NEW;
+END;
+$$ LANGUAGE plpgsql;
+
+DROP INDEX IF EXISTS idx_location_region;
+DROP INDEX IF EXISTS idx_location_metro_station;
+DROP INDEX IF EXISTS idx_offer_price_history_offer;
@@ -0,0 +1,30 @@
+-- Price history and trends read the history per offer in time order
+CREATE INDEX idx_offer_price_history_offer ON offer_price_history (offer_id, changed_at);
+CREATE INDEX idx_location_metro_station ON location_metro (metro_station_id);
+CREATE INDEX idx_location_region ON location (region_id);
+
+-- Credit price changes to whoever made them: a moderator or admin edit is
+-- not the owner's. Falls back to the owner outside tagged transactions.
+CREATE OR REPLACE FUNCTION log_offer_price_change()
+RETURNS TRIGGER AS $$
+BEGIN
+    IF OLD.price IS DISTINCT FROM NEW.price THEN
+        INSERT INTO offer_price_history (
+            offer_id,
+            old_price,
+            new_price,
+            changed_at,
+            reason,
+            changed_by
+        ) VALUES (
+            NEW.id,
+            OLD.price,
+            NEW.price,
+            NEW.updated_at,
+            'price_updated',
+            COALESCE(NULLIF(current_setting('app.actor_id', true), '')::UUID, NEW.user_id)
+        );
+    END IF;
+    RETURN NEW;
+END;
+$$ LANGUAGE plpgsql;
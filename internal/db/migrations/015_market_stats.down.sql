@@ -0,0 +1,3 @@
+DROP MATERIALIZED VIEW IF EXISTS market_price_stats_rooms;
+DROP MATERIALIZED VIEW IF EXISTS market_price_stats;
+DROP VIEW IF EXISTS market_offer_scope;
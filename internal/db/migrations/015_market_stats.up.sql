@@ -0,0 +1,75 @@
+-- Every active offer once per scope it belongs to: each region up the
+-- hierarchy, its nearest metro station and its housing complex
+CREATE VIEW market_offer_scope AS
+WITH RECURSIVE region_ancestor AS (
+    SELECT id AS region_id, id AS ancestor_id FROM region
+    UNION
+    SELECT ra.region_id, r.parent_id
+    FROM region_ancestor ra
+    JOIN region r ON r.id = ra.ancestor_id
+    WHERE r.parent_id IS NOT NULL
+),
+listed AS (
+    SELECT
+        o.location_id,
+        o.housing_complex_id,
+        o.offer_type,
+        o.property_type,
+        LEAST(o.rooms, 4) AS rooms, -- 4 stands for 4 and more
+        (o.price / o.area)::float8 AS price_per_sqm
+    FROM offer o
+    WHERE o.status = 'active' AND o.area > 0 AND o.price > 0
+)
+SELECT 'region'::TEXT AS scope, ra.ancestor_id AS scope_id, l.offer_type, l.property_type, l.rooms, l.price_per_sqm
+FROM listed l
+JOIN location loc ON loc.id = l.location_id
+JOIN region_ancestor ra ON ra.region_id = loc.region_id
+UNION ALL
+SELECT 'metro', lm.metro_station_id, l.offer_type, l.property_type, l.rooms, l.price_per_sqm
+FROM listed l
+JOIN (
+    SELECT DISTINCT ON (location_id) location_id, metro_station_id
+    FROM location_metro
+    ORDER BY location_id, distance_meters ASC
+) lm ON lm.location_id = l.location_id
+UNION ALL
+SELECT 'complex', l.housing_complex_id, l.offer_type, l.property_type, l.rooms, l.price_per_sqm
+FROM listed l
+WHERE l.housing_complex_id IS NOT NULL;
+
+-- Price per square metre statistics, refreshed by the application on a
+-- schedule. The unique indexes allow REFRESH ... CONCURRENTLY.
+CREATE MATERIALIZED VIEW market_price_stats AS
+SELECT
+    scope,
+    scope_id,
+    offer_type,
+    property_type,
+    percentile_cont(0.25) WITHIN GROUP (ORDER BY price_per_sqm) AS p25,
+    percentile_cont(0.5) WITHIN GROUP (ORDER BY price_per_sqm) AS median,
+    percentile_cont(0.75) WITHIN GROUP (ORDER BY price_per_sqm) AS p75,
+    COUNT(*)::INT AS listings,
+    NOW() AS refreshed_at
+FROM market_offer_scope
+GROUP BY scope, scope_id, offer_type, property_type;
+
+CREATE UNIQUE INDEX idx_market_price_stats
+    ON market_price_stats (scope, scope_id, offer_type, property_type);
+
+CREATE MATERIALIZED VIEW market_price_stats_rooms AS
+SELECT
+    scope,
+    scope_id,
+    offer_type,
+    property_type,
+    rooms,
+    percentile_cont(0.25) WITHIN GROUP (ORDER BY price_per_sqm) AS p25,
+    percentile_cont(0.5) WITHIN GROUP (ORDER BY price_per_sqm) AS median,
+    percentile_cont(0.75) WITHIN GROUP (ORDER BY price_per_sqm) AS p75,
+    COUNT(*)::INT AS listings,
+    NOW() AS refreshed_at
+FROM market_offer_scope
+GROUP BY scope, scope_id, offer_type, property_type, rooms;
+
+CREATE UNIQUE INDEX idx_market_price_stats_rooms
+    ON market_price_stats_rooms (scope, scope_id, offer_type, property_type, rooms);
@@ -0,0 +1,4 @@
+DROP VIEW IF EXISTS offer_popularity;
+DROP TABLE IF EXISTS offer_viewer;
+DROP TABLE IF EXISTS offer_stats_daily;
+DROP TABLE IF EXISTS offer_favorite;
@@ -0,0 +1,41 @@
+CREATE TABLE offer_favorite (
+    user_id UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    offer_id UUID NOT NULL REFERENCES offer(id) ON DELETE CASCADE,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    PRIMARY KEY (user_id, offer_id)
+);
+
+CREATE INDEX idx_offer_favorite_offer ON offer_favorite (offer_id);
+CREATE INDEX idx_offer_favorite_user ON offer_favorite (user_id, created_at DESC);
+
+-- Per-day counters of offer activity; the individual events are not kept
+CREATE TABLE offer_stats_daily (
+    offer_id UUID NOT NULL REFERENCES offer(id) ON DELETE CASCADE,
+    day DATE NOT NULL,
+    views INT NOT NULL DEFAULT 0 CHECK (views >= 0),
+    unique_viewers INT NOT NULL DEFAULT 0 CHECK (unique_viewers >= 0),
+    favorites INT NOT NULL DEFAULT 0 CHECK (favorites >= 0),
+    contact_reveals INT NOT NULL DEFAULT 0 CHECK (contact_reveals >= 0),
+    PRIMARY KEY (offer_id, day)
+);
+
+CREATE INDEX idx_offer_stats_daily_day ON offer_stats_daily (day);
+
+-- Hashed viewers already counted today, so unique_viewers can be kept
+-- without storing who looked at what for longer than a day
+CREATE TABLE offer_viewer (
+    offer_id UUID NOT NULL REFERENCES offer(id) ON DELETE CASCADE,
+    day DATE NOT NULL,
+    viewer_key TEXT NOT NULL CHECK (LENGTH(viewer_key) <= 64),
+    PRIMARY KEY (offer_id, day, viewer_key)
+);
+
+CREATE INDEX idx_offer_viewer_day ON offer_viewer (day);
+
+-- Feed ranking: activity over the last seven days, weighing a contact
+-- reveal over a favorite over a view
+CREATE VIEW offer_popularity AS
+SELECT offer_id, SUM(unique_viewers + 3 * favorites + 5 * contact_reveals) AS score
+FROM offer_stats_daily
+WHERE day > CURRENT_DATE - 7
+GROUP BY offer_id;
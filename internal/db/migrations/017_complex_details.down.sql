@@ -0,0 +1,24 @@
+ALTER TABLE housing_complex ADD COLUMN starting_price BIGINT CHECK (starting_price >= 0);
+UPDATE housing_complex SET starting_price = complex_starting_price(id);
+
+DROP FUNCTION IF EXISTS complex_starting_price(UUID);
+DROP INDEX IF EXISTS idx_offer_housing_complex;
+
+CREATE OR REPLACE FUNCTION count_image_refs(blob_filename TEXT)
+RETURNS BIGINT AS $$
+    SELECT
+        (SELECT COUNT(*) FROM offer_photo WHERE image_filename_from_url(url) = blob_filename) +
+        (SELECT COUNT(*) FROM complex_photo WHERE image_filename_from_url(url) = blob_filename) +
+        (SELECT COUNT(*) FROM offer_draft_photo WHERE image_filename_from_url(url) = blob_filename) +
+        (SELECT COUNT(*) FROM profile WHERE image_filename_from_url(avatar_url) = blob_filename);
+$$ LANGUAGE sql STABLE;
+
+-- DROP TABLE fires no row triggers; delete the layouts first to release their image references
+DELETE FROM complex_layout;
+DROP TABLE IF EXISTS complex_amenity;
+DROP TABLE IF EXISTS complex_layout;
+DROP TABLE IF EXISTS complex_building;
+DROP FUNCTION IF EXISTS track_layout_image_refs();
+
+DROP TYPE IF EXISTS complex_amenity_enum;
+DROP TYPE IF EXISTS construction_stage_enum;
@@ -0,0 +1,98 @@
+CREATE TYPE construction_stage_enum AS ENUM ('planned', 'foundation', 'frame', 'facade', 'finishing', 'completed');
+CREATE TYPE complex_amenity_enum AS ENUM (
+    'parking', 'underground_parking', 'playground', 'sports_ground', 'kindergarten', 'school',
+    'fitness', 'closed_territory', 'security', 'concierge', 'commercial_premises', 'park'
+);
+
+-- Buildings (корпуса) of a complex. Phase is the construction queue
+-- (очередь); the handover deadline is a year and a quarter.
+CREATE TABLE complex_building (
+    id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+    complex_id UUID NOT NULL REFERENCES housing_complex(id) ON DELETE CASCADE,
+    name TEXT NOT NULL CHECK (LENGTH(name) BETWEEN 1 AND 100),
+    phase INT NOT NULL DEFAULT 1 CHECK (phase BETWEEN 1 AND 100),
+    floors INT NOT NULL CHECK (floors BETWEEN 1 AND 200),
+    stage construction_stage_enum NOT NULL DEFAULT 'planned',
+    handover_year INT CHECK (handover_year BETWEEN 1800 AND 2100),
+    handover_quarter INT CHECK (handover_quarter BETWEEN 1 AND 4),
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    updated_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    CHECK ((handover_year IS NULL) = (handover_quarter IS NULL))
+);
+CREATE TRIGGER set_updated_at_complex_building
+    BEFORE UPDATE ON complex_building
+    FOR EACH ROW EXECUTE FUNCTION update_updated_at_column();
+
+-- Apartment layouts (планировки) on sale in the complex. A layout without a
+-- building is offered in all of them.
+CREATE TABLE complex_layout (
+    id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+    complex_id UUID NOT NULL REFERENCES housing_complex(id) ON DELETE CASCADE,
+    building_id UUID REFERENCES complex_building(id) ON DELETE SET NULL,
+    rooms INT NOT NULL CHECK (rooms BETWEEN 0 AND 20), -- 0 is a studio
+    area DECIMAL(10,2) NOT NULL CHECK (area > 0),
+    price_min BIGINT CHECK (price_min >= 0),
+    price_max BIGINT CHECK (price_max >= 0),
+    image_url TEXT CHECK (LENGTH(image_url) <= 1024),
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    updated_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    CHECK (price_max IS NULL OR price_min IS NULL OR price_max >= price_min)
+);
+CREATE TRIGGER set_updated_at_complex_layout
+    BEFORE UPDATE ON complex_layout
+    FOR EACH ROW EXECUTE FUNCTION update_updated_at_column();
+
+CREATE INDEX idx_complex_building_complex ON complex_building (complex_id);
+CREATE INDEX idx_complex_layout_complex ON complex_layout (complex_id, rooms, area);
+
+-- Layout plans hold image references like photos do
+CREATE OR REPLACE FUNCTION track_layout_image_refs()
+RETURNS TRIGGER AS $$
+BEGIN
+    IF TG_OP = 'INSERT' THEN
+        PERFORM adjust_image_blob_refs(NEW.image_url, 1);
+    ELSIF TG_OP = 'DELETE' THEN
+        PERFORM adjust_image_blob_refs(OLD.image_url, -1);
+    ELSIF OLD.image_url IS DISTINCT FROM NEW.image_url THEN
+        PERFORM adjust_image_blob_refs(OLD.image_url, -1);
+        PERFORM adjust_image_blob_refs(NEW.image_url, 1);
+    END IF;
+    RETURN NULL;
+END;
+$$ LANGUAGE plpgsql;
+
+CREATE TRIGGER trigger_track_complex_layout_refs
+    AFTER INSERT OR UPDATE OF image_url OR DELETE ON complex_layout
+    FOR EACH ROW EXECUTE FUNCTION track_layout_image_refs();
+
+CREATE OR REPLACE FUNCTION count_image_refs(blob_filename TEXT)
+RETURNS BIGINT AS $$
+    SELECT
+        (SELECT COUNT(*) FROM offer_photo WHERE image_filename_from_url(url) = blob_filename) +
+        (SELECT COUNT(*) FROM complex_photo WHERE image_filename_from_url(url) = blob_filename) +
+        (SELECT COUNT(*) FROM offer_draft_photo WHERE image_filename_from_url(url) = blob_filename) +
+        (SELECT COUNT(*) FROM complex_layout WHERE image_filename_from_url(image_url) = blob_filename) +
+        (SELECT COUNT(*) FROM profile WHERE image_filename_from_url(avatar_url) = blob_filename);
+$$ LANGUAGE sql STABLE;
+
+CREATE TABLE complex_amenity (
+    complex_id UUID NOT NULL REFERENCES housing_complex(id) ON DELETE CASCADE,
+    amenity complex_amenity_enum NOT NULL,
+    PRIMARY KEY (complex_id, amenity)
+);
+
+CREATE INDEX idx_offer_housing_complex ON offer (housing_complex_id, price) WHERE status = 'active';
+
+-- The starting price is no longer entered by hand: it is the cheapest of
+-- the layout prices and the active sale offers in the complex
+CREATE OR REPLACE FUNCTION complex_starting_price(p_complex_id UUID)
+RETURNS BIGINT AS $$
+    SELECT MIN(price) FROM (
+        SELECT price_min AS price FROM complex_layout WHERE complex_id = p_complex_id
+        UNION ALL
+        SELECT price FROM offer
+        WHERE housing_complex_id = p_complex_id AND status = 'active' AND offer_type = 'sale'
+    ) p;
+$$ LANGUAGE sql STABLE;
+
+ALTER TABLE housing_complex DROP COLUMN starting_price;
@@ -0,0 +1,25 @@
+ALTER TABLE housing_complex ADD COLUMN developer TEXT CHECK (LENGTH(developer) <= 255);
+
+UPDATE housing_complex hc SET developer = d.name
+FROM developer d
+WHERE d.id = hc.developer_id;
+
+DROP INDEX IF EXISTS idx_housing_complex_developer;
+ALTER TABLE housing_complex DROP COLUMN developer_id;
+
+CREATE OR REPLACE FUNCTION count_image_refs(blob_filename TEXT)
+RETURNS BIGINT AS $$
+    SELECT
+        (SELECT COUNT(*) FROM offer_photo WHERE image_filename_from_url(url) = blob_filename) +
+        (SELECT COUNT(*) FROM complex_photo WHERE image_filename_from_url(url) = blob_filename) +
+        (SELECT COUNT(*) FROM offer_draft_photo WHERE image_filename_from_url(url) = blob_filename) +
+        (SELECT COUNT(*) FROM complex_layout WHERE image_filename_from_url(image_url) = blob_filename) +
+        (SELECT COUNT(*) FROM profile WHERE image_filename_from_url(avatar_url) = blob_filename);
+$$ LANGUAGE sql STABLE;
+
+-- DROP TABLE fires no row triggers; delete the developers first to release their logos
+DROP TABLE IF EXISTS developer_member;
+DELETE FROM developer;
+DROP TABLE IF EXISTS developer;
+DROP FUNCTION IF EXISTS track_developer_logo_refs();
+DROP FUNCTION IF EXISTS complex_completed(UUID, INT);
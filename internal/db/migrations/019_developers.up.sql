@@ -0,0 +1,85 @@
+-- Developers (застройщики) replace the free-text housing_complex.developer
+CREATE TABLE developer (
+    id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+    name TEXT NOT NULL CHECK (LENGTH(name) BETWEEN 1 AND 255),
+    description TEXT CHECK (LENGTH(description) <= 5000),
+    logo_url TEXT CHECK (LENGTH(logo_url) <= 1024),
+    website TEXT CHECK (LENGTH(website) <= 255),
+    phone TEXT CHECK (LENGTH(phone) <= 20),
+    founded_year INT CHECK (founded_year BETWEEN 1800 AND 2100),
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    updated_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+CREATE TRIGGER set_updated_at_developer
+    BEFORE UPDATE ON developer
+    FOR EACH ROW EXECUTE FUNCTION update_updated_at_column();
+
+-- One developer per spelling up to case
+CREATE UNIQUE INDEX idx_developer_name ON developer (LOWER(name));
+
+-- Accounts acting for a developer; a user represents at most one
+CREATE TABLE developer_member (
+    developer_id UUID NOT NULL REFERENCES developer(id) ON DELETE CASCADE,
+    user_id UUID NOT NULL UNIQUE REFERENCES users(id) ON DELETE CASCADE,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    PRIMARY KEY (developer_id, user_id)
+);
+
+-- Logos hold image references like photos do
+CREATE OR REPLACE FUNCTION track_developer_logo_refs()
+RETURNS TRIGGER AS $$
+BEGIN
+    IF TG_OP = 'INSERT' THEN
+        PERFORM adjust_image_blob_refs(NEW.logo_url, 1);
+    ELSIF TG_OP = 'DELETE' THEN
+        PERFORM adjust_image_blob_refs(OLD.logo_url, -1);
+    ELSIF OLD.logo_url IS DISTINCT FROM NEW.logo_url THEN
+        PERFORM adjust_image_blob_refs(OLD.logo_url, -1);
+        PERFORM adjust_image_blob_refs(NEW.logo_url, 1);
+    END IF;
+    RETURN NULL;
+END;
+$$ LANGUAGE plpgsql;
+
+CREATE TRIGGER trigger_track_developer_logo_refs
+    AFTER INSERT OR UPDATE OF logo_url OR DELETE ON developer
+    FOR EACH ROW EXECUTE FUNCTION track_developer_logo_refs();
+
+CREATE OR REPLACE FUNCTION count_image_refs(blob_filename TEXT)
+RETURNS BIGINT AS $$
+    SELECT
+        (SELECT COUNT(*) FROM offer_photo WHERE image_filename_from_url(url) = blob_filename) +
+        (SELECT COUNT(*) FROM complex_photo WHERE image_filename_from_url(url) = blob_filename) +
+        (SELECT COUNT(*) FROM offer_draft_photo WHERE image_filename_from_url(url) = blob_filename) +
+        (SELECT COUNT(*) FROM complex_layout WHERE image_filename_from_url(image_url) = blob_filename) +
+        (SELECT COUNT(*) FROM developer WHERE image_filename_from_url(logo_url) = blob_filename) +
+        (SELECT COUNT(*) FROM profile WHERE image_filename_from_url(avatar_url) = blob_filename);
+$$ LANGUAGE sql STABLE;
+
+-- A complex is delivered when all of its buildings are completed or, without
+-- buildings, once its year built has come. NULL when that is unknown.
+CREATE OR REPLACE FUNCTION complex_completed(p_complex_id UUID, p_year_built INT)
+RETURNS BOOLEAN AS $$
+    SELECT COALESCE(
+        (SELECT bool_and(stage = 'completed') FROM complex_building WHERE complex_id = p_complex_id),
+        p_year_built <= EXTRACT(YEAR FROM CURRENT_DATE)
+    );
+$$ LANGUAGE sql STABLE;
+
+-- Existing spellings are merged up to case and spacing; the rest is left to
+-- the admins
+INSERT INTO developer (name)
+SELECT DISTINCT ON (LOWER(n.name)) n.name
+FROM housing_complex hc
+CROSS JOIN LATERAL (SELECT REGEXP_REPLACE(BTRIM(hc.developer), '\s+', ' ', 'g') AS name) n
+WHERE n.name <> ''
+ORDER BY LOWER(n.name), hc.created_at;
+
+ALTER TABLE housing_complex ADD COLUMN developer_id UUID REFERENCES developer(id) ON DELETE SET NULL;
+CREATE INDEX idx_housing_complex_developer ON housing_complex (developer_id);
+
+UPDATE housing_complex hc SET developer_id = d.id
+FROM developer d
+WHERE LOWER(d.name) = LOWER(REGEXP_REPLACE(BTRIM(hc.developer), '\s+', ' ', 'g'));
+
+ALTER TABLE housing_complex DROP COLUMN developer;
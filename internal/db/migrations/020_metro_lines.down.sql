@@ -0,0 +1,4 @@
+DROP INDEX IF EXISTS idx_region_parent;
+DROP INDEX IF EXISTS idx_metro_station_line;
+ALTER TABLE metro_station DROP COLUMN IF EXISTS line_id;
+DROP TABLE IF EXISTS metro_line;
@@ -0,0 +1,19 @@
+-- Metro lines of a city; stations show the line name and color
+CREATE TABLE metro_line (
+    id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+    region_id UUID NOT NULL REFERENCES region(id) ON DELETE CASCADE,
+    name TEXT NOT NULL CHECK (LENGTH(name) BETWEEN 1 AND 100),
+    color TEXT NOT NULL CHECK (color ~ '^#[0-9A-Fa-f]{6}$'),
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    updated_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    UNIQUE (region_id, name)
+);
+CREATE TRIGGER set_updated_at_metro_line
+    BEFORE UPDATE ON metro_line
+    FOR EACH ROW EXECUTE FUNCTION update_updated_at_column();
+
+ALTER TABLE metro_station ADD COLUMN line_id UUID REFERENCES metro_line(id) ON DELETE SET NULL;
+CREATE INDEX idx_metro_station_line ON metro_station (line_id);
+
+-- Region tree walks go from parent to children
+CREATE INDEX idx_region_parent ON region (parent_id);
@@ -0,0 +1,2 @@
+DROP INDEX IF EXISTS idx_offer_attributes;
+ALTER TABLE offer DROP COLUMN IF EXISTS attributes;
@@ -0,0 +1,6 @@
+-- Optional characteristics of an offer by key, validated against the
+-- catalogue in the application; the feed filters them by containment and
+-- by range
+ALTER TABLE offer ADD COLUMN attributes JSONB NOT NULL DEFAULT '{}'
+    CHECK (jsonb_typeof(attributes) = 'object');
+CREATE INDEX idx_offer_attributes ON offer USING GIN (attributes jsonb_path_ops);
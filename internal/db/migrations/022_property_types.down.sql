@@ -0,0 +1,9 @@
+-- Postgres cannot drop enum values, and the market stats views keep the
+-- type from being rebuilt; the values stay. Listings of the new types
+-- must be removed or converted by hand before rolling back.
+DO $$
+BEGIN
+    IF EXISTS (SELECT 1 FROM offer WHERE property_type IN ('room', 'land', 'commercial', 'garage')) THEN
+        RAISE EXCEPTION 'offers of room, land, commercial or garage type exist; convert or remove them first';
+    END IF;
+END $$;
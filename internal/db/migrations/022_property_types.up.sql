@@ -0,0 +1,5 @@
+-- Kept separate: a new enum value cannot be used in the transaction that adds it
+ALTER TYPE property_type_enum ADD VALUE IF NOT EXISTS 'room';
+ALTER TYPE property_type_enum ADD VALUE IF NOT EXISTS 'land';
+ALTER TYPE property_type_enum ADD VALUE IF NOT EXISTS 'commercial';
+ALTER TYPE property_type_enum ADD VALUE IF NOT EXISTS 'garage';
@@ -0,0 +1,5 @@
+DROP TABLE IF EXISTS offer_booking;
+DROP TYPE IF EXISTS booking_status_enum;
+DROP TYPE IF EXISTS booking_kind_enum;
+DROP TABLE IF EXISTS offer_short_term;
+DROP EXTENSION IF EXISTS btree_gist;
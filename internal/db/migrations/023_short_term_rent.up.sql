@@ -0,0 +1,45 @@
+-- Exclusion constraints below mix = on UUID with && on ranges
+CREATE EXTENSION IF NOT EXISTS btree_gist;
+
+-- Terms of letting a rent offer by the night; the offer is in the
+-- short-term mode while it has a row here
+CREATE TABLE offer_short_term (
+    offer_id UUID PRIMARY KEY REFERENCES offer(id) ON DELETE CASCADE,
+    nightly_price BIGINT NOT NULL CHECK (nightly_price > 0),
+    min_nights INT NOT NULL DEFAULT 1 CHECK (min_nights BETWEEN 1 AND 365),
+    max_nights INT CHECK (max_nights BETWEEN min_nights AND 365),
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    updated_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+CREATE TRIGGER set_updated_at_offer_short_term
+    BEFORE UPDATE ON offer_short_term
+    FOR EACH ROW EXECUTE FUNCTION update_updated_at_column();
+
+CREATE TYPE booking_kind_enum AS ENUM ('guest', 'blocked');
+CREATE TYPE booking_status_enum AS ENUM ('active', 'cancelled');
+
+-- Nights taken by guests or closed by the owner. The check-out day is free
+-- for the next guest, so the stay is the half-open range [check_in, check_out).
+CREATE TABLE offer_booking (
+    id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+    offer_id UUID NOT NULL REFERENCES offer(id) ON DELETE CASCADE,
+    kind booking_kind_enum NOT NULL,
+    guest_id UUID REFERENCES users(id) ON DELETE SET NULL,
+    check_in DATE NOT NULL,
+    check_out DATE NOT NULL CHECK (check_out > check_in),
+    total_price BIGINT CHECK (total_price > 0),
+    note TEXT CHECK (LENGTH(note) <= 500),
+    status booking_status_enum NOT NULL DEFAULT 'active',
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    updated_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    -- A night is taken at most once, whoever takes it
+    CONSTRAINT offer_booking_no_overlap EXCLUDE USING gist (
+        offer_id WITH =,
+        daterange(check_in, check_out) WITH &&
+    ) WHERE (status = 'active')
+);
+CREATE TRIGGER set_updated_at_offer_booking
+    BEFORE UPDATE ON offer_booking
+    FOR EACH ROW EXECUTE FUNCTION update_updated_at_column();
+
+CREATE INDEX idx_offer_booking_guest ON offer_booking (guest_id, check_in);
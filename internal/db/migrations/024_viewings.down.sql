@@ -0,0 +1,5 @@
+DROP TABLE IF EXISTS notification;
+DROP TYPE IF EXISTS notification_kind_enum;
+DROP TABLE IF EXISTS viewing;
+DROP TYPE IF EXISTS viewing_status_enum;
+DROP TABLE IF EXISTS viewing_slot;
@@ -0,0 +1,67 @@
+-- Times the owner is ready to show the offer. Slots of one offer never
+-- overlap; btree_gist comes with 023_short_term_rent.
+CREATE TABLE viewing_slot (
+    id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+    offer_id UUID NOT NULL REFERENCES offer(id) ON DELETE CASCADE,
+    starts_at TIMESTAMPTZ NOT NULL,
+    ends_at TIMESTAMPTZ NOT NULL CHECK (ends_at > starts_at),
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    CONSTRAINT viewing_slot_no_overlap EXCLUDE USING gist (
+        offer_id WITH =,
+        tstzrange(starts_at, ends_at) WITH &&
+    )
+);
+
+CREATE TYPE viewing_status_enum AS ENUM ('requested', 'confirmed', 'cancelled');
+
+-- A viewing keeps the time of its slot, so that the slot can go while the
+-- viewing stays on record. sequence counts the changes for iCalendar clients.
+CREATE TABLE viewing (
+    id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+    offer_id UUID NOT NULL REFERENCES offer(id) ON DELETE CASCADE,
+    slot_id UUID REFERENCES viewing_slot(id) ON DELETE SET NULL,
+    buyer_id UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    starts_at TIMESTAMPTZ NOT NULL,
+    ends_at TIMESTAMPTZ NOT NULL CHECK (ends_at > starts_at),
+    status viewing_status_enum NOT NULL DEFAULT 'requested',
+    comment TEXT CHECK (LENGTH(comment) <= 500),
+    cancel_reason TEXT CHECK (LENGTH(cancel_reason) <= 500),
+    cancelled_by UUID REFERENCES users(id) ON DELETE SET NULL,
+    sequence INT NOT NULL DEFAULT 0,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    updated_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    -- The owner shows the offer to one buyer at a time
+    CONSTRAINT viewing_offer_no_overlap EXCLUDE USING gist (
+        offer_id WITH =,
+        tstzrange(starts_at, ends_at) WITH &&
+    ) WHERE (status <> 'cancelled'),
+    -- and a buyer can't be at two viewings at once
+    CONSTRAINT viewing_buyer_no_overlap EXCLUDE USING gist (
+        buyer_id WITH =,
+        tstzrange(starts_at, ends_at) WITH &&
+    ) WHERE (status <> 'cancelled')
+);
+CREATE TRIGGER set_updated_at_viewing
+    BEFORE UPDATE ON viewing
+    FOR EACH ROW EXECUTE FUNCTION update_updated_at_column();
+
+CREATE INDEX idx_viewing_buyer ON viewing (buyer_id, starts_at);
+CREATE INDEX idx_viewing_offer ON viewing (offer_id, starts_at);
+
+CREATE TYPE notification_kind_enum AS ENUM (
+    'viewing_requested', 'viewing_confirmed', 'viewing_rescheduled', 'viewing_cancelled'
+);
+
+-- In-app notifications of a user
+CREATE TABLE notification (
+    id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+    user_id UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    kind notification_kind_enum NOT NULL,
+    viewing_id UUID REFERENCES viewing(id) ON DELETE CASCADE,
+    message TEXT NOT NULL,
+    read_at TIMESTAMPTZ,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX idx_notification_user ON notification (user_id, created_at DESC);
+CREATE INDEX idx_notification_unread ON notification (user_id) WHERE read_at IS NULL;
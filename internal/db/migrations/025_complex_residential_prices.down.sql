@@ -0,0 +1,9 @@
+CREATE OR REPLACE FUNCTION complex_starting_price(p_complex_id UUID)
+RETURNS BIGINT AS $$
+    SELECT MIN(price) FROM (
+        SELECT price_min AS price FROM complex_layout WHERE complex_id = p_complex_id
+        UNION ALL
+        SELECT price FROM offer
+        WHERE housing_complex_id = p_complex_id AND status = 'active' AND offer_type = 'sale'
+    ) p;
+$$ LANGUAGE sql STABLE;
@@ -0,0 +1,12 @@
+-- Only flats and houses make the "from" price of a complex; garages,
+-- commercial premises, land and rooms sold in it don't
+CREATE OR REPLACE FUNCTION complex_starting_price(p_complex_id UUID)
+RETURNS BIGINT AS $$
+    SELECT MIN(price) FROM (
+        SELECT price_min AS price FROM complex_layout WHERE complex_id = p_complex_id
+        UNION ALL
+        SELECT price FROM offer
+        WHERE housing_complex_id = p_complex_id AND status = 'active' AND offer_type = 'sale'
+          AND property_type IN ('apartment', 'house')
+    ) p;
+$$ LANGUAGE sql STABLE;
@@ -0,0 +1,8 @@
+DELETE FROM offer_viewer WHERE action <> 'view';
+
+ALTER TABLE offer_viewer
+    DROP CONSTRAINT offer_viewer_pkey,
+    DROP COLUMN action,
+    ADD PRIMARY KEY (offer_id, day, viewer_key);
+
+DROP TYPE IF EXISTS offer_action_enum;
@@ -0,0 +1,8 @@
+-- offer_viewer now remembers every kind of counted action of the day, so a
+-- favorite or a contact reveal is counted once per viewer per day like a view
+CREATE TYPE offer_action_enum AS ENUM ('view', 'favorite', 'contact');
+
+ALTER TABLE offer_viewer
+    ADD COLUMN action offer_action_enum NOT NULL DEFAULT 'view',
+    DROP CONSTRAINT offer_viewer_pkey,
+    ADD PRIMARY KEY (offer_id, day, action, viewer_key);
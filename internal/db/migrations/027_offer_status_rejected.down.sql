@@ -0,0 +1 @@
+ALTER TYPE offer_status_enum RENAME VALUE 'rejected' TO 'draft';
@@ -0,0 +1,3 @@
+-- Unpublished content lives in offer_draft; an offer sent back by moderation
+-- is rejected, not a draft
+ALTER TYPE offer_status_enum RENAME VALUE 'draft' TO 'rejected';
@@ -0,0 +1,39 @@
+-- Peers of an offer are computed from the scope rows directly so that the
+-- offer being rated can be left out of them
+CREATE OR REPLACE VIEW market_offer_scope AS
+WITH RECURSIVE region_ancestor AS (
+    SELECT id AS region_id, id AS ancestor_id FROM region
+    UNION
+    SELECT ra.region_id, r.parent_id
+    FROM region_ancestor ra
+    JOIN region r ON r.id = ra.ancestor_id
+    WHERE r.parent_id IS NOT NULL
+),
+listed AS (
+    SELECT
+        o.id,
+        o.location_id,
+        o.housing_complex_id,
+        o.offer_type,
+        o.property_type,
+        LEAST(o.rooms, 4) AS rooms, -- 4 stands for 4 and more
+        (o.price / o.area)::float8 AS price_per_sqm
+    FROM offer o
+    WHERE o.status = 'active' AND o.area > 0 AND o.price > 0
+)
+SELECT 'region'::TEXT AS scope, ra.ancestor_id AS scope_id, l.offer_type, l.property_type, l.rooms, l.price_per_sqm, l.id AS offer_id
+FROM listed l
+JOIN location loc ON loc.id = l.location_id
+JOIN region_ancestor ra ON ra.region_id = loc.region_id
+UNION ALL
+SELECT 'metro', lm.metro_station_id, l.offer_type, l.property_type, l.rooms, l.price_per_sqm, l.id
+FROM listed l
+JOIN (
+    SELECT DISTINCT ON (location_id) location_id, metro_station_id
+    FROM location_metro
+    ORDER BY location_id, distance_meters ASC
+) lm ON lm.location_id = l.location_id
+UNION ALL
+SELECT 'complex', l.housing_complex_id, l.offer_type, l.property_type, l.rooms, l.price_per_sqm, l.id
+FROM listed l
+WHERE l.housing_complex_id IS NOT NULL;
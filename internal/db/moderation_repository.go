@@ -0,0 +1,326 @@
+package db
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"time"
+
+	"github.com/go-park-mail-ru/2025_2_Avrora/internal/domain"
+	"github.com/go-park-mail-ru/2025_2_Avrora/internal/log"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+	"go.uber.org/zap"
+)
+
+const (
+	listOpenDuplicateFlagsQuery = `
+		SELECT f.id, f.offer_id, o.user_id, f.matched_offer_id, mo.user_id,
+			p.url, mp.url, f.distance, f.status, f.created_at
+		FROM offer_duplicate_flag f
+		JOIN offer o ON o.id = f.offer_id
+		JOIN offer mo ON mo.id = f.matched_offer_id
+		JOIN offer_photo p ON p.id = f.photo_id
+		JOIN offer_photo mp ON mp.id = f.matched_photo_id
+		WHERE f.status = 'open'
+		ORDER BY f.created_at DESC
+		LIMIT $1`
+
+	countOpenDuplicateFlagsQuery = `
+		SELECT COUNT(*) FROM offer_duplicate_flag
+		WHERE offer_id = $1 AND status = 'open'`
+
+	// A resubmission while the offer still waits replaces the pre-screen
+	// result but keeps the original kind
+	submitModerationItemQuery = `
+		INSERT INTO moderation_item (offer_id, kind, violations, submitted_at)
+		VALUES ($1, $2, $3, $4)
+		ON CONFLICT (offer_id) WHERE state = 'pending'
+		DO UPDATE SET violations = EXCLUDED.violations, submitted_at = EXCLUDED.submitted_at
+		RETURNING id, kind`
+
+	selectModerationItemQuery = `
+		SELECT m.id, m.offer_id, o.user_id, o.title, m.kind, m.state, m.violations,
+			m.submitted_at, m.decided_by, m.decided_at, m.reason
+		FROM moderation_item m
+		JOIN offer o ON o.id = m.offer_id`
+
+	listPendingModerationItemsQuery = selectModerationItemQuery + `
+		WHERE m.state = 'pending' AND o.status = 'moderation'
+		ORDER BY m.submitted_at ASC
+		LIMIT $1 OFFSET $2`
+
+	getModerationItemQuery = selectModerationItemQuery + `
+		WHERE m.id = $1`
+
+	getLatestModerationItemQuery = selectModerationItemQuery + `
+		WHERE m.offer_id = $1
+		ORDER BY m.submitted_at DESC
+		LIMIT 1`
+
+	decideModerationItemQuery = `
+		UPDATE moderation_item SET
+			state = $2, decided_by = $3, decided_at = $4, reason = $5
+		WHERE id = $1 AND state = 'pending'`
+
+	insertModerationAuditQuery = `
+		INSERT INTO moderation_audit (item_id, offer_id, action, actor_id, reason, violations, created_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7)`
+
+	listModerationAuditQuery = `
+		SELECT id, item_id, offer_id, action, actor_id, reason, violations, created_at
+		FROM moderation_audit
+		WHERE offer_id = $1
+		ORDER BY created_at ASC`
+
+	// Median price per square metre of comparable listings, excluding the offer itself
+	medianPricePerSqmInComplexQuery = `
+		SELECT COALESCE(percentile_cont(0.5) WITHIN GROUP (ORDER BY (price / area)::float8), 0), COUNT(*)
+		FROM offer
+		WHERE housing_complex_id = $1 AND offer_type = $2 AND property_type = $3
+		  AND id <> $4 AND status IN ('active', 'sold')`
+
+	medianPricePerSqmInRegionQuery = `
+		SELECT COALESCE(percentile_cont(0.5) WITHIN GROUP (ORDER BY (o.price / o.area)::float8), 0), COUNT(*)
+		FROM offer o
+		JOIN location l ON l.id = o.location_id
+		WHERE l.region_id = (SELECT region_id FROM location WHERE id = $1)
+		  AND o.offer_type = $2 AND o.property_type = $3
+		  AND o.id <> $4 AND o.status IN ('active', 'sold')`
+)
+
+type ModerationRepository struct {
+	db  *pgxpool.Pool
+	log *log.Logger
+}
+
+func NewModerationRepository(db *pgxpool.Pool, log *log.Logger) *ModerationRepository {
+	return &ModerationRepository{db: db, log: log}
+}
+
+// ListOpenDuplicateFlags returns the most recent unresolved duplicate flags
+func (r *ModerationRepository) ListOpenDuplicateFlags(ctx context.Context, limit int) ([]domain.DuplicateFlag, error) {
+	rows, err := r.db.Query(ctx, listOpenDuplicateFlagsQuery, limit)
+	if err != nil {
+		r.log.Error(ctx, "failed to list duplicate flags", zap.Error(err))
+		return nil, err
+	}
+	defer rows.Close()
+
+	var flags []domain.DuplicateFlag
+	for rows.Next() {
+		var f domain.DuplicateFlag
+		if err := rows.Scan(
+			&f.ID,
+			&f.OfferID,
+			&f.OfferUserID,
+			&f.MatchedOfferID,
+			&f.MatchedUserID,
+			&f.PhotoURL,
+			&f.MatchedPhotoURL,
+			&f.Distance,
+			&f.Status,
+			&f.CreatedAt,
+		); err != nil {
+			r.log.Error(ctx, "failed to scan duplicate flag", zap.Error(err))
+			return nil, err
+		}
+		flags = append(flags, f)
+	}
+	return flags, rows.Err()
+}
+
+func (r *ModerationRepository) CountOpenDuplicateFlags(ctx context.Context, offerID string) (int, error) {
+	var count int
+	if err := r.db.QueryRow(ctx, countOpenDuplicateFlagsQuery, offerID).Scan(&count); err != nil {
+		r.log.Error(ctx, "failed to count duplicate flags", zap.String("offer_id", offerID), zap.Error(err))
+		return 0, err
+	}
+	return count, nil
+}
+
+// MedianPricePerSqmInComplex returns the median and the number of comparable
+// listings in the complex
+func (r *ModerationRepository) MedianPricePerSqmInComplex(ctx context.Context, offer *domain.Offer) (float64, int, error) {
+	var median float64
+	var count int
+	err := r.db.QueryRow(ctx, medianPricePerSqmInComplexQuery,
+		offer.HousingComplexID, offer.OfferType, offer.PropertyType, offer.ID,
+	).Scan(&median, &count)
+	if err != nil {
+		r.log.Error(ctx, "failed to get complex price median", zap.String("offer_id", offer.ID), zap.Error(err))
+		return 0, 0, err
+	}
+	return median, count, nil
+}
+
+// MedianPricePerSqmInRegion returns the median and the number of comparable
+// listings in the region of the offer location
+func (r *ModerationRepository) MedianPricePerSqmInRegion(ctx context.Context, offer *domain.Offer) (float64, int, error) {
+	var median float64
+	var count int
+	err := r.db.QueryRow(ctx, medianPricePerSqmInRegionQuery,
+		offer.LocationID, offer.OfferType, offer.PropertyType, offer.ID,
+	).Scan(&median, &count)
+	if err != nil {
+		r.log.Error(ctx, "failed to get region price median", zap.String("offer_id", offer.ID), zap.Error(err))
+		return 0, 0, err
+	}
+	return median, count, nil
+}
+
+// Submit puts the offer into the queue, or refreshes its pending submission,
+// and records it in the audit trail
+func (r *ModerationRepository) Submit(ctx context.Context, item *domain.ModerationItem) error {
+	if item.Violations == nil {
+		item.Violations = []domain.RuleViolation{}
+	}
+
+	return pgx.BeginFunc(ctx, r.db, func(tx pgx.Tx) error {
+		err := tx.QueryRow(ctx, submitModerationItemQuery,
+			item.OfferID, item.Kind, item.Violations, item.SubmittedAt,
+		).Scan(&item.ID, &item.Kind)
+		if err != nil {
+			r.log.Error(ctx, "failed to submit offer for moderation", zap.String("offer_id", item.OfferID), zap.Error(err))
+			return err
+		}
+
+		if _, err := tx.Exec(ctx, insertModerationAuditQuery,
+			item.ID, item.OfferID, domain.ModerationActionSubmitted, item.OfferUserID, nil, item.Violations, item.SubmittedAt,
+		); err != nil {
+			r.log.Error(ctx, "failed to audit moderation submission", zap.String("offer_id", item.OfferID), zap.Error(err))
+			return err
+		}
+		return nil
+	})
+}
+
+func scanModerationItem(row pgx.Row) (*domain.ModerationItem, error) {
+	var item domain.ModerationItem
+	err := row.Scan(
+		&item.ID,
+		&item.OfferID,
+		&item.OfferUserID,
+		&item.OfferTitle,
+		&item.Kind,
+		&item.State,
+		&item.Violations,
+		&item.SubmittedAt,
+		&item.DecidedBy,
+		&item.DecidedAt,
+		&item.Reason,
+	)
+	if err != nil {
+		return nil, err
+	}
+	return &item, nil
+}
+
+// ListPending returns the queue, oldest submissions first
+func (r *ModerationRepository) ListPending(ctx context.Context, limit, offset int) ([]domain.ModerationItem, error) {
+	rows, err := r.db.Query(ctx, listPendingModerationItemsQuery, limit, offset)
+	if err != nil {
+		r.log.Error(ctx, "failed to list moderation queue", zap.Error(err))
+		return nil, err
+	}
+	defer rows.Close()
+
+	items := []domain.ModerationItem{}
+	for rows.Next() {
+		item, err := scanModerationItem(rows)
+		if err != nil {
+			r.log.Error(ctx, "failed to scan moderation item", zap.Error(err))
+			return nil, err
+		}
+		items = append(items, *item)
+	}
+	return items, rows.Err()
+}
+
+func (r *ModerationRepository) GetItem(ctx context.Context, id string) (*domain.ModerationItem, error) {
+	item, err := scanModerationItem(r.db.QueryRow(ctx, getModerationItemQuery, id))
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, domain.ErrModerationItemNotFound
+		}
+		r.log.Error(ctx, "failed to get moderation item", zap.String("id", id), zap.Error(err))
+		return nil, err
+	}
+	return item, nil
+}
+
+// GetLatestForOffer returns the most recent submission of the offer
+func (r *ModerationRepository) GetLatestForOffer(ctx context.Context, offerID string) (*domain.ModerationItem, error) {
+	item, err := scanModerationItem(r.db.QueryRow(ctx, getLatestModerationItemQuery, offerID))
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, domain.ErrModerationItemNotFound
+		}
+		r.log.Error(ctx, "failed to get latest moderation item", zap.String("offer_id", offerID), zap.Error(err))
+		return nil, err
+	}
+	return item, nil
+}
+
+// Decide closes a pending submission, moves the offer out of moderation and
+// records the decision in the audit trail, all or nothing
+func (r *ModerationRepository) Decide(ctx context.Context, item *domain.ModerationItem, action domain.ModerationAction, change *domain.OfferStatusChange, expiresAt *time.Time) error {
+	return auditedTx(ctx, r.db, func(tx pgx.Tx) error {
+		tag, err := tx.Exec(ctx, decideModerationItemQuery,
+			item.ID, item.State, item.DecidedBy, item.DecidedAt, item.Reason,
+		)
+		if err != nil {
+			r.log.Error(ctx, "failed to decide moderation item", zap.String("id", item.ID), zap.Error(err))
+			return err
+		}
+		if tag.RowsAffected() == 0 {
+			return domain.ErrModerationDecided
+		}
+
+		tag, err = tx.Exec(ctx, changeOfferStatusQuery,
+			change.OfferID,
+			change.FromStatus,
+			change.ToStatus,
+			change.ChangedAt,
+			expiresAt,
+			change.ChangedBy,
+			change.Reason,
+			nil, // decisions do not depend on the offer version
+		)
+		if err != nil {
+			r.log.Error(ctx, "failed to change offer status on moderation", zap.String("offer_id", change.OfferID), zap.Error(err))
+			return err
+		}
+		if tag.RowsAffected() == 0 {
+			return domain.ErrStatusConflict
+		}
+
+		if _, err := tx.Exec(ctx, insertModerationAuditQuery,
+			item.ID, item.OfferID, action, item.DecidedBy, item.Reason, item.Violations, change.ChangedAt,
+		); err != nil {
+			r.log.Error(ctx, "failed to audit moderation decision", zap.String("id", item.ID), zap.Error(err))
+			return err
+		}
+		return nil
+	})
+}
+
+func (r *ModerationRepository) ListAudit(ctx context.Context, offerID string) ([]domain.ModerationAuditEntry, error) {
+	rows, err := r.db.Query(ctx, listModerationAuditQuery, offerID)
+	if err != nil {
+		r.log.Error(ctx, "failed to list moderation audit", zap.String("offer_id", offerID), zap.Error(err))
+		return nil, err
+	}
+	defer rows.Close()
+
+	entries := []domain.ModerationAuditEntry{}
+	for rows.Next() {
+		var e domain.ModerationAuditEntry
+		if err := rows.Scan(&e.ID, &e.ItemID, &e.OfferID, &e.Action, &e.ActorID, &e.Reason, &e.Violations, &e.CreatedAt); err != nil {
+			r.log.Error(ctx, "failed to scan moderation audit entry", zap.Error(err))
+			return nil, err
+		}
+		entries = append(entries, e)
+	}
+	return entries, rows.Err()
+}
@@ -0,0 +1,106 @@
+package db
+
+import (
+	"context"
+
+	"github.com/go-park-mail-ru/2025_2_Avrora/internal/domain"
+	"github.com/go-park-mail-ru/2025_2_Avrora/internal/log"
+	"github.com/jackc/pgx/v5/pgxpool"
+	"go.uber.org/zap"
+)
+
+const (
+	createNotificationQuery = `
+		INSERT INTO notification (user_id, kind, viewing_id, message)
+		VALUES ($1, $2, $3, $4)
+		RETURNING id, created_at`
+
+	listNotificationsQuery = `
+		SELECT id, user_id, kind, viewing_id, message, read_at, created_at
+		FROM notification
+		WHERE user_id = $1 AND (NOT $2 OR read_at IS NULL)
+		ORDER BY created_at DESC, id
+		LIMIT $3 OFFSET $4`
+
+	countUnreadNotificationsQuery = `
+		SELECT COUNT(*) FROM notification WHERE user_id = $1 AND read_at IS NULL`
+
+	markNotificationReadQuery = `
+		UPDATE notification SET read_at = COALESCE(read_at, NOW())
+		WHERE id = $1 AND user_id = $2`
+
+	markAllNotificationsReadQuery = `
+		UPDATE notification SET read_at = NOW()
+		WHERE user_id = $1 AND read_at IS NULL`
+)
+
+type NotificationRepository struct {
+	db  *pgxpool.Pool
+	log *log.Logger
+}
+
+func NewNotificationRepository(db *pgxpool.Pool, log *log.Logger) *NotificationRepository {
+	return &NotificationRepository{db: db, log: log}
+}
+
+func (r *NotificationRepository) Create(ctx context.Context, n *domain.Notification) error {
+	err := r.db.QueryRow(ctx, createNotificationQuery, n.UserID, n.Kind, n.ViewingID, n.Message).Scan(&n.ID, &n.CreatedAt)
+	if err != nil {
+		r.log.Error(ctx, "failed to create notification", zap.String("user_id", n.UserID), zap.Error(err))
+		return err
+	}
+	return nil
+}
+
+// List returns the notifications of the user, newest first; unreadOnly
+// leaves out the read ones
+func (r *NotificationRepository) List(ctx context.Context, userID string, unreadOnly bool, limit, offset int) ([]domain.Notification, error) {
+	rows, err := r.db.Query(ctx, listNotificationsQuery, userID, unreadOnly, limit, offset)
+	if err != nil {
+		r.log.Error(ctx, "failed to list notifications", zap.String("user_id", userID), zap.Error(err))
+		return nil, err
+	}
+	defer rows.Close()
+
+	var notifications []domain.Notification
+	for rows.Next() {
+		var n domain.Notification
+		if err := rows.Scan(&n.ID, &n.UserID, &n.Kind, &n.ViewingID, &n.Message, &n.ReadAt, &n.CreatedAt); err != nil {
+			r.log.Error(ctx, "failed to scan notification", zap.Error(err))
+			return nil, err
+		}
+		notifications = append(notifications, n)
+	}
+	return notifications, rows.Err()
+}
+
+func (r *NotificationRepository) CountUnread(ctx context.Context, userID string) (int, error) {
+	var count int
+	if err := r.db.QueryRow(ctx, countUnreadNotificationsQuery, userID).Scan(&count); err != nil {
+		r.log.Error(ctx, "failed to count unread notifications", zap.String("user_id", userID), zap.Error(err))
+		return 0, err
+	}
+	return count, nil
+}
+
+// MarkRead marks one notification of the user as read
+func (r *NotificationRepository) MarkRead(ctx context.Context, userID, id string) error {
+	tag, err := r.db.Exec(ctx, markNotificationReadQuery, id, userID)
+	if err != nil {
+		r.log.Error(ctx, "failed to mark notification read", zap.String("id", id), zap.Error(err))
+		return err
+	}
+	if tag.RowsAffected() == 0 {
+		return domain.ErrNotificationNotFound
+	}
+	return nil
+}
+
+// MarkAllRead marks every notification of the user as read
+func (r *NotificationRepository) MarkAllRead(ctx context.Context, userID string) error {
+	if _, err := r.db.Exec(ctx, markAllNotificationsReadQuery, userID); err != nil {
+		r.log.Error(ctx, "failed to mark notifications read", zap.String("user_id", userID), zap.Error(err))
+		return err
+	}
+	return nil
+}
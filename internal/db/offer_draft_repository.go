@@ -0,0 +1,207 @@
+package db
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+
+	"github.com/go-park-mail-ru/2025_2_Avrora/internal/domain"
+	"github.com/go-park-mail-ru/2025_2_Avrora/internal/log"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+	"go.uber.org/zap"
+)
+
+const (
+	createOfferDraftQuery = `
+		INSERT INTO offer_draft (id, user_id, payload)
+		VALUES ($1, $2, $3)
+		RETURNING created_at, updated_at`
+
+	updateOfferDraftQuery = `
+		UPDATE offer_draft SET payload = $2
+		WHERE id = $1
+		RETURNING updated_at`
+
+	getOfferDraftQuery = `
+		SELECT id, user_id, payload, created_at, updated_at
+		FROM offer_draft
+		WHERE id = $1`
+
+	listOfferDraftsByUserQuery = `
+		SELECT id, user_id, payload, created_at, updated_at
+		FROM offer_draft
+		WHERE user_id = $1
+		ORDER BY updated_at DESC
+		LIMIT $2 OFFSET $3`
+
+	listOfferDraftPhotosQuery = `
+		SELECT draft_id, url
+		FROM offer_draft_photo
+		WHERE draft_id = ANY($1)
+		ORDER BY draft_id, position`
+
+	deleteRemovedOfferDraftPhotosQuery = `
+		DELETE FROM offer_draft_photo
+		WHERE draft_id = $1 AND url <> ALL($2::TEXT[])`
+
+	upsertOfferDraftPhotosQuery = `
+		INSERT INTO offer_draft_photo (draft_id, url, position)
+		SELECT $1, u.url, u.ord - 1
+		FROM UNNEST($2::TEXT[]) WITH ORDINALITY AS u(url, ord)
+		ON CONFLICT (draft_id, url) DO UPDATE SET position = EXCLUDED.position`
+
+	deleteOfferDraftQuery = `DELETE FROM offer_draft WHERE id = $1`
+)
+
+type OfferDraftRepository struct {
+	db  *pgxpool.Pool
+	log *log.Logger
+}
+
+func NewOfferDraftRepository(db *pgxpool.Pool, log *log.Logger) *OfferDraftRepository {
+	return &OfferDraftRepository{db: db, log: log}
+}
+
+// syncDraftPhotos makes the draft photos equal to urls, which must be unique
+func syncDraftPhotos(ctx context.Context, tx pgx.Tx, draftID string, urls []string) error {
+	if urls == nil {
+		urls = []string{}
+	}
+	if _, err := tx.Exec(ctx, deleteRemovedOfferDraftPhotosQuery, draftID, urls); err != nil {
+		return err
+	}
+	_, err := tx.Exec(ctx, upsertOfferDraftPhotosQuery, draftID, urls)
+	return err
+}
+
+// saveDraftPhotos stores the photos of the draft; attached uploads do not
+// expire while the realtor keeps editing
+func saveDraftPhotos(ctx context.Context, l *log.Logger, tx pgx.Tx, draft *domain.OfferDraft) error {
+	if err := syncDraftPhotos(ctx, tx, draft.ID, draft.Fields.ImageURLs); err != nil {
+		l.Error(ctx, "failed to save draft photos", zap.String("draft_id", draft.ID), zap.Error(err))
+		return err
+	}
+	if err := attachUploads(ctx, tx, draft.UserID, draft.Fields.ImageURLs); err != nil {
+		l.Error(ctx, "failed to attach draft images", zap.String("draft_id", draft.ID), zap.Error(err))
+		return err
+	}
+	return nil
+}
+
+func (r *OfferDraftRepository) Create(ctx context.Context, draft *domain.OfferDraft) error {
+	return pgx.BeginFunc(ctx, r.db, func(tx pgx.Tx) error {
+		err := tx.QueryRow(ctx, createOfferDraftQuery, draft.ID, draft.UserID, draft.Fields).
+			Scan(&draft.CreatedAt, &draft.UpdatedAt)
+		if err != nil {
+			r.log.Error(ctx, "failed to create offer draft", zap.Error(err))
+			return err
+		}
+		return saveDraftPhotos(ctx, r.log, tx, draft)
+	})
+}
+
+func (r *OfferDraftRepository) Update(ctx context.Context, draft *domain.OfferDraft) error {
+	return pgx.BeginFunc(ctx, r.db, func(tx pgx.Tx) error {
+		err := tx.QueryRow(ctx, updateOfferDraftQuery, draft.ID, draft.Fields).Scan(&draft.UpdatedAt)
+		if err != nil {
+			if errors.Is(err, sql.ErrNoRows) {
+				return domain.ErrDraftNotFound
+			}
+			r.log.Error(ctx, "failed to update offer draft", zap.String("draft_id", draft.ID), zap.Error(err))
+			return err
+		}
+		return saveDraftPhotos(ctx, r.log, tx, draft)
+	})
+}
+
+func (r *OfferDraftRepository) GetByID(ctx context.Context, id string) (*domain.OfferDraft, error) {
+	var d domain.OfferDraft
+	err := r.db.QueryRow(ctx, getOfferDraftQuery, id).Scan(&d.ID, &d.UserID, &d.Fields, &d.CreatedAt, &d.UpdatedAt)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, domain.ErrDraftNotFound
+		}
+		r.log.Error(ctx, "failed to get offer draft", zap.String("draft_id", id), zap.Error(err))
+		return nil, err
+	}
+
+	drafts := []*domain.OfferDraft{&d}
+	if err := r.fetchDraftPhotos(ctx, drafts); err != nil {
+		return nil, err
+	}
+	return &d, nil
+}
+
+// ListByUserID returns the user's drafts, most recently edited first
+func (r *OfferDraftRepository) ListByUserID(ctx context.Context, userID string, limit, offset int) ([]domain.OfferDraft, error) {
+	rows, err := r.db.Query(ctx, listOfferDraftsByUserQuery, userID, limit, offset)
+	if err != nil {
+		r.log.Error(ctx, "failed to list offer drafts", zap.String("user_id", userID), zap.Error(err))
+		return nil, err
+	}
+	defer rows.Close()
+
+	var drafts []*domain.OfferDraft
+	for rows.Next() {
+		var d domain.OfferDraft
+		if err := rows.Scan(&d.ID, &d.UserID, &d.Fields, &d.CreatedAt, &d.UpdatedAt); err != nil {
+			r.log.Error(ctx, "failed to scan offer draft", zap.Error(err))
+			return nil, err
+		}
+		drafts = append(drafts, &d)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	if err := r.fetchDraftPhotos(ctx, drafts); err != nil {
+		return nil, err
+	}
+	result := make([]domain.OfferDraft, 0, len(drafts))
+	for _, d := range drafts {
+		result = append(result, *d)
+	}
+	return result, nil
+}
+
+func (r *OfferDraftRepository) fetchDraftPhotos(ctx context.Context, drafts []*domain.OfferDraft) error {
+	if len(drafts) == 0 {
+		return nil
+	}
+	byID := make(map[string]*domain.OfferDraft, len(drafts))
+	ids := make([]string, 0, len(drafts))
+	for _, d := range drafts {
+		byID[d.ID] = d
+		ids = append(ids, d.ID)
+	}
+
+	rows, err := r.db.Query(ctx, listOfferDraftPhotosQuery, ids)
+	if err != nil {
+		r.log.Error(ctx, "failed to list draft photos", zap.Error(err))
+		return err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var draftID, url string
+		if err := rows.Scan(&draftID, &url); err != nil {
+			return err
+		}
+		d := byID[draftID]
+		d.Fields.ImageURLs = append(d.Fields.ImageURLs, url)
+	}
+	return rows.Err()
+}
+
+func (r *OfferDraftRepository) Delete(ctx context.Context, id string) error {
+	tag, err := r.db.Exec(ctx, deleteOfferDraftQuery, id)
+	if err != nil {
+		r.log.Error(ctx, "failed to delete offer draft", zap.String("draft_id", id), zap.Error(err))
+		return err
+	}
+	if tag.RowsAffected() == 0 {
+		return domain.ErrDraftNotFound
+	}
+	return nil
+}
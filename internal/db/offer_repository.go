@@ -3,9 +3,10 @@ package db
 import (
 	"context"
 	"database/sql"
-	"encoding/json"
 	"errors"
 	"fmt"
+	"slices"
+	"strings"
 	"time"
 
 	"github.com/go-park-mail-ru/2025_2_Avrora/internal/domain"
@@ -19,10 +20,10 @@ import (
 // SQL constants using actual schema
 const (
 	listPhotosForOfferQuery = `
-		SELECT image_url
+		SELECT url
 		FROM offer_photo
 		WHERE offer_id = $1
-		ORDER BY created_at DESC`
+		ORDER BY position ASC, created_at ASC`
 
 	getOfferByIDQuery = `
 	SELECT
@@ -46,10 +47,15 @@ const (
 		o.rental_period,
 		o.living_area,
 		o.kitchen_area,
+		o.attributes,
 		ms.name AS metro,  -- ← added metro station name
-		COALESCE(ARRAY_AGG(op.url) FILTER (WHERE op.url IS NOT NULL), '{}') AS image_urls,
+		COALESCE(ARRAY_AGG(op.url ORDER BY op.position) FILTER (WHERE op.url IS NOT NULL), '{}') AS image_urls,
+		o.status_changed_at,
+		o.published_at,
+		o.expires_at,
 		o.created_at,
-		o.updated_at
+		o.updated_at,
+		o.version
 	FROM offer o
 	-- Join nearest metro station (same logic as listOffersQuery)
 	LEFT JOIN (
@@ -84,9 +90,14 @@ const (
 			o.rental_period,
 			o.living_area,
 			o.kitchen_area,
+			o.attributes,
 			ms.name,  -- ← don't forget to GROUP BY metro!
+			o.status_changed_at,
+			o.published_at,
+			o.expires_at,
 			o.created_at,
-			o.updated_at
+			o.updated_at,
+			o.version
 	`
 
 	createOfferQuery = `
@@ -111,6 +122,10 @@ const (
 			rental_period,
 			living_area,
 			kitchen_area,
+			attributes,
+			status_changed_at,
+			published_at,
+			expires_at,
 			created_at,
 			updated_at
 		) VALUES (
@@ -126,7 +141,7 @@ const (
 			$10, -- rooms
 			$11, -- property_type
 			$12, -- offer_type
-			'active', -- status (default to 'active')
+			$20::offer_status_enum, -- status (initial lifecycle state)
 			$13, -- floor (can be NULL)
 			$14, -- total_floors (can be NULL)
 			$15, -- deposit (can be NULL)
@@ -134,23 +149,21 @@ const (
 			$17, -- rental_period (can be NULL)
 			$18, -- living_area (can be NULL)
 			$19, -- kitchen_area (can be NULL)
+			$22, -- attributes
+			NOW(),
+			CASE WHEN $20::offer_status_enum = 'active' THEN NOW() END, -- published_at
+			$21, -- expires_at (NULL until the offer goes live)
 			NOW(),
 			NOW()
 		)
 		RETURNING id
 	`
 
-	updateOfferQuery = `
-		UPDATE offer SET
-			location_id = $1, housing_complex_id = $2, title = $3, description = $4,
-			price = $5, area = $6, address = $7, rooms = $8, property_type = $9,
-			offer_type = $10, status = $11, floor = $12, total_floors = $13,
-			deposit = $14, commission = $15, rental_period = $16,
-			living_area = $17, kitchen_area = $18, updated_at = $19
-		WHERE id = $20
-	`
+	// %s is filled with the patched column assignments. A NULL version
+	// skips the check (If-Match: *).
+	updateOfferQueryTmpl = `UPDATE offer SET %s WHERE id = $1 AND ($2::INT IS NULL OR version = $2)`
 
-	deleteOfferQuery = "DELETE FROM offer WHERE id = $1"
+	deleteOfferQuery = "DELETE FROM offer WHERE id = $1 AND ($2::INT IS NULL OR version = $2)"
 
 	countAllOffersQuery = "SELECT COUNT(*) FROM offer WHERE status = 'active'"
 
@@ -169,7 +182,8 @@ const (
 			ms.name AS metro,
 			op.url AS image_url,
 			o.created_at,
-			o.updated_at
+			o.updated_at,
+			o.status
 		FROM offer o
 		LEFT JOIN (
 			SELECT DISTINCT ON (location_id)
@@ -184,10 +198,13 @@ const (
 				offer_id,
 				url
 			FROM offer_photo
-			ORDER BY offer_id, created_at ASC
+			ORDER BY offer_id, is_cover DESC, position ASC, created_at ASC
 		) op ON op.offer_id = o.id
+		LEFT JOIN offer_popularity pop ON pop.offer_id = o.id
 		WHERE o.status = 'active'
-		ORDER BY o.created_at DESC
+		ORDER BY
+			CASE WHEN $3::TEXT = 'popular' THEN COALESCE(pop.score, 0) END DESC,
+			o.created_at DESC, o.id
 		LIMIT $1 OFFSET $2
 	`
 
@@ -206,7 +223,8 @@ const (
 			ms.name AS metro,
 			op.url AS image_url,
 			o.created_at,
-			o.updated_at
+			o.updated_at,
+			o.status
 		FROM offer o
 		LEFT JOIN (
 			SELECT DISTINCT ON (location_id)
@@ -221,19 +239,225 @@ const (
 				offer_id,
 				url
 			FROM offer_photo
-			ORDER BY offer_id, created_at ASC
+			ORDER BY offer_id, is_cover DESC, position ASC, created_at ASC
 		) op ON op.offer_id = o.id
 		WHERE o.user_id = $1
-		AND o.status = 'active'
+		AND ($4 OR o.status = 'active')
 		ORDER BY o.created_at DESC
 		LIMIT $2 OFFSET $3
 		`
 
+	// Pairs every hashed photo of the offer with near-identical photos of
+	// other users' offers; bit_count of the XOR is the Hamming distance
+	flagDuplicatePhotosQuery = `
+		INSERT INTO offer_duplicate_flag (offer_id, matched_offer_id, photo_id, matched_photo_id, distance)
+		SELECT p.offer_id, q.offer_id, p.id, q.id, bit_count((p.phash # q.phash)::BIT(64))
+		FROM offer_photo p
+		JOIN offer o ON o.id = p.offer_id
+		JOIN offer_photo q ON q.offer_id <> p.offer_id AND q.phash IS NOT NULL
+		JOIN offer qo ON qo.id = q.offer_id AND qo.user_id <> o.user_id
+		WHERE p.offer_id = $1
+		  AND p.phash IS NOT NULL
+		  AND bit_count((p.phash # q.phash)::BIT(64)) <= $2
+		ON CONFLICT (photo_id, matched_photo_id) DO NOTHING`
+
+	insertOfferStatusHistoryQuery = `
+		INSERT INTO offer_status_history (offer_id, from_status, to_status, changed_by, reason, changed_at)
+		VALUES ($1, $2, $3, $4, $5, $6)`
+
+	// Nothing deleted means the draft was published or deleted concurrently
+	deletePublishedDraftQuery = `DELETE FROM offer_draft WHERE id = $1 AND user_id = $2`
+
+	// Compare-and-set on the current status so concurrent changes cannot
+	// skip a lifecycle check; the history row is written in the same statement
+	changeOfferStatusQuery = `
+		WITH changed AS (
+			UPDATE offer SET
+				status = $3::offer_status_enum,
+				status_changed_at = $4,
+				published_at = CASE WHEN $3::offer_status_enum = 'active' THEN COALESCE(published_at, $4) ELSE published_at END,
+				expires_at = $5
+			WHERE id = $1 AND status = $2::offer_status_enum
+			  AND ($8::INT IS NULL OR version = $8)
+			RETURNING id
+		)
+		INSERT INTO offer_status_history (offer_id, from_status, to_status, changed_by, reason, changed_at)
+		SELECT id, $2::offer_status_enum, $3::offer_status_enum, $6, $7, $4 FROM changed`
+
+	renewOfferQuery = `
+		UPDATE offer SET expires_at = $2
+		WHERE id = $1 AND status IN ('active', 'paused')`
+
+	expireOffersQuery = `
+		WITH expired AS (
+			UPDATE offer o SET
+				status = 'expired',
+				status_changed_at = $1,
+				expires_at = NULL
+			FROM offer prev
+			WHERE prev.id = o.id
+			  AND o.status IN ('active', 'paused')
+			  AND o.expires_at < $1
+			RETURNING o.id, prev.status AS from_status
+		)
+		INSERT INTO offer_status_history (offer_id, from_status, to_status, changed_by, reason, changed_at)
+		SELECT id, from_status, 'expired', NULL, 'listing period ended', $1 FROM expired`
+
+	listOfferStatusHistoryQuery = `
+		SELECT id, offer_id, from_status, to_status, changed_by, reason, changed_at
+		FROM offer_status_history
+		WHERE offer_id = $1
+		ORDER BY changed_at ASC`
+
 	countOffersByUserIDQuery = `
 		SELECT COUNT(*)
 		FROM offer
-		WHERE user_id = $1 AND status = 'active'
+		WHERE user_id = $1 AND ($2 OR status = 'active')
 		`
+
+	// The listing row shares its timestamp with the offer creation, so it is
+	// put first explicitly
+	listOfferPriceEventsQuery = `
+		SELECT changed_at, old_price, new_price, reason, changed_by
+		FROM offer_price_history
+		WHERE offer_id = $1
+		ORDER BY changed_at ASC, old_price IS NOT NULL`
+
+	// For every period the price and status each offer in scope had at its
+	// end: offers that were active or paused then count with their asking
+	// price at that moment. Periods without listings come back with zeros.
+	priceTrendQuery = `
+		WITH RECURSIVE scope_region AS (
+			SELECT id FROM region WHERE $1::TEXT = 'region' AND id = $2::UUID
+			UNION
+			SELECT r.id FROM region r JOIN scope_region s ON r.parent_id = s.id
+		),
+		scoped AS (
+			SELECT o.id, o.area
+			FROM offer o
+			JOIN location l ON l.id = o.location_id
+			WHERE o.offer_type = $4
+			  AND ($5::property_type_enum IS NULL OR o.property_type = $5)
+			  AND o.area > 0
+			  AND (
+				($1 = 'complex' AND o.housing_complex_id = $2)
+				OR ($1 = 'metro' AND EXISTS (
+					SELECT 1 FROM location_metro lm
+					WHERE lm.location_id = o.location_id AND lm.metro_station_id = $2))
+				OR ($1 = 'region' AND l.region_id IN (SELECT id FROM scope_region))
+			  )
+		),
+		periods AS (
+			SELECT p AS period_start, LEAST(p + ('1 ' || $3::TEXT)::INTERVAL, NOW()) AS period_end
+			FROM generate_series(
+				date_trunc($3, $6::TIMESTAMPTZ),
+				date_trunc($3, $7::TIMESTAMPTZ),
+				('1 ' || $3)::INTERVAL
+			) AS p
+		),
+		listed AS (
+			SELECT p.period_start, s.area, price.new_price
+			FROM periods p
+			CROSS JOIN scoped s
+			JOIN LATERAL (
+				SELECT h.new_price
+				FROM offer_price_history h
+				WHERE h.offer_id = s.id AND h.changed_at < p.period_end
+				ORDER BY h.changed_at DESC
+				LIMIT 1
+			) price ON TRUE
+			JOIN LATERAL (
+				SELECT sh.to_status
+				FROM offer_status_history sh
+				WHERE sh.offer_id = s.id AND sh.changed_at < p.period_end
+				ORDER BY sh.changed_at DESC
+				LIMIT 1
+			) st ON st.to_status IN ('active', 'paused')
+		)
+		SELECT
+			p.period_start,
+			COALESCE(percentile_cont(0.5) WITHIN GROUP (ORDER BY (l.new_price / l.area)::float8), 0),
+			COALESCE(AVG(l.new_price / l.area)::float8, 0),
+			COUNT(l.area)
+		FROM periods p
+		LEFT JOIN listed l ON l.period_start = p.period_start
+		GROUP BY p.period_start
+		ORDER BY p.period_start`
+
+	// What offers are compared on when recommending similar ones; see
+	// domain.SimilarityFeatures
+	similarityFeaturesQuery = `
+		SELECT
+			o.id, o.property_type, o.housing_complex_id, nm.metro_station_id, l.region_id,
+			o.rooms, o.area::float8, o.price,
+			l.latitude::float8, l.longitude::float8, o.created_at
+		FROM offer o
+		JOIN location l ON l.id = o.location_id
+		LEFT JOIN LATERAL (
+			SELECT metro_station_id FROM location_metro
+			WHERE location_id = o.location_id
+			ORDER BY distance_meters ASC
+			LIMIT 1
+		) nm ON TRUE`
+
+	getSimilarityTargetQuery = similarityFeaturesQuery + `
+		WHERE o.id = $1`
+
+	// Candidates are active offers of the same deal type within half to
+	// double the price that share the complex, nearest metro station or
+	// region, or lie in the box around the $2 km circle. The exact distance
+	// and the score are worked out by domain.SimilarityFeatures.
+	listSimilarityCandidatesQuery = `
+		WITH target AS (
+			SELECT
+				o.id, o.offer_type, o.housing_complex_id, o.price,
+				l.latitude::float8 AS lat, l.longitude::float8 AS lon, l.region_id,
+				nm.metro_station_id
+			FROM offer o
+			JOIN location l ON l.id = o.location_id
+			LEFT JOIN LATERAL (
+				SELECT metro_station_id FROM location_metro
+				WHERE location_id = o.location_id
+				ORDER BY distance_meters ASC
+				LIMIT 1
+			) nm ON TRUE
+			WHERE o.id = $1
+		)` + similarityFeaturesQuery + `
+		JOIN target t ON o.offer_type = t.offer_type AND o.id <> t.id
+		WHERE o.status = 'active'
+		  AND o.price BETWEEN t.price / 2 AND t.price * 2
+		  AND (
+			o.housing_complex_id = t.housing_complex_id
+			OR nm.metro_station_id = t.metro_station_id
+			OR l.region_id = t.region_id
+			OR (
+				ABS(l.latitude::float8 - t.lat) * 111.2 <= $2
+				AND ABS(l.longitude::float8 - t.lon) * 111.2 * COS(RADIANS(t.lat)) <= $2
+			)
+		  )`
+
+	// Feed rows of the offers in $1, in no particular order
+	listOffersInFeedByIDsQuery = `
+		SELECT
+			o.id, o.user_id, o.offer_type, o.property_type, o.price, o.area, o.rooms,
+			o.floor, o.total_floors, o.address,
+			ms.name AS metro, op.url AS image_url,
+			o.created_at, o.updated_at, o.status
+		FROM offer o
+		LEFT JOIN (
+			SELECT DISTINCT ON (location_id)
+				location_id, metro_station_id
+			FROM location_metro
+			ORDER BY location_id, distance_meters ASC
+		) lm ON lm.location_id = o.location_id
+		LEFT JOIN metro_station ms ON ms.id = lm.metro_station_id
+		LEFT JOIN (
+			SELECT DISTINCT ON (offer_id)
+				offer_id, url
+			FROM offer_photo
+			ORDER BY offer_id, is_cover DESC, position ASC, created_at ASC
+		) op ON op.offer_id = o.id
+		WHERE o.id = ANY($1)`
 )
 
 type OfferRepository struct {
@@ -245,6 +469,28 @@ func NewOfferRepository(db *pgxpool.Pool, log *log.Logger) *OfferRepository {
 	return &OfferRepository{db: db, log: log}
 }
 
+// offerPatchColumns are the offer columns an owner may change, by JSON name
+var offerPatchColumns = map[string]string{
+	"location_id":        "location_id",
+	"housing_complex_id": "housing_complex_id",
+	"title":              "title",
+	"description":        "description",
+	"price":              "price",
+	"area":               "area",
+	"address":            "address",
+	"rooms":              "rooms",
+	"property_type":      "property_type",
+	"offer_type":         "offer_type",
+	"floor":              "floor",
+	"total_floors":       "total_floors",
+	"deposit":            "deposit",
+	"commission":         "commission",
+	"rental_period":      "rental_period",
+	"living_area":        "living_area",
+	"kitchen_area":       "kitchen_area",
+	"attributes":         "attributes",
+}
+
 func scanOfferRow(scanner interface {
 	Scan(dest ...any) error
 }) (*domain.Offer, error) {
@@ -254,6 +500,8 @@ func scanOfferRow(scanner interface {
 		deposit, commission     *int64
 		rentalPeriod            *string
 		livingArea, kitchenArea *float64
+		attributes              domain.OfferAttributes
+		description             *string
 		metro                   *string   // ← ADDED: metro station name (nullable)
 		imageURLs               []string
 		offer                   domain.Offer
@@ -265,7 +513,7 @@ func scanOfferRow(scanner interface {
 		&offer.LocationID,
 		&housingComplexID,
 		&offer.Title,
-		&offer.Description,
+		&description,
 		&offer.Price,
 		&offer.Area,
 		&offer.Address,
@@ -280,16 +528,24 @@ func scanOfferRow(scanner interface {
 		&rentalPeriod,
 		&livingArea,
 		&kitchenArea,
+		&attributes,
 		&metro,          // ← ADDED in correct position (after kitchenArea, before imageURLs)
 		&imageURLs,
+		&offer.StatusChangedAt,
+		&offer.PublishedAt,
+		&offer.ExpiresAt,
 		&offer.CreatedAt,
 		&offer.UpdatedAt,
+		&offer.Version,
 	)
 	if err != nil {
 		return nil, err
 	}
 
 	// Assign nullable fields
+	if description != nil {
+		offer.Description = *description
+	}
 	offer.HousingComplexID = housingComplexID
 	offer.Floor = floor
 	offer.TotalFloors = totalFloors
@@ -298,6 +554,7 @@ func scanOfferRow(scanner interface {
 	offer.RentalPeriod = rentalPeriod
 	offer.LivingArea = livingArea
 	offer.KitchenArea = kitchenArea
+	offer.Attributes = attributes
 	offer.Metro = metro
 
 	offer.ImageURLs = imageURLs
@@ -330,6 +587,7 @@ func scanOfferInFeedRow(scanner interface {
 		&imageURL,
 		&o.CreatedAt,
 		&o.UpdatedAt,
+		&o.Status,
 	)
 	if err != nil {
 		return nil, err
@@ -372,7 +630,7 @@ func (r *OfferRepository) fetchPhotosForOffers(ctx context.Context, offers []*do
 		SELECT offer_id, url 
 		FROM offer_photo 
 		WHERE offer_id = ANY($1)
-		ORDER BY created_at ASC
+		ORDER BY position ASC, created_at ASC
 	`, pq.Array(ids))
 	if err != nil {
 		return err
@@ -400,6 +658,68 @@ func (r *OfferRepository) fetchPhotosForOffers(ctx context.Context, offers []*do
 	return nil
 }
 
+// Up to $2 nearest stations of each offer in $1
+const listOfferMetroQuery = `
+	SELECT offer_id, station_id, name, line_name, line_color, distance_meters
+	FROM (
+		SELECT
+			o.id AS offer_id, ms.id AS station_id, ms.name, ml.name AS line_name, ml.color AS line_color,
+			lm.distance_meters,
+			ROW_NUMBER() OVER (PARTITION BY o.id ORDER BY lm.distance_meters, ms.name) AS n
+		FROM offer o
+		JOIN location_metro lm ON lm.location_id = o.location_id
+		JOIN metro_station ms ON ms.id = lm.metro_station_id
+		LEFT JOIN metro_line ml ON ml.id = ms.line_id
+		WHERE o.id = ANY($1)
+	) s
+	WHERE n <= $2
+	ORDER BY offer_id, n`
+
+// fetchOfferMetro returns the nearest stations of the offers by offer ID
+func fetchOfferMetro(ctx context.Context, db *pgxpool.Pool, ids []string) (map[string][]domain.OfferMetro, error) {
+	metro := make(map[string][]domain.OfferMetro)
+	if len(ids) == 0 {
+		return metro, nil
+	}
+
+	rows, err := db.Query(ctx, listOfferMetroQuery, ids, domain.MaxOfferMetroStations)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var offerID string
+		var m domain.OfferMetro
+		if err := rows.Scan(&offerID, &m.StationID, &m.Name, &m.LineName, &m.LineColor, &m.DistanceMeters); err != nil {
+			return nil, err
+		}
+		m.WalkMinutes = domain.WalkMinutes(m.DistanceMeters)
+		m.TransportMinutes = domain.TransportMinutes(m.DistanceMeters)
+		metro[offerID] = append(metro[offerID], m)
+	}
+	return metro, rows.Err()
+}
+
+// fetchMetroForFeed fills in the nearest stations of feed offers
+func fetchMetroForFeed(ctx context.Context, db *pgxpool.Pool, offers []domain.OfferInFeed) error {
+	ids := make([]string, len(offers))
+	for i := range offers {
+		ids[i] = offers[i].ID
+	}
+	metro, err := fetchOfferMetro(ctx, db, ids)
+	if err != nil {
+		return err
+	}
+	for i := range offers {
+		offers[i].MetroStations = metro[offers[i].ID]
+		if offers[i].MetroStations == nil {
+			offers[i].MetroStations = []domain.OfferMetro{}
+		}
+	}
+	return nil
+}
+
 func (r *OfferRepository) GetByID(ctx context.Context, id string) (*domain.Offer, error) {
 	offer, err := scanOffer(r.db.QueryRow(ctx, getOfferByIDQuery, id))
 	if err != nil {
@@ -415,13 +735,23 @@ func (r *OfferRepository) GetByID(ctx context.Context, id string) (*domain.Offer
 		offer.ImageURLs = []string{}
 	}
 
+	metro, err := fetchOfferMetro(ctx, r.db, []string{offer.ID})
+	if err != nil {
+		r.log.Error(ctx, "failed to load metro for offer", zap.String("id", id), zap.Error(err))
+		return nil, err
+	}
+	offer.MetroStations = metro[offer.ID]
+	if offer.MetroStations == nil {
+		offer.MetroStations = []domain.OfferMetro{}
+	}
+
 	return offer, nil
 }
 
-func (r *OfferRepository) List(ctx context.Context, page, limit int) (*domain.OffersInFeed, error) {
+func (r *OfferRepository) List(ctx context.Context, page, limit int, sort domain.OfferSort) (*domain.OffersInFeed, error) {
 	offset := (page - 1) * limit
 
-	rows, err := r.db.Query(ctx, listOffersQuery, limit, offset)
+	rows, err := r.db.Query(ctx, listOffersQuery, limit, offset, sort)
 	if err != nil {
 		r.log.Error(ctx, "failed to list offers", zap.Error(err))
 		return nil, err
@@ -433,6 +763,10 @@ func (r *OfferRepository) List(ctx context.Context, page, limit int) (*domain.Of
 		r.log.Error(ctx, "failed to scan offers for feed", zap.Error(err))
 		return nil, err
 	}
+	if err := fetchMetroForFeed(ctx, r.db, offers); err != nil {
+		r.log.Error(ctx, "failed to load metro for offers", zap.Error(err))
+		return nil, err
+	}
 
 	total, err := r.CountAll(ctx)
 	if err != nil {
@@ -453,11 +787,21 @@ func (r *OfferRepository) List(ctx context.Context, page, limit int) (*domain.Of
 }
 
 func (r *OfferRepository) Create(ctx context.Context, offer *domain.Offer) error {
+	return r.create(ctx, offer, "")
+}
+
+// CreateFromDraft creates the offer and deletes the draft it was published
+// from in one transaction, so a draft is never published twice
+func (r *OfferRepository) CreateFromDraft(ctx context.Context, offer *domain.Offer, draftID string) error {
+	return r.create(ctx, offer, draftID)
+}
+
+func (r *OfferRepository) create(ctx context.Context, offer *domain.Offer, draftID string) error {
 	now := time.Now().UTC()
 	offer.CreatedAt = now
 	offer.UpdatedAt = now
 
-	return pgx.BeginFunc(ctx, r.db, func(tx pgx.Tx) error {
+	return auditedTx(ctx, r.db, func(tx pgx.Tx) error {
 		err := tx.QueryRow(ctx, createOfferQuery,
 			offer.ID,
 			offer.UserID,
@@ -478,23 +822,39 @@ func (r *OfferRepository) Create(ctx context.Context, offer *domain.Offer) error
 			offer.RentalPeriod,
 			offer.LivingArea,
 			offer.KitchenArea,
+			offer.Status,
+			offer.ExpiresAt,
+			offer.Attributes,
 		).Scan(&offer.ID)
 		if err != nil {
 			r.log.Error(ctx, "failed to create offer", zap.Error(err))
 			return err // triggers ROLLBACK
 		}
 
-		// Insert photos
-		for _, url := range offer.ImageURLs {
-			_, err := tx.Exec(ctx,
-				"INSERT INTO offer_photo (offer_id, url, created_at, updated_at) VALUES ($1, $2, $3, $3)",
-				offer.ID, url, now)
+		if _, err := tx.Exec(ctx, insertOfferStatusHistoryQuery,
+			offer.ID, nil, offer.Status, offer.UserID, nil, now,
+		); err != nil {
+			r.log.Error(ctx, "failed to record initial offer status", zap.String("offer_id", offer.ID), zap.Error(err))
+			return err
+		}
+
+		if err := syncPhotos(ctx, tx, offerPhotoTable, offer.ID, offer.ImageURLs, now); err != nil {
+			r.log.Error(ctx, "failed to insert offer photos", zap.String("offer_id", offer.ID), zap.Error(err))
+			return err
+		}
+		if err := attachUploads(ctx, tx, offer.UserID, offer.ImageURLs); err != nil {
+			r.log.Error(ctx, "failed to attach offer images", zap.String("offer_id", offer.ID), zap.Error(err))
+			return err
+		}
+
+		if draftID != "" {
+			tag, err := tx.Exec(ctx, deletePublishedDraftQuery, draftID, offer.UserID)
 			if err != nil {
-				r.log.Warn(ctx, "failed to insert photo",
-					zap.String("offer_id", offer.ID),
-					zap.String("url", url),
-					zap.Error(err))
-				// Continue on photo error (as in original)
+				r.log.Error(ctx, "failed to delete published draft", zap.String("draft_id", draftID), zap.Error(err))
+				return err
+			}
+			if tag.RowsAffected() == 0 {
+				return domain.ErrDraftNotFound
 			}
 		}
 
@@ -503,60 +863,164 @@ func (r *OfferRepository) Create(ctx context.Context, offer *domain.Offer) error
 	})
 }
 
-func (r *OfferRepository) Update(ctx context.Context, offer *domain.Offer) error {
-	offer.UpdatedAt = time.Now().UTC()
+// Update writes only the fields present in the patch; null clears a column.
+// "image_urls" replaces the photo list, keeping captions, tags and cover of
+// the photos that stay. With a version the write only applies while the
+// offer is still at it.
+// Update applies the patch; a non-nil change moves the offer to another
+// status in the same transaction
+func (r *OfferRepository) Update(ctx context.Context, userID, id string, patch domain.Patch, version *int, change *domain.OfferStatusChange) error {
+	now := time.Now().UTC()
+	sets, args := patchAssignments(patch, offerPatchColumns, 3)
+	sets = append(sets, fmt.Sprintf("updated_at = $%d", len(args)+3))
+	query := fmt.Sprintf(updateOfferQueryTmpl, strings.Join(sets, ", "))
 
-	return pgx.BeginFunc(ctx, r.db, func(tx pgx.Tx) error {
-		_, err := tx.Exec(ctx, updateOfferQuery,
-			offer.LocationID,
-			offer.HousingComplexID,
-			offer.Title,
-			offer.Description,
-			offer.Price,
-			offer.Area,
-			offer.Address,
-			offer.Rooms,
-			offer.PropertyType,
-			offer.OfferType,
-			offer.Status,
-			offer.Floor,
-			offer.TotalFloors,
-			offer.Deposit,
-			offer.Commission,
-			offer.RentalPeriod,
-			offer.LivingArea,
-			offer.KitchenArea,
-			offer.UpdatedAt,
-			offer.ID,
-		)
+	return auditedTx(ctx, r.db, func(tx pgx.Tx) error {
+		tag, err := tx.Exec(ctx, query, append([]any{id, version}, append(args, now)...)...)
 		if err != nil {
-			r.log.Error(ctx, "failed to update offer", zap.String("id", offer.ID), zap.Error(err))
+			r.log.Error(ctx, "failed to update offer", zap.String("id", id), zap.Error(err))
 			return err
 		}
+		if tag.RowsAffected() == 0 {
+			return notFoundOrStale(version, domain.ErrOfferNotFound)
+		}
+
+		if patch.Has("image_urls") {
+			urls, _ := patch["image_urls"].([]string)
+			if err := syncPhotos(ctx, tx, offerPhotoTable, id, urls, now); err != nil {
+				r.log.Error(ctx, "failed to sync offer photos", zap.String("offer_id", id), zap.Error(err))
+				return err
+			}
+			if err := attachUploads(ctx, tx, userID, urls); err != nil {
+				r.log.Error(ctx, "failed to attach offer images", zap.String("offer_id", id), zap.Error(err))
+				return err
+			}
+		}
+
+		if change != nil {
+			if err := changeOfferStatus(ctx, tx, change, nil, nil); err != nil {
+				r.log.Error(ctx, "failed to change offer status", zap.String("offer_id", id), zap.Error(err))
+				return err
+			}
+		}
+
+		r.log.Info(ctx, "updated offer", zap.String("id", id))
+		return nil
+	})
+}
 
-		_, _ = tx.Exec(ctx, "DELETE FROM offer_photo WHERE offer_id = $1", offer.ID)
-		now := time.Now().UTC()
-		for _, url := range offer.ImageURLs {
-			_, _ = tx.Exec(ctx,
-				"INSERT INTO offer_photo (offer_id, url, created_at, updated_at) VALUES ($1, $2, $3, $3)",
-				offer.ID, url, now)
+// Delete removes the offer; with a version only while it is still current
+func (r *OfferRepository) Delete(ctx context.Context, id string, version *int) error {
+	return auditedTx(ctx, r.db, func(tx pgx.Tx) error {
+		tag, err := tx.Exec(ctx, deleteOfferQuery, id, version)
+		if err != nil {
+			r.log.Error(ctx, "failed to delete offer", zap.String("id", id), zap.Error(err))
+			return err
 		}
+		if tag.RowsAffected() == 0 {
+			return notFoundOrStale(version, domain.ErrOfferNotFound)
+		}
+		r.log.Info(ctx, "deleted offer", zap.String("id", id))
+		return nil
+	})
+}
 
-		r.log.Info(ctx, "updated offer", zap.String("id", offer.ID))
+// FlagDuplicatePhotos records photos of the offer that near-match other users'
+// photos and returns how many new flags were raised
+func (r *OfferRepository) FlagDuplicatePhotos(ctx context.Context, offerID string, maxDistance int) (int64, error) {
+	tag, err := r.db.Exec(ctx, flagDuplicatePhotosQuery, offerID, maxDistance)
+	if err != nil {
+		r.log.Error(ctx, "failed to flag duplicate photos", zap.String("offer_id", offerID), zap.Error(err))
+		return 0, err
+	}
+	return tag.RowsAffected(), nil
+}
+
+// ChangeStatus moves the offer from change.FromStatus to change.ToStatus and
+// records the change. Returns ErrStatusConflict if the status was changed
+// meanwhile, or ErrVersionMismatch if a version is given and is stale.
+func (r *OfferRepository) ChangeStatus(ctx context.Context, change *domain.OfferStatusChange, expiresAt *time.Time, version *int) error {
+	return auditedTx(ctx, r.db, func(tx pgx.Tx) error {
+		if err := changeOfferStatus(ctx, tx, change, expiresAt, version); err != nil {
+			if !errors.Is(err, domain.ErrVersionMismatch) && !errors.Is(err, domain.ErrStatusConflict) {
+				r.log.Error(ctx, "failed to change offer status", zap.String("offer_id", change.OfferID), zap.Error(err))
+			}
+			return err
+		}
 		return nil
 	})
 }
 
-func (r *OfferRepository) Delete(ctx context.Context, id string) error {
-	_, err := r.db.Exec(ctx, deleteOfferQuery, id)
+// changeOfferStatus is ChangeStatus within a transaction of the caller, for
+// writes that move the offer to another status along with other changes
+func changeOfferStatus(ctx context.Context, tx pgx.Tx, change *domain.OfferStatusChange, expiresAt *time.Time, version *int) error {
+	tag, err := tx.Exec(ctx, changeOfferStatusQuery,
+		change.OfferID,
+		change.FromStatus,
+		change.ToStatus,
+		change.ChangedAt,
+		expiresAt,
+		change.ChangedBy,
+		change.Reason,
+		version,
+	)
 	if err != nil {
-		r.log.Error(ctx, "failed to delete offer", zap.String("id", id), zap.Error(err))
 		return err
 	}
-	r.log.Info(ctx, "deleted offer", zap.String("id", id))
+	if tag.RowsAffected() == 0 {
+		if version != nil {
+			return domain.ErrVersionMismatch
+		}
+		return domain.ErrStatusConflict
+	}
 	return nil
 }
 
+// Renew extends the listing period of a live offer
+func (r *OfferRepository) Renew(ctx context.Context, offerID string, expiresAt time.Time) error {
+	return auditedTx(ctx, r.db, func(tx pgx.Tx) error {
+		tag, err := tx.Exec(ctx, renewOfferQuery, offerID, expiresAt)
+		if err != nil {
+			r.log.Error(ctx, "failed to renew offer", zap.String("offer_id", offerID), zap.Error(err))
+			return err
+		}
+		if tag.RowsAffected() == 0 {
+			return domain.ErrStatusConflict
+		}
+		return nil
+	})
+}
+
+// ExpireOffers moves live offers past their expiry date to the expired state
+func (r *OfferRepository) ExpireOffers(ctx context.Context, now time.Time) (int64, error) {
+	tag, err := r.db.Exec(ctx, expireOffersQuery, now)
+	if err != nil {
+		r.log.Error(ctx, "failed to expire offers", zap.Error(err))
+		return 0, err
+	}
+	return tag.RowsAffected(), nil
+}
+
+func (r *OfferRepository) ListStatusHistory(ctx context.Context, offerID string) ([]domain.OfferStatusChange, error) {
+	rows, err := r.db.Query(ctx, listOfferStatusHistoryQuery, offerID)
+	if err != nil {
+		r.log.Error(ctx, "failed to list offer status history", zap.String("offer_id", offerID), zap.Error(err))
+		return nil, err
+	}
+	defer rows.Close()
+
+	history := []domain.OfferStatusChange{}
+	for rows.Next() {
+		var c domain.OfferStatusChange
+		if err := rows.Scan(&c.ID, &c.OfferID, &c.FromStatus, &c.ToStatus, &c.ChangedBy, &c.Reason, &c.ChangedAt); err != nil {
+			r.log.Error(ctx, "failed to scan offer status change", zap.Error(err))
+			return nil, err
+		}
+		history = append(history, c)
+	}
+	return history, rows.Err()
+}
+
 func (r *OfferRepository) CountAll(ctx context.Context) (int, error) {
 	var total int
 	err := r.db.QueryRow(ctx, countAllOffersQuery).Scan(&total)
@@ -567,7 +1031,9 @@ func (r *OfferRepository) CountAll(ctx context.Context) (int, error) {
 	return total, nil
 }
 
-func (r *OfferRepository) ListByUserID(ctx context.Context, userID string, page, limit int) (*domain.OffersInFeed, error) {
+// ListByUserID returns the user's offers, newest first; only the active ones
+// unless withUnlisted, which is for the owner
+func (r *OfferRepository) ListByUserID(ctx context.Context, userID string, page, limit int, withUnlisted bool) (*domain.OffersInFeed, error) {
 	if page < 1 {
 		page = 1
 	}
@@ -577,7 +1043,7 @@ func (r *OfferRepository) ListByUserID(ctx context.Context, userID string, page,
 	offset := (page - 1) * limit
 
 	// Fetch offers
-	rows, err := r.db.Query(ctx, listOffersByUserIDQuery, userID, limit, offset)
+	rows, err := r.db.Query(ctx, listOffersByUserIDQuery, userID, limit, offset, withUnlisted)
 	if err != nil {
 		r.log.Error(ctx, "failed to list offers by user", zap.String("user_id", userID), zap.Error(err))
 		return nil, err
@@ -589,10 +1055,14 @@ func (r *OfferRepository) ListByUserID(ctx context.Context, userID string, page,
 		r.log.Error(ctx, "failed to scan offers for user feed", zap.String("user_id", userID), zap.Error(err))
 		return nil, err
 	}
+	if err := fetchMetroForFeed(ctx, r.db, offers); err != nil {
+		r.log.Error(ctx, "failed to load metro for offers", zap.Error(err))
+		return nil, err
+	}
 
 	// Fetch total count for pagination metadata
 	var total int
-	err = r.db.QueryRow(ctx, countOffersByUserIDQuery, userID).Scan(&total)
+	err = r.db.QueryRow(ctx, countOffersByUserIDQuery, userID, withUnlisted).Scan(&total)
 	if err != nil {
 		r.log.Warn(ctx, "failed to count total offers for user", zap.String("user_id", userID), zap.Error(err))
 		total = len(offers) // fallback
@@ -617,7 +1087,7 @@ func (r *OfferRepository) FilterOffers(ctx context.Context, f *domain.OfferFilte
 			o.id, o.user_id, o.offer_type, o.property_type, o.price, o.area, o.rooms,
 			o.floor, o.total_floors, o.address,
 			ms.name AS metro, op.url AS image_url,
-			o.created_at, o.updated_at
+			o.created_at, o.updated_at, o.status
 		FROM offer o
 		LEFT JOIN (
 			SELECT DISTINCT ON (location_id)
@@ -630,8 +1100,9 @@ func (r *OfferRepository) FilterOffers(ctx context.Context, f *domain.OfferFilte
 			SELECT DISTINCT ON (offer_id)
 				offer_id, url
 			FROM offer_photo
-			ORDER BY offer_id, created_at ASC
+			ORDER BY offer_id, is_cover DESC, position ASC, created_at ASC
 		) op ON op.offer_id = o.id
+		LEFT JOIN offer_popularity pop ON pop.offer_id = o.id
 		WHERE 1=1
 	`
 
@@ -683,15 +1154,91 @@ func (r *OfferRepository) FilterOffers(ctx context.Context, f *domain.OfferFilte
 		args = append(args, *f.Status)
 		argIndex++
 	}
-	if f.Utug != nil {
-		// гипотетическая колонка o.utug (BOOLEAN)
-		baseQuery += fmt.Sprintf(" AND o.utug = $%d", argIndex)
-		args = append(args, *f.Utug)
+	if f.ComplexID != nil {
+		baseQuery += fmt.Sprintf(" AND o.housing_complex_id = $%d", argIndex)
+		args = append(args, *f.ComplexID)
 		argIndex++
 	}
+	if f.RegionSlug != nil {
+		baseQuery += fmt.Sprintf(` AND o.location_id IN (
+			SELECT l.id FROM location l WHERE l.region_id IN (
+				WITH RECURSIVE sub_region AS (
+					SELECT id FROM region WHERE slug = $%d
+					UNION
+					SELECT r.id FROM region r JOIN sub_region s ON r.parent_id = s.id
+				)
+				SELECT id FROM sub_region))`, argIndex)
+		args = append(args, *f.RegionSlug)
+		argIndex++
+	}
+	if len(f.MetroIDs) > 0 || f.MetroWalk != nil {
+		// Near one of the stations, within the walk when it is given
+		baseQuery += fmt.Sprintf(` AND EXISTS (
+			SELECT 1 FROM location_metro lm
+			WHERE lm.location_id = o.location_id
+			  AND ($%d::UUID[] IS NULL OR lm.metro_station_id = ANY($%d))
+			  AND ($%d::INT IS NULL OR lm.distance_meters <= $%d))`, argIndex, argIndex, argIndex+1, argIndex+1)
+		var stations []string
+		if len(f.MetroIDs) > 0 {
+			stations = f.MetroIDs
+		}
+		var maxDistance *int
+		if f.MetroWalk != nil {
+			d := *f.MetroWalk * domain.WalkMetersPerMinute
+			maxDistance = &d
+		}
+		args = append(args, stations, maxDistance)
+		argIndex += 2
+	}
+	for _, af := range f.Attributes {
+		// Keys come from the catalogue, values are passed as arguments
+		switch {
+		case af.Bool != nil:
+			baseQuery += fmt.Sprintf(" AND o.attributes @> jsonb_build_object($%d::TEXT, $%d::BOOLEAN)", argIndex, argIndex+1)
+			args = append(args, af.Key, *af.Bool)
+			argIndex += 2
+		case len(af.Values) > 0:
+			baseQuery += fmt.Sprintf(" AND o.attributes->>$%d = ANY($%d::TEXT[])", argIndex, argIndex+1)
+			args = append(args, af.Key, af.Values)
+			argIndex += 2
+		default:
+			// Offers without the attribute fall out on the NULL comparison
+			baseQuery += fmt.Sprintf(` AND ($%d::NUMERIC IS NULL OR (o.attributes->>$%d)::NUMERIC >= $%d)
+				AND ($%d::NUMERIC IS NULL OR (o.attributes->>$%d)::NUMERIC <= $%d)`,
+				argIndex+1, argIndex, argIndex+1, argIndex+2, argIndex, argIndex+2)
+			args = append(args, af.Key, af.Min, af.Max)
+			argIndex += 3
+		}
+	}
+	if f.ShortTerm != nil {
+		not := ""
+		if !*f.ShortTerm {
+			not = "NOT "
+		}
+		baseQuery += " AND " + not + "EXISTS (SELECT 1 FROM offer_short_term st WHERE st.offer_id = o.id)"
+	}
+	if f.CheckIn != nil && f.CheckOut != nil {
+		// The stay must fit the terms and miss every active booking
+		baseQuery += fmt.Sprintf(` AND EXISTS (
+				SELECT 1 FROM offer_short_term st
+				WHERE st.offer_id = o.id
+				  AND $%[2]d::DATE - $%[1]d::DATE >= st.min_nights
+				  AND (st.max_nights IS NULL OR $%[2]d::DATE - $%[1]d::DATE <= st.max_nights))
+			AND NOT EXISTS (
+				SELECT 1 FROM offer_booking ob
+				WHERE ob.offer_id = o.id AND ob.status = 'active'
+				  AND daterange(ob.check_in, ob.check_out) && daterange($%[1]d::DATE, $%[2]d::DATE))`,
+			argIndex, argIndex+1)
+		args = append(args, *f.CheckIn, *f.CheckOut)
+		argIndex += 2
+	}
 
 	// Pagination
-	baseQuery += fmt.Sprintf(" ORDER BY o.created_at DESC LIMIT $%d OFFSET $%d", argIndex, argIndex+1)
+	order := "o.created_at DESC, o.id"
+	if f.Sort == domain.OfferSortPopular {
+		order = "COALESCE(pop.score, 0) DESC, " + order
+	}
+	baseQuery += fmt.Sprintf(" ORDER BY %s LIMIT $%d OFFSET $%d", order, argIndex, argIndex+1)
 	args = append(args, limit, offset)
 
 	rows, err := r.db.Query(ctx, baseQuery, args...)
@@ -706,23 +1253,147 @@ func (r *OfferRepository) FilterOffers(ctx context.Context, f *domain.OfferFilte
 		r.log.Error(ctx, "failed to scan filtered offers", zap.Error(err))
 		return nil, err
 	}
+	if err := fetchMetroForFeed(ctx, r.db, offers); err != nil {
+		r.log.Error(ctx, "failed to load metro for offers", zap.Error(err))
+		return nil, err
+	}
 
 	return offers, nil
 }
 
-func (r *OfferRepository) GetOfferPriceHistory(ctx context.Context, id string) ([]domain.PricePoint, error) {
-	const query = `SELECT get_offer_price_history($1)::json`
+// ListSimilar ranks active offers by their similarity to the given one
+func scanSimilarityFeatures(scanner interface {
+	Scan(dest ...any) error
+}) (*domain.SimilarityFeatures, error) {
+	var f domain.SimilarityFeatures
+	err := scanner.Scan(
+		&f.OfferID,
+		&f.PropertyType,
+		&f.HousingComplexID,
+		&f.MetroStationID,
+		&f.RegionID,
+		&f.Rooms,
+		&f.Area,
+		&f.Price,
+		&f.Lat,
+		&f.Lon,
+		&f.CreatedAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+	return &f, nil
+}
+
+// ListSimilar returns a page of the offers like the given one, best match
+// first. The database narrows down the candidates, the ranking is done by
+// domain.SimilarityFeatures.
+func (r *OfferRepository) ListSimilar(ctx context.Context, offerID string, limit, offset int) ([]domain.OfferInFeed, error) {
+	target, err := scanSimilarityFeatures(r.db.QueryRow(ctx, getSimilarityTargetQuery, offerID))
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, domain.ErrOfferNotFound
+		}
+		r.log.Error(ctx, "failed to get similarity features", zap.String("offer_id", offerID), zap.Error(err))
+		return nil, err
+	}
 
-	var jsonData []byte
-	err := r.db.QueryRow(ctx, query, id).Scan(&jsonData)
+	rows, err := r.db.Query(ctx, listSimilarityCandidatesQuery, offerID, domain.SimilarMaxDistanceKm)
 	if err != nil {
-		return nil, fmt.Errorf("call get_offer_price_history: %w", err)
+		r.log.Error(ctx, "failed to list similar offer candidates", zap.String("offer_id", offerID), zap.Error(err))
+		return nil, err
+	}
+	var candidates []domain.SimilarityFeatures
+	for rows.Next() {
+		c, err := scanSimilarityFeatures(rows)
+		if err != nil {
+			rows.Close()
+			r.log.Error(ctx, "failed to scan similar offer candidate", zap.Error(err))
+			return nil, err
+		}
+		candidates = append(candidates, *c)
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		r.log.Error(ctx, "failed to list similar offer candidates", zap.String("offer_id", offerID), zap.Error(err))
+		return nil, err
+	}
+
+	ranked := target.RankSimilar(candidates)
+	if offset >= len(ranked) {
+		return nil, nil
+	}
+	ranked = ranked[offset:min(offset+limit, len(ranked))]
+	ids := make([]string, len(ranked))
+	position := make(map[string]int, len(ranked))
+	for i, c := range ranked {
+		ids[i] = c.OfferID
+		position[c.OfferID] = i
 	}
 
-	var points []domain.PricePoint
-	if err := json.Unmarshal(jsonData, &points); err != nil {
-		return nil, fmt.Errorf("unmarshal price history: %w", err)
+	feedRows, err := r.db.Query(ctx, listOffersInFeedByIDsQuery, ids)
+	if err != nil {
+		r.log.Error(ctx, "failed to list similar offers", zap.String("offer_id", offerID), zap.Error(err))
+		return nil, err
 	}
+	defer feedRows.Close()
 
-	return points, nil
-}
\ No newline at end of file
+	offers, err := scanOffersInFeed(feedRows)
+	if err != nil {
+		r.log.Error(ctx, "failed to scan similar offers", zap.Error(err))
+		return nil, err
+	}
+	slices.SortFunc(offers, func(a, b domain.OfferInFeed) int {
+		return position[a.ID] - position[b.ID]
+	})
+	if err := fetchMetroForFeed(ctx, r.db, offers); err != nil {
+		r.log.Error(ctx, "failed to load metro for offers", zap.Error(err))
+		return nil, err
+	}
+	return offers, nil
+}
+
+// ListPriceEvents returns the offer price history, oldest first
+func (r *OfferRepository) ListPriceEvents(ctx context.Context, offerID string) ([]domain.PriceEvent, error) {
+	rows, err := r.db.Query(ctx, listOfferPriceEventsQuery, offerID)
+	if err != nil {
+		r.log.Error(ctx, "failed to list price history", zap.String("offer_id", offerID), zap.Error(err))
+		return nil, err
+	}
+	defer rows.Close()
+
+	var events []domain.PriceEvent
+	for rows.Next() {
+		var e domain.PriceEvent
+		if err := rows.Scan(&e.Date, &e.OldPrice, &e.Price, &e.Reason, &e.ChangedBy); err != nil {
+			r.log.Error(ctx, "failed to scan price event", zap.Error(err))
+			return nil, err
+		}
+		events = append(events, e)
+	}
+	return events, rows.Err()
+}
+
+// PriceTrend returns one point per period between q.From and q.To
+func (r *OfferRepository) PriceTrend(ctx context.Context, q *domain.PriceTrendQuery) ([]domain.PriceTrendPoint, error) {
+	rows, err := r.db.Query(ctx, priceTrendQuery,
+		q.Scope, q.ScopeID, q.Bucket, q.OfferType, q.PropertyType, q.From, q.To,
+	)
+	if err != nil {
+		r.log.Error(ctx, "failed to get price trend",
+			zap.String("scope", string(q.Scope)), zap.String("scope_id", q.ScopeID), zap.Error(err))
+		return nil, err
+	}
+	defer rows.Close()
+
+	var points []domain.PriceTrendPoint
+	for rows.Next() {
+		var p domain.PriceTrendPoint
+		if err := rows.Scan(&p.PeriodStart, &p.MedianPricePerSqm, &p.AvgPricePerSqm, &p.Offers); err != nil {
+			r.log.Error(ctx, "failed to scan price trend point", zap.Error(err))
+			return nil, err
+		}
+		points = append(points, p)
+	}
+	return points, rows.Err()
+}
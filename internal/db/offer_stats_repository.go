@@ -0,0 +1,268 @@
+package db
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+
+	"github.com/go-park-mail-ru/2025_2_Avrora/internal/domain"
+	"github.com/go-park-mail-ru/2025_2_Avrora/internal/log"
+	"github.com/jackc/pgx/v5/pgxpool"
+	"go.uber.org/zap"
+)
+
+const (
+	// A view counts towards unique_viewers only the first time the viewer
+	// is seen on the offer that day
+	recordOfferViewQuery = `
+		WITH new_viewer AS (
+			INSERT INTO offer_viewer (offer_id, day, viewer_key)
+			VALUES ($1, CURRENT_DATE, $2)
+			ON CONFLICT DO NOTHING
+			RETURNING 1
+		)
+		INSERT INTO offer_stats_daily (offer_id, day, views, unique_viewers)
+		VALUES ($1, CURRENT_DATE, 1, (SELECT COUNT(*) FROM new_viewer))
+		ON CONFLICT (offer_id, day) DO UPDATE SET
+			views = offer_stats_daily.views + 1,
+			unique_viewers = offer_stats_daily.unique_viewers + EXCLUDED.unique_viewers`
+
+	// Adding an offer that is already in favorites changes nothing; adding
+	// it again after removing counts only the first time that day
+	addOfferFavoriteQuery = `
+		WITH added AS (
+			INSERT INTO offer_favorite (user_id, offer_id)
+			VALUES ($1, $2)
+			ON CONFLICT DO NOTHING
+			RETURNING offer_id
+		), first_today AS (
+			INSERT INTO offer_viewer (offer_id, day, action, viewer_key)
+			SELECT offer_id, CURRENT_DATE, 'favorite', $3 FROM added
+			ON CONFLICT DO NOTHING
+			RETURNING offer_id
+		)
+		INSERT INTO offer_stats_daily (offer_id, day, favorites)
+		SELECT offer_id, CURRENT_DATE, 1 FROM first_today
+		ON CONFLICT (offer_id, day) DO UPDATE SET
+			favorites = offer_stats_daily.favorites + 1`
+
+	removeOfferFavoriteQuery = `DELETE FROM offer_favorite WHERE user_id = $1 AND offer_id = $2`
+
+	countOfferFavoritesQuery = `SELECT COUNT(*) FROM offer_favorite WHERE offer_id = $1`
+
+	listFavoriteOffersQuery = `
+		SELECT
+			o.id, o.user_id, o.offer_type, o.property_type, o.price, o.area, o.rooms,
+			o.floor, o.total_floors, o.address,
+			ms.name AS metro, op.url AS image_url,
+			o.created_at, o.updated_at, o.status
+		FROM offer_favorite f
+		JOIN offer o ON o.id = f.offer_id
+		LEFT JOIN (
+			SELECT DISTINCT ON (location_id)
+				location_id, metro_station_id
+			FROM location_metro
+			ORDER BY location_id, distance_meters ASC
+		) lm ON lm.location_id = o.location_id
+		LEFT JOIN metro_station ms ON ms.id = lm.metro_station_id
+		LEFT JOIN (
+			SELECT DISTINCT ON (offer_id)
+				offer_id, url
+			FROM offer_photo
+			ORDER BY offer_id, is_cover DESC, position ASC, created_at ASC
+		) op ON op.offer_id = o.id
+		WHERE f.user_id = $1 AND o.status IN ('active', 'paused', 'sold')
+		ORDER BY f.created_at DESC, o.id
+		LIMIT $2 OFFSET $3`
+
+	getOfferContactQuery = `
+		SELECT o.id, o.user_id, COALESCE(p.first_name, ''), COALESCE(p.last_name, ''), COALESCE(p.phone, '')
+		FROM offer o
+		LEFT JOIN profile p ON p.user_id = o.user_id
+		WHERE o.id = $1`
+
+	// A viewer revealing the contacts again the same day is not counted
+	recordContactRevealQuery = `
+		WITH first_today AS (
+			INSERT INTO offer_viewer (offer_id, day, action, viewer_key)
+			VALUES ($1, CURRENT_DATE, 'contact', $2)
+			ON CONFLICT DO NOTHING
+			RETURNING offer_id
+		)
+		INSERT INTO offer_stats_daily (offer_id, day, contact_reveals)
+		SELECT offer_id, CURRENT_DATE, 1 FROM first_today
+		ON CONFLICT (offer_id, day) DO UPDATE SET
+			contact_reveals = offer_stats_daily.contact_reveals + 1`
+
+	listOfferDailyStatsQuery = `
+		SELECT day, views, unique_viewers, favorites, contact_reveals
+		FROM offer_stats_daily
+		WHERE offer_id = $1 AND day > CURRENT_DATE - $2::INT
+		ORDER BY day ASC`
+
+	summarizeOfferStatsQuery = `
+		SELECT
+			COALESCE(s.offer_id, f.offer_id),
+			COALESCE(s.views, 0),
+			COALESCE(s.daily_viewers, 0),
+			COALESCE(s.contact_reveals, 0),
+			COALESCE(f.favorites, 0)
+		FROM (
+			SELECT offer_id,
+			       SUM(views)::INT AS views,
+			       SUM(unique_viewers)::INT AS daily_viewers,
+			       SUM(contact_reveals)::INT AS contact_reveals
+			FROM offer_stats_daily
+			WHERE offer_id = ANY($1) AND day > CURRENT_DATE - $2::INT
+			GROUP BY offer_id
+		) s
+		FULL JOIN (
+			SELECT offer_id, COUNT(*)::INT AS favorites
+			FROM offer_favorite
+			WHERE offer_id = ANY($1)
+			GROUP BY offer_id
+		) f ON f.offer_id = s.offer_id`
+
+	purgeOfferViewersQuery = `DELETE FROM offer_viewer WHERE day < CURRENT_DATE`
+)
+
+type OfferStatsRepository struct {
+	db  *pgxpool.Pool
+	log *log.Logger
+}
+
+func NewOfferStatsRepository(db *pgxpool.Pool, log *log.Logger) *OfferStatsRepository {
+	return &OfferStatsRepository{db: db, log: log}
+}
+
+func (r *OfferStatsRepository) RecordView(ctx context.Context, offerID, viewerKey string) error {
+	if _, err := r.db.Exec(ctx, recordOfferViewQuery, offerID, viewerKey); err != nil {
+		r.log.Error(ctx, "failed to record offer view", zap.String("offer_id", offerID), zap.Error(err))
+		return err
+	}
+	return nil
+}
+
+func (r *OfferStatsRepository) AddFavorite(ctx context.Context, userID, offerID, viewerKey string) error {
+	if _, err := r.db.Exec(ctx, addOfferFavoriteQuery, userID, offerID, viewerKey); err != nil {
+		r.log.Error(ctx, "failed to add favorite", zap.String("offer_id", offerID), zap.Error(err))
+		return err
+	}
+	return nil
+}
+
+func (r *OfferStatsRepository) RemoveFavorite(ctx context.Context, userID, offerID string) error {
+	if _, err := r.db.Exec(ctx, removeOfferFavoriteQuery, userID, offerID); err != nil {
+		r.log.Error(ctx, "failed to remove favorite", zap.String("offer_id", offerID), zap.Error(err))
+		return err
+	}
+	return nil
+}
+
+func (r *OfferStatsRepository) CountFavorites(ctx context.Context, offerID string) (int, error) {
+	var count int
+	if err := r.db.QueryRow(ctx, countOfferFavoritesQuery, offerID).Scan(&count); err != nil {
+		r.log.Error(ctx, "failed to count favorites", zap.String("offer_id", offerID), zap.Error(err))
+		return 0, err
+	}
+	return count, nil
+}
+
+// ListFavorites returns the user's favorite offers that are still on the
+// market or sold, most recently added first
+func (r *OfferStatsRepository) ListFavorites(ctx context.Context, userID string, limit, offset int) ([]domain.OfferInFeed, error) {
+	rows, err := r.db.Query(ctx, listFavoriteOffersQuery, userID, limit, offset)
+	if err != nil {
+		r.log.Error(ctx, "failed to list favorites", zap.String("user_id", userID), zap.Error(err))
+		return nil, err
+	}
+	defer rows.Close()
+
+	offers, err := scanOffersInFeed(rows)
+	if err != nil {
+		r.log.Error(ctx, "failed to scan favorite offers", zap.Error(err))
+		return nil, err
+	}
+	if err := fetchMetroForFeed(ctx, r.db, offers); err != nil {
+		r.log.Error(ctx, "failed to load metro for favorites", zap.Error(err))
+		return nil, err
+	}
+	return offers, nil
+}
+
+func (r *OfferStatsRepository) GetContact(ctx context.Context, offerID string) (*domain.OfferContact, error) {
+	var c domain.OfferContact
+	err := r.db.QueryRow(ctx, getOfferContactQuery, offerID).
+		Scan(&c.OfferID, &c.UserID, &c.FirstName, &c.LastName, &c.Phone)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, domain.ErrOfferNotFound
+		}
+		r.log.Error(ctx, "failed to get offer contact", zap.String("offer_id", offerID), zap.Error(err))
+		return nil, err
+	}
+	return &c, nil
+}
+
+func (r *OfferStatsRepository) RecordContactReveal(ctx context.Context, offerID, viewerKey string) error {
+	if _, err := r.db.Exec(ctx, recordContactRevealQuery, offerID, viewerKey); err != nil {
+		r.log.Error(ctx, "failed to record contact reveal", zap.String("offer_id", offerID), zap.Error(err))
+		return err
+	}
+	return nil
+}
+
+// DailyStats returns the counters of the last days days, today included.
+// Days without activity are left out.
+func (r *OfferStatsRepository) DailyStats(ctx context.Context, offerID string, days int) ([]domain.OfferDayStats, error) {
+	rows, err := r.db.Query(ctx, listOfferDailyStatsQuery, offerID, days)
+	if err != nil {
+		r.log.Error(ctx, "failed to list offer stats", zap.String("offer_id", offerID), zap.Error(err))
+		return nil, err
+	}
+	defer rows.Close()
+
+	var stats []domain.OfferDayStats
+	for rows.Next() {
+		var d domain.OfferDayStats
+		if err := rows.Scan(&d.Day, &d.Views, &d.UniqueViewers, &d.Favorites, &d.ContactReveals); err != nil {
+			r.log.Error(ctx, "failed to scan offer stats", zap.Error(err))
+			return nil, err
+		}
+		stats = append(stats, d)
+	}
+	return stats, rows.Err()
+}
+
+// Summaries totals the counters of the offers over the last days days.
+// Offers without any activity are left out.
+func (r *OfferStatsRepository) Summaries(ctx context.Context, offerIDs []string, days int) (map[string]domain.OfferStatsSummary, error) {
+	rows, err := r.db.Query(ctx, summarizeOfferStatsQuery, offerIDs, days)
+	if err != nil {
+		r.log.Error(ctx, "failed to summarize offer stats", zap.Error(err))
+		return nil, err
+	}
+	defer rows.Close()
+
+	summaries := make(map[string]domain.OfferStatsSummary, len(offerIDs))
+	for rows.Next() {
+		var id string
+		var s domain.OfferStatsSummary
+		if err := rows.Scan(&id, &s.Views, &s.DailyViewers, &s.ContactReveals, &s.Favorites); err != nil {
+			r.log.Error(ctx, "failed to scan offer stats summary", zap.Error(err))
+			return nil, err
+		}
+		summaries[id] = s
+	}
+	return summaries, rows.Err()
+}
+
+// PurgeViewers forgets the viewers, and who favorited or revealed, of past days
+func (r *OfferStatsRepository) PurgeViewers(ctx context.Context) (int64, error) {
+	tag, err := r.db.Exec(ctx, purgeOfferViewersQuery)
+	if err != nil {
+		r.log.Error(ctx, "failed to purge offer viewers", zap.Error(err))
+		return 0, err
+	}
+	return tag.RowsAffected(), nil
+}
@@ -0,0 +1,40 @@
+package db
+
+import (
+	"fmt"
+	"sort"
+
+	"github.com/go-park-mail-ru/2025_2_Avrora/internal/domain"
+)
+
+// patchAssignments turns the patched fields into "column = $n" assignments
+// for an UPDATE, numbering parameters from firstArg. columns maps JSON field
+// names to table columns; fields outside it are skipped, so a patch can never
+// reach a column the caller did not list. A nil value writes NULL.
+func patchAssignments(p domain.Patch, columns map[string]string, firstArg int) ([]string, []any) {
+	fields := make([]string, 0, len(p))
+	for field := range p {
+		if _, ok := columns[field]; ok {
+			fields = append(fields, field)
+		}
+	}
+	sort.Strings(fields)
+
+	sets := make([]string, 0, len(fields))
+	args := make([]any, 0, len(fields))
+	for i, field := range fields {
+		sets = append(sets, fmt.Sprintf("%s = $%d", columns[field], firstArg+i))
+		args = append(args, p[field])
+	}
+	return sets, args
+}
+
+// notFoundOrStale explains a version-checked write that touched no rows. The
+// callers have just read the row, so with a version the likely cause is a
+// concurrent write.
+func notFoundOrStale(version *int, notFound error) error {
+	if version != nil {
+		return domain.ErrVersionMismatch
+	}
+	return notFound
+}
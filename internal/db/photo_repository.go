@@ -0,0 +1,392 @@
+package db
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/go-park-mail-ru/2025_2_Avrora/internal/domain"
+	"github.com/go-park-mail-ru/2025_2_Avrora/internal/log"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+	"go.uber.org/zap"
+)
+
+// photoTable describes one of the photo tables; offer_photo and complex_photo share the layout
+type photoTable struct {
+	name       string
+	ownerCol   string
+	ownerTable string
+	notFound   error // returned when the owner does not exist
+}
+
+var (
+	offerPhotoTable   = photoTable{name: "offer_photo", ownerCol: "offer_id", ownerTable: "offer", notFound: domain.ErrOfferNotFound}
+	complexPhotoTable = photoTable{name: "complex_photo", ownerCol: "complex_id", ownerTable: "housing_complex", notFound: domain.ErrComplexNotFound}
+)
+
+// Queries are templated with the table and owner column names only, never with user input
+const (
+	listPhotosQueryTmpl = `
+		SELECT id, %[2]s, url, position, is_cover, caption, room_tag, created_at, updated_at
+		FROM %[1]s
+		WHERE %[2]s = $1
+		ORDER BY position ASC, created_at ASC`
+
+	getPhotoQueryTmpl = `
+		SELECT id, %[2]s, url, position, is_cover, caption, room_tag, created_at, updated_at
+		FROM %[1]s
+		WHERE %[2]s = $1 AND id = $2`
+
+	// Serializes photo additions to one listing: the cover and position of a
+	// new photo depend on those already there. Templated with the owner table.
+	lockPhotoOwnerQueryTmpl = `SELECT 1 FROM %s WHERE id = $1 FOR NO KEY UPDATE`
+
+	// New photo goes last; the first photo of a listing becomes its cover
+	addPhotoQueryTmpl = `
+		INSERT INTO %[1]s (%[2]s, url, position, is_cover, caption, room_tag, created_at, updated_at)
+		SELECT $1, $2,
+			COALESCE(MAX(position) + 1, 0),
+			COUNT(*) FILTER (WHERE is_cover) = 0,
+			$3, $4, $5, $5
+		FROM %[1]s
+		WHERE %[2]s = $1
+		RETURNING id, position, is_cover`
+
+	updatePhotoQueryTmpl = `
+		UPDATE %[1]s SET
+			caption = CASE WHEN $3 THEN $4 ELSE caption END,
+			room_tag = CASE WHEN $5 THEN $6::photo_room_tag_enum ELSE room_tag END,
+			updated_at = $7
+		WHERE %[2]s = $1 AND id = $2`
+
+	clearCoverQueryTmpl = `
+		UPDATE %[1]s SET is_cover = FALSE, updated_at = $2
+		WHERE %[2]s = $1 AND is_cover`
+
+	setCoverQueryTmpl = `
+		UPDATE %[1]s SET is_cover = TRUE, updated_at = $3
+		WHERE %[2]s = $1 AND id = $2`
+
+	deletePhotoQueryTmpl = `
+		DELETE FROM %[1]s
+		WHERE %[2]s = $1 AND id = $2`
+
+	listPhotoIDsQueryTmpl = `
+		SELECT id FROM %[1]s WHERE %[2]s = $1`
+
+	reorderPhotosQueryTmpl = `
+		UPDATE %[1]s p SET position = u.ord - 1, updated_at = $3
+		FROM UNNEST($2::UUID[]) WITH ORDINALITY AS u(id, ord)
+		WHERE p.%[2]s = $1 AND p.id = u.id`
+
+	// Closes the gaps left by removed photos and keeps the original order
+	compactPhotoPositionsQueryTmpl = `
+		UPDATE %[1]s p SET position = ranked.rn - 1
+		FROM (
+			SELECT id, ROW_NUMBER() OVER (ORDER BY position, created_at) AS rn
+			FROM %[1]s
+			WHERE %[2]s = $1
+		) ranked
+		WHERE p.id = ranked.id AND p.position <> ranked.rn - 1`
+
+	// Promotes the first photo when a listing has photos but no cover
+	ensureCoverQueryTmpl = `
+		UPDATE %[1]s SET is_cover = TRUE
+		WHERE id = (
+			SELECT id FROM %[1]s
+			WHERE %[2]s = $1
+			ORDER BY position, created_at
+			LIMIT 1
+		)
+		AND NOT EXISTS (SELECT 1 FROM %[1]s WHERE %[2]s = $1 AND is_cover)`
+
+	deleteMissingPhotosQueryTmpl = `
+		DELETE FROM %[1]s
+		WHERE %[2]s = $1 AND NOT (url = ANY($2::TEXT[]))`
+
+	insertNewPhotosQueryTmpl = `
+		INSERT INTO %[1]s (%[2]s, url, position, created_at, updated_at)
+		SELECT $1, u.url, u.ord - 1, $3, $3
+		FROM UNNEST($2::TEXT[]) WITH ORDINALITY AS u(url, ord)
+		WHERE NOT EXISTS (
+			SELECT 1 FROM %[1]s p WHERE p.%[2]s = $1 AND p.url = u.url
+		)`
+
+	positionPhotosByURLQueryTmpl = `
+		UPDATE %[1]s p SET position = u.ord - 1
+		FROM UNNEST($2::TEXT[]) WITH ORDINALITY AS u(url, ord)
+		WHERE p.%[2]s = $1 AND p.url = u.url AND p.position <> u.ord - 1`
+)
+
+func (t photoTable) query(tmpl string) string {
+	return fmt.Sprintf(tmpl, t.name, t.ownerCol)
+}
+
+// syncPhotos makes the listing's photos match urls (in that order) without
+// dropping captions, tags or the cover of photos that are kept
+func syncPhotos(ctx context.Context, tx pgx.Tx, t photoTable, ownerID string, urls []string, now time.Time) error {
+	if urls == nil {
+		urls = []string{}
+	}
+	if _, err := tx.Exec(ctx, t.query(deleteMissingPhotosQueryTmpl), ownerID, urls); err != nil {
+		return err
+	}
+	if _, err := tx.Exec(ctx, t.query(insertNewPhotosQueryTmpl), ownerID, urls, now); err != nil {
+		return err
+	}
+	if _, err := tx.Exec(ctx, t.query(positionPhotosByURLQueryTmpl), ownerID, urls); err != nil {
+		return err
+	}
+	_, err := tx.Exec(ctx, t.query(ensureCoverQueryTmpl), ownerID)
+	return err
+}
+
+type PhotoRepository struct {
+	db    *pgxpool.Pool
+	table photoTable
+	log   *log.Logger
+}
+
+func NewOfferPhotoRepository(db *pgxpool.Pool, log *log.Logger) *PhotoRepository {
+	return &PhotoRepository{db: db, table: offerPhotoTable, log: log}
+}
+
+func NewComplexPhotoRepository(db *pgxpool.Pool, log *log.Logger) *PhotoRepository {
+	return &PhotoRepository{db: db, table: complexPhotoTable, log: log}
+}
+
+func scanPhoto(scanner interface {
+	Scan(dest ...any) error
+}) (*domain.Photo, error) {
+	var p domain.Photo
+	var roomTag *string
+	err := scanner.Scan(
+		&p.ID,
+		&p.OwnerID,
+		&p.URL,
+		&p.Position,
+		&p.IsCover,
+		&p.Caption,
+		&roomTag,
+		&p.CreatedAt,
+		&p.UpdatedAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+	if roomTag != nil {
+		tag := domain.PhotoRoomTag(*roomTag)
+		p.RoomTag = &tag
+	}
+	return &p, nil
+}
+
+func (r *PhotoRepository) List(ctx context.Context, ownerID string) ([]domain.Photo, error) {
+	rows, err := r.db.Query(ctx, r.table.query(listPhotosQueryTmpl), ownerID)
+	if err != nil {
+		r.log.Error(ctx, "failed to list photos", zap.String("table", r.table.name), zap.String("owner_id", ownerID), zap.Error(err))
+		return nil, err
+	}
+	defer rows.Close()
+
+	photos := []domain.Photo{}
+	for rows.Next() {
+		p, err := scanPhoto(rows)
+		if err != nil {
+			r.log.Error(ctx, "failed to scan photo", zap.String("table", r.table.name), zap.Error(err))
+			return nil, err
+		}
+		photos = append(photos, *p)
+	}
+	return photos, rows.Err()
+}
+
+func (r *PhotoRepository) Get(ctx context.Context, ownerID, photoID string) (*domain.Photo, error) {
+	p, err := scanPhoto(r.db.QueryRow(ctx, r.table.query(getPhotoQueryTmpl), ownerID, photoID))
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, domain.ErrPhotoNotFound
+		}
+		r.log.Error(ctx, "failed to get photo", zap.String("table", r.table.name), zap.String("id", photoID), zap.Error(err))
+		return nil, err
+	}
+	return p, nil
+}
+
+// Add appends a photo to the end of the listing; a non-nil change moves the
+// offer to another status in the same transaction
+func (r *PhotoRepository) Add(ctx context.Context, userID string, p *domain.Photo, change *domain.OfferStatusChange) error {
+	if change != nil && r.table != offerPhotoTable {
+		return fmt.Errorf("status change for %s photos", r.table.name)
+	}
+
+	now := time.Now().UTC()
+	p.CreatedAt = now
+	p.UpdatedAt = now
+
+	var roomTag *string
+	if p.RoomTag != nil {
+		tag := string(*p.RoomTag)
+		roomTag = &tag
+	}
+
+	return auditedTx(ctx, r.db, func(tx pgx.Tx) error {
+		var locked int
+		if err := tx.QueryRow(ctx, fmt.Sprintf(lockPhotoOwnerQueryTmpl, r.table.ownerTable), p.OwnerID).Scan(&locked); err != nil {
+			if errors.Is(err, sql.ErrNoRows) {
+				return r.table.notFound
+			}
+			r.log.Error(ctx, "failed to lock photo owner", zap.String("table", r.table.name), zap.String("owner_id", p.OwnerID), zap.Error(err))
+			return err
+		}
+
+		err := tx.QueryRow(ctx, r.table.query(addPhotoQueryTmpl),
+			p.OwnerID,
+			p.URL,
+			p.Caption,
+			roomTag,
+			now,
+		).Scan(&p.ID, &p.Position, &p.IsCover)
+		if err != nil {
+			r.log.Error(ctx, "failed to add photo", zap.String("table", r.table.name), zap.String("owner_id", p.OwnerID), zap.Error(err))
+			return err
+		}
+		if err := attachUploads(ctx, tx, userID, []string{p.URL}); err != nil {
+			r.log.Error(ctx, "failed to attach photo", zap.String("owner_id", p.OwnerID), zap.Error(err))
+			return err
+		}
+		if change != nil {
+			if err := changeOfferStatus(ctx, tx, change, nil, nil); err != nil {
+				r.log.Error(ctx, "failed to change offer status", zap.String("offer_id", p.OwnerID), zap.Error(err))
+				return err
+			}
+		}
+		return nil
+	})
+}
+
+// Update applies caption/room tag changes and moves the cover if requested
+func (r *PhotoRepository) Update(ctx context.Context, ownerID, photoID string, upd *domain.PhotoUpdate) error {
+	now := time.Now().UTC()
+
+	return pgx.BeginFunc(ctx, r.db, func(tx pgx.Tx) error {
+		var roomTag *string
+		if upd.RoomTag != nil && *upd.RoomTag != "" {
+			tag := string(*upd.RoomTag)
+			roomTag = &tag
+		}
+		var caption *string
+		if upd.Caption != nil && *upd.Caption != "" {
+			caption = upd.Caption
+		}
+
+		tag, err := tx.Exec(ctx, r.table.query(updatePhotoQueryTmpl),
+			ownerID,
+			photoID,
+			upd.Caption != nil,
+			caption,
+			upd.RoomTag != nil,
+			roomTag,
+			now,
+		)
+		if err != nil {
+			r.log.Error(ctx, "failed to update photo", zap.String("table", r.table.name), zap.String("id", photoID), zap.Error(err))
+			return err
+		}
+		if tag.RowsAffected() == 0 {
+			return domain.ErrPhotoNotFound
+		}
+
+		if upd.IsCover == nil {
+			return nil
+		}
+		if *upd.IsCover {
+			if _, err := tx.Exec(ctx, r.table.query(clearCoverQueryTmpl), ownerID, now); err != nil {
+				r.log.Error(ctx, "failed to clear cover photo", zap.String("table", r.table.name), zap.String("owner_id", ownerID), zap.Error(err))
+				return err
+			}
+			if _, err := tx.Exec(ctx, r.table.query(setCoverQueryTmpl), ownerID, photoID, now); err != nil {
+				r.log.Error(ctx, "failed to set cover photo", zap.String("table", r.table.name), zap.String("id", photoID), zap.Error(err))
+				return err
+			}
+			return nil
+		}
+
+		// Unsetting the cover hands it over to the first photo
+		if _, err := tx.Exec(ctx, r.table.query(clearCoverQueryTmpl), ownerID, now); err != nil {
+			r.log.Error(ctx, "failed to clear cover photo", zap.String("table", r.table.name), zap.String("owner_id", ownerID), zap.Error(err))
+			return err
+		}
+		_, err = tx.Exec(ctx, r.table.query(ensureCoverQueryTmpl), ownerID)
+		return err
+	})
+}
+
+// Delete removes a photo, closes the position gap and reassigns the cover if needed
+func (r *PhotoRepository) Delete(ctx context.Context, ownerID, photoID string) error {
+	return pgx.BeginFunc(ctx, r.db, func(tx pgx.Tx) error {
+		tag, err := tx.Exec(ctx, r.table.query(deletePhotoQueryTmpl), ownerID, photoID)
+		if err != nil {
+			r.log.Error(ctx, "failed to delete photo", zap.String("table", r.table.name), zap.String("id", photoID), zap.Error(err))
+			return err
+		}
+		if tag.RowsAffected() == 0 {
+			return domain.ErrPhotoNotFound
+		}
+		if _, err := tx.Exec(ctx, r.table.query(compactPhotoPositionsQueryTmpl), ownerID); err != nil {
+			r.log.Error(ctx, "failed to compact photo positions", zap.String("table", r.table.name), zap.String("owner_id", ownerID), zap.Error(err))
+			return err
+		}
+		_, err = tx.Exec(ctx, r.table.query(ensureCoverQueryTmpl), ownerID)
+		return err
+	})
+}
+
+// Reorder sets positions from photoIDs, which must list every photo of the listing exactly once
+func (r *PhotoRepository) Reorder(ctx context.Context, ownerID string, photoIDs []string) error {
+	now := time.Now().UTC()
+
+	return pgx.BeginFunc(ctx, r.db, func(tx pgx.Tx) error {
+		rows, err := tx.Query(ctx, r.table.query(listPhotoIDsQueryTmpl)+" FOR UPDATE", ownerID)
+		if err != nil {
+			r.log.Error(ctx, "failed to list photo ids", zap.String("table", r.table.name), zap.String("owner_id", ownerID), zap.Error(err))
+			return err
+		}
+		existing := make(map[string]bool)
+		for rows.Next() {
+			var id string
+			if err := rows.Scan(&id); err != nil {
+				rows.Close()
+				return err
+			}
+			existing[id] = false
+		}
+		rows.Close()
+		if err := rows.Err(); err != nil {
+			return err
+		}
+
+		if len(photoIDs) != len(existing) {
+			return domain.ErrInvalidInput
+		}
+		for _, id := range photoIDs {
+			seen, ok := existing[id]
+			if !ok {
+				return domain.ErrPhotoNotFound
+			}
+			if seen {
+				return domain.ErrInvalidInput
+			}
+			existing[id] = true
+		}
+
+		if _, err := tx.Exec(ctx, r.table.query(reorderPhotosQueryTmpl), ownerID, photoIDs, now); err != nil {
+			r.log.Error(ctx, "failed to reorder photos", zap.String("table", r.table.name), zap.String("owner_id", ownerID), zap.Error(err))
+			return err
+		}
+		return nil
+	})
+}
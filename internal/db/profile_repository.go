@@ -4,10 +4,13 @@ import (
 	"context"
 	"database/sql"
 	"errors"
+	"fmt"
+	"strings"
 	"time"
 
 	"github.com/go-park-mail-ru/2025_2_Avrora/internal/domain"
 	"github.com/go-park-mail-ru/2025_2_Avrora/internal/log"
+	"github.com/jackc/pgx/v5"
 	"github.com/jackc/pgx/v5/pgxpool"
 	"go.uber.org/zap"
 )
@@ -35,21 +38,21 @@ const (
 			p.created_at,
 			p.updated_at,
 			u.email,
-			u.role                  
+			u.role,
+			COALESCE(p.version, 0) AS version -- 0 until the profile row exists
 		FROM users u
 		LEFT JOIN profile p ON u.id = p.user_id
 		WHERE u.id = $1`
 
-	updateProfileQuery = `
-		INSERT INTO profile (user_id, first_name, last_name, phone, avatar_url, created_at, updated_at)
-		VALUES ($1, $2, $3, $4, $5, NOW(), NOW())
-		ON CONFLICT (user_id) 
-		DO UPDATE SET
-			first_name = EXCLUDED.first_name,
-			last_name = EXCLUDED.last_name,
-			phone = EXCLUDED.phone,
-			avatar_url = EXCLUDED.avatar_url,
-			updated_at = NOW();`
+	// A new row starts at version 0, matching what GetByUserID reports for
+	// a missing profile; the update right after moves it to 1
+	ensureProfileQuery = `
+		INSERT INTO profile (user_id, version) VALUES ($1, 0)
+		ON CONFLICT (user_id) DO NOTHING`
+
+	// %s is filled with the patched column assignments. A NULL version
+	// skips the check (If-Match: *).
+	updateProfileQueryTmpl = `UPDATE profile SET %s WHERE user_id = $1 AND ($2::INT IS NULL OR version = $2)`
 
 	updateUserPasswordHashQuery = `
 		UPDATE users
@@ -69,6 +72,7 @@ func NewProfileRepository(db *pgxpool.Pool, log *log.Logger) *ProfileRepository
 func (r *ProfileRepository) GetByUserID(ctx context.Context, userID string) (*domain.Profile, string, error) {
 	var p domain.Profile
 	var email, role string
+	var version int
 	var id, userIDFromDB, firstName, lastName, phone, avatarURL *string
 	var createdAt, updatedAt *time.Time
 
@@ -83,6 +87,7 @@ func (r *ProfileRepository) GetByUserID(ctx context.Context, userID string) (*do
 		&updatedAt,
 		&email,
 		&role,
+		&version,
 	)
 
 	if err != nil {
@@ -102,6 +107,7 @@ func (r *ProfileRepository) GetByUserID(ctx context.Context, userID string) (*do
 		AvatarURL: SafeStringDeref(avatarURL),
 		Role:      role,
 		Email:     email,
+		Version:   version,
 	}
 	if createdAt != nil {
 		p.CreatedAt = *createdAt
@@ -113,22 +119,43 @@ func (r *ProfileRepository) GetByUserID(ctx context.Context, userID string) (*do
 	return &p, email, nil
 }
 
-func (r *ProfileRepository) Update(ctx context.Context, userID string, upd *domain.ProfileUpdate) error {
-	if upd == nil {
-		return nil
-	}
+// profilePatchColumns are the profile columns a user may change, by JSON name
+var profilePatchColumns = map[string]string{
+	"first_name": "first_name",
+	"last_name":  "last_name",
+	"phone":      "phone",
+	"avatar_url": "avatar_url",
+}
 
-	_, err := r.db.Exec(ctx, updateProfileQuery,
-		userID,
-		upd.FirstName,
-		upd.LastName,
-		upd.Phone,
-		upd.AvatarURL,
-	)
-	if err != nil {
-		r.log.Error(ctx, "failed to upsert profile", zap.String("user_id", userID), zap.Error(err))
-	}
-	return err
+// Update writes only the fields present in the patch, creating the profile
+// row on first use; null clears a column. With a version the write only
+// applies while the profile is still at it.
+func (r *ProfileRepository) Update(ctx context.Context, userID string, patch domain.Patch, version *int) error {
+	sets, args := patchAssignments(patch, profilePatchColumns, 3)
+	sets = append(sets, "updated_at = NOW()")
+	query := fmt.Sprintf(updateProfileQueryTmpl, strings.Join(sets, ", "))
+
+	return auditedTx(ctx, r.db, func(tx pgx.Tx) error {
+		if _, err := tx.Exec(ctx, ensureProfileQuery, userID); err != nil {
+			r.log.Error(ctx, "failed to create profile", zap.String("user_id", userID), zap.Error(err))
+			return err
+		}
+		tag, err := tx.Exec(ctx, query, append([]any{userID, version}, args...)...)
+		if err != nil {
+			r.log.Error(ctx, "failed to update profile", zap.String("user_id", userID), zap.Error(err))
+			return err
+		}
+		if tag.RowsAffected() == 0 {
+			return notFoundOrStale(version, domain.ErrProfileNotFound)
+		}
+		if avatar, _ := patch["avatar_url"].(string); avatar != "" {
+			if err := attachUploads(ctx, tx, userID, []string{avatar}); err != nil {
+				r.log.Error(ctx, "failed to attach avatar", zap.String("user_id", userID), zap.Error(err))
+				return err
+			}
+		}
+		return nil
+	})
 }
 
 func (r *ProfileRepository) UpdateSecurity(ctx context.Context, userID string, passwordHash string) error {
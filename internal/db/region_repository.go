@@ -0,0 +1,556 @@
+package db
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+
+	"github.com/go-park-mail-ru/2025_2_Avrora/internal/domain"
+	"github.com/go-park-mail-ru/2025_2_Avrora/internal/log"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+	"go.uber.org/zap"
+)
+
+const (
+	regionColumns = `r.id, r.name, r.parent_id, r.level, r.slug, r.created_at, r.updated_at`
+
+	getRegionByIDQuery   = `SELECT ` + regionColumns + ` FROM region r WHERE r.id = $1`
+	getRegionBySlugQuery = `SELECT ` + regionColumns + ` FROM region r WHERE r.slug = $1`
+
+	// Roots when $1 is NULL
+	listRegionChildrenQuery = `
+		SELECT ` + regionColumns + `
+		FROM region r
+		WHERE r.parent_id IS NOT DISTINCT FROM $1::UUID
+		ORDER BY r.name, r.id`
+
+	// From the root down to the parent of $1
+	listRegionAncestorsQuery = `
+		WITH RECURSIVE up AS (
+			SELECT parent_id FROM region WHERE id = $1
+			UNION ALL
+			SELECT r.parent_id FROM region r JOIN up ON r.id = up.parent_id
+		)
+		SELECT ` + regionColumns + `
+		FROM region r
+		WHERE r.id IN (SELECT parent_id FROM up)
+		ORDER BY r.level`
+
+	// Level follows the parent
+	createRegionQuery = `
+		INSERT INTO region (name, parent_id, level, slug)
+		VALUES ($1, $2::UUID, COALESCE((SELECT level + 1 FROM region WHERE id = $2::UUID), 0), $3)
+		ON CONFLICT (slug) DO NOTHING
+		RETURNING id, level, created_at, updated_at`
+
+	regionSlugTakenQuery = `SELECT EXISTS (SELECT 1 FROM region WHERE slug = $2 AND id <> $1)`
+
+	// Moving a region under its own subtree would make a cycle
+	regionInSubtreeQuery = `
+		WITH RECURSIVE sub AS (
+			SELECT id FROM region WHERE id = $1
+			UNION ALL
+			SELECT r.id FROM region r JOIN sub s ON r.parent_id = s.id
+		)
+		SELECT EXISTS (SELECT 1 FROM sub WHERE id = $2)`
+
+	updateRegionQuery = `
+		UPDATE region SET
+			name = $2, slug = $4, parent_id = $3::UUID,
+			level = COALESCE((SELECT level + 1 FROM region WHERE id = $3::UUID), 0)
+		WHERE id = $1
+		RETURNING level, created_at, updated_at`
+
+	// Re-derives the levels below $1 after a move
+	relevelRegionSubtreeQuery = `
+		WITH RECURSIVE sub AS (
+			SELECT id, level FROM region WHERE id = $1
+			UNION ALL
+			SELECT r.id, s.level + 1 FROM region r JOIN sub s ON r.parent_id = s.id
+		)
+		UPDATE region r SET level = sub.level
+		FROM sub
+		WHERE r.id = sub.id AND r.level <> sub.level`
+
+	// Locations would cascade away with the region, so used regions are kept
+	deleteRegionQuery = `
+		DELETE FROM region r
+		WHERE r.id = $1
+		  AND NOT EXISTS (SELECT 1 FROM region c WHERE c.parent_id = r.id)
+		  AND NOT EXISTS (SELECT 1 FROM location l WHERE l.region_id = r.id)
+		  AND NOT EXISTS (SELECT 1 FROM metro_line ml WHERE ml.region_id = r.id)`
+
+	regionExistsQuery = `SELECT EXISTS (SELECT 1 FROM region WHERE id = $1)`
+
+	// Regions in the subtree of $1; all of them when $1 is NULL
+	subRegionsQuery = `
+		WITH RECURSIVE sub_region AS (
+			SELECT id FROM region WHERE $1::UUID IS NULL OR id = $1
+			UNION
+			SELECT r.id FROM region r JOIN sub_region s ON r.parent_id = s.id
+		)
+		SELECT id FROM sub_region`
+
+	metroLineColumns = `ml.id, ml.region_id, ml.name, ml.color, ml.created_at, ml.updated_at`
+
+	listMetroLinesQuery = `
+		SELECT ` + metroLineColumns + `
+		FROM metro_line ml
+		WHERE ml.region_id IN (` + subRegionsQuery + `)
+		ORDER BY ml.name, ml.id`
+
+	getMetroLineQuery = `SELECT ` + metroLineColumns + ` FROM metro_line ml WHERE ml.id = $1`
+
+	createMetroLineQuery = `
+		INSERT INTO metro_line (region_id, name, color)
+		VALUES ($1, $2, $3)
+		ON CONFLICT (region_id, name) DO NOTHING
+		RETURNING id, created_at, updated_at`
+
+	metroLineNameTakenQuery = `
+		SELECT EXISTS (SELECT 1 FROM metro_line WHERE region_id = $2 AND name = $3 AND id <> $1)`
+
+	updateMetroLineQuery = `
+		UPDATE metro_line SET region_id = $2, name = $3, color = $4
+		WHERE id = $1
+		RETURNING created_at, updated_at`
+
+	deleteMetroLineQuery = `DELETE FROM metro_line WHERE id = $1`
+
+	metroStationColumns = `
+		ms.id, ms.name, ms.location_id, l.region_id, l.latitude::float8, l.longitude::float8,
+		ml.id, ml.region_id, ml.name, ml.color,
+		ms.created_at, ms.updated_at`
+
+	metroStationFrom = `
+		FROM metro_station ms
+		JOIN location l ON l.id = ms.location_id
+		LEFT JOIN metro_line ml ON ml.id = ms.line_id`
+
+	// $1 narrows to a region subtree, $2 to a line
+	listMetroStationsQuery = `
+		SELECT ` + metroStationColumns + metroStationFrom + `
+		WHERE l.region_id IN (` + subRegionsQuery + `)
+		  AND ($2::UUID IS NULL OR ms.line_id = $2)
+		ORDER BY ml.name NULLS LAST, ms.name, ms.id`
+
+	getMetroStationQuery = `SELECT ` + metroStationColumns + metroStationFrom + ` WHERE ms.id = $1`
+
+	createStationLocationQuery = `
+		INSERT INTO location (region_id, latitude, longitude)
+		VALUES ($1, $2, $3)
+		RETURNING id`
+
+	createMetroStationQuery = `
+		INSERT INTO metro_station (name, location_id, line_id)
+		VALUES ($1, $2, $3)
+		RETURNING id, created_at, updated_at`
+
+	// Returns whether the station's place differs from its location
+	stationMovedQuery = `
+		SELECT (l.region_id, l.latitude, l.longitude) IS DISTINCT FROM ($2::UUID, $3::DECIMAL, $4::DECIMAL)
+		FROM location l
+		JOIN metro_station ms ON ms.location_id = l.id
+		WHERE ms.id = $1`
+
+	repointMetroStationQuery = `UPDATE metro_station SET location_id = $2 WHERE id = $1`
+
+	updateMetroStationQuery = `
+		UPDATE metro_station SET name = $2, line_id = $3
+		WHERE id = $1
+		RETURNING location_id, created_at, updated_at`
+
+	deleteMetroStationQuery = `DELETE FROM metro_station WHERE id = $1`
+
+	unlinkMetroStationQuery = `DELETE FROM location_metro WHERE metro_station_id = $1`
+
+	// Links the station to every location within $2 meters, straight-line
+	linkMetroStationQuery = `
+		INSERT INTO location_metro (location_id, metro_station_id, distance_meters)
+		SELECT l.id, ms.id, d.meters
+		FROM metro_station ms
+		JOIN location sl ON sl.id = ms.location_id
+		CROSS JOIN location l
+		CROSS JOIN LATERAL (
+			SELECT ROUND(2 * 6371000 * ASIN(SQRT(
+				POWER(SIN(RADIANS(l.latitude::float8 - sl.latitude::float8) / 2), 2)
+				+ COS(RADIANS(sl.latitude::float8)) * COS(RADIANS(l.latitude::float8))
+				* POWER(SIN(RADIANS(l.longitude::float8 - sl.longitude::float8) / 2), 2)
+			)))::INT AS meters
+		) d
+		WHERE ms.id = $1 AND d.meters <= $2`
+)
+
+type RegionRepository struct {
+	db  *pgxpool.Pool
+	log *log.Logger
+}
+
+func NewRegionRepository(db *pgxpool.Pool, log *log.Logger) *RegionRepository {
+	return &RegionRepository{db: db, log: log}
+}
+
+func scanRegion(row pgx.Row, r *domain.Region) error {
+	return row.Scan(&r.ID, &r.Name, &r.ParentID, &r.Level, &r.Slug, &r.CreatedAt, &r.UpdatedAt)
+}
+
+func (r *RegionRepository) getRegion(ctx context.Context, query, key string) (*domain.Region, error) {
+	var region domain.Region
+	if err := scanRegion(r.db.QueryRow(ctx, query, key), &region); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, domain.ErrRegionNotFound
+		}
+		r.log.Error(ctx, "failed to get region", zap.String("key", key), zap.Error(err))
+		return nil, err
+	}
+	return &region, nil
+}
+
+func (r *RegionRepository) GetRegion(ctx context.Context, id string) (*domain.Region, error) {
+	return r.getRegion(ctx, getRegionByIDQuery, id)
+}
+
+func (r *RegionRepository) GetRegionBySlug(ctx context.Context, slug string) (*domain.Region, error) {
+	return r.getRegion(ctx, getRegionBySlugQuery, slug)
+}
+
+func (r *RegionRepository) listRegions(ctx context.Context, query string, arg any) ([]domain.Region, error) {
+	rows, err := r.db.Query(ctx, query, arg)
+	if err != nil {
+		r.log.Error(ctx, "failed to list regions", zap.Error(err))
+		return nil, err
+	}
+	defer rows.Close()
+
+	regions := []domain.Region{}
+	for rows.Next() {
+		var region domain.Region
+		if err := scanRegion(rows, &region); err != nil {
+			r.log.Error(ctx, "failed to scan region", zap.Error(err))
+			return nil, err
+		}
+		regions = append(regions, region)
+	}
+	return regions, rows.Err()
+}
+
+// ListRegionChildren returns the direct children of the region, or the roots for nil
+func (r *RegionRepository) ListRegionChildren(ctx context.Context, parentID *string) ([]domain.Region, error) {
+	return r.listRegions(ctx, listRegionChildrenQuery, parentID)
+}
+
+// ListRegionAncestors returns the regions above the given one, root first
+func (r *RegionRepository) ListRegionAncestors(ctx context.Context, id string) ([]domain.Region, error) {
+	return r.listRegions(ctx, listRegionAncestorsQuery, id)
+}
+
+func (r *RegionRepository) CreateRegion(ctx context.Context, region *domain.Region) error {
+	err := r.db.QueryRow(ctx, createRegionQuery, region.Name, region.ParentID, region.Slug).
+		Scan(&region.ID, &region.Level, &region.CreatedAt, &region.UpdatedAt)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return domain.ErrRegionSlugTaken
+		}
+		r.log.Error(ctx, "failed to create region", zap.String("slug", region.Slug), zap.Error(err))
+		return err
+	}
+	r.log.Info(ctx, "created region", zap.String("id", region.ID), zap.String("slug", region.Slug))
+	return nil
+}
+
+// UpdateRegion renames or moves the region; levels below it follow
+func (r *RegionRepository) UpdateRegion(ctx context.Context, region *domain.Region) error {
+	return auditedTx(ctx, r.db, func(tx pgx.Tx) error {
+		var taken bool
+		if err := tx.QueryRow(ctx, regionSlugTakenQuery, region.ID, region.Slug).Scan(&taken); err != nil {
+			r.log.Error(ctx, "failed to check region slug", zap.String("id", region.ID), zap.Error(err))
+			return err
+		}
+		if taken {
+			return domain.ErrRegionSlugTaken
+		}
+		if region.ParentID != nil {
+			var cycle bool
+			if err := tx.QueryRow(ctx, regionInSubtreeQuery, region.ID, *region.ParentID).Scan(&cycle); err != nil {
+				r.log.Error(ctx, "failed to check region parent", zap.String("id", region.ID), zap.Error(err))
+				return err
+			}
+			if cycle {
+				return domain.ErrInvalidInput
+			}
+		}
+
+		err := tx.QueryRow(ctx, updateRegionQuery, region.ID, region.Name, region.ParentID, region.Slug).
+			Scan(&region.Level, &region.CreatedAt, &region.UpdatedAt)
+		if err != nil {
+			if errors.Is(err, sql.ErrNoRows) {
+				return domain.ErrRegionNotFound
+			}
+			r.log.Error(ctx, "failed to update region", zap.String("id", region.ID), zap.Error(err))
+			return err
+		}
+		if _, err := tx.Exec(ctx, relevelRegionSubtreeQuery, region.ID); err != nil {
+			r.log.Error(ctx, "failed to relevel regions", zap.String("id", region.ID), zap.Error(err))
+			return err
+		}
+		return nil
+	})
+}
+
+// DeleteRegion removes a region nothing refers to
+func (r *RegionRepository) DeleteRegion(ctx context.Context, id string) error {
+	tag, err := r.db.Exec(ctx, deleteRegionQuery, id)
+	if err != nil {
+		r.log.Error(ctx, "failed to delete region", zap.String("id", id), zap.Error(err))
+		return err
+	}
+	if tag.RowsAffected() > 0 {
+		r.log.Info(ctx, "deleted region", zap.String("id", id))
+		return nil
+	}
+
+	var exists bool
+	if err := r.db.QueryRow(ctx, regionExistsQuery, id).Scan(&exists); err != nil {
+		r.log.Error(ctx, "failed to check region", zap.String("id", id), zap.Error(err))
+		return err
+	}
+	if !exists {
+		return domain.ErrRegionNotFound
+	}
+	return domain.ErrRegionInUse
+}
+
+func scanMetroLine(row pgx.Row, l *domain.MetroLine) error {
+	return row.Scan(&l.ID, &l.RegionID, &l.Name, &l.Color, &l.CreatedAt, &l.UpdatedAt)
+}
+
+// ListMetroLines returns the lines in the region subtree; all of them for nil
+func (r *RegionRepository) ListMetroLines(ctx context.Context, regionID *string) ([]domain.MetroLine, error) {
+	rows, err := r.db.Query(ctx, listMetroLinesQuery, regionID)
+	if err != nil {
+		r.log.Error(ctx, "failed to list metro lines", zap.Error(err))
+		return nil, err
+	}
+	defer rows.Close()
+
+	lines := []domain.MetroLine{}
+	for rows.Next() {
+		var l domain.MetroLine
+		if err := scanMetroLine(rows, &l); err != nil {
+			r.log.Error(ctx, "failed to scan metro line", zap.Error(err))
+			return nil, err
+		}
+		lines = append(lines, l)
+	}
+	return lines, rows.Err()
+}
+
+func (r *RegionRepository) GetMetroLine(ctx context.Context, id string) (*domain.MetroLine, error) {
+	var l domain.MetroLine
+	if err := scanMetroLine(r.db.QueryRow(ctx, getMetroLineQuery, id), &l); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, domain.ErrMetroLineNotFound
+		}
+		r.log.Error(ctx, "failed to get metro line", zap.String("id", id), zap.Error(err))
+		return nil, err
+	}
+	return &l, nil
+}
+
+func (r *RegionRepository) CreateMetroLine(ctx context.Context, l *domain.MetroLine) error {
+	err := r.db.QueryRow(ctx, createMetroLineQuery, l.RegionID, l.Name, l.Color).
+		Scan(&l.ID, &l.CreatedAt, &l.UpdatedAt)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return domain.ErrMetroLineExists
+		}
+		r.log.Error(ctx, "failed to create metro line", zap.Error(err))
+		return err
+	}
+	return nil
+}
+
+func (r *RegionRepository) UpdateMetroLine(ctx context.Context, l *domain.MetroLine) error {
+	var taken bool
+	if err := r.db.QueryRow(ctx, metroLineNameTakenQuery, l.ID, l.RegionID, l.Name).Scan(&taken); err != nil {
+		r.log.Error(ctx, "failed to check metro line name", zap.String("id", l.ID), zap.Error(err))
+		return err
+	}
+	if taken {
+		return domain.ErrMetroLineExists
+	}
+
+	err := r.db.QueryRow(ctx, updateMetroLineQuery, l.ID, l.RegionID, l.Name, l.Color).
+		Scan(&l.CreatedAt, &l.UpdatedAt)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return domain.ErrMetroLineNotFound
+		}
+		r.log.Error(ctx, "failed to update metro line", zap.String("id", l.ID), zap.Error(err))
+		return err
+	}
+	return nil
+}
+
+// DeleteMetroLine removes the line; its stations stay without one
+func (r *RegionRepository) DeleteMetroLine(ctx context.Context, id string) error {
+	tag, err := r.db.Exec(ctx, deleteMetroLineQuery, id)
+	if err != nil {
+		r.log.Error(ctx, "failed to delete metro line", zap.String("id", id), zap.Error(err))
+		return err
+	}
+	if tag.RowsAffected() == 0 {
+		return domain.ErrMetroLineNotFound
+	}
+	return nil
+}
+
+func scanMetroStation(row pgx.Row, s *domain.MetroStation) error {
+	var lineID, lineRegionID, lineName, lineColor *string
+	err := row.Scan(
+		&s.ID,
+		&s.Name,
+		&s.LocationID,
+		&s.RegionID,
+		&s.Latitude,
+		&s.Longitude,
+		&lineID,
+		&lineRegionID,
+		&lineName,
+		&lineColor,
+		&s.CreatedAt,
+		&s.UpdatedAt,
+	)
+	if err != nil {
+		return err
+	}
+	if lineID != nil {
+		s.Line = &domain.MetroLine{ID: *lineID, RegionID: *lineRegionID, Name: *lineName, Color: *lineColor}
+	}
+	return nil
+}
+
+// ListMetroStations returns stations with their lines; regionID narrows them
+// to a region subtree and lineID to one line
+func (r *RegionRepository) ListMetroStations(ctx context.Context, regionID, lineID *string) ([]domain.MetroStation, error) {
+	rows, err := r.db.Query(ctx, listMetroStationsQuery, regionID, lineID)
+	if err != nil {
+		r.log.Error(ctx, "failed to list metro stations", zap.Error(err))
+		return nil, err
+	}
+	defer rows.Close()
+
+	stations := []domain.MetroStation{}
+	for rows.Next() {
+		var s domain.MetroStation
+		if err := scanMetroStation(rows, &s); err != nil {
+			r.log.Error(ctx, "failed to scan metro station", zap.Error(err))
+			return nil, err
+		}
+		stations = append(stations, s)
+	}
+	return stations, rows.Err()
+}
+
+func (r *RegionRepository) GetMetroStation(ctx context.Context, id string) (*domain.MetroStation, error) {
+	var s domain.MetroStation
+	if err := scanMetroStation(r.db.QueryRow(ctx, getMetroStationQuery, id), &s); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, domain.ErrMetroStationNotFound
+		}
+		r.log.Error(ctx, "failed to get metro station", zap.String("id", id), zap.Error(err))
+		return nil, err
+	}
+	return &s, nil
+}
+
+func lineIDOf(s *domain.MetroStation) *string {
+	if s.Line == nil {
+		return nil
+	}
+	return &s.Line.ID
+}
+
+// CreateMetroStation places the station at a new location and links it to
+// the locations around
+func (r *RegionRepository) CreateMetroStation(ctx context.Context, s *domain.MetroStation) error {
+	return auditedTx(ctx, r.db, func(tx pgx.Tx) error {
+		err := tx.QueryRow(ctx, createStationLocationQuery, s.RegionID, s.Latitude, s.Longitude).Scan(&s.LocationID)
+		if err != nil {
+			r.log.Error(ctx, "failed to create station location", zap.Error(err))
+			return err
+		}
+		err = tx.QueryRow(ctx, createMetroStationQuery, s.Name, s.LocationID, lineIDOf(s)).
+			Scan(&s.ID, &s.CreatedAt, &s.UpdatedAt)
+		if err != nil {
+			r.log.Error(ctx, "failed to create metro station", zap.Error(err))
+			return err
+		}
+		if _, err := tx.Exec(ctx, linkMetroStationQuery, s.ID, domain.MetroLinkRadiusMeters); err != nil {
+			r.log.Error(ctx, "failed to link metro station", zap.String("id", s.ID), zap.Error(err))
+			return err
+		}
+		r.log.Info(ctx, "created metro station", zap.String("id", s.ID))
+		return nil
+	})
+}
+
+// UpdateMetroStation replaces the station; a moved one is linked anew
+func (r *RegionRepository) UpdateMetroStation(ctx context.Context, s *domain.MetroStation) error {
+	return auditedTx(ctx, r.db, func(tx pgx.Tx) error {
+		err := tx.QueryRow(ctx, updateMetroStationQuery, s.ID, s.Name, lineIDOf(s)).
+			Scan(&s.LocationID, &s.CreatedAt, &s.UpdatedAt)
+		if err != nil {
+			if errors.Is(err, sql.ErrNoRows) {
+				return domain.ErrMetroStationNotFound
+			}
+			r.log.Error(ctx, "failed to update metro station", zap.String("id", s.ID), zap.Error(err))
+			return err
+		}
+
+		var moved bool
+		err = tx.QueryRow(ctx, stationMovedQuery, s.ID, s.RegionID, s.Latitude, s.Longitude).Scan(&moved)
+		if err != nil {
+			r.log.Error(ctx, "failed to compare metro station place", zap.String("id", s.ID), zap.Error(err))
+			return err
+		}
+		if !moved {
+			return nil
+		}
+
+		// Offers and complexes may share the old location, so the station
+		// gets a location of its own instead of moving that one
+		if err := tx.QueryRow(ctx, createStationLocationQuery, s.RegionID, s.Latitude, s.Longitude).Scan(&s.LocationID); err != nil {
+			r.log.Error(ctx, "failed to create station location", zap.String("id", s.ID), zap.Error(err))
+			return err
+		}
+		if _, err := tx.Exec(ctx, repointMetroStationQuery, s.ID, s.LocationID); err != nil {
+			r.log.Error(ctx, "failed to move metro station", zap.String("id", s.ID), zap.Error(err))
+			return err
+		}
+		if _, err := tx.Exec(ctx, unlinkMetroStationQuery, s.ID); err != nil {
+			r.log.Error(ctx, "failed to unlink metro station", zap.String("id", s.ID), zap.Error(err))
+			return err
+		}
+		if _, err := tx.Exec(ctx, linkMetroStationQuery, s.ID, domain.MetroLinkRadiusMeters); err != nil {
+			r.log.Error(ctx, "failed to link metro station", zap.String("id", s.ID), zap.Error(err))
+			return err
+		}
+		return nil
+	})
+}
+
+// DeleteMetroStation removes the station and its links; the location stays
+// since offers may share it
+func (r *RegionRepository) DeleteMetroStation(ctx context.Context, id string) error {
+	tag, err := r.db.Exec(ctx, deleteMetroStationQuery, id)
+	if err != nil {
+		r.log.Error(ctx, "failed to delete metro station", zap.String("id", id), zap.Error(err))
+		return err
+	}
+	if tag.RowsAffected() == 0 {
+		return domain.ErrMetroStationNotFound
+	}
+	return nil
+}
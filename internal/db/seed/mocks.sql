@@ -11,10 +11,15 @@ INSERT INTO location (id, region_id, latitude, longitude) VALUES
   ('20000000-0000-0000-0000-000000000001', '10000000-0000-0000-0000-000000000002', 55.7558, 37.6176), -- Moscow center
   ('20000000-0000-0000-0000-000000000002', '10000000-0000-0000-0000-000000000002', 55.7512, 37.6184); -- Nearby
 
+-- Insert metro lines
+INSERT INTO metro_line (id, region_id, name, color) VALUES
+  ('31000000-0000-0000-0000-000000000001', '10000000-0000-0000-0000-000000000002', 'Sokolnicheskaya', '#EF161E'),
+  ('31000000-0000-0000-0000-000000000002', '10000000-0000-0000-0000-000000000002', 'Zamoskvoretskaya', '#2DBE2C');
+
 -- Insert metro stations
-INSERT INTO metro_station (id, name, location_id) VALUES
-  ('30000000-0000-0000-0000-000000000001', 'Teatralnaya', '20000000-0000-0000-0000-000000000001'),
-  ('30000000-0000-0000-0000-000000000002', 'Okhotny Ryad', '20000000-0000-0000-0000-000000000002');
+INSERT INTO metro_station (id, name, location_id, line_id) VALUES
+  ('30000000-0000-0000-0000-000000000001', 'Teatralnaya', '20000000-0000-0000-0000-000000000001', '31000000-0000-0000-0000-000000000002'),
+  ('30000000-0000-0000-0000-000000000002', 'Okhotny Ryad', '20000000-0000-0000-0000-000000000002', '31000000-0000-0000-0000-000000000001');
 
 -- Link locations to metro stations
 INSERT INTO location_metro (location_id, metro_station_id, distance_meters) VALUES
@@ -32,10 +37,16 @@ INSERT INTO profile (user_id, first_name, last_name, phone, avatar_url) VALUES
   ('40000000-0000-0000-0000-000000000001', 'Alex', 'Petrov', '+79001234567', 'http://37.139.40.252:8080/api/v1/image/default_avatar.jpg'),
   ('40000000-0000-0000-0000-000000000002', 'Maria', 'Ivanova', '+79007654321', 'http://37.139.40.252:8080/api/v1/image/default_avatar.jpg');
 
+-- Insert developers
+INSERT INTO developer (id, name, description, website, founded_year) VALUES
+  ('55000000-0000-0000-0000-000000000001', 'Capital Development', 'Business class housing in central Moscow', 'https://capital-development.example', 2005),
+  ('55000000-0000-0000-0000-000000000002', 'PIK Group', 'Комплексная застройка жилых кварталов', 'https://pik.example', 1994),
+  ('55000000-0000-0000-0000-000000000003', 'LSR Group', 'Жилая недвижимость в Москве и Санкт-Петербурге', 'https://lsr.example', 1993);
+
 -- Insert housing complexes
-INSERT INTO housing_complex (id, name, description, year_built, location_id, developer, address, starting_price) VALUES
+INSERT INTO housing_complex (id, name, description, year_built, location_id, developer_id, address) VALUES
   ('50000000-0000-0000-0000-000000000001', 'Moscow City Residences', 'Luxury apartments in the business district', 2020,
-   '20000000-0000-0000-0000-000000000001', 'Capital Development', 'Presnenskaya Embankment, 10', 15000000);
+   '20000000-0000-0000-0000-000000000001', '55000000-0000-0000-0000-000000000001', 'Presnenskaya Embankment, 10');
 
 -- Insert complex photos
 INSERT INTO complex_photo (complex_id, url) VALUES
@@ -76,12 +87,12 @@ INSERT INTO offer_photo (offer_id, url) VALUES
 
 -- Дополнительные жилищные комплексы
 
-INSERT INTO housing_complex (id, name, description, year_built, location_id, developer, address, starting_price) VALUES
+INSERT INTO housing_complex (id, name, description, year_built, location_id, developer_id, address) VALUES
   ('50000000-0000-0000-0000-000000000002', 'Garden Quarters', 'Экологичный ЖК с парковой зоной и детской инфраструктурой', 2022,
-   '20000000-0000-0000-0000-000000000002', 'PIK Group', 'Kutuzovsky Prospekt, 44', 12000000),
+   '20000000-0000-0000-0000-000000000002', '55000000-0000-0000-0000-000000000002', 'Kutuzovsky Prospekt, 44'),
 
   ('50000000-0000-0000-0000-000000000003', 'Neva Tower Residences', 'Элитные апартаменты в высотке бизнес-класса', 2021,
-   '20000000-0000-0000-0000-000000000001', 'LSR Group', 'Krasnopresnenskaya Embankment, 12', 22000000);
+   '20000000-0000-0000-0000-000000000001', '55000000-0000-0000-0000-000000000003', 'Krasnopresnenskaya Embankment, 12');
 
 -- Фото для новых жилищных комплексов
 
@@ -90,6 +101,26 @@ INSERT INTO complex_photo (complex_id, url) VALUES
   ('50000000-0000-0000-0000-000000000002', 'http://37.139.40.252:8080/api/v1/image/default_complex.jpg'),
   ('50000000-0000-0000-0000-000000000003', 'http://37.139.40.252:8080/api/v1/image/default_complex.jpg');
 
+-- Корпуса, планировки и инфраструктура ЖК; стартовая цена считается по планировкам
+
+INSERT INTO complex_building (id, complex_id, name, phase, floors, stage, handover_year, handover_quarter) VALUES
+  ('51000000-0000-0000-0000-000000000001', '50000000-0000-0000-0000-000000000002', 'Корпус 1', 1, 17, 'completed', 2022, 4),
+  ('51000000-0000-0000-0000-000000000002', '50000000-0000-0000-0000-000000000002', 'Корпус 2', 2, 24, 'finishing', 2026, 2);
+
+INSERT INTO complex_layout (complex_id, building_id, rooms, area, price_min, price_max) VALUES
+  ('50000000-0000-0000-0000-000000000001', NULL, 1, 48.50, 15000000, 17500000),
+  ('50000000-0000-0000-0000-000000000002', '51000000-0000-0000-0000-000000000001', 0, 26.30, 12000000, 12800000),
+  ('50000000-0000-0000-0000-000000000002', '51000000-0000-0000-0000-000000000002', 2, 58.70, 18500000, 21000000),
+  ('50000000-0000-0000-0000-000000000003', NULL, 3, 112.00, 22000000, 29000000);
+
+INSERT INTO complex_amenity (complex_id, amenity) VALUES
+  ('50000000-0000-0000-0000-000000000001', 'underground_parking'),
+  ('50000000-0000-0000-0000-000000000001', 'concierge'),
+  ('50000000-0000-0000-0000-000000000002', 'playground'),
+  ('50000000-0000-0000-0000-000000000002', 'kindergarten'),
+  ('50000000-0000-0000-0000-000000000002', 'park'),
+  ('50000000-0000-0000-0000-000000000003', 'security');
+
 -- Дополнительные объявления (offers)
 
 INSERT INTO offer (
@@ -150,4 +181,86 @@ INSERT INTO offer_photo (offer_id, url) VALUES
   ('60000000-0000-0000-0000-000000000005', 'http://37.139.40.252:8080/api/v1/image/default_offer3.jpg'),
   ('60000000-0000-0000-0000-000000000005', 'http://37.139.40.252:8080/api/v1/image/default_offer4.jpg'),
   ('60000000-0000-0000-0000-000000000005', 'http://37.139.40.252:8080/api/v1/image/default_offer5.jpg'),
-  ('60000000-0000-0000-0000-000000000006', 'http://37.139.40.252:8080/api/v1/image/default_offer6.jpg');
\ No newline at end of file
+  ('60000000-0000-0000-0000-000000000006', 'http://37.139.40.252:8080/api/v1/image/default_offer6.jpg');
+
+-- Характеристики объявлений
+
+UPDATE offer SET attributes = '{"renovation": "euro", "bathroom": "separate", "balcony": "loggia", "ceiling_height": 3.0, "building_type": "monolith", "build_year": 2015, "parking": "underground"}'
+WHERE id = '60000000-0000-0000-0000-000000000001';
+UPDATE offer SET attributes = '{"renovation": "cosmetic", "bathroom": "combined", "furniture": true, "appliances": true, "pets_allowed": false, "children_allowed": true}'
+WHERE id = '60000000-0000-0000-0000-000000000002';
+UPDATE offer SET attributes = '{"renovation": "none", "balcony": "balcony", "building_type": "monolith_brick", "build_year": 2022, "parking": "underground"}'
+WHERE id = '60000000-0000-0000-0000-000000000003';
+UPDATE offer SET attributes = '{"renovation": "designer", "furniture": true, "appliances": true, "pets_allowed": true, "children_allowed": true}'
+WHERE id = '60000000-0000-0000-0000-000000000004';
+UPDATE offer SET attributes = '{"renovation": "designer", "bathroom": "multiple", "ceiling_height": 3.6, "building_type": "monolith", "build_year": 2021, "parking": "multilevel"}'
+WHERE id = '60000000-0000-0000-0000-000000000005';
+UPDATE offer SET attributes = '{"renovation": "euro", "bathroom": "separate", "building_type": "brick", "build_year": 1957, "parking": "open", "furniture": true, "pets_allowed": false}'
+WHERE id = '60000000-0000-0000-0000-000000000006';
+
+-- Участок, офис и машино-место
+
+INSERT INTO offer (
+  id, user_id, location_id, housing_complex_id, title, description, price, area,
+  address, rooms, property_type, offer_type, status, floor, total_floors,
+  deposit, commission, rental_period, living_area, kitchen_area, attributes
+) VALUES
+  ('60000000-0000-0000-0000-000000000007',
+   '40000000-0000-0000-0000-000000000002',
+   '20000000-0000-0000-0000-000000000002',
+   NULL,
+   'Участок 12 соток под ИЖС',
+   'Ровный участок, электричество и газ по границе',
+   4500000, 1200.00,
+   'Kutuzovsky Prospekt, 44', 0, 'land', 'sale', 'active',
+   NULL, NULL, NULL, NULL, NULL, NULL, NULL,
+   '{"land_category": "izhs"}'),
+
+  ('60000000-0000-0000-0000-000000000008',
+   '40000000-0000-0000-0000-000000000003',
+   '20000000-0000-0000-0000-000000000001',
+   NULL,
+   'Офис 120 м² в бизнес-центре',
+   'Open space, две переговорные, отдельный вход',
+   360000, 120.00,
+   'Presnenskaya Embankment, 10', 4, 'commercial', 'rent', 'active',
+   8, 42, 360000, NULL, '11 months', NULL, NULL,
+   '{"commercial_type": "office", "renovation": "euro", "furniture": true, "parking": "underground"}'),
+
+  ('60000000-0000-0000-0000-000000000009',
+   '40000000-0000-0000-0000-000000000002',
+   '20000000-0000-0000-0000-000000000001',
+   '50000000-0000-0000-0000-000000000001',
+   'Машино-место в подземном паркинге',
+   'Охраняемый паркинг, въезд по пропуску',
+   2800000, 15.00,
+   'Presnenskaya Embankment, 10', 0, 'garage', 'sale', 'active',
+   NULL, NULL, NULL, NULL, NULL, NULL, NULL,
+   '{"garage_type": "parking_space"}');
+
+-- Посуточная аренда студии: бронь гостя и даты, закрытые владельцем
+
+INSERT INTO offer_short_term (offer_id, nightly_price, min_nights, max_nights) VALUES
+  ('60000000-0000-0000-0000-000000000004', 4500, 2, 30);
+
+INSERT INTO offer_booking (offer_id, kind, guest_id, check_in, check_out, total_price, note) VALUES
+  ('60000000-0000-0000-0000-000000000004', 'guest', '40000000-0000-0000-0000-000000000001',
+   CURRENT_DATE + 7, CURRENT_DATE + 10, 13500, 'Заезд после 18:00'),
+  ('60000000-0000-0000-0000-000000000004', 'blocked', NULL,
+   CURRENT_DATE + 20, CURRENT_DATE + 25, NULL, 'Косметический ремонт');
+
+-- Время показов квартиры и заявка покупателя на первое из них
+
+INSERT INTO viewing_slot (id, offer_id, starts_at, ends_at) VALUES
+  ('70000000-0000-0000-0000-000000000001', '60000000-0000-0000-0000-000000000001',
+   date_trunc('day', NOW()) + INTERVAL '2 days 15 hours', date_trunc('day', NOW()) + INTERVAL '2 days 15 hours 30 minutes'),
+  ('70000000-0000-0000-0000-000000000002', '60000000-0000-0000-0000-000000000001',
+   date_trunc('day', NOW()) + INTERVAL '3 days 11 hours', date_trunc('day', NOW()) + INTERVAL '3 days 11 hours 30 minutes');
+
+INSERT INTO viewing (offer_id, slot_id, buyer_id, starts_at, ends_at, comment)
+SELECT offer_id, id, '40000000-0000-0000-0000-000000000001', starts_at, ends_at, 'Приду с супругой'
+FROM viewing_slot WHERE id = '70000000-0000-0000-0000-000000000001';
+
+INSERT INTO notification (user_id, kind, viewing_id, message)
+SELECT '40000000-0000-0000-0000-000000000002', 'viewing_requested', v.id, 'Новая заявка на просмотр «' || o.title || '»'
+FROM viewing v JOIN offer o ON o.id = v.offer_id;
\ No newline at end of file
@@ -0,0 +1,232 @@
+package db
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"time"
+
+	"github.com/go-park-mail-ru/2025_2_Avrora/internal/domain"
+	"github.com/go-park-mail-ru/2025_2_Avrora/internal/log"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+	"go.uber.org/zap"
+)
+
+const (
+	getShortTermTermsQuery = `
+		SELECT offer_id, nightly_price, min_nights, max_nights, created_at, updated_at
+		FROM offer_short_term
+		WHERE offer_id = $1`
+
+	saveShortTermTermsQuery = `
+		INSERT INTO offer_short_term (offer_id, nightly_price, min_nights, max_nights)
+		VALUES ($1, $2, $3, $4)
+		ON CONFLICT (offer_id) DO UPDATE SET
+			nightly_price = EXCLUDED.nightly_price,
+			min_nights = EXCLUDED.min_nights,
+			max_nights = EXCLUDED.max_nights
+		RETURNING created_at, updated_at`
+
+	lockShortTermTermsQuery = `SELECT 1 FROM offer_short_term WHERE offer_id = $1 FOR UPDATE`
+
+	countUpcomingGuestsQuery = `
+		SELECT COUNT(*) FROM offer_booking
+		WHERE offer_id = $1 AND kind = 'guest' AND status = 'active' AND check_out > CURRENT_DATE`
+
+	deleteShortTermTermsQuery = `DELETE FROM offer_short_term WHERE offer_id = $1`
+
+	selectBookingQuery = `
+		SELECT id, offer_id, kind, guest_id, check_in, check_out, total_price, note, status, created_at, updated_at
+		FROM offer_booking`
+
+	getBookingQuery = selectBookingQuery + ` WHERE id = $1`
+
+	// Bookings touching the nights from $2 up to $3
+	listOfferBookingsQuery = selectBookingQuery + `
+		WHERE offer_id = $1 AND daterange(check_in, check_out) && daterange($2::DATE, $3::DATE)
+		ORDER BY check_in, created_at`
+
+	listGuestBookingsQuery = selectBookingQuery + `
+		WHERE guest_id = $1
+		ORDER BY check_in DESC, created_at DESC
+		LIMIT $2 OFFSET $3`
+
+	// Taken nights violate offer_booking_no_overlap and insert nothing. The
+	// share lock on the terms holds off DeleteTerms until the booking is in.
+	createBookingQuery = `
+		INSERT INTO offer_booking (offer_id, kind, guest_id, check_in, check_out, total_price, note)
+		SELECT $1::UUID, $2::booking_kind_enum, $3::UUID, $4::DATE, $5::DATE, $6::BIGINT, $7::TEXT
+		FROM offer_short_term WHERE offer_id = $1
+		FOR SHARE
+		ON CONFLICT DO NOTHING
+		RETURNING id, status, created_at, updated_at`
+
+	cancelBookingQuery = `
+		UPDATE offer_booking SET status = 'cancelled'
+		WHERE id = $1 AND status = 'active'
+		RETURNING updated_at`
+)
+
+type ShortTermRepository struct {
+	db  *pgxpool.Pool
+	log *log.Logger
+}
+
+func NewShortTermRepository(db *pgxpool.Pool, log *log.Logger) *ShortTermRepository {
+	return &ShortTermRepository{db: db, log: log}
+}
+
+func scanBooking(row pgx.Row, b *domain.Booking) error {
+	return row.Scan(
+		&b.ID,
+		&b.OfferID,
+		&b.Kind,
+		&b.GuestID,
+		&b.CheckIn,
+		&b.CheckOut,
+		&b.TotalPrice,
+		&b.Note,
+		&b.Status,
+		&b.CreatedAt,
+		&b.UpdatedAt,
+	)
+}
+
+func (r *ShortTermRepository) listBookings(ctx context.Context, query string, args ...any) ([]domain.Booking, error) {
+	rows, err := r.db.Query(ctx, query, args...)
+	if err != nil {
+		r.log.Error(ctx, "failed to list bookings", zap.Error(err))
+		return nil, err
+	}
+	defer rows.Close()
+
+	var bookings []domain.Booking
+	for rows.Next() {
+		var b domain.Booking
+		if err := scanBooking(rows, &b); err != nil {
+			r.log.Error(ctx, "failed to scan booking", zap.Error(err))
+			return nil, err
+		}
+		bookings = append(bookings, b)
+	}
+	return bookings, rows.Err()
+}
+
+// GetTerms returns the nightly terms of the offer
+func (r *ShortTermRepository) GetTerms(ctx context.Context, offerID string) (*domain.ShortTermTerms, error) {
+	var t domain.ShortTermTerms
+	err := r.db.QueryRow(ctx, getShortTermTermsQuery, offerID).Scan(
+		&t.OfferID, &t.NightlyPrice, &t.MinNights, &t.MaxNights, &t.CreatedAt, &t.UpdatedAt,
+	)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, domain.ErrNotShortTerm
+		}
+		r.log.Error(ctx, "failed to get short-term terms", zap.String("offer_id", offerID), zap.Error(err))
+		return nil, err
+	}
+	return &t, nil
+}
+
+// SaveTerms sets the nightly terms, switching the offer to the mode
+func (r *ShortTermRepository) SaveTerms(ctx context.Context, t *domain.ShortTermTerms) error {
+	err := r.db.QueryRow(ctx, saveShortTermTermsQuery,
+		t.OfferID, t.NightlyPrice, t.MinNights, t.MaxNights,
+	).Scan(&t.CreatedAt, &t.UpdatedAt)
+	if err != nil {
+		r.log.Error(ctx, "failed to save short-term terms", zap.String("offer_id", t.OfferID), zap.Error(err))
+		return err
+	}
+	r.log.Info(ctx, "saved short-term terms", zap.String("offer_id", t.OfferID))
+	return nil
+}
+
+// DeleteTerms leaves the short-term mode unless guests are still to come.
+// Past bookings and blocked dates stay for the record.
+func (r *ShortTermRepository) DeleteTerms(ctx context.Context, offerID string) error {
+	return pgx.BeginFunc(ctx, r.db, func(tx pgx.Tx) error {
+		// Lock the terms so no booking slips in between the check and the delete
+		var locked int
+		if err := tx.QueryRow(ctx, lockShortTermTermsQuery, offerID).Scan(&locked); err != nil {
+			if errors.Is(err, sql.ErrNoRows) {
+				return domain.ErrNotShortTerm
+			}
+			r.log.Error(ctx, "failed to lock short-term terms", zap.String("offer_id", offerID), zap.Error(err))
+			return err
+		}
+
+		var guests int
+		if err := tx.QueryRow(ctx, countUpcomingGuestsQuery, offerID).Scan(&guests); err != nil {
+			r.log.Error(ctx, "failed to count upcoming guests", zap.String("offer_id", offerID), zap.Error(err))
+			return err
+		}
+		if guests > 0 {
+			return domain.ErrShortTermHasGuests
+		}
+
+		if _, err := tx.Exec(ctx, deleteShortTermTermsQuery, offerID); err != nil {
+			r.log.Error(ctx, "failed to delete short-term terms", zap.String("offer_id", offerID), zap.Error(err))
+			return err
+		}
+		r.log.Info(ctx, "deleted short-term terms", zap.String("offer_id", offerID))
+		return nil
+	})
+}
+
+func (r *ShortTermRepository) GetBooking(ctx context.Context, id string) (*domain.Booking, error) {
+	var b domain.Booking
+	if err := scanBooking(r.db.QueryRow(ctx, getBookingQuery, id), &b); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, domain.ErrBookingNotFound
+		}
+		r.log.Error(ctx, "failed to get booking", zap.String("id", id), zap.Error(err))
+		return nil, err
+	}
+	return &b, nil
+}
+
+// ListOfferBookings returns the bookings of the offer, cancelled ones
+// included, touching the nights from from up to to
+func (r *ShortTermRepository) ListOfferBookings(ctx context.Context, offerID string, from, to time.Time) ([]domain.Booking, error) {
+	return r.listBookings(ctx, listOfferBookingsQuery, offerID, from, to)
+}
+
+// ListGuestBookings returns the stays the user booked, latest first
+func (r *ShortTermRepository) ListGuestBookings(ctx context.Context, guestID string, limit, offset int) ([]domain.Booking, error) {
+	return r.listBookings(ctx, listGuestBookingsQuery, guestID, limit, offset)
+}
+
+// CreateBooking takes the nights; nights already taken give
+// ErrDatesUnavailable, terms removed meanwhile ErrNotShortTerm
+func (r *ShortTermRepository) CreateBooking(ctx context.Context, b *domain.Booking) error {
+	err := r.db.QueryRow(ctx, createBookingQuery,
+		b.OfferID, b.Kind, b.GuestID, b.CheckIn, b.CheckOut, b.TotalPrice, b.Note,
+	).Scan(&b.ID, &b.Status, &b.CreatedAt, &b.UpdatedAt)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			if _, err := r.GetTerms(ctx, b.OfferID); err != nil {
+				return err
+			}
+			return domain.ErrDatesUnavailable
+		}
+		r.log.Error(ctx, "failed to create booking", zap.String("offer_id", b.OfferID), zap.Error(err))
+		return err
+	}
+	r.log.Info(ctx, "created booking", zap.String("id", b.ID), zap.String("kind", string(b.Kind)))
+	return nil
+}
+
+// CancelBooking frees the nights of an active booking
+func (r *ShortTermRepository) CancelBooking(ctx context.Context, b *domain.Booking) error {
+	if err := r.db.QueryRow(ctx, cancelBookingQuery, b.ID).Scan(&b.UpdatedAt); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return domain.ErrBookingOver
+		}
+		r.log.Error(ctx, "failed to cancel booking", zap.String("id", b.ID), zap.Error(err))
+		return err
+	}
+	b.Status = domain.BookingStatusCancelled
+	r.log.Info(ctx, "cancelled booking", zap.String("id", b.ID))
+	return nil
+}
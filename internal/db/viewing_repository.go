@@ -0,0 +1,317 @@
+package db
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"time"
+
+	"github.com/go-park-mail-ru/2025_2_Avrora/internal/domain"
+	"github.com/go-park-mail-ru/2025_2_Avrora/internal/log"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/jackc/pgx/v5/pgxpool"
+	"go.uber.org/zap"
+)
+
+// SQLSTATE of a violated exclusion constraint
+const exclusionViolationCode = "23P01"
+
+const (
+	// Overlapping slots violate viewing_slot_no_overlap and insert nothing
+	createViewingSlotQuery = `
+		INSERT INTO viewing_slot (offer_id, starts_at, ends_at)
+		VALUES ($1, $2, $3)
+		ON CONFLICT DO NOTHING
+		RETURNING id, created_at`
+
+	selectViewingSlotQuery = `
+		SELECT s.id, s.offer_id, s.starts_at, s.ends_at,
+			NOT EXISTS (
+				SELECT 1 FROM viewing v WHERE v.slot_id = s.id AND v.status <> 'cancelled'
+			) AS free,
+			s.created_at
+		FROM viewing_slot s`
+
+	getViewingSlotQuery = selectViewingSlotQuery + ` WHERE s.id = $1`
+
+	listViewingSlotsQuery = selectViewingSlotQuery + `
+		WHERE s.offer_id = $1 AND s.starts_at > $2
+		ORDER BY s.starts_at`
+
+	deleteViewingSlotQuery = `
+		DELETE FROM viewing_slot s
+		WHERE s.id = $1 AND NOT EXISTS (
+			SELECT 1 FROM viewing v WHERE v.slot_id = s.id AND v.status <> 'cancelled'
+		)`
+
+	// The viewing takes the time of the slot; a time already held by the
+	// offer or the buyer violates the exclusion constraints and inserts
+	// nothing. The share lock keeps the slot until the viewing is in.
+	createViewingQuery = `
+		INSERT INTO viewing (offer_id, slot_id, buyer_id, starts_at, ends_at, comment)
+		SELECT s.offer_id, s.id, $2::UUID, s.starts_at, s.ends_at, $3::TEXT
+		FROM viewing_slot s
+		WHERE s.id = $1
+		FOR SHARE
+		ON CONFLICT DO NOTHING
+		RETURNING id`
+
+	selectViewingQuery = `
+		SELECT v.id, v.offer_id, v.slot_id, v.buyer_id, o.user_id, o.title, o.address,
+			v.starts_at, v.ends_at, v.status, v.comment, v.cancel_reason, v.cancelled_by,
+			v.sequence, v.created_at, v.updated_at
+		FROM viewing v
+		JOIN offer o ON o.id = v.offer_id`
+
+	getViewingQuery = selectViewingQuery + ` WHERE v.id = $1`
+
+	listBuyerViewingsQuery = selectViewingQuery + `
+		WHERE v.buyer_id = $1
+		ORDER BY v.starts_at DESC
+		LIMIT $2 OFFSET $3`
+
+	listOwnerViewingsQuery = selectViewingQuery + `
+		WHERE o.user_id = $1
+		ORDER BY v.starts_at DESC
+		LIMIT $2 OFFSET $3`
+
+	// Both sides of the user, for the calendar feed
+	listCalendarViewingsQuery = selectViewingQuery + `
+		WHERE (v.buyer_id = $1 OR o.user_id = $1) AND v.starts_at > $2
+		ORDER BY v.starts_at
+		LIMIT $3`
+
+	confirmViewingQuery = `
+		UPDATE viewing SET status = 'confirmed', sequence = sequence + 1
+		WHERE id = $1 AND status = 'requested' AND starts_at > NOW()`
+
+	// The check spares a failed statement in the common case; a concurrent
+	// request trips the exclusion constraints, see execViewingChange
+	rescheduleViewingQuery = `
+		UPDATE viewing v SET
+			slot_id = s.id,
+			starts_at = s.starts_at,
+			ends_at = s.ends_at,
+			status = 'confirmed',
+			sequence = v.sequence + 1
+		FROM viewing_slot s
+		WHERE v.id = $1 AND s.id = $2 AND s.offer_id = v.offer_id
+		  AND v.status <> 'cancelled' AND v.starts_at > NOW()
+		  AND NOT EXISTS (
+			SELECT 1 FROM viewing other
+			WHERE other.id <> v.id AND other.status <> 'cancelled'
+			  AND (other.offer_id = v.offer_id OR other.buyer_id = v.buyer_id)
+			  AND tstzrange(other.starts_at, other.ends_at) && tstzrange(s.starts_at, s.ends_at)
+		  )`
+
+	cancelViewingQuery = `
+		UPDATE viewing SET
+			status = 'cancelled',
+			cancelled_by = $2,
+			cancel_reason = $3,
+			sequence = sequence + 1
+		WHERE id = $1 AND status <> 'cancelled' AND starts_at > NOW()`
+)
+
+type ViewingRepository struct {
+	db  *pgxpool.Pool
+	log *log.Logger
+}
+
+func NewViewingRepository(db *pgxpool.Pool, log *log.Logger) *ViewingRepository {
+	return &ViewingRepository{db: db, log: log}
+}
+
+func scanViewingSlot(row pgx.Row, s *domain.ViewingSlot) error {
+	return row.Scan(&s.ID, &s.OfferID, &s.StartsAt, &s.EndsAt, &s.Free, &s.CreatedAt)
+}
+
+func scanViewing(row pgx.Row, v *domain.Viewing) error {
+	return row.Scan(
+		&v.ID,
+		&v.OfferID,
+		&v.SlotID,
+		&v.BuyerID,
+		&v.OwnerID,
+		&v.OfferTitle,
+		&v.OfferAddress,
+		&v.StartsAt,
+		&v.EndsAt,
+		&v.Status,
+		&v.Comment,
+		&v.CancelReason,
+		&v.CancelledBy,
+		&v.Sequence,
+		&v.CreatedAt,
+		&v.UpdatedAt,
+	)
+}
+
+// CreateSlot opens a time for viewings; one overlapping another slot of the
+// offer gives ErrViewingSlotOverlap
+func (r *ViewingRepository) CreateSlot(ctx context.Context, s *domain.ViewingSlot) error {
+	err := r.db.QueryRow(ctx, createViewingSlotQuery, s.OfferID, s.StartsAt, s.EndsAt).Scan(&s.ID, &s.CreatedAt)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return domain.ErrViewingSlotOverlap
+		}
+		r.log.Error(ctx, "failed to create viewing slot", zap.String("offer_id", s.OfferID), zap.Error(err))
+		return err
+	}
+	s.Free = true
+	r.log.Info(ctx, "created viewing slot", zap.String("id", s.ID), zap.String("offer_id", s.OfferID))
+	return nil
+}
+
+func (r *ViewingRepository) GetSlot(ctx context.Context, id string) (*domain.ViewingSlot, error) {
+	var s domain.ViewingSlot
+	if err := scanViewingSlot(r.db.QueryRow(ctx, getViewingSlotQuery, id), &s); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, domain.ErrViewingSlotNotFound
+		}
+		r.log.Error(ctx, "failed to get viewing slot", zap.String("id", id), zap.Error(err))
+		return nil, err
+	}
+	return &s, nil
+}
+
+// ListSlots returns the slots of the offer starting after since
+func (r *ViewingRepository) ListSlots(ctx context.Context, offerID string, since time.Time) ([]domain.ViewingSlot, error) {
+	rows, err := r.db.Query(ctx, listViewingSlotsQuery, offerID, since)
+	if err != nil {
+		r.log.Error(ctx, "failed to list viewing slots", zap.String("offer_id", offerID), zap.Error(err))
+		return nil, err
+	}
+	defer rows.Close()
+
+	var slots []domain.ViewingSlot
+	for rows.Next() {
+		var s domain.ViewingSlot
+		if err := scanViewingSlot(rows, &s); err != nil {
+			r.log.Error(ctx, "failed to scan viewing slot", zap.Error(err))
+			return nil, err
+		}
+		slots = append(slots, s)
+	}
+	return slots, rows.Err()
+}
+
+// DeleteSlot removes a slot no viewing holds; a held one gives
+// ErrViewingSlotTaken
+func (r *ViewingRepository) DeleteSlot(ctx context.Context, id string) error {
+	tag, err := r.db.Exec(ctx, deleteViewingSlotQuery, id)
+	if err != nil {
+		r.log.Error(ctx, "failed to delete viewing slot", zap.String("id", id), zap.Error(err))
+		return err
+	}
+	if tag.RowsAffected() == 0 {
+		if _, err := r.GetSlot(ctx, id); err != nil {
+			return err
+		}
+		return domain.ErrViewingSlotTaken
+	}
+	r.log.Info(ctx, "deleted viewing slot", zap.String("id", id))
+	return nil
+}
+
+// CreateViewing books the slot for the buyer; a time already taken gives
+// ErrViewingSlotTaken
+func (r *ViewingRepository) CreateViewing(ctx context.Context, slotID, buyerID string, comment *string) (string, error) {
+	var id string
+	if err := r.db.QueryRow(ctx, createViewingQuery, slotID, buyerID, comment).Scan(&id); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			if _, err := r.GetSlot(ctx, slotID); err != nil {
+				return "", err
+			}
+			return "", domain.ErrViewingSlotTaken
+		}
+		r.log.Error(ctx, "failed to create viewing", zap.String("slot_id", slotID), zap.Error(err))
+		return "", err
+	}
+	r.log.Info(ctx, "created viewing", zap.String("id", id), zap.String("slot_id", slotID))
+	return id, nil
+}
+
+func (r *ViewingRepository) GetViewing(ctx context.Context, id string) (*domain.Viewing, error) {
+	var v domain.Viewing
+	if err := scanViewing(r.db.QueryRow(ctx, getViewingQuery, id), &v); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, domain.ErrViewingNotFound
+		}
+		r.log.Error(ctx, "failed to get viewing", zap.String("id", id), zap.Error(err))
+		return nil, err
+	}
+	return &v, nil
+}
+
+func (r *ViewingRepository) listViewings(ctx context.Context, query string, args ...any) ([]domain.Viewing, error) {
+	rows, err := r.db.Query(ctx, query, args...)
+	if err != nil {
+		r.log.Error(ctx, "failed to list viewings", zap.Error(err))
+		return nil, err
+	}
+	defer rows.Close()
+
+	var viewings []domain.Viewing
+	for rows.Next() {
+		var v domain.Viewing
+		if err := scanViewing(rows, &v); err != nil {
+			r.log.Error(ctx, "failed to scan viewing", zap.Error(err))
+			return nil, err
+		}
+		viewings = append(viewings, v)
+	}
+	return viewings, rows.Err()
+}
+
+// ListViewings returns the viewings the user requested, or with asOwner the
+// ones of the user's offers, latest first
+func (r *ViewingRepository) ListViewings(ctx context.Context, userID string, asOwner bool, limit, offset int) ([]domain.Viewing, error) {
+	query := listBuyerViewingsQuery
+	if asOwner {
+		query = listOwnerViewingsQuery
+	}
+	return r.listViewings(ctx, query, userID, limit, offset)
+}
+
+// ListCalendarViewings returns the viewings of both sides of the user
+// starting after since
+func (r *ViewingRepository) ListCalendarViewings(ctx context.Context, userID string, since time.Time, limit int) ([]domain.Viewing, error) {
+	return r.listViewings(ctx, listCalendarViewingsQuery, userID, since, limit)
+}
+
+// execViewingChange runs an update of one viewing; nothing updated gives
+// notChanged, a time taken meanwhile by another viewing ErrViewingSlotTaken
+func (r *ViewingRepository) execViewingChange(ctx context.Context, action string, notChanged error, query string, args ...any) error {
+	tag, err := r.db.Exec(ctx, query, args...)
+	if err != nil {
+		var pgErr *pgconn.PgError
+		if errors.As(err, &pgErr) && pgErr.Code == exclusionViolationCode {
+			return domain.ErrViewingSlotTaken
+		}
+		r.log.Error(ctx, "failed to "+action+" viewing", zap.Any("id", args[0]), zap.Error(err))
+		return err
+	}
+	if tag.RowsAffected() == 0 {
+		return notChanged
+	}
+	r.log.Info(ctx, action+" viewing", zap.Any("id", args[0]))
+	return nil
+}
+
+// ConfirmViewing accepts a requested viewing not yet begun
+func (r *ViewingRepository) ConfirmViewing(ctx context.Context, id string) error {
+	return r.execViewingChange(ctx, "confirm", domain.ErrViewingClosed, confirmViewingQuery, id)
+}
+
+// RescheduleViewing moves an open viewing to another slot of its offer; a
+// slot whose time is taken gives ErrViewingSlotTaken
+func (r *ViewingRepository) RescheduleViewing(ctx context.Context, id, slotID string) error {
+	return r.execViewingChange(ctx, "reschedule", domain.ErrViewingSlotTaken, rescheduleViewingQuery, id, slotID)
+}
+
+// CancelViewing cancels an open viewing on behalf of one of its sides
+func (r *ViewingRepository) CancelViewing(ctx context.Context, id, cancelledBy string, reason *string) error {
+	return r.execViewingChange(ctx, "cancel", domain.ErrViewingClosed, cancelViewingQuery, id, cancelledBy, reason)
+}
@@ -6,6 +6,7 @@ import (
 	"os"
 	"path/filepath"
 
+	"github.com/go-park-mail-ru/2025_2_Avrora/internal/delivery/http/utils"
 	"github.com/go-park-mail-ru/2025_2_Avrora/internal/log"
 	fileserverpb "github.com/go-park-mail-ru/2025_2_Avrora/proto/fileserver"
 	"go.uber.org/zap"
@@ -14,24 +15,45 @@ import (
 	"google.golang.org/grpc/status"
 )
 
+const (
+	BucketPublic  = "public"
+	BucketPrivate = "private"
+)
+
 type FileServer struct {
 	fileserverpb.UnimplementedFileServerServer
 	storageDir string
+	privateDir string // never served directly, only through signed URLs
 	baseURL    string
 	logger     *log.Logger
 }
 
-func NewFileServer(storageDir, baseURL string, logger *log.Logger) *FileServer {
-	if err := os.MkdirAll(storageDir, 0755); err != nil {
-		logger.Panic("failed to create storage dir", zap.Error(err))
+func NewFileServer(storageDir, privateDir, baseURL string, logger *log.Logger) *FileServer {
+	for _, dir := range []string{storageDir, privateDir} {
+		if err := os.MkdirAll(dir, 0755); err != nil {
+			logger.Panic("failed to create storage dir", zap.Error(err))
+		}
 	}
 	return &FileServer{
 		storageDir: storageDir,
+		privateDir: privateDir,
 		baseURL:    baseURL,
 		logger:     logger.With(zap.String("service", "fileserver")),
 	}
 }
 
+// bucket maps a bucket name to its directory and public URL prefix
+func (s *FileServer) bucket(name string) (string, string, error) {
+	switch name {
+	case "", BucketPublic:
+		return s.storageDir, s.baseURL, nil
+	case BucketPrivate:
+		return s.privateDir, s.baseURL + "/" + BucketPrivate, nil
+	default:
+		return "", "", status.Error(codes.InvalidArgument, "unknown bucket")
+	}
+}
+
 func (s *FileServer) Upload(ctx context.Context, req *fileserverpb.UploadRequest) (*fileserverpb.UploadResponse, error) {
 	s.logger.Info(ctx, "uploading file", zap.String("filename", req.Filename))
 
@@ -42,18 +64,57 @@ func (s *FileServer) Upload(ctx context.Context, req *fileserverpb.UploadRequest
 		return nil, status.Error(codes.InvalidArgument, "filename required")
 	}
 
+	dir, urlPrefix, err := s.bucket(req.Bucket)
+	if err != nil {
+		return nil, err
+	}
+
 	// Sanitize: only basename (no path traversal)
 	filename := filepath.Base(req.Filename)
-	fullPath := filepath.Join(s.storageDir, filename)
+	fullPath := filepath.Join(dir, filename)
+
+	// Perceptual hash lets the app spot the same photo re-posted after re-encoding.
+	// Private documents are never compared, so they are not hashed.
+	var phash uint64
+	if req.Bucket != BucketPrivate {
+		if phash, err = utils.PerceptualHash(req.Data); err != nil {
+			s.logger.Warn(ctx, "failed to compute perceptual hash", zap.String("filename", filename), zap.Error(err))
+		}
+	}
 
-	if err := os.WriteFile(fullPath, req.Data, 0644); err != nil {
+	// The file is always rewritten: a blob that is being deleted concurrently
+	// must not be reported as stored
+	if err := writeFileAtomic(dir, fullPath, req.Data); err != nil {
 		s.logger.Error(ctx, "failed to save file", zap.Error(err))
 		return nil, status.Error(codes.Internal, "storage error")
 	}
 
-	// Construct URL using baseURL and filename
-	url := s.baseURL + "/" + filename
-	return &fileserverpb.UploadResponse{Url: url}, nil
+	// Construct URL using bucket prefix and filename
+	url := urlPrefix + "/" + filename
+	return &fileserverpb.UploadResponse{Url: url, Phash: phash}, nil
+}
+
+// writeFileAtomic writes data next to path and renames it into place, so that
+// readers never see a partially written file
+func writeFileAtomic(dir, path string, data []byte) error {
+	tmp, err := os.CreateTemp(dir, ".upload-*")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name()) // no-op once renamed
+
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Chmod(0644); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+	return os.Rename(tmp.Name(), path)
 }
 
 func (s *FileServer) Get(req *fileserverpb.GetRequest, stream fileserverpb.FileServer_GetServer) error {
@@ -63,8 +124,13 @@ func (s *FileServer) Get(req *fileserverpb.GetRequest, stream fileserverpb.FileS
 		return status.Error(codes.InvalidArgument, "filename required")
 	}
 
+	dir, _, err := s.bucket(req.Bucket)
+	if err != nil {
+		return err
+	}
+
 	filename := filepath.Base(req.Filename)
-	fullPath := filepath.Join(s.storageDir, filename)
+	fullPath := filepath.Join(dir, filename)
 
 	file, err := os.Open(fullPath)
 	if err != nil {
@@ -119,6 +185,6 @@ func (s *FileServer) Get(req *fileserverpb.GetRequest, stream fileserverpb.FileS
 	}
 }
 
-func RegisterFileServerServer(s *grpc.Server, logger *log.Logger) {
-	fileserverpb.RegisterFileServerServer(s, NewFileServer("./image", "/api/v1/image", logger))
+func RegisterFileServerServer(s *grpc.Server, storageDir, privateDir, baseURL string, logger *log.Logger) {
+	fileserverpb.RegisterFileServerServer(s, NewFileServer(storageDir, privateDir, baseURL, logger))
 }
\ No newline at end of file
@@ -0,0 +1,85 @@
+package handlers
+
+import (
+	"context"
+	"errors"
+	"net/http"
+
+	"github.com/go-park-mail-ru/2025_2_Avrora/internal/delivery/http/response"
+	"github.com/go-park-mail-ru/2025_2_Avrora/internal/domain"
+	"github.com/go-park-mail-ru/2025_2_Avrora/internal/log"
+	"go.uber.org/zap"
+)
+
+type IChangeLogUsecase interface {
+	List(ctx context.Context, f *domain.ChangeLogFilter, limit, offset int) ([]domain.ChangeLogEntry, error)
+}
+
+type ChangeLogHandler struct {
+	changeLogUsecase IChangeLogUsecase
+	logger           *log.Logger
+}
+
+func NewChangeLogHandler(uc IChangeLogUsecase, logger *log.Logger) *ChangeLogHandler {
+	return &ChangeLogHandler{changeLogUsecase: uc, logger: logger}
+}
+
+// ListChanges — GET /api/v1/admin/changes?entity_type=offer&entity_id=...&actor_id=...&request_id=...&limit=50&offset=0
+func (h *ChangeLogHandler) ListChanges(w http.ResponseWriter, r *http.Request) {
+	limit, err := parseIntQueryParam(r, "limit", 50)
+	if err != nil {
+		response.HandleError(w, err, http.StatusBadRequest, "некорректный параметр limit")
+		return
+	}
+	offset, err := parseIntQueryParam(r, "offset", 0)
+	if err != nil {
+		response.HandleError(w, err, http.StatusBadRequest, "некорректный параметр offset")
+		return
+	}
+
+	q := r.URL.Query()
+	f := &domain.ChangeLogFilter{}
+	if v := q.Get("entity_type"); v != "" {
+		t := domain.ChangeEntityType(v)
+		f.EntityType = &t
+	}
+	if v := q.Get("entity_id"); v != "" {
+		f.EntityID = &v
+	}
+	if v := q.Get("actor_id"); v != "" {
+		f.ActorID = &v
+	}
+	if v := q.Get("request_id"); v != "" {
+		f.RequestID = &v
+	}
+
+	entries, err := h.changeLogUsecase.List(r.Context(), f, limit, offset)
+	if err != nil {
+		if errors.Is(err, domain.ErrInvalidInput) {
+			response.HandleError(w, err, http.StatusBadRequest, "укажите entity_id, actor_id или request_id")
+			return
+		}
+		h.logger.Error(r.Context(), "failed to list change log", zap.Error(err))
+		response.HandleError(w, nil, http.StatusInternalServerError, "ошибка получения истории изменений")
+		return
+	}
+
+	resp := make([]ChangeLogEntryResponse, 0, len(entries))
+	for _, e := range entries {
+		changes := make([]FieldChangeResponse, 0, len(e.Changes))
+		for _, c := range e.Changes {
+			changes = append(changes, FieldChangeResponse{Field: c.Field, Old: c.Old, New: c.New})
+		}
+		resp = append(resp, ChangeLogEntryResponse{
+			ID:         e.ID,
+			EntityType: string(e.EntityType),
+			EntityID:   e.EntityID,
+			Action:     string(e.Action),
+			ActorID:    e.ActorID,
+			RequestID:  e.RequestID,
+			Changes:    changes,
+			CreatedAt:  e.CreatedAt,
+		})
+	}
+	response.WriteJSON(w, http.StatusOK, resp)
+}
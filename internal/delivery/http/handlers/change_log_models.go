@@ -0,0 +1,23 @@
+package handlers
+
+import (
+	"encoding/json"
+	"time"
+)
+
+type FieldChangeResponse struct {
+	Field string          `json:"field"`
+	Old   json.RawMessage `json:"old"`
+	New   json.RawMessage `json:"new"`
+}
+
+type ChangeLogEntryResponse struct {
+	ID         string                `json:"id"`
+	EntityType string                `json:"entity_type"` // offer | housing_complex | profile
+	EntityID   string                `json:"entity_id"`   // user ID for profiles
+	Action     string                `json:"action"`      // create | update | delete
+	ActorID    *string               `json:"actor_id"`    // null for background jobs
+	RequestID  *string               `json:"request_id,omitempty"`
+	Changes    []FieldChangeResponse `json:"changes"`
+	CreatedAt  time.Time             `json:"created_at"`
+}
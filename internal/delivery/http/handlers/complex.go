@@ -5,8 +5,10 @@ import (
 	"encoding/json"
 	"errors"
 	"net/http"
+	"net/url"
 	"strconv"
 
+	"github.com/go-park-mail-ru/2025_2_Avrora/internal/delivery/http/middleware"
 	"github.com/go-park-mail-ru/2025_2_Avrora/internal/delivery/http/response"
 	"github.com/go-park-mail-ru/2025_2_Avrora/internal/domain"
 	"github.com/go-park-mail-ru/2025_2_Avrora/internal/log"
@@ -16,10 +18,19 @@ import (
 
 type IComplexUsecase interface {
 	GetByID(ctx context.Context, id string) (*domain.HousingComplex, error)
-	List(ctx context.Context, page, limit int) (*domain.ComplexesInFeed, error)
-	Create(ctx context.Context, complex *domain.HousingComplex) error
-	Update(ctx context.Context, complex *domain.HousingComplex) error
-	Delete(ctx context.Context, id string) error
+	List(ctx context.Context, f *domain.ComplexFilter, page, limit int) (*domain.ComplexesInFeed, error)
+	Create(ctx context.Context, userID string, complex *domain.HousingComplex) error
+	Update(ctx context.Context, userID, id string, patch domain.Patch, version *int) (*domain.HousingComplex, error)
+	Delete(ctx context.Context, userID, id string, version *int) error
+
+	AddBuilding(ctx context.Context, userID string, building *domain.ComplexBuilding) error
+	UpdateBuilding(ctx context.Context, userID string, building *domain.ComplexBuilding) error
+	DeleteBuilding(ctx context.Context, userID, complexID, buildingID string) error
+	AddLayout(ctx context.Context, userID string, layout *domain.ComplexLayout) error
+	UpdateLayout(ctx context.Context, userID string, layout *domain.ComplexLayout) error
+	DeleteLayout(ctx context.Context, userID, complexID, layoutID string) error
+	SetAmenities(ctx context.Context, userID, complexID string, amenities []domain.ComplexAmenity) ([]domain.ComplexAmenity, error)
+	ListOffers(ctx context.Context, complexID string, f *domain.OfferFilter, limit, offset int) ([]domain.OfferInFeed, error)
 }
 
 type ComplexHandler struct {
@@ -60,10 +71,13 @@ func (h *ComplexHandler) GetComplexByID(w http.ResponseWriter, r *http.Request)
 		return
 	}
 
+	setETag(w, complex.Version)
 	response.WriteJSON(w, http.StatusOK, complex)
 }
 
-// ListComplexes handles GET /api/v1/complexes
+// ListComplexes handles GET /api/v1/complexes/list?page=1&limit=10&region_id=...&metro_id=...
+// &developer=...&developer_id=...&year_min=&year_max=&price_min=&price_max=&completion=completed|under_construction
+// &sort=newest|price_asc|price_desc|distance&lat=&lon=
 func (h *ComplexHandler) ListComplexes(w http.ResponseWriter, r *http.Request) {
 	pageStr := r.URL.Query().Get("page")
 	limitStr := r.URL.Query().Get("limit")
@@ -89,7 +103,17 @@ func (h *ComplexHandler) ListComplexes(w http.ResponseWriter, r *http.Request) {
 		limit = l
 	}
 
-	result, err := h.complexUsecase.List(r.Context(), page, limit)
+	filter, err := complexFilterFromQuery(r.URL.Query())
+	if err != nil {
+		response.HandleError(w, err, http.StatusBadRequest, "некорректные параметры фильтра")
+		return
+	}
+
+	result, err := h.complexUsecase.List(r.Context(), filter, page, limit)
+	if errors.Is(err, domain.ErrInvalidInput) {
+		response.HandleError(w, err, http.StatusBadRequest, "некорректные параметры фильтра")
+		return
+	}
 	if err != nil {
 		h.logger.Error(r.Context(), "failed to list complexes", zap.Error(err))
 		response.HandleError(w, nil, http.StatusInternalServerError, "ошибка получения списка жилых комплексов")
@@ -109,19 +133,35 @@ func (h *ComplexHandler) CreateComplex(w http.ResponseWriter, r *http.Request) {
 
 	complexID := uuid.NewString()
 	complex := &domain.HousingComplex{
-		ID:            complexID,
-		Name:          req.Name,
-		Description:   req.Description,
-		YearBuilt:     req.YearBuilt,
-		LocationID:    req.LocationID,
-		Developer:     req.Developer,
-		Address:       req.Address,
-		StartingPrice: req.StartingPrice,
-		ImageURLs:     req.ImageURLs,
-	}
-
-	err := h.complexUsecase.Create(r.Context(), complex)
+		ID:          complexID,
+		Name:        req.Name,
+		Description: req.Description,
+		YearBuilt:   req.YearBuilt,
+		LocationID:  req.LocationID,
+		DeveloperID: req.DeveloperID,
+		Address:     req.Address,
+		ImageURLs:   req.ImageURLs,
+	}
+
+	userID, _ := middleware.GetUserIDFromContext(r.Context())
+	err := h.complexUsecase.Create(r.Context(), userID, complex)
 	if err != nil {
+		if errors.Is(err, domain.ErrImageNotOwned) {
+			response.HandleError(w, err, http.StatusForbidden, "изображение не найдено среди ваших загрузок")
+			return
+		}
+		if errors.Is(err, domain.ErrForbidden) {
+			response.HandleError(w, err, http.StatusForbidden, "жилой комплекс другого застройщика")
+			return
+		}
+		if errors.Is(err, domain.ErrInvalidInput) {
+			response.HandleError(w, err, http.StatusBadRequest, "невалидные данные")
+			return
+		}
+		if errors.Is(err, domain.ErrDeveloperNotFound) {
+			response.HandleError(w, nil, http.StatusNotFound, "застройщик не найден")
+			return
+		}
 		response.HandleError(w, nil, http.StatusInternalServerError, "ошибка создания жилого комплекса")
 		return
 	}
@@ -129,52 +169,50 @@ func (h *ComplexHandler) CreateComplex(w http.ResponseWriter, r *http.Request) {
 	response.WriteJSON(w, http.StatusCreated, complex)
 }
 
+// UpdateComplex takes a JSON merge patch: omitted fields are kept, null
+// clears an optional one. If-Match must carry the ETag the edit is based on.
 func (h *ComplexHandler) UpdateComplex(w http.ResponseWriter, r *http.Request) {
-	id := GetPathParameter(r, "/api/v1/complexes/update/")
-	if id == "" {
-		response.HandleError(w, nil, http.StatusBadRequest, "отсутствует ID жилого комплекса")
+	id, ok := uuidPathParameter(w, r, "/api/v1/complexes/update/")
+	if !ok {
 		return
 	}
-
-	if _, err := uuid.Parse(id); err != nil {
-		response.HandleError(w, nil, http.StatusBadRequest, "некорректный формат UUID")
+	version, ok := ifMatchVersion(w, r)
+	if !ok {
 		return
 	}
-
-	var req UpdateComplexRequest
-	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
-		response.HandleError(w, nil, http.StatusBadRequest, "некорректный JSON")
+	patch, ok := readMergePatch(w, r, complexPatchFields)
+	if !ok {
 		return
 	}
 
-	existing, err := h.complexUsecase.GetByID(r.Context(), id)
+	userID, _ := middleware.GetUserIDFromContext(r.Context())
+	complex, err := h.complexUsecase.Update(r.Context(), userID, id, patch, version)
 	if err != nil {
-		if errors.Is(err, domain.ErrComplexNotFound) {
+		var verr domain.ValidationErrors
+		switch {
+		case errors.As(err, &verr):
+			response.HandleValidationError(w, "проверьте заполнение полей", fieldErrorsMap(verr))
+		case errors.Is(err, domain.ErrInvalidInput):
+			response.HandleError(w, err, http.StatusBadRequest, "невалидные данные")
+		case errors.Is(err, domain.ErrComplexNotFound):
 			response.HandleError(w, nil, http.StatusNotFound, "жилой комплекс не найден")
-			return
+		case errors.Is(err, domain.ErrVersionMismatch):
+			response.HandleError(w, err, http.StatusPreconditionFailed, "жилой комплекс изменился, обновите страницу")
+		case errors.Is(err, domain.ErrImageNotOwned):
+			response.HandleError(w, err, http.StatusForbidden, "изображение не найдено среди ваших загрузок")
+		case errors.Is(err, domain.ErrForbidden):
+			response.HandleError(w, err, http.StatusForbidden, "жилой комплекс другого застройщика")
+		case errors.Is(err, domain.ErrDeveloperNotFound):
+			response.HandleError(w, nil, http.StatusNotFound, "застройщик не найден")
+		default:
+			h.logger.Error(r.Context(), "failed to update complex", zap.String("id", id), zap.Error(err))
+			response.HandleError(w, nil, http.StatusInternalServerError, "ошибка обновления жилого комплекса")
 		}
-		h.logger.Error(r.Context(), "failed to fetch complex for update", zap.String("id", id), zap.Error(err))
-		response.HandleError(w, nil, http.StatusInternalServerError, "ошибка обновления жилого комплекса")
-		return
-	}
-
-	existing.Name = req.Name
-	existing.Description = req.Description
-	existing.YearBuilt = req.YearBuilt
-	existing.LocationID = req.LocationID
-	existing.Developer = req.Developer
-	existing.Address = req.Address
-	existing.StartingPrice = req.StartingPrice
-	existing.ImageURLs = req.ImageURLs
-
-	err = h.complexUsecase.Update(r.Context(), existing)
-	if err != nil {
-		h.logger.Error(r.Context(), "failed to update complex", zap.String("id", id), zap.Error(err))
-		response.HandleError(w, nil, http.StatusInternalServerError, "ошибка обновления жилого комплекса")
 		return
 	}
 
-	response.WriteJSON(w, http.StatusOK, existing)
+	setETag(w, complex.Version)
+	response.WriteJSON(w, http.StatusOK, complex)
 }
 
 func (h *ComplexHandler) DeleteComplex(w http.ResponseWriter, r *http.Request) {
@@ -189,16 +227,110 @@ func (h *ComplexHandler) DeleteComplex(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	err := h.complexUsecase.Delete(r.Context(), id)
+	version, ok := ifMatchVersion(w, r)
+	if !ok {
+		return
+	}
+
+	userID, _ := middleware.GetUserIDFromContext(r.Context())
+	err := h.complexUsecase.Delete(r.Context(), userID, id, version)
 	if err != nil {
 		if errors.Is(err, domain.ErrComplexNotFound) {
 			response.HandleError(w, nil, http.StatusNotFound, "жилой комплекс не найден")
 			return
 		}
+		if errors.Is(err, domain.ErrForbidden) {
+			response.HandleError(w, err, http.StatusForbidden, "жилой комплекс другого застройщика")
+			return
+		}
+		if errors.Is(err, domain.ErrVersionMismatch) {
+			response.HandleError(w, err, http.StatusPreconditionFailed, "жилой комплекс изменился, обновите страницу")
+			return
+		}
 		h.logger.Error(r.Context(), "failed to delete complex", zap.String("id", id), zap.Error(err))
 		response.HandleError(w, nil, http.StatusInternalServerError, "ошибка удаления жилого комплекса")
 		return
 	}
 
 	w.WriteHeader(http.StatusNoContent)
+}
+
+// ListComplexOffers — GET /api/v1/complexes/offers/{id}?limit=20&offset=0 with the feed filters
+func (h *ComplexHandler) ListComplexOffers(w http.ResponseWriter, r *http.Request) {
+	id, ok := uuidPathParameter(w, r, "/api/v1/complexes/offers/")
+	if !ok {
+		return
+	}
+	limit, err := parseIntQueryParam(r, "limit", 20)
+	if err != nil {
+		response.HandleError(w, err, http.StatusBadRequest, "некорректный limit")
+		return
+	}
+	offset, err := parseIntQueryParam(r, "offset", 0)
+	if err != nil {
+		response.HandleError(w, err, http.StatusBadRequest, "некорректный offset")
+		return
+	}
+
+	offers, err := h.complexUsecase.ListOffers(r.Context(), id, offerFilterFromQuery(r.URL.Query()), limit, offset)
+	if err != nil {
+		switch {
+		case errors.Is(err, domain.ErrInvalidInput):
+			response.HandleError(w, err, http.StatusBadRequest, "некорректные параметры фильтра")
+		case errors.Is(err, domain.ErrComplexNotFound):
+			response.HandleError(w, nil, http.StatusNotFound, "жилой комплекс не найден")
+		default:
+			h.logger.Error(r.Context(), "failed to list complex offers", zap.String("id", id), zap.Error(err))
+			response.HandleError(w, nil, http.StatusInternalServerError, "ошибка получения объявлений жилого комплекса")
+		}
+		return
+	}
+
+	response.WriteJSON(w, http.StatusOK, offers)
+}
+
+// complexFilterFromQuery reads the complex feed filters, rejecting malformed numbers
+func complexFilterFromQuery(q url.Values) (*domain.ComplexFilter, error) {
+	f := &domain.ComplexFilter{
+		Completion: domain.ComplexCompletion(q.Get("completion")),
+		Sort:       domain.ComplexSort(q.Get("sort")),
+	}
+	for key, dst := range map[string]**string{
+		"region_id":    &f.RegionID,
+		"metro_id":     &f.MetroStationID,
+		"developer":    &f.Developer,
+		"developer_id": &f.DeveloperID,
+	} {
+		if v := q.Get(key); v != "" {
+			*dst = &v
+		}
+	}
+	for key, dst := range map[string]**int{"year_min": &f.YearBuiltMin, "year_max": &f.YearBuiltMax} {
+		if v := q.Get(key); v != "" {
+			i, err := strconv.Atoi(v)
+			if err != nil {
+				return nil, err
+			}
+			*dst = &i
+		}
+	}
+	for key, dst := range map[string]**int64{"price_min": &f.PriceMin, "price_max": &f.PriceMax} {
+		if v := q.Get(key); v != "" {
+			i, err := strconv.ParseInt(v, 10, 64)
+			if err != nil {
+				return nil, err
+			}
+			*dst = &i
+		}
+	}
+	for key, dst := range map[string]**float64{"lat": &f.Lat, "lon": &f.Lon} {
+		if v := q.Get(key); v != "" {
+			f64, err := strconv.ParseFloat(v, 64)
+			if err != nil {
+				return nil, err
+			}
+			*dst = &f64
+		}
+	}
+	return f, nil
 }
\ No newline at end of file
@@ -0,0 +1,218 @@
+package handlers
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+
+	"github.com/go-park-mail-ru/2025_2_Avrora/internal/delivery/http/middleware"
+	"github.com/go-park-mail-ru/2025_2_Avrora/internal/delivery/http/response"
+	"github.com/go-park-mail-ru/2025_2_Avrora/internal/domain"
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+)
+
+// complexItemFromPath reads {complexID}/{itemID} after base
+func complexItemFromPath(w http.ResponseWriter, r *http.Request, base string) (string, string, bool) {
+	params := GetPathParameters(r, base)
+	if len(params) != 2 {
+		response.HandleError(w, nil, http.StatusBadRequest, "ожидается путь вида {id}/{item_id}")
+		return "", "", false
+	}
+	for _, id := range params {
+		if _, err := uuid.Parse(id); err != nil {
+			response.HandleError(w, nil, http.StatusBadRequest, "некорректный формат UUID")
+			return "", "", false
+		}
+	}
+	return params[0], params[1], true
+}
+
+func (h *ComplexHandler) writeDetailsError(w http.ResponseWriter, r *http.Request, err error, msg string) {
+	var verr domain.ValidationErrors
+	switch {
+	case errors.As(err, &verr):
+		response.HandleValidationError(w, "проверьте заполнение полей", fieldErrorsMap(verr))
+	case errors.Is(err, domain.ErrInvalidInput):
+		response.HandleError(w, err, http.StatusBadRequest, "невалидные данные")
+	case errors.Is(err, domain.ErrComplexNotFound):
+		response.HandleError(w, nil, http.StatusNotFound, "жилой комплекс не найден")
+	case errors.Is(err, domain.ErrBuildingNotFound):
+		response.HandleError(w, nil, http.StatusNotFound, "корпус не найден")
+	case errors.Is(err, domain.ErrLayoutNotFound):
+		response.HandleError(w, nil, http.StatusNotFound, "планировка не найдена")
+	case errors.Is(err, domain.ErrImageNotOwned):
+		response.HandleError(w, err, http.StatusForbidden, "изображение не найдено среди ваших загрузок")
+	case errors.Is(err, domain.ErrForbidden):
+		response.HandleError(w, err, http.StatusForbidden, "жилой комплекс другого застройщика")
+	default:
+		h.logger.Error(r.Context(), msg, zap.String("path", r.URL.Path), zap.Error(err))
+		response.HandleError(w, nil, http.StatusInternalServerError, "ошибка изменения жилого комплекса")
+	}
+}
+
+func decodeBuilding(r *http.Request, building *domain.ComplexBuilding) error {
+	var req ComplexBuildingRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		return err
+	}
+	building.Name = req.Name
+	building.Phase = req.Phase
+	building.Floors = req.Floors
+	building.Stage = domain.ConstructionStage(req.Stage)
+	building.HandoverYear = req.HandoverYear
+	building.HandoverQuarter = req.HandoverQuarter
+	return nil
+}
+
+func decodeLayout(r *http.Request, layout *domain.ComplexLayout) error {
+	var req ComplexLayoutRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		return err
+	}
+	layout.BuildingID = req.BuildingID
+	layout.Rooms = req.Rooms
+	layout.Area = req.Area
+	layout.PriceMin = req.PriceMin
+	layout.PriceMax = req.PriceMax
+	layout.ImageURL = req.ImageURL
+	return nil
+}
+
+// AddBuilding — POST /api/v1/complexes/buildings/add/{id}
+func (h *ComplexHandler) AddBuilding(w http.ResponseWriter, r *http.Request) {
+	complexID, ok := uuidPathParameter(w, r, "/api/v1/complexes/buildings/add/")
+	if !ok {
+		return
+	}
+	building := &domain.ComplexBuilding{ComplexID: complexID}
+	if err := decodeBuilding(r, building); err != nil {
+		response.HandleError(w, nil, http.StatusBadRequest, "некорректный JSON")
+		return
+	}
+
+	userID, _ := middleware.GetUserIDFromContext(r.Context())
+	if err := h.complexUsecase.AddBuilding(r.Context(), userID, building); err != nil {
+		h.writeDetailsError(w, r, err, "failed to add complex building")
+		return
+	}
+	response.WriteJSON(w, http.StatusCreated, building)
+}
+
+// UpdateBuilding — PUT /api/v1/complexes/buildings/update/{id}/{building_id}
+func (h *ComplexHandler) UpdateBuilding(w http.ResponseWriter, r *http.Request) {
+	complexID, buildingID, ok := complexItemFromPath(w, r, "/api/v1/complexes/buildings/update/")
+	if !ok {
+		return
+	}
+	building := &domain.ComplexBuilding{ID: buildingID, ComplexID: complexID}
+	if err := decodeBuilding(r, building); err != nil {
+		response.HandleError(w, nil, http.StatusBadRequest, "некорректный JSON")
+		return
+	}
+
+	userID, _ := middleware.GetUserIDFromContext(r.Context())
+	if err := h.complexUsecase.UpdateBuilding(r.Context(), userID, building); err != nil {
+		h.writeDetailsError(w, r, err, "failed to update complex building")
+		return
+	}
+	response.WriteJSON(w, http.StatusOK, building)
+}
+
+// DeleteBuilding — DELETE /api/v1/complexes/buildings/delete/{id}/{building_id}
+func (h *ComplexHandler) DeleteBuilding(w http.ResponseWriter, r *http.Request) {
+	complexID, buildingID, ok := complexItemFromPath(w, r, "/api/v1/complexes/buildings/delete/")
+	if !ok {
+		return
+	}
+
+	userID, _ := middleware.GetUserIDFromContext(r.Context())
+	if err := h.complexUsecase.DeleteBuilding(r.Context(), userID, complexID, buildingID); err != nil {
+		h.writeDetailsError(w, r, err, "failed to delete complex building")
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// AddLayout — POST /api/v1/complexes/layouts/add/{id}
+func (h *ComplexHandler) AddLayout(w http.ResponseWriter, r *http.Request) {
+	complexID, ok := uuidPathParameter(w, r, "/api/v1/complexes/layouts/add/")
+	if !ok {
+		return
+	}
+	layout := &domain.ComplexLayout{ComplexID: complexID}
+	if err := decodeLayout(r, layout); err != nil {
+		response.HandleError(w, nil, http.StatusBadRequest, "некорректный JSON")
+		return
+	}
+
+	userID, _ := middleware.GetUserIDFromContext(r.Context())
+	if err := h.complexUsecase.AddLayout(r.Context(), userID, layout); err != nil {
+		h.writeDetailsError(w, r, err, "failed to add complex layout")
+		return
+	}
+	response.WriteJSON(w, http.StatusCreated, layout)
+}
+
+// UpdateLayout — PUT /api/v1/complexes/layouts/update/{id}/{layout_id}
+func (h *ComplexHandler) UpdateLayout(w http.ResponseWriter, r *http.Request) {
+	complexID, layoutID, ok := complexItemFromPath(w, r, "/api/v1/complexes/layouts/update/")
+	if !ok {
+		return
+	}
+	layout := &domain.ComplexLayout{ID: layoutID, ComplexID: complexID}
+	if err := decodeLayout(r, layout); err != nil {
+		response.HandleError(w, nil, http.StatusBadRequest, "некорректный JSON")
+		return
+	}
+
+	userID, _ := middleware.GetUserIDFromContext(r.Context())
+	if err := h.complexUsecase.UpdateLayout(r.Context(), userID, layout); err != nil {
+		h.writeDetailsError(w, r, err, "failed to update complex layout")
+		return
+	}
+	response.WriteJSON(w, http.StatusOK, layout)
+}
+
+// DeleteLayout — DELETE /api/v1/complexes/layouts/delete/{id}/{layout_id}
+func (h *ComplexHandler) DeleteLayout(w http.ResponseWriter, r *http.Request) {
+	complexID, layoutID, ok := complexItemFromPath(w, r, "/api/v1/complexes/layouts/delete/")
+	if !ok {
+		return
+	}
+
+	userID, _ := middleware.GetUserIDFromContext(r.Context())
+	if err := h.complexUsecase.DeleteLayout(r.Context(), userID, complexID, layoutID); err != nil {
+		h.writeDetailsError(w, r, err, "failed to delete complex layout")
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// SetAmenities — PUT /api/v1/complexes/amenities/{id} with the full list
+func (h *ComplexHandler) SetAmenities(w http.ResponseWriter, r *http.Request) {
+	complexID, ok := uuidPathParameter(w, r, "/api/v1/complexes/amenities/")
+	if !ok {
+		return
+	}
+	var req ComplexAmenitiesRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		response.HandleError(w, nil, http.StatusBadRequest, "некорректный JSON")
+		return
+	}
+	amenities := make([]domain.ComplexAmenity, 0, len(req.Amenities))
+	for _, a := range req.Amenities {
+		amenities = append(amenities, domain.ComplexAmenity(a))
+	}
+
+	userID, _ := middleware.GetUserIDFromContext(r.Context())
+	amenities, err := h.complexUsecase.SetAmenities(r.Context(), userID, complexID, amenities)
+	if err != nil {
+		h.writeDetailsError(w, r, err, "failed to set complex amenities")
+		return
+	}
+	if amenities == nil {
+		amenities = []domain.ComplexAmenity{}
+	}
+	response.WriteJSON(w, http.StatusOK, amenities)
+}
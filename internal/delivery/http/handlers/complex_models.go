@@ -1,23 +1,48 @@
 package handlers
 
+import "github.com/go-park-mail-ru/2025_2_Avrora/internal/delivery/http/utils"
+
 type CreateComplexRequest struct {
-	Name          string   `json:"name"`
-	Description   string   `json:"description"`
-	YearBuilt     *int     `json:"year_built,omitempty"`
-	LocationID    string   `json:"location_id"`
-	Developer     string   `json:"developer"`
-	Address       string   `json:"address"`
-	StartingPrice *int64   `json:"starting_price,omitempty"`
-	ImageURLs     []string `json:"image_urls"`
+	Name        string   `json:"name"`
+	Description string   `json:"description"`
+	YearBuilt   *int     `json:"year_built,omitempty"`
+	LocationID  string   `json:"location_id"`
+	DeveloperID *string  `json:"developer_id,omitempty"`
+	Address     string   `json:"address"`
+	ImageURLs   []string `json:"image_urls"`
+}
+
+// complexPatchFields are the complex fields UpdateComplex accepts
+var complexPatchFields = map[string]utils.PatchField{
+	"name":         utils.PatchString(false),
+	"description":  utils.PatchString(true),
+	"year_built":   utils.PatchInt(true),
+	"location_id":  utils.PatchUUID(false),
+	"developer_id": utils.PatchUUID(true),
+	"address":      utils.PatchString(true),
+	"image_urls":   utils.PatchStrings(),
+}
+
+// ComplexBuildingRequest is the whole building, for both adding and updating
+type ComplexBuildingRequest struct {
+	Name            string `json:"name"`
+	Phase           int    `json:"phase,omitempty"` // 1 when omitted
+	Floors          int    `json:"floors"`
+	Stage           string `json:"stage,omitempty"` // planned, foundation, frame, facade, finishing, completed
+	HandoverYear    *int   `json:"handover_year,omitempty"`
+	HandoverQuarter *int   `json:"handover_quarter,omitempty"`
+}
+
+// ComplexLayoutRequest is the whole layout, for both adding and updating
+type ComplexLayoutRequest struct {
+	BuildingID *string `json:"building_id,omitempty"` // omitted when offered in all buildings
+	Rooms      int     `json:"rooms"`
+	Area       float64 `json:"area"`
+	PriceMin   *int64  `json:"price_min,omitempty"`
+	PriceMax   *int64  `json:"price_max,omitempty"`
+	ImageURL   *string `json:"image_url,omitempty"`
 }
 
-type UpdateComplexRequest struct {
-	Name          string   `json:"name"`
-	Description   string   `json:"description"`
-	YearBuilt     *int     `json:"year_built,omitempty"`
-	LocationID    string   `json:"location_id"`
-	Developer     string   `json:"developer"`
-	Address       string   `json:"address"`
-	StartingPrice *int64   `json:"starting_price,omitempty"`
-	ImageURLs     []string `json:"image_urls"`
-}
\ No newline at end of file
+type ComplexAmenitiesRequest struct {
+	Amenities []string `json:"amenities"`
+}
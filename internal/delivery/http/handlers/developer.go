@@ -0,0 +1,201 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+
+	"github.com/go-park-mail-ru/2025_2_Avrora/internal/delivery/http/middleware"
+	"github.com/go-park-mail-ru/2025_2_Avrora/internal/delivery/http/response"
+	"github.com/go-park-mail-ru/2025_2_Avrora/internal/domain"
+	"github.com/go-park-mail-ru/2025_2_Avrora/internal/log"
+	"go.uber.org/zap"
+)
+
+type IDeveloperUsecase interface {
+	GetByID(ctx context.Context, id string) (*domain.Developer, error)
+	List(ctx context.Context, query *string, limit, offset int) ([]domain.Developer, error)
+	Create(ctx context.Context, userID string, d *domain.Developer) error
+	Update(ctx context.Context, userID string, d *domain.Developer) error
+	AddMember(ctx context.Context, developerID, userID string) error
+	RemoveMember(ctx context.Context, developerID, userID string) error
+}
+
+type DeveloperHandler struct {
+	developerUsecase IDeveloperUsecase
+	logger           *log.Logger
+}
+
+func NewDeveloperHandler(uc IDeveloperUsecase, logger *log.Logger) *DeveloperHandler {
+	return &DeveloperHandler{developerUsecase: uc, logger: logger}
+}
+
+func (h *DeveloperHandler) writeError(w http.ResponseWriter, r *http.Request, err error, msg string) {
+	var verr domain.ValidationErrors
+	switch {
+	case errors.As(err, &verr):
+		response.HandleValidationError(w, "проверьте заполнение полей", fieldErrorsMap(verr))
+	case errors.Is(err, domain.ErrInvalidInput):
+		response.HandleError(w, err, http.StatusBadRequest, "невалидные данные")
+	case errors.Is(err, domain.ErrDeveloperNotFound):
+		response.HandleError(w, nil, http.StatusNotFound, "застройщик не найден")
+	case errors.Is(err, domain.ErrUserNotFound):
+		response.HandleError(w, nil, http.StatusNotFound, "пользователь не найден")
+	case errors.Is(err, domain.ErrDeveloperExists):
+		response.HandleError(w, err, http.StatusConflict, "застройщик с таким названием уже есть")
+	case errors.Is(err, domain.ErrForbidden):
+		response.HandleError(w, err, http.StatusForbidden, "профиль другого застройщика")
+	case errors.Is(err, domain.ErrImageNotOwned):
+		response.HandleError(w, err, http.StatusForbidden, "изображение не найдено среди ваших загрузок")
+	default:
+		h.logger.Error(r.Context(), "developer operation failed", zap.String("path", r.URL.Path), zap.Error(err))
+		response.HandleError(w, nil, http.StatusInternalServerError, msg)
+	}
+}
+
+func toDeveloperResponse(d *domain.Developer) DeveloperResponse {
+	return DeveloperResponse{
+		ID:          d.ID,
+		Name:        d.Name,
+		Description: d.Description,
+		LogoURL:     d.LogoURL,
+		Website:     d.Website,
+		Phone:       d.Phone,
+		FoundedYear: d.FoundedYear,
+		Complexes:   d.Complexes,
+		Delivered:   d.Delivered,
+		CreatedAt:   d.CreatedAt,
+		UpdatedAt:   d.UpdatedAt,
+	}
+}
+
+func decodeDeveloper(r *http.Request, d *domain.Developer) error {
+	var req DeveloperRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		return err
+	}
+	d.Name = req.Name
+	d.Description = req.Description
+	d.LogoURL = req.LogoURL
+	d.Website = req.Website
+	d.Phone = req.Phone
+	d.FoundedYear = req.FoundedYear
+	return nil
+}
+
+// ListDevelopers — GET /api/v1/developers/list?q=...&limit=20&offset=0
+func (h *DeveloperHandler) ListDevelopers(w http.ResponseWriter, r *http.Request) {
+	limit, err := parseIntQueryParam(r, "limit", 20)
+	if err != nil {
+		response.HandleError(w, err, http.StatusBadRequest, "некорректный limit")
+		return
+	}
+	offset, err := parseIntQueryParam(r, "offset", 0)
+	if err != nil {
+		response.HandleError(w, err, http.StatusBadRequest, "некорректный offset")
+		return
+	}
+	var query *string
+	if q := r.URL.Query().Get("q"); q != "" {
+		query = &q
+	}
+
+	developers, err := h.developerUsecase.List(r.Context(), query, limit, offset)
+	if err != nil {
+		h.writeError(w, r, err, "ошибка получения списка застройщиков")
+		return
+	}
+
+	resp := make([]DeveloperResponse, 0, len(developers))
+	for i := range developers {
+		resp = append(resp, toDeveloperResponse(&developers[i]))
+	}
+	response.WriteJSON(w, http.StatusOK, resp)
+}
+
+// GetDeveloper — GET /api/v1/developers/{id}. Complexes of the developer are
+// listed by /api/v1/complexes/list?developer_id={id}, the delivered ones
+// with &completion=completed.
+func (h *DeveloperHandler) GetDeveloper(w http.ResponseWriter, r *http.Request) {
+	id, ok := uuidPathParameter(w, r, "/api/v1/developers/")
+	if !ok {
+		return
+	}
+
+	developer, err := h.developerUsecase.GetByID(r.Context(), id)
+	if err != nil {
+		h.writeError(w, r, err, "ошибка получения застройщика")
+		return
+	}
+	response.WriteJSON(w, http.StatusOK, toDeveloperResponse(developer))
+}
+
+// CreateDeveloper — POST /api/v1/developers/create
+func (h *DeveloperHandler) CreateDeveloper(w http.ResponseWriter, r *http.Request) {
+	var developer domain.Developer
+	if err := decodeDeveloper(r, &developer); err != nil {
+		response.HandleError(w, nil, http.StatusBadRequest, "некорректный JSON")
+		return
+	}
+
+	userID, _ := middleware.GetUserIDFromContext(r.Context())
+	if err := h.developerUsecase.Create(r.Context(), userID, &developer); err != nil {
+		h.writeError(w, r, err, "ошибка создания застройщика")
+		return
+	}
+	response.WriteJSON(w, http.StatusCreated, toDeveloperResponse(&developer))
+}
+
+// UpdateDeveloper — PUT /api/v1/developers/update/{id} with the whole profile
+func (h *DeveloperHandler) UpdateDeveloper(w http.ResponseWriter, r *http.Request) {
+	id, ok := uuidPathParameter(w, r, "/api/v1/developers/update/")
+	if !ok {
+		return
+	}
+	developer := domain.Developer{ID: id}
+	if err := decodeDeveloper(r, &developer); err != nil {
+		response.HandleError(w, nil, http.StatusBadRequest, "некорректный JSON")
+		return
+	}
+
+	userID, _ := middleware.GetUserIDFromContext(r.Context())
+	if err := h.developerUsecase.Update(r.Context(), userID, &developer); err != nil {
+		h.writeError(w, r, err, "ошибка обновления застройщика")
+		return
+	}
+	response.WriteJSON(w, http.StatusOK, toDeveloperResponse(&developer))
+}
+
+// AddMember — POST /api/v1/developers/members/add/{id} {"user_id": ...}
+func (h *DeveloperHandler) AddMember(w http.ResponseWriter, r *http.Request) {
+	id, ok := uuidPathParameter(w, r, "/api/v1/developers/members/add/")
+	if !ok {
+		return
+	}
+	var req DeveloperMemberRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		response.HandleError(w, nil, http.StatusBadRequest, "некорректный JSON")
+		return
+	}
+
+	if err := h.developerUsecase.AddMember(r.Context(), id, req.UserID); err != nil {
+		h.writeError(w, r, err, "ошибка привязки аккаунта к застройщику")
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// RemoveMember — DELETE /api/v1/developers/members/remove/{id}/{user_id}
+func (h *DeveloperHandler) RemoveMember(w http.ResponseWriter, r *http.Request) {
+	id, userID, ok := complexItemFromPath(w, r, "/api/v1/developers/members/remove/")
+	if !ok {
+		return
+	}
+
+	if err := h.developerUsecase.RemoveMember(r.Context(), id, userID); err != nil {
+		h.writeError(w, r, err, "ошибка отвязки аккаунта от застройщика")
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
@@ -0,0 +1,31 @@
+package handlers
+
+import "time"
+
+// DeveloperRequest is the whole developer profile, for both creating and updating
+type DeveloperRequest struct {
+	Name        string  `json:"name"`
+	Description string  `json:"description"`
+	LogoURL     *string `json:"logo_url,omitempty"`
+	Website     *string `json:"website,omitempty"`
+	Phone       *string `json:"phone,omitempty"`
+	FoundedYear *int    `json:"founded_year,omitempty"`
+}
+
+type DeveloperResponse struct {
+	ID          string    `json:"id"`
+	Name        string    `json:"name"`
+	Description string    `json:"description"`
+	LogoURL     *string   `json:"logo_url"`
+	Website     *string   `json:"website"`
+	Phone       *string   `json:"phone"`
+	FoundedYear *int      `json:"founded_year"`
+	Complexes   int       `json:"complexes"`
+	Delivered   int       `json:"delivered"`
+	CreatedAt   time.Time `json:"created_at"`
+	UpdatedAt   time.Time `json:"updated_at"`
+}
+
+type DeveloperMemberRequest struct {
+	UserID string `json:"user_id"`
+}
@@ -1,12 +1,19 @@
 package handlers
 
 import (
+	"encoding/json"
 	"errors"
+	"io"
 	"net/http"
 	"regexp"
 	"strconv"
 	"strings"
 	"unicode"
+
+	"github.com/go-park-mail-ru/2025_2_Avrora/internal/delivery/http/response"
+	"github.com/go-park-mail-ru/2025_2_Avrora/internal/delivery/http/utils"
+	"github.com/go-park-mail-ru/2025_2_Avrora/internal/domain"
+	"github.com/google/uuid"
 )
 
 func validateEmail(email string) bool {
@@ -86,4 +93,94 @@ func GetPathParameter(r *http.Request, basePattern string) string {
 	}
 
 	return remainder
-}
\ No newline at end of file
+}
+// GetPathParameters returns all path segments after basePattern, e.g. {offerID, photoID}
+func GetPathParameters(r *http.Request, basePattern string) []string {
+	if !strings.HasSuffix(basePattern, "/") {
+		basePattern += "/"
+	}
+
+	remainder, ok := strings.CutPrefix(r.URL.Path, basePattern)
+	if !ok {
+		return nil
+	}
+
+	var params []string
+	for _, segment := range strings.Split(remainder, "/") {
+		if segment != "" {
+			params = append(params, segment)
+		}
+	}
+	return params
+}
+
+// uuidPathParameter reads a UUID right after base, answering 400 if it is missing or malformed
+func uuidPathParameter(w http.ResponseWriter, r *http.Request, base string) (string, bool) {
+	id := GetPathParameter(r, base)
+	if id == "" {
+		response.HandleError(w, nil, http.StatusBadRequest, "отсутствует ID")
+		return "", false
+	}
+	if _, err := uuid.Parse(id); err != nil {
+		response.HandleError(w, nil, http.StatusBadRequest, "некорректный формат UUID")
+		return "", false
+	}
+	return id, true
+}
+
+// nullFields lists the top-level keys of a JSON object that are explicitly null
+func nullFields(body []byte) ([]string, error) {
+	var raw map[string]json.RawMessage
+	if err := json.Unmarshal(body, &raw); err != nil {
+		return nil, err
+	}
+	var fields []string
+	for key, value := range raw {
+		if string(value) == "null" {
+			fields = append(fields, key)
+		}
+	}
+	return fields, nil
+}
+
+// readMergePatch decodes a JSON merge patch body limited to fields,
+// answering 400 or 422 itself when the body is unusable
+func readMergePatch(w http.ResponseWriter, r *http.Request, fields map[string]utils.PatchField) (domain.Patch, bool) {
+	body, err := io.ReadAll(r.Body)
+	if err != nil {
+		response.HandleError(w, err, http.StatusBadRequest, "не удалось прочитать тело запроса")
+		return nil, false
+	}
+
+	patch, err := utils.ParseMergePatch(body, fields)
+	var verr domain.ValidationErrors
+	switch {
+	case errors.As(err, &verr):
+		response.HandleValidationError(w, "проверьте заполнение полей", fieldErrorsMap(verr))
+		return nil, false
+	case err != nil:
+		response.HandleError(w, err, http.StatusBadRequest, "тело запроса должно быть JSON-объектом")
+		return nil, false
+	}
+	return patch, true
+}
+
+// ifMatchVersion reads the If-Match precondition writes require, answering
+// 428 when it is missing and 400 when it is malformed. nil stands for "*".
+func ifMatchVersion(w http.ResponseWriter, r *http.Request) (*int, bool) {
+	header := r.Header.Get("If-Match")
+	if header == "" {
+		response.HandleError(w, nil, http.StatusPreconditionRequired, "нужен заголовок If-Match с ETag ресурса")
+		return nil, false
+	}
+	version, err := utils.ParseIfMatch(header)
+	if err != nil {
+		response.HandleError(w, err, http.StatusBadRequest, "некорректный заголовок If-Match")
+		return nil, false
+	}
+	return version, true
+}
+
+func setETag(w http.ResponseWriter, version int) {
+	w.Header().Set("ETag", utils.FormatETag(version))
+}
@@ -1,33 +1,44 @@
 package handlers
 
 import (
+	"context"
 	"fmt"
 	"io"
 	"net/http"
-	"path/filepath"
 	"strings"
 
+	"github.com/go-park-mail-ru/2025_2_Avrora/internal/delivery/http/middleware"
 	"github.com/go-park-mail-ru/2025_2_Avrora/internal/delivery/http/response"
+	"github.com/go-park-mail-ru/2025_2_Avrora/internal/delivery/http/utils"
+	"github.com/go-park-mail-ru/2025_2_Avrora/internal/domain"
 	"github.com/go-park-mail-ru/2025_2_Avrora/internal/log"
 	fileserverpb "github.com/go-park-mail-ru/2025_2_Avrora/proto/fileserver"
 	"go.uber.org/zap"
-
-	"github.com/google/uuid"
 )
 
+type IImageUsecase interface {
+	RegisterUpload(ctx context.Context, userID, url string, blob *domain.ImageBlob) (*domain.ImageUpload, error)
+	RegisterPrivateFile(ctx context.Context, f *domain.PrivateFile) error
+	GetOwnedPrivateFile(ctx context.Context, userID, filename string) (*domain.PrivateFile, error)
+}
+
 type ImageHandler struct {
-	fileserver fileserverpb.FileServerClient // gRPC client for file operations
-	logger     *log.Logger
-	baseURL    string
+	fileserver   fileserverpb.FileServerClient // gRPC client for file operations
+	imageUsecase IImageUsecase
+	signer       *utils.URLSigner // signs links to the private bucket
+	logger       *log.Logger
+	baseURL      string
 }
 
 const MAX_SIZE = 10 << 20 // 10MB
 
-func NewImageHandler(fs fileserverpb.FileServerClient, logger *log.Logger, baseURL string) *ImageHandler {
+func NewImageHandler(fs fileserverpb.FileServerClient, imageUC IImageUsecase, signer *utils.URLSigner, logger *log.Logger, baseURL string) *ImageHandler {
 	return &ImageHandler{
-		fileserver: fs,
-		logger:     logger,
-		baseURL:    baseURL,
+		fileserver:   fs,
+		imageUsecase: imageUC,
+		signer:       signer,
+		logger:       logger,
+		baseURL:      baseURL,
 	}
 }
 
@@ -57,31 +68,27 @@ func (h *ImageHandler) UploadImage(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	// Get and sanitize file extension
-	ext := strings.ToLower(filepath.Ext(header.Filename))
-	if ext == "" {
-		ext = ".jpg"
-	}
-
-	// Generate UUID-based filename
-	uuidName := fmt.Sprintf("%s%s", uuid.New().String(), ext)
-
-	// Get content type (fallback if not provided)
-	contentType := header.Header.Get("Content-Type")
-	if contentType == "" {
-		contentType = "application/octet-stream"
+	// Content-addressed filename: identical uploads share one stored file.
+	// Type and extension come from the bytes, not from what the client claims.
+	hash := utils.ContentHash(data)
+	contentType := utils.SniffContentType(data)
+	if !utils.IsImageContentType(contentType) {
+		h.logger.Warn(ctx, "upload is not an image", zap.String("content_type", contentType))
+		response.HandleError(w, nil, http.StatusBadRequest, "файл не является изображением")
+		return
 	}
+	storedName := utils.ContentAddressedFilename(hash, contentType)
 
 	// ✅ Call gRPC file server to upload
 	req := &fileserverpb.UploadRequest{
 		Data:        data,
-		Filename:    uuidName,
+		Filename:    storedName,
 		ContentType: contentType,
 	}
 
 	resp, err := h.fileserver.Upload(ctx, req)
 	if err != nil {
-		h.logger.Error(ctx, "gRPC upload failed", zap.Error(err), zap.String("filename", uuidName))
+		h.logger.Error(ctx, "gRPC upload failed", zap.Error(err), zap.String("filename", storedName))
 		response.HandleError(w, err, http.StatusInternalServerError, "не удалось сохранить картинку на сервере")
 		return
 	}
@@ -92,27 +99,52 @@ func (h *ImageHandler) UploadImage(w http.ResponseWriter, r *http.Request) {
 		fileURL = fmt.Sprintf("%s%s", h.baseURL, fileURL)
 	}
 
+	userID, _ := middleware.GetUserIDFromContext(ctx)
+	blob := &domain.ImageBlob{
+		Hash:        hash,
+		Filename:    storedName,
+		ContentType: contentType,
+		SizeBytes:   int64(len(data)),
+		PHash:       resp.Phash,
+	}
+	upload, err := h.imageUsecase.RegisterUpload(ctx, userID, fileURL, blob)
+	if err != nil {
+		h.logger.Error(ctx, "failed to register image upload", zap.Error(err), zap.String("filename", storedName))
+		response.HandleError(w, err, http.StatusInternalServerError, "не удалось сохранить картинку на сервере")
+		return
+	}
+
 	h.logger.Info(ctx, "image uploaded successfully via gRPC",
 		zap.String("original_filename", header.Filename),
-		zap.String("stored_filename", uuidName),
+		zap.String("stored_filename", storedName),
 		zap.String("url", fileURL),
 	)
 
-	response.WriteJSON(w, http.StatusCreated, map[string]string{"url": fileURL})
+	response.WriteJSON(w, http.StatusCreated, UploadImageResponse{
+		URL:       fileURL,
+		UploadID:  upload.ID,
+		ExpiresAt: upload.ExpiresAt,
+	})
 }
 
 // GetImage — GET /api/v1/image/{filename}
 func (h *ImageHandler) GetImage(w http.ResponseWriter, r *http.Request) {
-	ctx := r.Context()
-
 	filename := strings.TrimPrefix(r.URL.Path, "/api/v1/image/")
 	if filename == "" || strings.Contains(filename, "/") || strings.Contains(filename, "..") {
 		response.HandleError(w, nil, http.StatusNotFound, "некорректное имя файла")
 		return
 	}
 
+	h.streamFile(w, r, filename, "", "public, max-age=86400") // Cache for 24 hours
+}
+
+// streamFile pipes a file from the gRPC file server to the client
+func (h *ImageHandler) streamFile(w http.ResponseWriter, r *http.Request, filename, bucket, cacheControl string) {
+	ctx := r.Context()
+
 	req := &fileserverpb.GetRequest{
 		Filename: filename,
+		Bucket:   bucket,
 	}
 
 	stream, err := h.fileserver.Get(ctx, req)
@@ -123,7 +155,7 @@ func (h *ImageHandler) GetImage(w http.ResponseWriter, r *http.Request) {
 	}
 
 	// Stream response chunks to client
-	w.Header().Set("Cache-Control", "public, max-age=86400") // Cache for 24 hours
+	w.Header().Set("Cache-Control", cacheControl)
 
 	for {
 		resp, err := stream.Recv()
@@ -0,0 +1,20 @@
+package handlers
+
+import "time"
+
+type UploadImageResponse struct {
+	URL       string    `json:"url"`
+	UploadID  string    `json:"upload_id"`
+	ExpiresAt time.Time `json:"expires_at"` // attach the image to an offer, complex or avatar before this
+}
+
+type SignURLRequest struct {
+	TTLSeconds int  `json:"ttl_seconds"` // defaults to 15 minutes, at most 24 hours
+	BindUser   bool `json:"bind_user"`   // only the requesting user may open the link
+}
+
+type SignedURLResponse struct {
+	Filename  string    `json:"filename"`
+	URL       string    `json:"url"`
+	ExpiresAt time.Time `json:"expires_at"`
+}
@@ -0,0 +1,70 @@
+package handlers
+
+import (
+	"context"
+	"errors"
+	"net/http"
+
+	"github.com/go-park-mail-ru/2025_2_Avrora/internal/delivery/http/response"
+	"github.com/go-park-mail-ru/2025_2_Avrora/internal/domain"
+	"github.com/go-park-mail-ru/2025_2_Avrora/internal/log"
+	"go.uber.org/zap"
+)
+
+type IMarketUsecase interface {
+	Stats(ctx context.Context, q *domain.MarketStatsQuery) ([]domain.MarketStats, error)
+}
+
+type MarketHandler struct {
+	marketUsecase IMarketUsecase
+	logger        *log.Logger
+}
+
+func NewMarketHandler(uc IMarketUsecase, logger *log.Logger) *MarketHandler {
+	return &MarketHandler{marketUsecase: uc, logger: logger}
+}
+
+// GetStats — GET /api/v1/market/stats?scope=complex|metro|region&id=...&offer_type=sale&property_type=apartment
+func (h *MarketHandler) GetStats(w http.ResponseWriter, r *http.Request) {
+	query := r.URL.Query()
+	q := &domain.MarketStatsQuery{
+		Scope:   domain.MarketScope(query.Get("scope")),
+		ScopeID: query.Get("id"),
+	}
+	if v := query.Get("offer_type"); v != "" {
+		ot := domain.OfferType(v)
+		q.OfferType = &ot
+	}
+	if v := query.Get("property_type"); v != "" {
+		pt := domain.PropertyType(v)
+		q.PropertyType = &pt
+	}
+
+	stats, err := h.marketUsecase.Stats(r.Context(), q)
+	if err != nil {
+		if errors.Is(err, domain.ErrInvalidInput) {
+			response.HandleError(w, err, http.StatusBadRequest, "некорректные параметры статистики")
+			return
+		}
+		h.logger.Error(r.Context(), "failed to get market stats", zap.Error(err))
+		response.HandleError(w, nil, http.StatusInternalServerError, "ошибка получения статистики цен")
+		return
+	}
+
+	resp := make([]MarketStatsResponse, 0, len(stats))
+	for _, s := range stats {
+		resp = append(resp, MarketStatsResponse{
+			Scope:        string(s.Scope),
+			ScopeID:      s.ScopeID,
+			OfferType:    string(s.OfferType),
+			PropertyType: string(s.PropertyType),
+			Rooms:        s.Rooms,
+			P25:          s.P25,
+			Median:       s.Median,
+			P75:          s.P75,
+			Listings:     s.Listings,
+			RefreshedAt:  s.RefreshedAt,
+		})
+	}
+	response.WriteJSON(w, http.StatusOK, resp)
+}
@@ -0,0 +1,16 @@
+package handlers
+
+import "time"
+
+type MarketStatsResponse struct {
+	Scope        string    `json:"scope"`
+	ScopeID      string    `json:"scope_id"`
+	OfferType    string    `json:"offer_type"`
+	PropertyType string    `json:"property_type"`
+	Rooms        *int      `json:"rooms"` // null for all offers, 4 means 4 and more
+	P25          float64   `json:"p25_price_per_sqm"`
+	Median       float64   `json:"median_price_per_sqm"`
+	P75          float64   `json:"p75_price_per_sqm"`
+	Listings     int       `json:"listings"`
+	RefreshedAt  time.Time `json:"refreshed_at"`
+}
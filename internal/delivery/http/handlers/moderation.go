@@ -0,0 +1,237 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+
+	"github.com/go-park-mail-ru/2025_2_Avrora/internal/delivery/http/middleware"
+	"github.com/go-park-mail-ru/2025_2_Avrora/internal/delivery/http/response"
+	"github.com/go-park-mail-ru/2025_2_Avrora/internal/domain"
+	"github.com/go-park-mail-ru/2025_2_Avrora/internal/log"
+	"go.uber.org/zap"
+)
+
+type IModerationUsecase interface {
+	ListDuplicateClusters(ctx context.Context, limit int) ([]domain.DuplicateCluster, error)
+	Queue(ctx context.Context, limit, offset int) ([]domain.ModerationItem, error)
+	Approve(ctx context.Context, moderatorID, itemID string) (*domain.ModerationItem, error)
+	Reject(ctx context.Context, moderatorID, itemID, reason string) (*domain.ModerationItem, error)
+	Audit(ctx context.Context, offerID string) ([]domain.ModerationAuditEntry, error)
+	OfferModeration(ctx context.Context, userID, offerID string) (*domain.ModerationItem, error)
+}
+
+type ModerationHandler struct {
+	moderationUsecase IModerationUsecase
+	logger            *log.Logger
+}
+
+func NewModerationHandler(uc IModerationUsecase, logger *log.Logger) *ModerationHandler {
+	return &ModerationHandler{moderationUsecase: uc, logger: logger}
+}
+
+// ListDuplicateClusters — GET /api/v1/moderation/duplicates?limit=20
+func (h *ModerationHandler) ListDuplicateClusters(w http.ResponseWriter, r *http.Request) {
+	limit, err := parseIntQueryParam(r, "limit", 20)
+	if err != nil {
+		response.HandleError(w, err, http.StatusBadRequest, "некорректный параметр limit")
+		return
+	}
+
+	clusters, err := h.moderationUsecase.ListDuplicateClusters(r.Context(), limit)
+	if err != nil {
+		if errors.Is(err, domain.ErrInvalidInput) {
+			response.HandleError(w, err, http.StatusBadRequest, "limit должен быть от 1 до 100")
+			return
+		}
+		h.logger.Error(r.Context(), "failed to list duplicate clusters", zap.Error(err))
+		response.HandleError(w, nil, http.StatusInternalServerError, "ошибка получения дубликатов")
+		return
+	}
+
+	resp := make([]DuplicateClusterResponse, 0, len(clusters))
+	for _, c := range clusters {
+		cluster := DuplicateClusterResponse{
+			OfferIDs:      c.OfferIDs,
+			UserIDs:       c.UserIDs,
+			MinDistance:   c.MinDistance,
+			LastFlaggedAt: c.LastFlaggedAt,
+			Flags:         make([]DuplicateFlagResponse, 0, len(c.Flags)),
+		}
+		for _, f := range c.Flags {
+			cluster.Flags = append(cluster.Flags, DuplicateFlagResponse{
+				ID:              f.ID,
+				OfferID:         f.OfferID,
+				MatchedOfferID:  f.MatchedOfferID,
+				PhotoURL:        f.PhotoURL,
+				MatchedPhotoURL: f.MatchedPhotoURL,
+				Distance:        f.Distance,
+				CreatedAt:       f.CreatedAt,
+			})
+		}
+		resp = append(resp, cluster)
+	}
+
+	response.WriteJSON(w, http.StatusOK, resp)
+}
+
+func toRuleViolationResponses(violations []domain.RuleViolation) []RuleViolationResponse {
+	resp := make([]RuleViolationResponse, 0, len(violations))
+	for _, v := range violations {
+		resp = append(resp, RuleViolationResponse{Rule: v.Rule, Severity: string(v.Severity), Message: v.Message})
+	}
+	return resp
+}
+
+func toModerationItemResponse(item *domain.ModerationItem) ModerationItemResponse {
+	return ModerationItemResponse{
+		ID:          item.ID,
+		OfferID:     item.OfferID,
+		UserID:      item.OfferUserID,
+		OfferTitle:  item.OfferTitle,
+		Kind:        string(item.Kind),
+		State:       string(item.State),
+		Violations:  toRuleViolationResponses(item.Violations),
+		SubmittedAt: item.SubmittedAt,
+		DecidedBy:   item.DecidedBy,
+		DecidedAt:   item.DecidedAt,
+		Reason:      item.Reason,
+	}
+}
+
+func (h *ModerationHandler) handleError(w http.ResponseWriter, r *http.Request, err error, msg string) {
+	switch {
+	case errors.Is(err, domain.ErrInvalidInput):
+		response.HandleError(w, err, http.StatusBadRequest, "невалидные данные")
+	case errors.Is(err, domain.ErrModerationItemNotFound):
+		response.HandleError(w, err, http.StatusNotFound, "заявка на модерацию не найдена")
+	case errors.Is(err, domain.ErrOfferNotFound):
+		response.HandleError(w, err, http.StatusNotFound, "объявление не найдено")
+	case errors.Is(err, domain.ErrForbidden):
+		response.HandleError(w, err, http.StatusForbidden, "недостаточно прав")
+	case errors.Is(err, domain.ErrModerationDecided):
+		response.HandleError(w, err, http.StatusConflict, "по заявке уже принято решение")
+	case errors.Is(err, domain.ErrStatusConflict), errors.Is(err, domain.ErrInvalidTransition):
+		response.HandleError(w, err, http.StatusConflict, "объявление больше не ожидает модерации")
+	default:
+		h.logger.Error(r.Context(), msg, zap.String("path", r.URL.Path), zap.Error(err))
+		response.HandleError(w, nil, http.StatusInternalServerError, "ошибка модерации")
+	}
+}
+
+// ListQueue — GET /api/v1/moderation/queue?limit=20&offset=0
+func (h *ModerationHandler) ListQueue(w http.ResponseWriter, r *http.Request) {
+	limit, err := parseIntQueryParam(r, "limit", 20)
+	if err != nil {
+		response.HandleError(w, err, http.StatusBadRequest, "некорректный параметр limit")
+		return
+	}
+	offset, err := parseIntQueryParam(r, "offset", 0)
+	if err != nil {
+		response.HandleError(w, err, http.StatusBadRequest, "некорректный параметр offset")
+		return
+	}
+
+	items, err := h.moderationUsecase.Queue(r.Context(), limit, offset)
+	if err != nil {
+		h.handleError(w, r, err, "failed to list moderation queue")
+		return
+	}
+
+	resp := make([]ModerationItemResponse, 0, len(items))
+	for i := range items {
+		resp = append(resp, toModerationItemResponse(&items[i]))
+	}
+	response.WriteJSON(w, http.StatusOK, resp)
+}
+
+// ApproveOffer — POST /api/v1/moderation/approve/{item_id}
+func (h *ModerationHandler) ApproveOffer(w http.ResponseWriter, r *http.Request) {
+	itemID, ok := uuidPathParameter(w, r, "/api/v1/moderation/approve/")
+	if !ok {
+		return
+	}
+
+	moderatorID, _ := middleware.GetUserIDFromContext(r.Context())
+	item, err := h.moderationUsecase.Approve(r.Context(), moderatorID, itemID)
+	if err != nil {
+		h.handleError(w, r, err, "failed to approve offer")
+		return
+	}
+	response.WriteJSON(w, http.StatusOK, toModerationItemResponse(item))
+}
+
+// RejectOffer — POST /api/v1/moderation/reject/{item_id}
+func (h *ModerationHandler) RejectOffer(w http.ResponseWriter, r *http.Request) {
+	itemID, ok := uuidPathParameter(w, r, "/api/v1/moderation/reject/")
+	if !ok {
+		return
+	}
+
+	var req RejectOfferRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		response.HandleError(w, nil, http.StatusBadRequest, "некорректный JSON")
+		return
+	}
+
+	moderatorID, _ := middleware.GetUserIDFromContext(r.Context())
+	item, err := h.moderationUsecase.Reject(r.Context(), moderatorID, itemID, req.Reason)
+	if err != nil {
+		h.handleError(w, r, err, "failed to reject offer")
+		return
+	}
+	response.WriteJSON(w, http.StatusOK, toModerationItemResponse(item))
+}
+
+// GetAudit — GET /api/v1/moderation/audit/{offer_id}
+func (h *ModerationHandler) GetAudit(w http.ResponseWriter, r *http.Request) {
+	offerID, ok := uuidPathParameter(w, r, "/api/v1/moderation/audit/")
+	if !ok {
+		return
+	}
+
+	entries, err := h.moderationUsecase.Audit(r.Context(), offerID)
+	if err != nil {
+		h.handleError(w, r, err, "failed to get moderation audit")
+		return
+	}
+
+	resp := make([]ModerationAuditEntryResponse, 0, len(entries))
+	for _, e := range entries {
+		resp = append(resp, ModerationAuditEntryResponse{
+			ID:         e.ID,
+			ItemID:     e.ItemID,
+			OfferID:    e.OfferID,
+			Action:     string(e.Action),
+			ActorID:    e.ActorID,
+			Reason:     e.Reason,
+			Violations: toRuleViolationResponses(e.Violations),
+			CreatedAt:  e.CreatedAt,
+		})
+	}
+	response.WriteJSON(w, http.StatusOK, resp)
+}
+
+// GetOfferModeration — GET /api/v1/offers/moderation/{id}, for the offer owner
+func (h *ModerationHandler) GetOfferModeration(w http.ResponseWriter, r *http.Request) {
+	offerID, ok := uuidPathParameter(w, r, "/api/v1/offers/moderation/")
+	if !ok {
+		return
+	}
+
+	userID, _ := middleware.GetUserIDFromContext(r.Context())
+	item, err := h.moderationUsecase.OfferModeration(r.Context(), userID, offerID)
+	if err != nil {
+		h.handleError(w, r, err, "failed to get offer moderation")
+		return
+	}
+	response.WriteJSON(w, http.StatusOK, OfferModerationResponse{
+		Kind:        string(item.Kind),
+		State:       string(item.State),
+		Violations:  toRuleViolationResponses(item.Violations),
+		Reason:      item.Reason,
+		SubmittedAt: item.SubmittedAt,
+		DecidedAt:   item.DecidedAt,
+	})
+}
@@ -0,0 +1,66 @@
+package handlers
+
+import "time"
+
+type DuplicateFlagResponse struct {
+	ID              string    `json:"id"`
+	OfferID         string    `json:"offer_id"`
+	MatchedOfferID  string    `json:"matched_offer_id"`
+	PhotoURL        string    `json:"photo_url"`
+	MatchedPhotoURL string    `json:"matched_photo_url"`
+	Distance        int       `json:"distance"` // differing bits of 64, lower is more similar
+	CreatedAt       time.Time `json:"created_at"`
+}
+
+type DuplicateClusterResponse struct {
+	OfferIDs      []string                `json:"offer_ids"`
+	UserIDs       []string                `json:"user_ids"`
+	MinDistance   int                     `json:"min_distance"`
+	LastFlaggedAt time.Time               `json:"last_flagged_at"`
+	Flags         []DuplicateFlagResponse `json:"flags"`
+}
+
+type RuleViolationResponse struct {
+	Rule     string `json:"rule"`
+	Severity string `json:"severity"` // block | review
+	Message  string `json:"message"`
+}
+
+type ModerationItemResponse struct {
+	ID          string                  `json:"id"`
+	OfferID     string                  `json:"offer_id"`
+	UserID      string                  `json:"user_id"`
+	OfferTitle  string                  `json:"offer_title"`
+	Kind        string                  `json:"kind"`  // new | edit
+	State       string                  `json:"state"` // pending | approved | rejected
+	Violations  []RuleViolationResponse `json:"violations"`
+	SubmittedAt time.Time               `json:"submitted_at"`
+	DecidedBy   *string                 `json:"decided_by,omitempty"`
+	DecidedAt   *time.Time              `json:"decided_at,omitempty"`
+	Reason      *string                 `json:"reason,omitempty"`
+}
+
+// OfferModerationResponse is what the owner sees about their submission
+type OfferModerationResponse struct {
+	Kind        string                  `json:"kind"`
+	State       string                  `json:"state"`
+	Violations  []RuleViolationResponse `json:"violations"`
+	Reason      *string                 `json:"reason,omitempty"` // set when rejected
+	SubmittedAt time.Time               `json:"submitted_at"`
+	DecidedAt   *time.Time              `json:"decided_at,omitempty"`
+}
+
+type RejectOfferRequest struct {
+	Reason string `json:"reason"`
+}
+
+type ModerationAuditEntryResponse struct {
+	ID         string                  `json:"id"`
+	ItemID     *string                 `json:"item_id"`
+	OfferID    string                  `json:"offer_id"`
+	Action     string                  `json:"action"`   // submitted | approved | rejected | auto_rejected
+	ActorID    *string                 `json:"actor_id"` // null for the automatic pre-screen
+	Reason     *string                 `json:"reason,omitempty"`
+	Violations []RuleViolationResponse `json:"violations"`
+	CreatedAt  time.Time               `json:"created_at"`
+}
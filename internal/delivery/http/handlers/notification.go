@@ -0,0 +1,107 @@
+package handlers
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"strconv"
+
+	"github.com/go-park-mail-ru/2025_2_Avrora/internal/delivery/http/middleware"
+	"github.com/go-park-mail-ru/2025_2_Avrora/internal/delivery/http/response"
+	"github.com/go-park-mail-ru/2025_2_Avrora/internal/domain"
+	"github.com/go-park-mail-ru/2025_2_Avrora/internal/log"
+	"go.uber.org/zap"
+)
+
+type INotificationUsecase interface {
+	List(ctx context.Context, userID string, unreadOnly bool, limit, offset int) ([]domain.Notification, int, error)
+	MarkRead(ctx context.Context, userID, id string) error
+	MarkAllRead(ctx context.Context, userID string) error
+}
+
+type NotificationHandler struct {
+	notificationUsecase INotificationUsecase
+	logger              *log.Logger
+}
+
+func NewNotificationHandler(uc INotificationUsecase, logger *log.Logger) *NotificationHandler {
+	return &NotificationHandler{notificationUsecase: uc, logger: logger}
+}
+
+func (h *NotificationHandler) writeError(w http.ResponseWriter, r *http.Request, err error, msg string) {
+	switch {
+	case errors.Is(err, domain.ErrInvalidInput):
+		response.HandleError(w, err, http.StatusBadRequest, "невалидные данные")
+	case errors.Is(err, domain.ErrNotificationNotFound):
+		response.HandleError(w, nil, http.StatusNotFound, "уведомление не найдено")
+	default:
+		h.logger.Error(r.Context(), "notification operation failed", zap.String("path", r.URL.Path), zap.Error(err))
+		response.HandleError(w, nil, http.StatusInternalServerError, msg)
+	}
+}
+
+// ListNotifications — GET /api/v1/notifications/list?unread=true&limit=20&offset=0
+func (h *NotificationHandler) ListNotifications(w http.ResponseWriter, r *http.Request) {
+	limit, err := parseIntQueryParam(r, "limit", 20)
+	if err != nil {
+		response.HandleError(w, err, http.StatusBadRequest, "некорректный limit")
+		return
+	}
+	offset, err := parseIntQueryParam(r, "offset", 0)
+	if err != nil {
+		response.HandleError(w, err, http.StatusBadRequest, "некорректный offset")
+		return
+	}
+	unreadOnly := false
+	if v := r.URL.Query().Get("unread"); v != "" {
+		if unreadOnly, err = strconv.ParseBool(v); err != nil {
+			response.HandleError(w, err, http.StatusBadRequest, "некорректный unread")
+			return
+		}
+	}
+
+	userID, _ := middleware.GetUserIDFromContext(r.Context())
+	notifications, unread, err := h.notificationUsecase.List(r.Context(), userID, unreadOnly, limit, offset)
+	if err != nil {
+		h.writeError(w, r, err, "ошибка получения уведомлений")
+		return
+	}
+
+	resp := NotificationsResponse{Items: make([]NotificationResponse, 0, len(notifications)), Unread: unread}
+	for _, n := range notifications {
+		resp.Items = append(resp.Items, NotificationResponse{
+			ID:        n.ID,
+			Kind:      string(n.Kind),
+			ViewingID: n.ViewingID,
+			Message:   n.Message,
+			ReadAt:    n.ReadAt,
+			CreatedAt: n.CreatedAt,
+		})
+	}
+	response.WriteJSON(w, http.StatusOK, resp)
+}
+
+// MarkRead — POST /api/v1/notifications/read/{id}
+func (h *NotificationHandler) MarkRead(w http.ResponseWriter, r *http.Request) {
+	id, ok := uuidPathParameter(w, r, "/api/v1/notifications/read/")
+	if !ok {
+		return
+	}
+
+	userID, _ := middleware.GetUserIDFromContext(r.Context())
+	if err := h.notificationUsecase.MarkRead(r.Context(), userID, id); err != nil {
+		h.writeError(w, r, err, "ошибка отметки уведомления")
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// MarkAllRead — POST /api/v1/notifications/readall
+func (h *NotificationHandler) MarkAllRead(w http.ResponseWriter, r *http.Request) {
+	userID, _ := middleware.GetUserIDFromContext(r.Context())
+	if err := h.notificationUsecase.MarkAllRead(r.Context(), userID); err != nil {
+		h.writeError(w, r, err, "ошибка отметки уведомлений")
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
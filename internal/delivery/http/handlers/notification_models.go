@@ -0,0 +1,17 @@
+package handlers
+
+import "time"
+
+type NotificationResponse struct {
+	ID        string     `json:"id"`
+	Kind      string     `json:"kind"`
+	ViewingID *string    `json:"viewing_id"`
+	Message   string     `json:"message"`
+	ReadAt    *time.Time `json:"read_at"`
+	CreatedAt time.Time  `json:"created_at"`
+}
+
+type NotificationsResponse struct {
+	Items  []NotificationResponse `json:"items"`
+	Unread int                    `json:"unread"`
+}
@@ -5,6 +5,7 @@ import (
 	"errors"
 	"net/http"
 
+	"github.com/go-park-mail-ru/2025_2_Avrora/internal/delivery/http/middleware"
 	"github.com/go-park-mail-ru/2025_2_Avrora/internal/delivery/http/response"
 	"github.com/go-park-mail-ru/2025_2_Avrora/internal/delivery/http/utils"
 	"github.com/go-park-mail-ru/2025_2_Avrora/internal/domain"
@@ -26,8 +27,10 @@ func (o *offerHandler) GetOffers(w http.ResponseWriter, r *http.Request) {
 		"offer_type", "property_type", "status",
 		"price_min", "price_max",
 		"area_min", "area_max",
-		"address",
+		"address", "region", "metro_id", "metro_walk",
+		"short_term", "check_in", "check_out",
 	}
+	filterKeys = append(filterKeys, offerAttributeQueryKeys()...)
 
 	hasFilter := false
 	for _, key := range filterKeys {
@@ -47,8 +50,12 @@ func (o *offerHandler) GetOffers(w http.ResponseWriter, r *http.Request) {
 		response.HandleError(w, err, http.StatusBadRequest, "нет параметра limit")
 		return
 	}
-	result, err := o.offerUsecase.ListOffersInFeed(r.Context(), page, limit)
+	result, err := o.offerUsecase.ListOffersInFeed(r.Context(), page, limit, domain.OfferSort(q.Get("sort")))
 	if err != nil {
+		if errors.Is(err, domain.ErrInvalidInput) {
+			response.HandleError(w, err, http.StatusBadRequest, "некорректные параметры ленты")
+			return
+		}
 		response.HandleError(w, err, http.StatusInternalServerError, "ошибка получения предложений")
 		return
 	}
@@ -84,12 +91,21 @@ func (o *offerHandler) CreateOffer(w http.ResponseWriter, r *http.Request) {
 		Deposit:          &req.Deposit,
 		Commission:       &req.Commission,
 		RentalPeriod:     &req.RentalPeriod,
+		Attributes:       req.Attributes,
 		UserID:           req.UserID,
 	}
+	if userID, ok := middleware.GetUserIDFromContext(r.Context()); ok {
+		offer.UserID = userID
+	}
 
 	if err := o.offerUsecase.Create(r.Context(), offer); err != nil {
-		if errors.Is(err, usecase.ErrInvalidInput) {
+		var verr domain.ValidationErrors
+		if errors.As(err, &verr) {
+			response.HandleValidationError(w, "проверьте заполнение полей", fieldErrorsMap(verr))
+		} else if errors.Is(err, usecase.ErrInvalidInput) || errors.Is(err, domain.ErrInvalidInput) {
 			response.HandleError(w, err, http.StatusBadRequest, "невалидные данные")
+		} else if errors.Is(err, domain.ErrImageNotOwned) {
+			response.HandleError(w, err, http.StatusForbidden, "изображение не найдено среди ваших загрузок")
 		} else {
 			response.HandleError(w, err, http.StatusInternalServerError, "ошибка создания предложения")
 		}
@@ -106,61 +122,57 @@ func (o *offerHandler) DeleteOffer(w http.ResponseWriter, r *http.Request) {
 		response.HandleError(w, nil, http.StatusBadRequest, "нет id")
 		return
 	}
-	if err := o.offerUsecase.Delete(r.Context(), id); err != nil {
-		response.HandleError(w, err, http.StatusInternalServerError, "ошибка удаления предложения")
+	version, ok := ifMatchVersion(w, r)
+	if !ok {
+		return
+	}
+	// Deleting archives the offer unless ?hard=true is passed
+	hard := r.URL.Query().Get("hard") == "true"
+	userID, _ := middleware.GetUserIDFromContext(r.Context())
+	if err := o.offerUsecase.Delete(r.Context(), userID, id, hard, version); err != nil {
+		o.writeLifecycleError(w, r, err, "ошибка удаления предложения")
 		return
 	}
 	response.WriteJSON(w, http.StatusOK, "success")
 }
 
+// UpdateOffer — PATCH /api/v1/offers/update/{id} with a JSON merge patch:
+// omitted fields are kept, null clears an optional one. If-Match must carry
+// the ETag the edit is based on.
 func (o *offerHandler) UpdateOffer(w http.ResponseWriter, r *http.Request) {
-	var req UpdateOfferRequest
-	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
-		o.logger.Error(r.Context(), "invalid JSON", zap.Error(err))
-		response.HandleError(w, err, http.StatusBadRequest, "ошибка обработки входных данных")
+	id, ok := uuidPathParameter(w, r, "/api/v1/offers/update/")
+	if !ok {
 		return
 	}
-
-	id := GetPathParameter(r, "/api/v1/offers/update/")
-	if id == "" {
-		o.logger.Error(r.Context(), "invalid or no id")
-		response.HandleError(w, nil, http.StatusBadRequest, "нет id")
+	version, ok := ifMatchVersion(w, r)
+	if !ok {
+		return
+	}
+	patch, ok := readMergePatch(w, r, offerPatchFields)
+	if !ok {
 		return
 	}
 
 	// Надо достать location_id как то через адрес
-	// YandexMapsURL
-	// Широта долгота
-	// Нормализованный адрес
-
-	location := utils.AddressToLocation(req.Address) // Пока так
-
-	offer := domain.Offer{
-		ID:           id,
-		UserID:       req.UserID,
-		LocationID:   location.ID,
-		Title:        req.Title,
-		Description:  req.Description,
-		ImageURLs:    req.ImageURLs,
-		Price:        int64(req.Price),
-		Area:         req.Area,
-		Rooms:        req.Rooms,
-		Address:      req.Address,
-		OfferType:    domain.OfferType(req.OfferType),
-		PropertyType: domain.PropertyType(req.PropertyType),
-		Floor:        &req.Floor,
-		TotalFloors:  &req.TotalFloors,
-		Deposit:      &req.Deposit,
-		Commission:   &req.Commission,
-		RentalPeriod: &req.RentalPeriod,
-		Status:       domain.OfferStatus(req.Status),
-		LivingArea:   &req.LivingArea,
-		KitchenArea:  &req.KitchenArea,
-	}
-	if err := o.offerUsecase.Update(r.Context(), &offer); err != nil {
-		response.HandleError(w, err, http.StatusInternalServerError, "ошибка обновления предложения")
+	if address, ok := patch["address"].(string); ok && address != "" {
+		patch["location_id"] = utils.AddressToLocation(address).ID // Пока так
+	}
+
+	userID, _ := middleware.GetUserIDFromContext(r.Context())
+	offer, err := o.offerUsecase.Update(r.Context(), userID, id, patch, version)
+	if err != nil {
+		var verr domain.ValidationErrors
+		switch {
+		case errors.As(err, &verr):
+			response.HandleValidationError(w, "проверьте заполнение полей", fieldErrorsMap(verr))
+		case errors.Is(err, domain.ErrImageNotOwned):
+			response.HandleError(w, err, http.StatusForbidden, "изображение не найдено среди ваших загрузок")
+		default:
+			o.writeLifecycleError(w, r, err, "ошибка обновления предложения")
+		}
 		return
 	}
+	setETag(w, offer.Version)
 	response.WriteJSON(w, http.StatusOK, offer)
 }
 
@@ -172,16 +184,46 @@ func (o *offerHandler) GetOffer(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	println(id)
+	viewer := domain.Viewer{IP: middleware.ClientIP(r), UserAgent: r.UserAgent()}
+	if userID, ok := middleware.GetUserIDFromContext(r.Context()); ok {
+		viewer.UserID = &userID
+	}
 
-	offer, err := o.offerUsecase.Get(r.Context(), id)
+	offer, err := o.offerUsecase.View(r.Context(), id, viewer)
 	if err != nil {
-		response.HandleError(w, err, http.StatusInternalServerError, "ошибка получения предложений")
+		o.writeLifecycleError(w, r, err, "ошибка получения предложений")
 		return
 	}
+	setETag(w, offer.Version)
 	response.WriteJSON(w, http.StatusOK, offer)
 }
 
+// GetSimilarOffers — GET /api/v1/offers/similar/{id}?limit=10&offset=0
+func (o *offerHandler) GetSimilarOffers(w http.ResponseWriter, r *http.Request) {
+	id := GetPathParameter(r, "/api/v1/offers/similar/")
+	if id == "" {
+		response.HandleError(w, nil, http.StatusBadRequest, "нет id")
+		return
+	}
+	limit, err := parseIntQueryParam(r, "limit", 10)
+	if err != nil {
+		response.HandleError(w, err, http.StatusBadRequest, "некорректный параметр limit")
+		return
+	}
+	offset, err := parseIntQueryParam(r, "offset", 0)
+	if err != nil {
+		response.HandleError(w, err, http.StatusBadRequest, "некорректный параметр offset")
+		return
+	}
+
+	offers, err := o.offerUsecase.SimilarOffers(r.Context(), id, limit, offset)
+	if err != nil {
+		o.writeLifecycleError(w, r, err, "ошибка получения похожих объявлений")
+		return
+	}
+	response.WriteJSON(w, http.StatusOK, offers)
+}
+
 func (o *offerHandler) GetMyOffers(w http.ResponseWriter, r *http.Request) {
 	userID := GetPathParameter(r, "/api/v1/profile/myoffers/")
 	if userID == "" {
@@ -189,7 +231,12 @@ func (o *offerHandler) GetMyOffers(w http.ResponseWriter, r *http.Request) {
 		response.HandleError(w, nil, http.StatusBadRequest, "нет userID")
 		return
 	}
-	offers, err := o.offerUsecase.ListOffersInFeedByUserID(r.Context(), userID, 1, 10)
+	// Owners also get the statistics of their offers
+	list := o.offerUsecase.ListOffersInFeedByUserID
+	if authID, _ := middleware.GetUserIDFromContext(r.Context()); authID == userID {
+		list = o.offerUsecase.ListMyOffers
+	}
+	offers, err := list(r.Context(), userID, 1, 10)
 	if err != nil {
 		response.HandleError(w, err, http.StatusInternalServerError, "ошибка получения предложений")
 		return
@@ -197,17 +244,34 @@ func (o *offerHandler) GetMyOffers(w http.ResponseWriter, r *http.Request) {
 	response.WriteJSON(w, http.StatusOK, offers)
 }
 
-func (o *offerHandler) GetOfferPriceHistory(w http.ResponseWriter, r *http.Request) {
-	id := GetPathParameter(r, "/api/v1/offers/pricehistory/")
-	if id == "" {
-		o.logger.Error(r.Context(), "invalid or no id")
-		response.HandleError(w, nil, http.StatusBadRequest, "нет id")
-		return
-	}
-	points, err := o.offerUsecase.GetOfferPriceHistory(r.Context(), id)
-	if err != nil {
-		response.HandleError(w, err, http.StatusInternalServerError, "ошибка получения предложений")
-		return
+// ListOfferAttributes — GET /api/v1/offers/attributes, the attributes an
+// offer may carry with their values and the types they apply to
+func (o *offerHandler) ListOfferAttributes(w http.ResponseWriter, r *http.Request) {
+	catalogue := domain.OfferAttributeCatalogue()
+	resp := make([]OfferAttributeResponse, 0, len(catalogue))
+	for _, attr := range catalogue {
+		item := OfferAttributeResponse{
+			Key:           attr.Key,
+			Title:         attr.Title,
+			Kind:          string(attr.Kind),
+			Values:        attr.Values,
+			PropertyTypes: make([]string, 0, len(attr.PropertyTypes)),
+			OfferTypes:    make([]string, 0, len(attr.OfferTypes)),
+			RequiredFor:   make([]string, 0, len(attr.RequiredFor)),
+		}
+		if attr.Numeric() {
+			item.Min, item.Max = &attr.Min, &attr.Max
+		}
+		for _, t := range attr.PropertyTypes {
+			item.PropertyTypes = append(item.PropertyTypes, string(t))
+		}
+		for _, t := range attr.OfferTypes {
+			item.OfferTypes = append(item.OfferTypes, string(t))
+		}
+		for _, t := range attr.RequiredFor {
+			item.RequiredFor = append(item.RequiredFor, string(t))
+		}
+		resp = append(resp, item)
 	}
-	response.WriteJSON(w, http.StatusOK, points)
+	response.WriteJSON(w, http.StatusOK, resp)
 }
\ No newline at end of file
@@ -0,0 +1,250 @@
+package handlers
+
+import (
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+
+	"github.com/go-park-mail-ru/2025_2_Avrora/internal/delivery/http/middleware"
+	"github.com/go-park-mail-ru/2025_2_Avrora/internal/delivery/http/response"
+	"github.com/go-park-mail-ru/2025_2_Avrora/internal/delivery/http/utils"
+	"github.com/go-park-mail-ru/2025_2_Avrora/internal/domain"
+	"go.uber.org/zap"
+)
+
+func (o *offerHandler) writeDraftError(w http.ResponseWriter, r *http.Request, err error, msg string) {
+	var verr domain.ValidationErrors
+	switch {
+	case errors.As(err, &verr):
+		response.HandleValidationError(w, "проверьте заполнение полей", fieldErrorsMap(verr))
+	case errors.Is(err, domain.ErrInvalidInput):
+		response.HandleError(w, err, http.StatusBadRequest, "невалидные данные")
+	case errors.Is(err, domain.ErrDraftNotFound):
+		response.HandleError(w, err, http.StatusNotFound, "черновик не найден")
+	case errors.Is(err, domain.ErrForbidden):
+		response.HandleError(w, err, http.StatusForbidden, "можно изменять только свои черновики")
+	case errors.Is(err, domain.ErrImageNotOwned):
+		response.HandleError(w, err, http.StatusForbidden, "изображение не найдено среди ваших загрузок")
+	default:
+		o.logger.Error(r.Context(), "offer draft operation failed", zap.String("path", r.URL.Path), zap.Error(err))
+		response.HandleError(w, err, http.StatusInternalServerError, msg)
+	}
+}
+
+func fieldErrorsMap(errs domain.ValidationErrors) map[string]string {
+	fields := make(map[string]string, len(errs))
+	for _, fe := range errs {
+		if _, ok := fields[fe.Field]; !ok {
+			fields[fe.Field] = fe.Message
+		}
+	}
+	return fields
+}
+
+// decodeDraftRequest reads a partial offer and the fields explicitly set to null
+func decodeDraftRequest(r *http.Request) (*domain.OfferDraftFields, []string, error) {
+	body, err := io.ReadAll(r.Body)
+	if err != nil {
+		return nil, nil, err
+	}
+	var req OfferDraftRequest
+	if err := json.Unmarshal(body, &req); err != nil {
+		return nil, nil, err
+	}
+	cleared, err := nullFields(body)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	f := &domain.OfferDraftFields{
+		Title:        req.Title,
+		Description:  req.Description,
+		Address:      req.Address,
+		Rooms:        req.Rooms,
+		Floor:        req.Floor,
+		TotalFloors:  req.TotalFloors,
+		Area:         req.Area,
+		LivingArea:   req.LivingArea,
+		KitchenArea:  req.KitchenArea,
+		Deposit:      req.Deposit,
+		Commission:   req.Commission,
+		RentalPeriod: req.RentalPeriod,
+		Attributes:   req.Attributes,
+		ImageURLs:    req.ImageURLs,
+	}
+	if req.OfferType != nil {
+		t := domain.OfferType(*req.OfferType)
+		f.OfferType = &t
+	}
+	if req.PropertyType != nil {
+		t := domain.PropertyType(*req.PropertyType)
+		f.PropertyType = &t
+	}
+	if req.Price != nil {
+		p := int64(*req.Price)
+		f.Price = &p
+	}
+	// Location and complex follow the address
+	if req.Address != nil && *req.Address != "" {
+		locationID := utils.AddressToLocation(*req.Address).ID
+		complexID := utils.AddressToComplex(*req.Address).ID
+		f.LocationID = &locationID
+		f.HousingComplexID = &complexID
+	}
+	for _, name := range cleared {
+		if name == "address" {
+			cleared = append(cleared, "location_id", "housing_complex_id")
+			break
+		}
+	}
+	return f, cleared, nil
+}
+
+func toOfferDraftResponse(d *domain.OfferDraft) OfferDraftResponse {
+	resp := OfferDraftResponse{
+		ID:            d.ID,
+		Title:         d.Fields.Title,
+		Address:       d.Fields.Address,
+		Floor:         d.Fields.Floor,
+		TotalFloors:   d.Fields.TotalFloors,
+		Rooms:         d.Fields.Rooms,
+		Area:          d.Fields.Area,
+		LivingArea:    d.Fields.LivingArea,
+		KitchenArea:   d.Fields.KitchenArea,
+		Price:         d.Fields.Price,
+		Description:   d.Fields.Description,
+		Deposit:       d.Fields.Deposit,
+		Commission:    d.Fields.Commission,
+		RentalPeriod:  d.Fields.RentalPeriod,
+		Attributes:    d.Fields.Attributes,
+		ImageURLs:     d.Fields.ImageURLs,
+		PublishErrors: fieldErrorsMap(d.Fields.Validate(true)),
+		CreatedAt:     d.CreatedAt,
+		UpdatedAt:     d.UpdatedAt,
+	}
+	if d.Fields.OfferType != nil {
+		t := string(*d.Fields.OfferType)
+		resp.OfferType = &t
+	}
+	if d.Fields.PropertyType != nil {
+		t := string(*d.Fields.PropertyType)
+		resp.PropertyType = &t
+	}
+	if resp.ImageURLs == nil {
+		resp.ImageURLs = []string{}
+	}
+	return resp
+}
+
+// CreateDraft — POST /api/v1/offers/drafts/create
+func (o *offerHandler) CreateDraft(w http.ResponseWriter, r *http.Request) {
+	fields, _, err := decodeDraftRequest(r)
+	if err != nil {
+		response.HandleError(w, err, http.StatusBadRequest, "некорректный JSON")
+		return
+	}
+
+	userID, _ := middleware.GetUserIDFromContext(r.Context())
+	draft, err := o.offerUsecase.CreateDraft(r.Context(), userID, fields)
+	if err != nil {
+		o.writeDraftError(w, r, err, "ошибка создания черновика")
+		return
+	}
+	response.WriteJSON(w, http.StatusCreated, toOfferDraftResponse(draft))
+}
+
+// ListDrafts — GET /api/v1/offers/drafts?page=1&limit=20
+func (o *offerHandler) ListDrafts(w http.ResponseWriter, r *http.Request) {
+	page, err := parseIntQueryParam(r, "page", 1)
+	if err != nil {
+		response.HandleError(w, err, http.StatusBadRequest, "некорректный параметр page")
+		return
+	}
+	limit, err := parseIntQueryParam(r, "limit", 20)
+	if err != nil {
+		response.HandleError(w, err, http.StatusBadRequest, "некорректный параметр limit")
+		return
+	}
+
+	userID, _ := middleware.GetUserIDFromContext(r.Context())
+	drafts, err := o.offerUsecase.ListDrafts(r.Context(), userID, page, limit)
+	if err != nil {
+		o.writeDraftError(w, r, err, "ошибка получения черновиков")
+		return
+	}
+
+	resp := make([]OfferDraftResponse, 0, len(drafts))
+	for i := range drafts {
+		resp = append(resp, toOfferDraftResponse(&drafts[i]))
+	}
+	response.WriteJSON(w, http.StatusOK, resp)
+}
+
+// GetDraft — GET /api/v1/offers/drafts/{id}
+func (o *offerHandler) GetDraft(w http.ResponseWriter, r *http.Request) {
+	id, ok := uuidPathParameter(w, r, "/api/v1/offers/drafts/")
+	if !ok {
+		return
+	}
+
+	userID, _ := middleware.GetUserIDFromContext(r.Context())
+	draft, err := o.offerUsecase.GetDraft(r.Context(), userID, id)
+	if err != nil {
+		o.writeDraftError(w, r, err, "ошибка получения черновика")
+		return
+	}
+	response.WriteJSON(w, http.StatusOK, toOfferDraftResponse(draft))
+}
+
+// SaveDraft — PATCH /api/v1/offers/drafts/update/{id}, used for autosave
+func (o *offerHandler) SaveDraft(w http.ResponseWriter, r *http.Request) {
+	id, ok := uuidPathParameter(w, r, "/api/v1/offers/drafts/update/")
+	if !ok {
+		return
+	}
+	patch, cleared, err := decodeDraftRequest(r)
+	if err != nil {
+		response.HandleError(w, err, http.StatusBadRequest, "некорректный JSON")
+		return
+	}
+
+	userID, _ := middleware.GetUserIDFromContext(r.Context())
+	draft, err := o.offerUsecase.SaveDraft(r.Context(), userID, id, patch, cleared)
+	if err != nil {
+		o.writeDraftError(w, r, err, "ошибка сохранения черновика")
+		return
+	}
+	response.WriteJSON(w, http.StatusOK, toOfferDraftResponse(draft))
+}
+
+// DeleteDraft — DELETE /api/v1/offers/drafts/delete/{id}
+func (o *offerHandler) DeleteDraft(w http.ResponseWriter, r *http.Request) {
+	id, ok := uuidPathParameter(w, r, "/api/v1/offers/drafts/delete/")
+	if !ok {
+		return
+	}
+
+	userID, _ := middleware.GetUserIDFromContext(r.Context())
+	if err := o.offerUsecase.DeleteDraft(r.Context(), userID, id); err != nil {
+		o.writeDraftError(w, r, err, "ошибка удаления черновика")
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// PublishDraft — POST /api/v1/offers/drafts/publish/{id}
+func (o *offerHandler) PublishDraft(w http.ResponseWriter, r *http.Request) {
+	id, ok := uuidPathParameter(w, r, "/api/v1/offers/drafts/publish/")
+	if !ok {
+		return
+	}
+
+	userID, _ := middleware.GetUserIDFromContext(r.Context())
+	offer, err := o.offerUsecase.PublishDraft(r.Context(), userID, id)
+	if err != nil {
+		o.writeDraftError(w, r, err, "ошибка публикации черновика")
+		return
+	}
+	response.WriteJSON(w, http.StatusCreated, offer)
+}
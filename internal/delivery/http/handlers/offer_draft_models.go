@@ -0,0 +1,51 @@
+package handlers
+
+import "time"
+
+// OfferDraftRequest is a partial CreateOfferRequest: omitted fields are left
+// as they are, null clears a field
+type OfferDraftRequest struct {
+	OfferType    *string  `json:"offer_type"`    // sale | rent
+	PropertyType *string  `json:"property_type"` // apartment | room | house | land | commercial | garage
+	Title        *string  `json:"title"`
+	Address      *string  `json:"address"`
+	Floor        *int     `json:"floor"`
+	TotalFloors  *int     `json:"total_floors"`
+	Rooms        *int     `json:"rooms"`
+	Area         *float64 `json:"area"`
+	LivingArea   *float64 `json:"living_area"`
+	KitchenArea  *float64 `json:"kitchen_area"`
+	Price        *float64 `json:"price"`
+	Description  *string  `json:"description"`
+	Deposit      *int64   `json:"deposit"`
+	Commission   *int64   `json:"commission"`
+	RentalPeriod *string  `json:"rental_period"`
+	// Merged key by key into the saved ones; null removes a key
+	Attributes map[string]any `json:"attributes"`
+	ImageURLs  []string       `json:"image_urls"`
+}
+
+type OfferDraftResponse struct {
+	ID           string         `json:"id"`
+	OfferType    *string        `json:"offer_type"`
+	PropertyType *string        `json:"property_type"`
+	Title        *string        `json:"title"`
+	Address      *string        `json:"address"`
+	Floor        *int           `json:"floor"`
+	TotalFloors  *int           `json:"total_floors"`
+	Rooms        *int           `json:"rooms"`
+	Area         *float64       `json:"area"`
+	LivingArea   *float64       `json:"living_area"`
+	KitchenArea  *float64       `json:"kitchen_area"`
+	Price        *int64         `json:"price"`
+	Description  *string        `json:"description"`
+	Deposit      *int64         `json:"deposit"`
+	Commission   *int64         `json:"commission"`
+	RentalPeriod *string        `json:"rental_period"`
+	Attributes   map[string]any `json:"attributes"`
+	ImageURLs    []string       `json:"image_urls"`
+	// What still keeps the draft from being published, by field
+	PublishErrors map[string]string `json:"publish_errors"`
+	CreatedAt     time.Time         `json:"created_at"`
+	UpdatedAt     time.Time         `json:"updated_at"`
+}
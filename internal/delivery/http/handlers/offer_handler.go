@@ -3,23 +3,40 @@ package handlers
 import (
 	"context"
 	"encoding/json"
+	"errors"
 	"go.uber.org/zap"
 	"net/http"
+	"net/url"
 	"strconv"
+	"strings"
+	"time"
 
 	"github.com/go-park-mail-ru/2025_2_Avrora/internal/domain"
 	"github.com/go-park-mail-ru/2025_2_Avrora/internal/log"
 )
 
 type IOfferUsecase interface {
-	ListOffersInFeed(ctx context.Context, page, limit int) (*domain.OffersInFeed, error)
+	ListOffersInFeed(ctx context.Context, page, limit int, sort domain.OfferSort) (*domain.OffersInFeed, error)
 	Get(ctx context.Context, id string) (*domain.Offer, error)
-	Update(ctx context.Context, offer *domain.Offer) error
+	View(ctx context.Context, id string, viewer domain.Viewer) (*domain.Offer, error)
+	Update(ctx context.Context, userID, id string, patch domain.Patch, version *int) (*domain.Offer, error)
 	Create(ctx context.Context, offer *domain.Offer) error
-	Delete(ctx context.Context, id string) error
+	Delete(ctx context.Context, userID, id string, hard bool, version *int) error
 	ListOffersInFeedByUserID(ctx context.Context, userID string, page, limit int) (*domain.OffersInFeed, error)
+	ListMyOffers(ctx context.Context, userID string, page, limit int) (*domain.OffersInFeed, error)
 	FilterOffers(ctx context.Context, f *domain.OfferFilter, limit, offset int) ([]domain.OfferInFeed, error)
-	GetOfferPriceHistory(ctx context.Context, id string) ([]domain.PricePoint, error)
+	SimilarOffers(ctx context.Context, id string, limit, offset int) ([]domain.OfferInFeed, error)
+	GetOfferPriceHistory(ctx context.Context, id string) (*domain.PriceHistory, error)
+	PriceTrend(ctx context.Context, q *domain.PriceTrendQuery) ([]domain.PriceTrendPoint, error)
+	ChangeStatus(ctx context.Context, userID, offerID string, to domain.OfferStatus, reason *string) (*domain.Offer, error)
+	Renew(ctx context.Context, userID, offerID string) (*domain.Offer, error)
+	StatusHistory(ctx context.Context, userID, offerID string) ([]domain.OfferStatusChange, error)
+	CreateDraft(ctx context.Context, userID string, fields *domain.OfferDraftFields) (*domain.OfferDraft, error)
+	SaveDraft(ctx context.Context, userID, draftID string, patch *domain.OfferDraftFields, cleared []string) (*domain.OfferDraft, error)
+	GetDraft(ctx context.Context, userID, draftID string) (*domain.OfferDraft, error)
+	ListDrafts(ctx context.Context, userID string, page, limit int) ([]domain.OfferDraft, error)
+	DeleteDraft(ctx context.Context, userID, draftID string) error
+	PublishDraft(ctx context.Context, userID, draftID string) (*domain.Offer, error)
 }
 
 type offerHandler struct {
@@ -34,6 +51,40 @@ func (h *offerHandler) FilterOffers(w http.ResponseWriter, r *http.Request) {
 	ctx := r.Context()
 	q := r.URL.Query()
 
+	f := offerFilterFromQuery(q)
+
+	limit := 20
+	offset := 0
+	if v := q.Get("limit"); v != "" {
+		if i, err := strconv.Atoi(v); err == nil {
+			limit = i
+		}
+	}
+	if v := q.Get("offset"); v != "" {
+		if i, err := strconv.Atoi(v); err == nil {
+			offset = i
+		}
+	}
+
+	offers, err := h.offerUsecase.FilterOffers(ctx, f, limit, offset)
+	if errors.Is(err, domain.ErrInvalidInput) {
+		http.Error(w, "invalid filter", http.StatusBadRequest)
+		return
+	}
+	if err != nil {
+		h.logger.Error(ctx, "failed to filter offers", zap.Error(err))
+		http.Error(w, "internal server error", http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	if err := json.NewEncoder(w).Encode(offers); err != nil {
+		http.Error(w, "failed to encode response", http.StatusInternalServerError)
+	}
+}
+
+// offerFilterFromQuery reads the feed filters; malformed numbers are ignored
+func offerFilterFromQuery(q url.Values) *domain.OfferFilter {
 	f := &domain.OfferFilter{}
 
 	if v := q.Get("offer_type"); v != "" {
@@ -73,29 +124,91 @@ func (h *offerHandler) FilterOffers(w http.ResponseWriter, r *http.Request) {
 	if v := q.Get("address"); v != "" {
 		f.Address = &v
 	}
-
-	limit := 20
-	offset := 0
-	if v := q.Get("limit"); v != "" {
-		if i, err := strconv.Atoi(v); err == nil {
-			limit = i
+	if v := q.Get("region"); v != "" {
+		f.RegionSlug = &v
+	}
+	// metro_id may repeat or hold a comma separated list
+	for _, v := range q["metro_id"] {
+		for _, id := range strings.Split(v, ",") {
+			if id = strings.TrimSpace(id); id != "" {
+				f.MetroIDs = append(f.MetroIDs, id)
+			}
 		}
 	}
-	if v := q.Get("offset"); v != "" {
+	if v := q.Get("metro_walk"); v != "" {
 		if i, err := strconv.Atoi(v); err == nil {
-			offset = i
+			f.MetroWalk = &i
+		}
+	}
+	f.Attributes = offerAttributeFiltersFromQuery(q)
+	if v := q.Get("short_term"); v != "" {
+		if b, err := strconv.ParseBool(v); err == nil {
+			f.ShortTerm = &b
+		}
+	}
+	// check_in and check_out go together: offers free for the whole stay
+	if v := q.Get("check_in"); v != "" {
+		if t, err := time.Parse(time.DateOnly, v); err == nil {
+			f.CheckIn = &t
 		}
 	}
+	if v := q.Get("check_out"); v != "" {
+		if t, err := time.Parse(time.DateOnly, v); err == nil {
+			f.CheckOut = &t
+		}
+	}
+	f.Sort = domain.OfferSort(q.Get("sort"))
+	return f
+}
 
-	offers, err := h.offerUsecase.FilterOffers(ctx, f, limit, offset)
-	if err != nil {
-		h.logger.Error(ctx, "failed to filter offers", zap.Error(err))
-		http.Error(w, "internal server error", http.StatusInternalServerError)
-		return
+// offerAttributeFiltersFromQuery reads the attribute filters named after the
+// catalogue keys: renovation=euro,designer, furniture=true,
+// ceiling_height_min=2.7, build_year_max=2000
+func offerAttributeFiltersFromQuery(q url.Values) []domain.OfferAttributeFilter {
+	var filters []domain.OfferAttributeFilter
+	for _, attr := range domain.OfferAttributeCatalogue() {
+		af := domain.OfferAttributeFilter{Key: attr.Key}
+		switch attr.Kind {
+		case domain.AttributeKindEnum:
+			for _, v := range strings.Split(q.Get(attr.Key), ",") {
+				if v = strings.TrimSpace(v); v != "" {
+					af.Values = append(af.Values, v)
+				}
+			}
+			if len(af.Values) == 0 {
+				continue
+			}
+		case domain.AttributeKindBool:
+			b, err := strconv.ParseBool(q.Get(attr.Key))
+			if err != nil {
+				continue
+			}
+			af.Bool = &b
+		default:
+			if v, err := strconv.ParseFloat(q.Get(attr.Key+"_min"), 64); err == nil {
+				af.Min = &v
+			}
+			if v, err := strconv.ParseFloat(q.Get(attr.Key+"_max"), 64); err == nil {
+				af.Max = &v
+			}
+			if af.Min == nil && af.Max == nil {
+				continue
+			}
+		}
+		filters = append(filters, af)
 	}
+	return filters
+}
 
-	w.Header().Set("Content-Type", "application/json")
-	if err := json.NewEncoder(w).Encode(offers); err != nil {
-		http.Error(w, "failed to encode response", http.StatusInternalServerError)
+// offerAttributeQueryKeys are the query parameters offerAttributeFiltersFromQuery reads
+func offerAttributeQueryKeys() []string {
+	var keys []string
+	for _, attr := range domain.OfferAttributeCatalogue() {
+		if attr.Numeric() {
+			keys = append(keys, attr.Key+"_min", attr.Key+"_max")
+		} else {
+			keys = append(keys, attr.Key)
+		}
 	}
+	return keys
 }
@@ -0,0 +1,104 @@
+package handlers
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+
+	"github.com/go-park-mail-ru/2025_2_Avrora/internal/delivery/http/middleware"
+	"github.com/go-park-mail-ru/2025_2_Avrora/internal/delivery/http/response"
+	"github.com/go-park-mail-ru/2025_2_Avrora/internal/domain"
+	"go.uber.org/zap"
+)
+
+func (o *offerHandler) writeLifecycleError(w http.ResponseWriter, r *http.Request, err error, msg string) {
+	switch {
+	case errors.Is(err, domain.ErrInvalidInput):
+		response.HandleError(w, err, http.StatusBadRequest, "невалидные данные")
+	case errors.Is(err, domain.ErrOfferNotFound):
+		response.HandleError(w, err, http.StatusNotFound, "объявление не найдено")
+	case errors.Is(err, domain.ErrForbidden):
+		response.HandleError(w, err, http.StatusForbidden, "можно изменять только свои объявления")
+	case errors.Is(err, domain.ErrInvalidTransition):
+		response.HandleError(w, err, http.StatusConflict, "переход в этот статус недоступен")
+	case errors.Is(err, domain.ErrStatusConflict):
+		response.HandleError(w, err, http.StatusConflict, "статус объявления изменился, обновите страницу")
+	case errors.Is(err, domain.ErrVersionMismatch):
+		response.HandleError(w, err, http.StatusPreconditionFailed, "объявление изменилось, обновите страницу")
+	default:
+		o.logger.Error(r.Context(), "offer lifecycle operation failed", zap.String("path", r.URL.Path), zap.Error(err))
+		response.HandleError(w, err, http.StatusInternalServerError, msg)
+	}
+}
+
+// ChangeOfferStatus — POST /api/v1/offers/status/{id}
+func (o *offerHandler) ChangeOfferStatus(w http.ResponseWriter, r *http.Request) {
+	id := GetPathParameter(r, "/api/v1/offers/status/")
+	if id == "" {
+		response.HandleError(w, nil, http.StatusBadRequest, "нет id")
+		return
+	}
+
+	var req ChangeOfferStatusRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		response.HandleError(w, err, http.StatusBadRequest, "ошибка обработки входных данных")
+		return
+	}
+
+	userID, _ := middleware.GetUserIDFromContext(r.Context())
+	offer, err := o.offerUsecase.ChangeStatus(r.Context(), userID, id, domain.OfferStatus(req.Status), req.Reason)
+	if err != nil {
+		o.writeLifecycleError(w, r, err, "ошибка смены статуса")
+		return
+	}
+	response.WriteJSON(w, http.StatusOK, offer)
+}
+
+// RenewOffer — POST /api/v1/offers/renew/{id}
+func (o *offerHandler) RenewOffer(w http.ResponseWriter, r *http.Request) {
+	id := GetPathParameter(r, "/api/v1/offers/renew/")
+	if id == "" {
+		response.HandleError(w, nil, http.StatusBadRequest, "нет id")
+		return
+	}
+
+	userID, _ := middleware.GetUserIDFromContext(r.Context())
+	offer, err := o.offerUsecase.Renew(r.Context(), userID, id)
+	if err != nil {
+		o.writeLifecycleError(w, r, err, "ошибка продления объявления")
+		return
+	}
+	response.WriteJSON(w, http.StatusOK, offer)
+}
+
+// GetOfferStatusHistory — GET /api/v1/offers/statushistory/{id}
+func (o *offerHandler) GetOfferStatusHistory(w http.ResponseWriter, r *http.Request) {
+	id := GetPathParameter(r, "/api/v1/offers/statushistory/")
+	if id == "" {
+		response.HandleError(w, nil, http.StatusBadRequest, "нет id")
+		return
+	}
+
+	userID, _ := middleware.GetUserIDFromContext(r.Context())
+	history, err := o.offerUsecase.StatusHistory(r.Context(), userID, id)
+	if err != nil {
+		o.writeLifecycleError(w, r, err, "ошибка получения истории статусов")
+		return
+	}
+
+	resp := make([]OfferStatusChangeResponse, 0, len(history))
+	for _, c := range history {
+		item := OfferStatusChangeResponse{
+			ToStatus:  string(c.ToStatus),
+			ChangedBy: c.ChangedBy,
+			Reason:    c.Reason,
+			ChangedAt: c.ChangedAt,
+		}
+		if c.FromStatus != nil {
+			from := string(*c.FromStatus)
+			item.FromStatus = &from
+		}
+		resp = append(resp, item)
+	}
+	response.WriteJSON(w, http.StatusOK, resp)
+}
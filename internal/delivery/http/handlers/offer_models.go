@@ -1,33 +1,40 @@
 package handlers
 
+import (
+	"time"
+
+	"github.com/go-park-mail-ru/2025_2_Avrora/internal/delivery/http/utils"
+)
+
 type CreateOfferRequest struct {
-	InHousingComplex bool     `json:"in_housing_complex"`
-	HousingComplex   string   `json:"housing_complex"`
-	OfferType        string   `json:"offer_type"`    // sale | rent
-	PropertyType     string   `json:"property_type"` // house | apartment
-	Title            string   `json:"title"`
-	UserID           string   `json:"user_id"`
-	Category         string   `json:"category"`
-	Address          string   `json:"address"`
-	Floor            int      `json:"floor"`
-	TotalFloors      int      `json:"total_floors"`
-	Rooms            int      `json:"rooms"`
-	Area             float64  `json:"area"`
-	LivingArea       float64  `json:"living_area"`
-	KitchenArea      float64  `json:"kitchen_area"`
-	Price            float64  `json:"price"`
-	Description      string   `json:"description"`
-	Deposit          int64    `json:"deposit"`
-	Commission       int64    `json:"commission"`
-	RentalPeriod     string   `json:"rental_period"`
-	ImageURLs        []string `json:"image_urls"`
+	InHousingComplex bool           `json:"in_housing_complex"`
+	HousingComplex   string         `json:"housing_complex"`
+	OfferType        string         `json:"offer_type"`    // sale | rent
+	PropertyType     string         `json:"property_type"` // apartment | room | house | land | commercial | garage
+	Title            string         `json:"title"`
+	UserID           string         `json:"user_id"`
+	Category         string         `json:"category"`
+	Address          string         `json:"address"`
+	Floor            int            `json:"floor"`
+	TotalFloors      int            `json:"total_floors"`
+	Rooms            int            `json:"rooms"`
+	Area             float64        `json:"area"`
+	LivingArea       float64        `json:"living_area"`
+	KitchenArea      float64        `json:"kitchen_area"`
+	Price            float64        `json:"price"`
+	Description      string         `json:"description"`
+	Deposit          int64          `json:"deposit"`
+	Commission       int64          `json:"commission"`
+	RentalPeriod     string         `json:"rental_period"`
+	Attributes       map[string]any `json:"attributes"` // keys from GET /api/v1/offers/attributes
+	ImageURLs        []string       `json:"image_urls"`
 }
 
 type FullOfferResponse struct {
 	InHousingComplex bool     `json:"in_housing_complex"`
 	HousingComplex   string   `json:"housing_complex"`
 	OfferType        string   `json:"offer_type"`    // sale | rent
-	PropertyType     string   `json:"property_type"` // house | apartment
+	PropertyType     string   `json:"property_type"` // apartment | room | house | land | commercial | garage
 	Title            string   `json:"title"`
 	Category         string   `json:"category"`
 	Address          string   `json:"address"`
@@ -51,7 +58,7 @@ type OfferInFeedResponse struct {
 	UserID       int     `json:"user_id"`
 	OfferURL     string  `json:"offer_url"`
 	OfferType    string  `json:"offer_type"`    // sale | rent
-	PropertyType string  `json:"property_type"` // house | apartment
+	PropertyType string  `json:"property_type"` // apartment | room | house | land | commercial | garage
 	Price        float64 `json:"price"`
 	Area         float64 `json:"area"`
 	Rooms        int     `json:"rooms"`
@@ -62,27 +69,78 @@ type OfferInFeedResponse struct {
 	ImageURL     string  `json:"image_url"`
 }
 
-type UpdateOfferRequest struct {
-	ID               int      `json:"id"`
-	InHousingComplex bool     `json:"in_housing_complex"`
-	HousingComplex   string   `json:"housing_complex"`
-	OfferType        string   `json:"offer_type"`    // sale | rent
-	PropertyType     string   `json:"property_type"` // house | apartment
-	Title            string   `json:"title"`
-	UserID           string   `json:"user_id"`
-	Category         string   `json:"category"`
-	Address          string   `json:"address"`
-	Status           string   `json:"status"` // active | sold | archived
-	Floor            int      `json:"floor"`
-	TotalFloors      int      `json:"total_floors"`
-	Rooms            int      `json:"rooms"`
-	Area             float64  `json:"area"`
-	LivingArea       float64  `json:"living_area"`
-	KitchenArea      float64  `json:"kitchen_area"`
-	Price            float64  `json:"price"`
-	Description      string   `json:"description"`
-	Deposit          int64    `json:"deposit"`
-	Commission       int64    `json:"commission"`
-	RentalPeriod     string   `json:"rental_period"`
-	ImageURLs        []string `json:"image_urls"`
+// offerPatchFields are the offer fields UpdateOffer accepts. location_id is
+// derived from the address, status changes go through ChangeOfferStatus.
+var offerPatchFields = map[string]utils.PatchField{
+	"housing_complex_id": utils.PatchUUID(true),
+	"offer_type":         utils.PatchString(false),
+	"property_type":      utils.PatchString(false),
+	"title":              utils.PatchString(false),
+	"description":        utils.PatchString(true),
+	"price":              utils.PatchInt64(false),
+	"area":               utils.PatchFloat(false),
+	"address":            utils.PatchString(false),
+	"rooms":              utils.PatchInt(false),
+	"floor":              utils.PatchInt(true),
+	"total_floors":       utils.PatchInt(true),
+	"deposit":            utils.PatchInt64(true),
+	"commission":         utils.PatchInt64(true),
+	"rental_period":      utils.PatchString(true),
+	"living_area":        utils.PatchFloat(true),
+	"kitchen_area":       utils.PatchFloat(true),
+	"attributes":         utils.PatchObject(),
+	"image_urls":         utils.PatchStrings(),
+}
+
+// OfferAttributeResponse describes an attribute offers may carry and how
+// the feed filters it
+type OfferAttributeResponse struct {
+	Key           string   `json:"key"`
+	Title         string   `json:"title"`
+	Kind          string   `json:"kind"`             // enum | bool | int | decimal
+	Values        []string `json:"values,omitempty"` // enum only
+	Min           *float64 `json:"min,omitempty"`    // int and decimal only
+	Max           *float64 `json:"max,omitempty"`
+	PropertyTypes []string `json:"property_types"` // empty for any
+	OfferTypes    []string `json:"offer_types"`    // empty for any
+	RequiredFor   []string `json:"required_for"`   // property types that can't go without it
+}
+
+type ChangeOfferStatusRequest struct {
+	Status string  `json:"status"` // rejected | moderation | active | paused | sold | archived
+	Reason *string `json:"reason,omitempty"`
+}
+
+type OfferStatusChangeResponse struct {
+	FromStatus *string   `json:"from_status"` // null for the initial status
+	ToStatus   string    `json:"to_status"`
+	ChangedBy  *string   `json:"changed_by"` // null when changed by the system
+	Reason     *string   `json:"reason,omitempty"`
+	ChangedAt  time.Time `json:"changed_at"`
+}
+
+type PriceEventResponse struct {
+	Date      time.Time `json:"date"`
+	OldPrice  *int64    `json:"old_price"` // null for the listing price
+	Price     int64     `json:"price"`
+	Reason    *string   `json:"reason,omitempty"`
+	ChangedBy *string   `json:"changed_by"`
+}
+
+type PriceHistoryResponse struct {
+	OfferID       string               `json:"offer_id"`
+	Events        []PriceEventResponse `json:"events"`
+	InitialPrice  int64                `json:"initial_price"`
+	CurrentPrice  int64                `json:"current_price"`
+	TotalChange   int64                `json:"total_change"`
+	ChangePercent float64              `json:"change_percent"`
+	DaysOnMarket  int                  `json:"days_on_market"`
+	Reductions    int                  `json:"reductions"`
+}
+
+type PriceTrendPointResponse struct {
+	PeriodStart       time.Time `json:"period_start"`
+	MedianPricePerSqm float64   `json:"median_price_per_sqm"`
+	AvgPricePerSqm    float64   `json:"avg_price_per_sqm"`
+	Offers            int       `json:"offers"` // 0 when nothing was listed in the period
 }
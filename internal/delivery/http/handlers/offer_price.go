@@ -0,0 +1,106 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+	"time"
+
+	"github.com/go-park-mail-ru/2025_2_Avrora/internal/delivery/http/response"
+	"github.com/go-park-mail-ru/2025_2_Avrora/internal/domain"
+	"go.uber.org/zap"
+)
+
+// GetOfferPriceHistory — GET /api/v1/offers/pricehistory/{id}
+func (o *offerHandler) GetOfferPriceHistory(w http.ResponseWriter, r *http.Request) {
+	id := GetPathParameter(r, "/api/v1/offers/pricehistory/")
+	if id == "" {
+		o.logger.Error(r.Context(), "invalid or no id")
+		response.HandleError(w, nil, http.StatusBadRequest, "нет id")
+		return
+	}
+
+	history, err := o.offerUsecase.GetOfferPriceHistory(r.Context(), id)
+	if err != nil {
+		o.writeLifecycleError(w, r, err, "ошибка получения истории цены")
+		return
+	}
+
+	events := make([]PriceEventResponse, 0, len(history.Events))
+	for _, e := range history.Events {
+		events = append(events, PriceEventResponse{
+			Date:      e.Date,
+			OldPrice:  e.OldPrice,
+			Price:     e.Price,
+			Reason:    e.Reason,
+			ChangedBy: e.ChangedBy,
+		})
+	}
+	response.WriteJSON(w, http.StatusOK, PriceHistoryResponse{
+		OfferID:       history.OfferID,
+		Events:        events,
+		InitialPrice:  history.InitialPrice,
+		CurrentPrice:  history.CurrentPrice,
+		TotalChange:   history.TotalChange,
+		ChangePercent: history.ChangePercent,
+		DaysOnMarket:  history.DaysOnMarket,
+		Reductions:    history.Reductions,
+	})
+}
+
+// GetPriceTrend — GET /api/v1/prices/trend?scope=complex|metro|region&id=...&bucket=week|month&offer_type=sale&property_type=apartment&from=2025-01-01&to=2025-12-31
+func (o *offerHandler) GetPriceTrend(w http.ResponseWriter, r *http.Request) {
+	query := r.URL.Query()
+	q := &domain.PriceTrendQuery{
+		Scope:     domain.MarketScope(query.Get("scope")),
+		ScopeID:   query.Get("id"),
+		Bucket:    domain.TrendBucketMonth,
+		OfferType: domain.OfferTypeSale,
+	}
+	if v := query.Get("bucket"); v != "" {
+		q.Bucket = domain.TrendBucket(v)
+	}
+	if v := query.Get("offer_type"); v != "" {
+		q.OfferType = domain.OfferType(v)
+	}
+	if v := query.Get("property_type"); v != "" {
+		pt := domain.PropertyType(v)
+		q.PropertyType = &pt
+	}
+	for _, bound := range []struct {
+		name string
+		dst  *time.Time
+	}{{"from", &q.From}, {"to", &q.To}} {
+		v := query.Get(bound.name)
+		if v == "" {
+			continue
+		}
+		t, err := time.Parse(time.DateOnly, v)
+		if err != nil {
+			response.HandleError(w, err, http.StatusBadRequest, "некорректный параметр "+bound.name+", ожидается ГГГГ-ММ-ДД")
+			return
+		}
+		*bound.dst = t
+	}
+
+	points, err := o.offerUsecase.PriceTrend(r.Context(), q)
+	if err != nil {
+		if errors.Is(err, domain.ErrInvalidInput) {
+			response.HandleError(w, err, http.StatusBadRequest, "некорректные параметры динамики цен")
+			return
+		}
+		o.logger.Error(r.Context(), "failed to get price trend", zap.Error(err))
+		response.HandleError(w, nil, http.StatusInternalServerError, "ошибка получения динамики цен")
+		return
+	}
+
+	resp := make([]PriceTrendPointResponse, 0, len(points))
+	for _, p := range points {
+		resp = append(resp, PriceTrendPointResponse{
+			PeriodStart:       p.PeriodStart,
+			MedianPricePerSqm: p.MedianPricePerSqm,
+			AvgPricePerSqm:    p.AvgPricePerSqm,
+			Offers:            p.Offers,
+		})
+	}
+	response.WriteJSON(w, http.StatusOK, resp)
+}
@@ -0,0 +1,165 @@
+package handlers
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"time"
+
+	"github.com/go-park-mail-ru/2025_2_Avrora/internal/delivery/http/middleware"
+	"github.com/go-park-mail-ru/2025_2_Avrora/internal/delivery/http/response"
+	"github.com/go-park-mail-ru/2025_2_Avrora/internal/domain"
+	"github.com/go-park-mail-ru/2025_2_Avrora/internal/log"
+	"go.uber.org/zap"
+)
+
+type IOfferStatsUsecase interface {
+	OfferStats(ctx context.Context, userID, offerID string, days int) (*domain.OfferStats, error)
+	AddFavorite(ctx context.Context, userID, offerID string) error
+	RemoveFavorite(ctx context.Context, userID, offerID string) error
+	ListFavorites(ctx context.Context, userID string, limit, offset int) ([]domain.OfferInFeed, error)
+	RevealContact(ctx context.Context, viewer domain.Viewer, offerID string) (*domain.OfferContact, error)
+}
+
+type OfferStatsHandler struct {
+	statsUsecase IOfferStatsUsecase
+	logger       *log.Logger
+}
+
+func NewOfferStatsHandler(uc IOfferStatsUsecase, logger *log.Logger) *OfferStatsHandler {
+	return &OfferStatsHandler{statsUsecase: uc, logger: logger}
+}
+
+func (h *OfferStatsHandler) writeError(w http.ResponseWriter, r *http.Request, err error, msg string) {
+	switch {
+	case errors.Is(err, domain.ErrInvalidInput):
+		response.HandleError(w, err, http.StatusBadRequest, "невалидные данные")
+	case errors.Is(err, domain.ErrOfferNotFound):
+		response.HandleError(w, err, http.StatusNotFound, "объявление не найдено")
+	case errors.Is(err, domain.ErrForbidden):
+		response.HandleError(w, err, http.StatusForbidden, "статистика доступна только автору объявления")
+	case errors.Is(err, domain.ErrOfferNotListed):
+		response.HandleError(w, err, http.StatusConflict, "объявление снято с публикации")
+	default:
+		h.logger.Error(r.Context(), "offer stats operation failed", zap.String("path", r.URL.Path), zap.Error(err))
+		response.HandleError(w, err, http.StatusInternalServerError, msg)
+	}
+}
+
+// GetOfferStats — GET /api/v1/offers/stats/{id}?days=30
+func (h *OfferStatsHandler) GetOfferStats(w http.ResponseWriter, r *http.Request) {
+	id := GetPathParameter(r, "/api/v1/offers/stats/")
+	if id == "" {
+		response.HandleError(w, nil, http.StatusBadRequest, "нет id")
+		return
+	}
+	days, err := parseIntQueryParam(r, "days", 30)
+	if err != nil {
+		response.HandleError(w, err, http.StatusBadRequest, "некорректный параметр days")
+		return
+	}
+
+	userID, _ := middleware.GetUserIDFromContext(r.Context())
+	stats, err := h.statsUsecase.OfferStats(r.Context(), userID, id, days)
+	if err != nil {
+		h.writeError(w, r, err, "ошибка получения статистики объявления")
+		return
+	}
+
+	resp := OfferStatsResponse{
+		OfferID: stats.OfferID,
+		Period:  stats.Period,
+		Days:    make([]OfferDayStatsResponse, 0, len(stats.Days)),
+		Summary: OfferStatsSummaryResponse(stats.Summary),
+	}
+	for _, d := range stats.Days {
+		resp.Days = append(resp.Days, OfferDayStatsResponse{
+			Day:            d.Day.Format(time.DateOnly),
+			Views:          d.Views,
+			UniqueViewers:  d.UniqueViewers,
+			Favorites:      d.Favorites,
+			ContactReveals: d.ContactReveals,
+		})
+	}
+	response.WriteJSON(w, http.StatusOK, resp)
+}
+
+// ListFavorites — GET /api/v1/favorites?limit=20&offset=0
+func (h *OfferStatsHandler) ListFavorites(w http.ResponseWriter, r *http.Request) {
+	limit, err := parseIntQueryParam(r, "limit", 20)
+	if err != nil {
+		response.HandleError(w, err, http.StatusBadRequest, "некорректный параметр limit")
+		return
+	}
+	offset, err := parseIntQueryParam(r, "offset", 0)
+	if err != nil {
+		response.HandleError(w, err, http.StatusBadRequest, "некорректный параметр offset")
+		return
+	}
+
+	userID, _ := middleware.GetUserIDFromContext(r.Context())
+	offers, err := h.statsUsecase.ListFavorites(r.Context(), userID, limit, offset)
+	if err != nil {
+		h.writeError(w, r, err, "ошибка получения избранного")
+		return
+	}
+	response.WriteJSON(w, http.StatusOK, offers)
+}
+
+// AddFavorite — POST /api/v1/favorites/add/{offer_id}
+func (h *OfferStatsHandler) AddFavorite(w http.ResponseWriter, r *http.Request) {
+	id := GetPathParameter(r, "/api/v1/favorites/add/")
+	if id == "" {
+		response.HandleError(w, nil, http.StatusBadRequest, "нет id")
+		return
+	}
+
+	userID, _ := middleware.GetUserIDFromContext(r.Context())
+	if err := h.statsUsecase.AddFavorite(r.Context(), userID, id); err != nil {
+		h.writeError(w, r, err, "ошибка добавления в избранное")
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// RemoveFavorite — POST /api/v1/favorites/remove/{offer_id}
+func (h *OfferStatsHandler) RemoveFavorite(w http.ResponseWriter, r *http.Request) {
+	id := GetPathParameter(r, "/api/v1/favorites/remove/")
+	if id == "" {
+		response.HandleError(w, nil, http.StatusBadRequest, "нет id")
+		return
+	}
+
+	userID, _ := middleware.GetUserIDFromContext(r.Context())
+	if err := h.statsUsecase.RemoveFavorite(r.Context(), userID, id); err != nil {
+		h.writeError(w, r, err, "ошибка удаления из избранного")
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// RevealContact — POST /api/v1/offers/contact/{id}
+func (h *OfferStatsHandler) RevealContact(w http.ResponseWriter, r *http.Request) {
+	id := GetPathParameter(r, "/api/v1/offers/contact/")
+	if id == "" {
+		response.HandleError(w, nil, http.StatusBadRequest, "нет id")
+		return
+	}
+
+	viewer := domain.Viewer{IP: middleware.ClientIP(r), UserAgent: r.UserAgent()}
+	if userID, ok := middleware.GetUserIDFromContext(r.Context()); ok {
+		viewer.UserID = &userID
+	}
+	contact, err := h.statsUsecase.RevealContact(r.Context(), viewer, id)
+	if err != nil {
+		h.writeError(w, r, err, "ошибка получения контактов")
+		return
+	}
+	response.WriteJSON(w, http.StatusOK, OfferContactResponse{
+		OfferID:   contact.OfferID,
+		UserID:    contact.UserID,
+		FirstName: contact.FirstName,
+		LastName:  contact.LastName,
+		Phone:     contact.Phone,
+	})
+}
@@ -0,0 +1,31 @@
+package handlers
+
+type OfferDayStatsResponse struct {
+	Day            string `json:"day"` // YYYY-MM-DD
+	Views          int    `json:"views"`
+	UniqueViewers  int    `json:"unique_viewers"`
+	Favorites      int    `json:"favorites"` // added that day
+	ContactReveals int    `json:"contact_reveals"`
+}
+
+type OfferStatsSummaryResponse struct {
+	Views          int `json:"views"`
+	DailyViewers   int `json:"daily_viewers"` // daily unique viewers summed up
+	Favorites      int `json:"favorites"`     // users keeping the offer in favorites now
+	ContactReveals int `json:"contact_reveals"`
+}
+
+type OfferStatsResponse struct {
+	OfferID string                    `json:"offer_id"`
+	Period  int                       `json:"period_days"`
+	Days    []OfferDayStatsResponse   `json:"days"`
+	Summary OfferStatsSummaryResponse `json:"summary"`
+}
+
+type OfferContactResponse struct {
+	OfferID   string `json:"offer_id"`
+	UserID    string `json:"user_id"`
+	FirstName string `json:"first_name"`
+	LastName  string `json:"last_name"`
+	Phone     string `json:"phone"`
+}
@@ -0,0 +1,208 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+
+	"github.com/go-park-mail-ru/2025_2_Avrora/internal/delivery/http/middleware"
+	"github.com/go-park-mail-ru/2025_2_Avrora/internal/delivery/http/response"
+	"github.com/go-park-mail-ru/2025_2_Avrora/internal/domain"
+	"github.com/go-park-mail-ru/2025_2_Avrora/internal/log"
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+)
+
+type IPhotoUsecase interface {
+	List(ctx context.Context, ownerID string) ([]domain.Photo, error)
+	Add(ctx context.Context, userID string, photo *domain.Photo) error
+	Update(ctx context.Context, userID, ownerID, photoID string, upd *domain.PhotoUpdate) (*domain.Photo, error)
+	Delete(ctx context.Context, userID, ownerID, photoID string) error
+	Reorder(ctx context.Context, userID, ownerID string, photoIDs []string) ([]domain.Photo, error)
+}
+
+// PhotoHandler serves photo management of one kind of listing (offers or complexes)
+// under basePath, e.g. /api/v1/offers/photos
+type PhotoHandler struct {
+	photoUsecase IPhotoUsecase
+	basePath     string
+	logger       *log.Logger
+}
+
+func NewPhotoHandler(uc IPhotoUsecase, basePath string, logger *log.Logger) *PhotoHandler {
+	return &PhotoHandler{photoUsecase: uc, basePath: basePath, logger: logger}
+}
+
+// ownerFromPath reads {ownerID} after basePath+sub
+func (h *PhotoHandler) ownerFromPath(w http.ResponseWriter, r *http.Request, sub string) (string, bool) {
+	id := GetPathParameter(r, h.basePath+sub)
+	if id == "" {
+		response.HandleError(w, nil, http.StatusBadRequest, "отсутствует ID")
+		return "", false
+	}
+	if _, err := uuid.Parse(id); err != nil {
+		response.HandleError(w, nil, http.StatusBadRequest, "некорректный формат UUID")
+		return "", false
+	}
+	return id, true
+}
+
+// ownerAndPhotoFromPath reads {ownerID}/{photoID} after basePath+sub
+func (h *PhotoHandler) ownerAndPhotoFromPath(w http.ResponseWriter, r *http.Request, sub string) (string, string, bool) {
+	params := GetPathParameters(r, h.basePath+sub)
+	if len(params) != 2 {
+		response.HandleError(w, nil, http.StatusBadRequest, "ожидается путь вида {id}/{photo_id}")
+		return "", "", false
+	}
+	for _, id := range params {
+		if _, err := uuid.Parse(id); err != nil {
+			response.HandleError(w, nil, http.StatusBadRequest, "некорректный формат UUID")
+			return "", "", false
+		}
+	}
+	return params[0], params[1], true
+}
+
+func (h *PhotoHandler) handleError(w http.ResponseWriter, r *http.Request, err error, msg string) {
+	switch {
+	case errors.Is(err, domain.ErrInvalidInput):
+		response.HandleError(w, err, http.StatusBadRequest, "некорректные данные фотографии")
+	case errors.Is(err, domain.ErrPhotoNotFound):
+		response.HandleError(w, err, http.StatusNotFound, "фотография не найдена")
+	case errors.Is(err, domain.ErrOfferNotFound):
+		response.HandleError(w, err, http.StatusNotFound, "объявление не найдено")
+	case errors.Is(err, domain.ErrComplexNotFound):
+		response.HandleError(w, err, http.StatusNotFound, "жилой комплекс не найден")
+	case errors.Is(err, domain.ErrForbidden):
+		response.HandleError(w, err, http.StatusForbidden, "недостаточно прав")
+	case errors.Is(err, domain.ErrImageNotOwned):
+		response.HandleError(w, err, http.StatusForbidden, "изображение не найдено среди ваших загрузок")
+	case errors.Is(err, domain.ErrStatusConflict):
+		response.HandleError(w, err, http.StatusConflict, "статус объявления изменился, обновите страницу")
+	default:
+		h.logger.Error(r.Context(), msg, zap.String("path", r.URL.Path), zap.Error(err))
+		response.HandleError(w, nil, http.StatusInternalServerError, "ошибка обработки фотографий")
+	}
+}
+
+// ListPhotos — GET {basePath}/{id}
+func (h *PhotoHandler) ListPhotos(w http.ResponseWriter, r *http.Request) {
+	ownerID, ok := h.ownerFromPath(w, r, "/")
+	if !ok {
+		return
+	}
+
+	photos, err := h.photoUsecase.List(r.Context(), ownerID)
+	if err != nil {
+		h.handleError(w, r, err, "failed to list photos")
+		return
+	}
+
+	response.WriteJSON(w, http.StatusOK, toPhotoResponses(photos))
+}
+
+// AddPhoto — POST {basePath}/add/{id}
+func (h *PhotoHandler) AddPhoto(w http.ResponseWriter, r *http.Request) {
+	ownerID, ok := h.ownerFromPath(w, r, "/add/")
+	if !ok {
+		return
+	}
+
+	var req AddPhotoRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		response.HandleError(w, nil, http.StatusBadRequest, "некорректный JSON")
+		return
+	}
+
+	photo := &domain.Photo{OwnerID: ownerID, URL: req.URL}
+	if req.Caption != nil && *req.Caption != "" {
+		photo.Caption = req.Caption
+	}
+	if req.RoomTag != nil && *req.RoomTag != "" {
+		tag := domain.PhotoRoomTag(*req.RoomTag)
+		photo.RoomTag = &tag
+	}
+
+	userID, _ := middleware.GetUserIDFromContext(r.Context())
+	if err := h.photoUsecase.Add(r.Context(), userID, photo); err != nil {
+		h.handleError(w, r, err, "failed to add photo")
+		return
+	}
+
+	response.WriteJSON(w, http.StatusCreated, toPhotoResponse(photo))
+}
+
+// UpdatePhoto — PATCH {basePath}/update/{id}/{photo_id}
+func (h *PhotoHandler) UpdatePhoto(w http.ResponseWriter, r *http.Request) {
+	ownerID, photoID, ok := h.ownerAndPhotoFromPath(w, r, "/update/")
+	if !ok {
+		return
+	}
+
+	var req UpdatePhotoRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		response.HandleError(w, nil, http.StatusBadRequest, "некорректный JSON")
+		return
+	}
+
+	upd := &domain.PhotoUpdate{Caption: req.Caption, IsCover: req.IsCover}
+	if req.RoomTag != nil {
+		tag := domain.PhotoRoomTag(*req.RoomTag)
+		upd.RoomTag = &tag
+	}
+
+	userID, _ := middleware.GetUserIDFromContext(r.Context())
+	photo, err := h.photoUsecase.Update(r.Context(), userID, ownerID, photoID, upd)
+	if err != nil {
+		h.handleError(w, r, err, "failed to update photo")
+		return
+	}
+
+	response.WriteJSON(w, http.StatusOK, toPhotoResponse(photo))
+}
+
+// DeletePhoto — DELETE {basePath}/delete/{id}/{photo_id}
+func (h *PhotoHandler) DeletePhoto(w http.ResponseWriter, r *http.Request) {
+	ownerID, photoID, ok := h.ownerAndPhotoFromPath(w, r, "/delete/")
+	if !ok {
+		return
+	}
+
+	userID, _ := middleware.GetUserIDFromContext(r.Context())
+	if err := h.photoUsecase.Delete(r.Context(), userID, ownerID, photoID); err != nil {
+		h.handleError(w, r, err, "failed to delete photo")
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// ReorderPhotos — PUT {basePath}/reorder/{id}
+func (h *PhotoHandler) ReorderPhotos(w http.ResponseWriter, r *http.Request) {
+	ownerID, ok := h.ownerFromPath(w, r, "/reorder/")
+	if !ok {
+		return
+	}
+
+	var req ReorderPhotosRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		response.HandleError(w, nil, http.StatusBadRequest, "некорректный JSON")
+		return
+	}
+	for _, id := range req.PhotoIDs {
+		if _, err := uuid.Parse(id); err != nil {
+			response.HandleError(w, nil, http.StatusBadRequest, "некорректный формат UUID")
+			return
+		}
+	}
+
+	userID, _ := middleware.GetUserIDFromContext(r.Context())
+	photos, err := h.photoUsecase.Reorder(r.Context(), userID, ownerID, req.PhotoIDs)
+	if err != nil {
+		h.handleError(w, r, err, "failed to reorder photos")
+		return
+	}
+
+	response.WriteJSON(w, http.StatusOK, toPhotoResponses(photos))
+}
@@ -0,0 +1,60 @@
+package handlers
+
+import (
+	"time"
+
+	"github.com/go-park-mail-ru/2025_2_Avrora/internal/domain"
+)
+
+type PhotoResponse struct {
+	ID        string    `json:"id"`
+	URL       string    `json:"url"`
+	Position  int       `json:"position"`
+	IsCover   bool      `json:"is_cover"`
+	Caption   *string   `json:"caption,omitempty"`
+	RoomTag   *string   `json:"room_tag,omitempty"`
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+type AddPhotoRequest struct {
+	URL     string  `json:"url"`
+	Caption *string `json:"caption,omitempty"`
+	RoomTag *string `json:"room_tag,omitempty"` // living_room, bedroom, kitchen, bathroom, hallway, balcony, exterior, view, floor_plan, other
+}
+
+// UpdatePhotoRequest changes only the fields that are present; "" clears caption or room tag
+type UpdatePhotoRequest struct {
+	Caption *string `json:"caption,omitempty"`
+	RoomTag *string `json:"room_tag,omitempty"`
+	IsCover *bool   `json:"is_cover,omitempty"`
+}
+
+type ReorderPhotosRequest struct {
+	PhotoIDs []string `json:"photo_ids"` // every photo of the listing, in the new order
+}
+
+func toPhotoResponse(p *domain.Photo) PhotoResponse {
+	resp := PhotoResponse{
+		ID:        p.ID,
+		URL:       p.URL,
+		Position:  p.Position,
+		IsCover:   p.IsCover,
+		Caption:   p.Caption,
+		CreatedAt: p.CreatedAt,
+		UpdatedAt: p.UpdatedAt,
+	}
+	if p.RoomTag != nil {
+		tag := string(*p.RoomTag)
+		resp.RoomTag = &tag
+	}
+	return resp
+}
+
+func toPhotoResponses(photos []domain.Photo) []PhotoResponse {
+	resp := make([]PhotoResponse, 0, len(photos))
+	for i := range photos {
+		resp = append(resp, toPhotoResponse(&photos[i]))
+	}
+	return resp
+}
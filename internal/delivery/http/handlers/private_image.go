@@ -0,0 +1,189 @@
+package handlers
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/go-park-mail-ru/2025_2_Avrora/internal/delivery/http/middleware"
+	"github.com/go-park-mail-ru/2025_2_Avrora/internal/delivery/http/response"
+	"github.com/go-park-mail-ru/2025_2_Avrora/internal/delivery/http/utils"
+	"github.com/go-park-mail-ru/2025_2_Avrora/internal/domain"
+	fileserverpb "github.com/go-park-mail-ru/2025_2_Avrora/proto/fileserver"
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+)
+
+const (
+	privateBucket     = "private"
+	defaultSignedTTL  = 15 * time.Minute
+	maxSignedTTL      = 24 * time.Hour
+	privateFilePrefix = "/api/v1/image/private/"
+)
+
+// signedURL builds an absolute link to a private file valid until expiresAt
+func (h *ImageHandler) signedURL(filename string, expiresAt time.Time, userID string) string {
+	q := h.signer.SignQuery(filename, expiresAt, userID)
+	return fmt.Sprintf("%s%s%s?%s", h.baseURL, privateFilePrefix, filename, q.Encode())
+}
+
+// UploadPrivateFile — POST /api/v1/image/private/upload
+func (h *ImageHandler) UploadPrivateFile(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	userID, ok := middleware.GetUserIDFromContext(ctx)
+	if !ok {
+		response.HandleError(w, nil, http.StatusUnauthorized, "требуется авторизация")
+		return
+	}
+
+	if err := r.ParseMultipartForm(MAX_SIZE); err != nil {
+		h.logger.Error(ctx, "failed to parse multipart form", zap.Error(err))
+		response.HandleError(w, err, http.StatusBadRequest, "размер файла превышает допустимый лимит(10MB)")
+		return
+	}
+
+	file, header, err := r.FormFile("file")
+	if err != nil {
+		h.logger.Error(ctx, "failed to get form file", zap.Error(err))
+		response.HandleError(w, err, http.StatusBadRequest, "не получилось загрузить файл")
+		return
+	}
+	defer file.Close()
+
+	data, err := io.ReadAll(file)
+	if err != nil {
+		h.logger.Error(ctx, "failed to read file data", zap.Error(err))
+		response.HandleError(w, err, http.StatusInternalServerError, "не удалось прочитать файл")
+		return
+	}
+
+	// Private files are not content-addressed: the name must not reveal
+	// that two users hold the same document. Type and extension come from
+	// the bytes, not from what the client claims.
+	contentType := utils.SniffContentType(data)
+	storedName := uuid.NewString() + utils.PrivateFileExtension(contentType)
+
+	if _, err := h.fileserver.Upload(ctx, &fileserverpb.UploadRequest{
+		Data:        data,
+		Filename:    storedName,
+		ContentType: contentType,
+		Bucket:      privateBucket,
+	}); err != nil {
+		h.logger.Error(ctx, "gRPC private upload failed", zap.Error(err), zap.String("filename", storedName))
+		response.HandleError(w, err, http.StatusInternalServerError, "не удалось сохранить файл на сервере")
+		return
+	}
+
+	pf := &domain.PrivateFile{
+		UserID:       userID,
+		Filename:     storedName,
+		OriginalName: header.Filename,
+		ContentType:  contentType,
+		SizeBytes:    int64(len(data)),
+	}
+	if err := h.imageUsecase.RegisterPrivateFile(ctx, pf); err != nil {
+		response.HandleError(w, err, http.StatusInternalServerError, "не удалось сохранить файл на сервере")
+		return
+	}
+
+	expiresAt := time.Now().Add(defaultSignedTTL)
+	response.WriteJSON(w, http.StatusCreated, SignedURLResponse{
+		Filename:  storedName,
+		URL:       h.signedURL(storedName, expiresAt, userID),
+		ExpiresAt: expiresAt,
+	})
+}
+
+// SignPrivateURL — POST /api/v1/image/private/sign/{filename}
+func (h *ImageHandler) SignPrivateURL(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	filename := GetPathParameter(r, "/api/v1/image/private/sign/")
+	if filename == "" {
+		response.HandleError(w, nil, http.StatusBadRequest, "некорректное имя файла")
+		return
+	}
+
+	userID, ok := middleware.GetUserIDFromContext(ctx)
+	if !ok {
+		response.HandleError(w, nil, http.StatusUnauthorized, "требуется авторизация")
+		return
+	}
+
+	var req SignURLRequest
+	if r.ContentLength != 0 {
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			response.HandleError(w, err, http.StatusBadRequest, "некорректный JSON")
+			return
+		}
+	}
+
+	ttl := defaultSignedTTL
+	if req.TTLSeconds > 0 {
+		ttl = time.Duration(req.TTLSeconds) * time.Second
+	}
+	if ttl > maxSignedTTL {
+		response.HandleError(w, nil, http.StatusBadRequest, "срок действия ссылки не может превышать 24 часа")
+		return
+	}
+
+	if _, err := h.imageUsecase.GetOwnedPrivateFile(ctx, userID, filename); err != nil {
+		switch {
+		case errors.Is(err, domain.ErrImageNotFound):
+			response.HandleError(w, err, http.StatusNotFound, "файл не найден")
+		case errors.Is(err, domain.ErrImageNotOwned):
+			response.HandleError(w, err, http.StatusForbidden, "нет доступа к файлу")
+		default:
+			response.HandleError(w, err, http.StatusInternalServerError, "не удалось подписать ссылку")
+		}
+		return
+	}
+
+	boundTo := ""
+	if req.BindUser {
+		boundTo = userID
+	}
+	expiresAt := time.Now().Add(ttl)
+
+	response.WriteJSON(w, http.StatusOK, SignedURLResponse{
+		Filename:  filename,
+		URL:       h.signedURL(filename, expiresAt, boundTo),
+		ExpiresAt: expiresAt,
+	})
+}
+
+// GetPrivateFile — GET /api/v1/image/private/{filename}?expires=...&uid=...&sig=...
+func (h *ImageHandler) GetPrivateFile(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	filename := strings.TrimPrefix(r.URL.Path, privateFilePrefix)
+	if filename == "" || strings.Contains(filename, "/") || strings.Contains(filename, "..") {
+		response.HandleError(w, nil, http.StatusNotFound, "некорректное имя файла")
+		return
+	}
+
+	boundTo, err := h.signer.Verify(filename, r.URL.Query(), time.Now())
+	if err != nil {
+		h.logger.Warn(ctx, "rejected private file request", zap.String("filename", filename), zap.Error(err))
+		if errors.Is(err, utils.ErrSignatureExpired) {
+			response.HandleError(w, err, http.StatusForbidden, "срок действия ссылки истёк")
+			return
+		}
+		response.HandleError(w, err, http.StatusForbidden, "недействительная ссылка")
+		return
+	}
+
+	if boundTo != "" {
+		if userID, ok := middleware.GetUserIDFromContext(ctx); !ok || userID != boundTo {
+			response.HandleError(w, nil, http.StatusForbidden, "ссылка выдана другому пользователю")
+			return
+		}
+	}
+
+	h.streamFile(w, r, filename, privateBucket, "private, no-store")
+}
@@ -2,6 +2,7 @@ package handlers
 
 import (
 	"encoding/json"
+	"errors"
 	"net/http"
 
 	"github.com/go-park-mail-ru/2025_2_Avrora/internal/delivery/http/response"
@@ -21,31 +22,42 @@ func (p *profileHandler) GetProfile(w http.ResponseWriter, r *http.Request) {
 		response.HandleError(w, err, http.StatusInternalServerError, "ошибка получения профиля")
 		return
 	}
+	setETag(w, result.Version)
 	response.WriteJSON(w, http.StatusOK, result)
 }
 
+// UpdateProfile takes a JSON merge patch: omitted fields are kept, null
+// clears one. If-Match must carry the ETag the edit is based on.
 func (p *profileHandler) UpdateProfile(w http.ResponseWriter, r *http.Request) {
-	var req ProfileUpdate
-	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
-		p.log.Error(r.Context(), "invalid JSON", zap.Error(err))
-		response.HandleError(w, err, http.StatusBadRequest, "неправильный формат JSON")
-		return
-	}
-
 	id := GetPathParameter(r, "/api/v1/profile/update/"); if id == "" {
 		p.log.Error(r.Context(), "invalid or no id")
 		response.HandleError(w, nil, http.StatusInternalServerError, "ошибка получения параметра")
 		return
 	}
 
-	if err := p.profileUsecase.UpdateProfile(r.Context(), id, &domain.ProfileUpdate{
-		FirstName: SafeStringDeref(req.FirstName),
-		LastName:  SafeStringDeref(req.LastName),
-		Phone:     SafeStringDeref(req.Phone),
-		Role:      SafeStringDeref(req.Role),
-		AvatarURL: SafeStringDeref(req.AvatarURL),
-	}); err != nil {
-		response.HandleError(w, err, http.StatusInternalServerError, "ошибка обновления профиля")
+	version, ok := ifMatchVersion(w, r)
+	if !ok {
+		return
+	}
+	patch, ok := readMergePatch(w, r, profilePatchFields)
+	if !ok {
+		return
+	}
+
+	if err := p.profileUsecase.UpdateProfile(r.Context(), id, patch, version); err != nil {
+		var verr domain.ValidationErrors
+		switch {
+		case errors.As(err, &verr):
+			response.HandleValidationError(w, "проверьте заполнение полей", fieldErrorsMap(verr))
+		case errors.Is(err, domain.ErrVersionMismatch):
+			response.HandleError(w, err, http.StatusPreconditionFailed, "профиль изменился, обновите страницу")
+		case errors.Is(err, domain.ErrImageNotOwned):
+			response.HandleError(w, err, http.StatusForbidden, "изображение не найдено среди ваших загрузок")
+		case errors.Is(err, domain.ErrInvalidInput):
+			response.HandleError(w, err, http.StatusBadRequest, "невалидные данные")
+		default:
+			response.HandleError(w, err, http.StatusInternalServerError, "ошибка обновления профиля")
+		}
 		return
 	}
 
@@ -94,10 +106,3 @@ func (p *profileHandler) UpdateEmail(w http.ResponseWriter, r *http.Request) {
 
 	response.WriteJSON(w, http.StatusOK, "success")
 }
-
-func SafeStringDeref(s *string) string {
-	if s == nil {
-		return ""
-	}
-	return *s
-}
@@ -7,7 +7,7 @@ import (
 )
 
 type IProfileUsecase interface {
-	UpdateProfile(ctx context.Context, userID string, profile *domain.ProfileUpdate) error
+	UpdateProfile(ctx context.Context, userID string, patch domain.Patch, version *int) error
 	GetProfileByID(ctx context.Context, userID string) (*domain.Profile, error)
 	UpdateProfileSecurityByID(ctx context.Context, userID string, oldPassword, newPassword string) error
 	UpdateEmail(ctx context.Context, userID string, email string) error
@@ -1,5 +1,7 @@
 package handlers
 
+import "github.com/go-park-mail-ru/2025_2_Avrora/internal/delivery/http/utils"
+
 type Profile struct {
 	FirstName string `json:"first_name"`
 	LastName  string `json:"last_name"`
@@ -9,12 +11,12 @@ type Profile struct {
 	Role      string `json:"role"`
 }
 
-type ProfileUpdate struct {
-	FirstName *string `json:"first_name"`
-	LastName  *string `json:"last_name"`
-	AvatarURL *string `json:"avatar_url"`
-	Phone     *string `json:"phone"`
-	Role      *string `json:"role"`
+// profilePatchFields are the profile fields UpdateProfile accepts; null clears one
+var profilePatchFields = map[string]utils.PatchField{
+	"first_name": utils.PatchString(true),
+	"last_name":  utils.PatchString(true),
+	"avatar_url": utils.PatchString(true),
+	"phone":      utils.PatchString(true),
 }
 
 type UpdateEmail struct {
@@ -0,0 +1,366 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+
+	"github.com/go-park-mail-ru/2025_2_Avrora/internal/delivery/http/response"
+	"github.com/go-park-mail-ru/2025_2_Avrora/internal/domain"
+	"github.com/go-park-mail-ru/2025_2_Avrora/internal/log"
+	"go.uber.org/zap"
+)
+
+type IRegionUsecase interface {
+	Roots(ctx context.Context) ([]domain.Region, error)
+	Tree(ctx context.Context, slug string) (*domain.RegionTree, error)
+	ListMetroLines(ctx context.Context, regionSlug *string) ([]domain.MetroLine, error)
+	ListMetroStations(ctx context.Context, regionSlug, lineID *string) ([]domain.MetroStation, error)
+	ListOffers(ctx context.Context, slug string, f *domain.OfferFilter, limit, offset int) ([]domain.OfferInFeed, error)
+
+	CreateRegion(ctx context.Context, region *domain.Region) error
+	UpdateRegion(ctx context.Context, region *domain.Region) error
+	DeleteRegion(ctx context.Context, id string) error
+	CreateMetroLine(ctx context.Context, line *domain.MetroLine) error
+	UpdateMetroLine(ctx context.Context, line *domain.MetroLine) error
+	DeleteMetroLine(ctx context.Context, id string) error
+	CreateMetroStation(ctx context.Context, station *domain.MetroStation) error
+	UpdateMetroStation(ctx context.Context, station *domain.MetroStation) error
+	DeleteMetroStation(ctx context.Context, id string) error
+}
+
+type RegionHandler struct {
+	regionUsecase IRegionUsecase
+	logger        *log.Logger
+}
+
+func NewRegionHandler(uc IRegionUsecase, logger *log.Logger) *RegionHandler {
+	return &RegionHandler{regionUsecase: uc, logger: logger}
+}
+
+func (h *RegionHandler) writeError(w http.ResponseWriter, r *http.Request, err error, msg string) {
+	var verr domain.ValidationErrors
+	switch {
+	case errors.As(err, &verr):
+		response.HandleValidationError(w, "проверьте заполнение полей", fieldErrorsMap(verr))
+	case errors.Is(err, domain.ErrInvalidInput):
+		response.HandleError(w, err, http.StatusBadRequest, "невалидные данные")
+	case errors.Is(err, domain.ErrRegionNotFound):
+		response.HandleError(w, nil, http.StatusNotFound, "регион не найден")
+	case errors.Is(err, domain.ErrMetroLineNotFound):
+		response.HandleError(w, nil, http.StatusNotFound, "линия метро не найдена")
+	case errors.Is(err, domain.ErrMetroStationNotFound):
+		response.HandleError(w, nil, http.StatusNotFound, "станция метро не найдена")
+	case errors.Is(err, domain.ErrRegionSlugTaken):
+		response.HandleError(w, err, http.StatusConflict, "регион с таким slug уже есть")
+	case errors.Is(err, domain.ErrRegionInUse):
+		response.HandleError(w, err, http.StatusConflict, "в регионе есть подрегионы, адреса или линии метро")
+	case errors.Is(err, domain.ErrMetroLineExists):
+		response.HandleError(w, err, http.StatusConflict, "линия с таким названием уже есть")
+	default:
+		h.logger.Error(r.Context(), "region operation failed", zap.String("path", r.URL.Path), zap.Error(err))
+		response.HandleError(w, nil, http.StatusInternalServerError, msg)
+	}
+}
+
+func toRegionResponse(region *domain.Region) RegionResponse {
+	return RegionResponse{
+		ID:       region.ID,
+		Name:     region.Name,
+		ParentID: region.ParentID,
+		Level:    region.Level,
+		Slug:     region.Slug,
+	}
+}
+
+func toRegionResponses(regions []domain.Region) []RegionResponse {
+	resp := make([]RegionResponse, 0, len(regions))
+	for i := range regions {
+		resp = append(resp, toRegionResponse(&regions[i]))
+	}
+	return resp
+}
+
+func toMetroLineResponse(line *domain.MetroLine) *MetroLineResponse {
+	if line == nil {
+		return nil
+	}
+	return &MetroLineResponse{ID: line.ID, RegionID: line.RegionID, Name: line.Name, Color: line.Color}
+}
+
+func toMetroStationResponse(station *domain.MetroStation) MetroStationResponse {
+	return MetroStationResponse{
+		ID:        station.ID,
+		Name:      station.Name,
+		RegionID:  station.RegionID,
+		Latitude:  station.Latitude,
+		Longitude: station.Longitude,
+		Line:      toMetroLineResponse(station.Line),
+	}
+}
+
+// optionalQueryParam returns nil for a missing or empty parameter
+func optionalQueryParam(r *http.Request, key string) *string {
+	if v := r.URL.Query().Get(key); v != "" {
+		return &v
+	}
+	return nil
+}
+
+// ListRegions — GET /api/v1/regions, the top level regions
+func (h *RegionHandler) ListRegions(w http.ResponseWriter, r *http.Request) {
+	regions, err := h.regionUsecase.Roots(r.Context())
+	if err != nil {
+		h.writeError(w, r, err, "ошибка получения регионов")
+		return
+	}
+	response.WriteJSON(w, http.StatusOK, toRegionResponses(regions))
+}
+
+// GetRegion — GET /api/v1/regions/{slug} with its path from the root and subregions
+func (h *RegionHandler) GetRegion(w http.ResponseWriter, r *http.Request) {
+	slug := GetPathParameter(r, "/api/v1/regions/")
+	if slug == "" {
+		response.HandleError(w, nil, http.StatusBadRequest, "отсутствует slug региона")
+		return
+	}
+
+	tree, err := h.regionUsecase.Tree(r.Context(), slug)
+	if err != nil {
+		h.writeError(w, r, err, "ошибка получения региона")
+		return
+	}
+	response.WriteJSON(w, http.StatusOK, RegionTreeResponse{
+		RegionResponse: toRegionResponse(&tree.Region),
+		Path:           toRegionResponses(tree.Path),
+		Children:       toRegionResponses(tree.Children),
+	})
+}
+
+// ListRegionOffers — GET /api/v1/regions/offers/{slug}?limit=20&offset=0 with
+// the feed filters; offers of subregions are included
+func (h *RegionHandler) ListRegionOffers(w http.ResponseWriter, r *http.Request) {
+	slug := GetPathParameter(r, "/api/v1/regions/offers/")
+	if slug == "" {
+		response.HandleError(w, nil, http.StatusBadRequest, "отсутствует slug региона")
+		return
+	}
+	limit, err := parseIntQueryParam(r, "limit", 20)
+	if err != nil {
+		response.HandleError(w, err, http.StatusBadRequest, "некорректный limit")
+		return
+	}
+	offset, err := parseIntQueryParam(r, "offset", 0)
+	if err != nil {
+		response.HandleError(w, err, http.StatusBadRequest, "некорректный offset")
+		return
+	}
+
+	offers, err := h.regionUsecase.ListOffers(r.Context(), slug, offerFilterFromQuery(r.URL.Query()), limit, offset)
+	if err != nil {
+		h.writeError(w, r, err, "ошибка получения объявлений региона")
+		return
+	}
+	response.WriteJSON(w, http.StatusOK, offers)
+}
+
+// ListMetroLines — GET /api/v1/metro/lines?region={slug}
+func (h *RegionHandler) ListMetroLines(w http.ResponseWriter, r *http.Request) {
+	lines, err := h.regionUsecase.ListMetroLines(r.Context(), optionalQueryParam(r, "region"))
+	if err != nil {
+		h.writeError(w, r, err, "ошибка получения линий метро")
+		return
+	}
+	resp := make([]*MetroLineResponse, 0, len(lines))
+	for i := range lines {
+		resp = append(resp, toMetroLineResponse(&lines[i]))
+	}
+	response.WriteJSON(w, http.StatusOK, resp)
+}
+
+// ListMetroStations — GET /api/v1/metro/stations?region={slug}&line_id=
+func (h *RegionHandler) ListMetroStations(w http.ResponseWriter, r *http.Request) {
+	stations, err := h.regionUsecase.ListMetroStations(r.Context(), optionalQueryParam(r, "region"), optionalQueryParam(r, "line_id"))
+	if err != nil {
+		h.writeError(w, r, err, "ошибка получения станций метро")
+		return
+	}
+	resp := make([]MetroStationResponse, 0, len(stations))
+	for i := range stations {
+		resp = append(resp, toMetroStationResponse(&stations[i]))
+	}
+	response.WriteJSON(w, http.StatusOK, resp)
+}
+
+func decodeRegion(r *http.Request, region *domain.Region) error {
+	var req RegionRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		return err
+	}
+	region.Name = req.Name
+	region.ParentID = req.ParentID
+	region.Slug = req.Slug
+	return nil
+}
+
+// CreateRegion — POST /api/v1/regions/create
+func (h *RegionHandler) CreateRegion(w http.ResponseWriter, r *http.Request) {
+	var region domain.Region
+	if err := decodeRegion(r, &region); err != nil {
+		response.HandleError(w, nil, http.StatusBadRequest, "некорректный JSON")
+		return
+	}
+	if err := h.regionUsecase.CreateRegion(r.Context(), &region); err != nil {
+		h.writeError(w, r, err, "ошибка создания региона")
+		return
+	}
+	response.WriteJSON(w, http.StatusCreated, toRegionResponse(&region))
+}
+
+// UpdateRegion — PUT /api/v1/regions/update/{id} with the whole region
+func (h *RegionHandler) UpdateRegion(w http.ResponseWriter, r *http.Request) {
+	id, ok := uuidPathParameter(w, r, "/api/v1/regions/update/")
+	if !ok {
+		return
+	}
+	region := domain.Region{ID: id}
+	if err := decodeRegion(r, &region); err != nil {
+		response.HandleError(w, nil, http.StatusBadRequest, "некорректный JSON")
+		return
+	}
+	if err := h.regionUsecase.UpdateRegion(r.Context(), &region); err != nil {
+		h.writeError(w, r, err, "ошибка обновления региона")
+		return
+	}
+	response.WriteJSON(w, http.StatusOK, toRegionResponse(&region))
+}
+
+// DeleteRegion — DELETE /api/v1/regions/delete/{id}
+func (h *RegionHandler) DeleteRegion(w http.ResponseWriter, r *http.Request) {
+	id, ok := uuidPathParameter(w, r, "/api/v1/regions/delete/")
+	if !ok {
+		return
+	}
+	if err := h.regionUsecase.DeleteRegion(r.Context(), id); err != nil {
+		h.writeError(w, r, err, "ошибка удаления региона")
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func decodeMetroLine(r *http.Request, line *domain.MetroLine) error {
+	var req MetroLineRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		return err
+	}
+	line.RegionID = req.RegionID
+	line.Name = req.Name
+	line.Color = req.Color
+	return nil
+}
+
+// CreateMetroLine — POST /api/v1/metro/lines/create
+func (h *RegionHandler) CreateMetroLine(w http.ResponseWriter, r *http.Request) {
+	var line domain.MetroLine
+	if err := decodeMetroLine(r, &line); err != nil {
+		response.HandleError(w, nil, http.StatusBadRequest, "некорректный JSON")
+		return
+	}
+	if err := h.regionUsecase.CreateMetroLine(r.Context(), &line); err != nil {
+		h.writeError(w, r, err, "ошибка создания линии метро")
+		return
+	}
+	response.WriteJSON(w, http.StatusCreated, toMetroLineResponse(&line))
+}
+
+// UpdateMetroLine — PUT /api/v1/metro/lines/update/{id}
+func (h *RegionHandler) UpdateMetroLine(w http.ResponseWriter, r *http.Request) {
+	id, ok := uuidPathParameter(w, r, "/api/v1/metro/lines/update/")
+	if !ok {
+		return
+	}
+	line := domain.MetroLine{ID: id}
+	if err := decodeMetroLine(r, &line); err != nil {
+		response.HandleError(w, nil, http.StatusBadRequest, "некорректный JSON")
+		return
+	}
+	if err := h.regionUsecase.UpdateMetroLine(r.Context(), &line); err != nil {
+		h.writeError(w, r, err, "ошибка обновления линии метро")
+		return
+	}
+	response.WriteJSON(w, http.StatusOK, toMetroLineResponse(&line))
+}
+
+// DeleteMetroLine — DELETE /api/v1/metro/lines/delete/{id}; its stations stay without a line
+func (h *RegionHandler) DeleteMetroLine(w http.ResponseWriter, r *http.Request) {
+	id, ok := uuidPathParameter(w, r, "/api/v1/metro/lines/delete/")
+	if !ok {
+		return
+	}
+	if err := h.regionUsecase.DeleteMetroLine(r.Context(), id); err != nil {
+		h.writeError(w, r, err, "ошибка удаления линии метро")
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func decodeMetroStation(r *http.Request, station *domain.MetroStation) error {
+	var req MetroStationRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		return err
+	}
+	station.Name = req.Name
+	station.RegionID = req.RegionID
+	station.Latitude = req.Latitude
+	station.Longitude = req.Longitude
+	if req.LineID != nil {
+		station.Line = &domain.MetroLine{ID: *req.LineID}
+	}
+	return nil
+}
+
+// CreateMetroStation — POST /api/v1/metro/stations/create
+func (h *RegionHandler) CreateMetroStation(w http.ResponseWriter, r *http.Request) {
+	var station domain.MetroStation
+	if err := decodeMetroStation(r, &station); err != nil {
+		response.HandleError(w, nil, http.StatusBadRequest, "некорректный JSON")
+		return
+	}
+	if err := h.regionUsecase.CreateMetroStation(r.Context(), &station); err != nil {
+		h.writeError(w, r, err, "ошибка создания станции метро")
+		return
+	}
+	response.WriteJSON(w, http.StatusCreated, toMetroStationResponse(&station))
+}
+
+// UpdateMetroStation — PUT /api/v1/metro/stations/update/{id}
+func (h *RegionHandler) UpdateMetroStation(w http.ResponseWriter, r *http.Request) {
+	id, ok := uuidPathParameter(w, r, "/api/v1/metro/stations/update/")
+	if !ok {
+		return
+	}
+	station := domain.MetroStation{ID: id}
+	if err := decodeMetroStation(r, &station); err != nil {
+		response.HandleError(w, nil, http.StatusBadRequest, "некорректный JSON")
+		return
+	}
+	if err := h.regionUsecase.UpdateMetroStation(r.Context(), &station); err != nil {
+		h.writeError(w, r, err, "ошибка обновления станции метро")
+		return
+	}
+	response.WriteJSON(w, http.StatusOK, toMetroStationResponse(&station))
+}
+
+// DeleteMetroStation — DELETE /api/v1/metro/stations/delete/{id}
+func (h *RegionHandler) DeleteMetroStation(w http.ResponseWriter, r *http.Request) {
+	id, ok := uuidPathParameter(w, r, "/api/v1/metro/stations/delete/")
+	if !ok {
+		return
+	}
+	if err := h.regionUsecase.DeleteMetroStation(r.Context(), id); err != nil {
+		h.writeError(w, r, err, "ошибка удаления станции метро")
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
@@ -0,0 +1,52 @@
+package handlers
+
+type RegionResponse struct {
+	ID       string  `json:"id"`
+	Name     string  `json:"name"`
+	ParentID *string `json:"parent_id"`
+	Level    int     `json:"level"`
+	Slug     string  `json:"slug"`
+}
+
+// RegionTreeResponse is the region with breadcrumbs from the root and its subregions
+type RegionTreeResponse struct {
+	RegionResponse
+	Path     []RegionResponse `json:"path"`
+	Children []RegionResponse `json:"children"`
+}
+
+type RegionRequest struct {
+	Name     string  `json:"name"`
+	ParentID *string `json:"parent_id,omitempty"` // omitted for a root
+	Slug     string  `json:"slug"`
+}
+
+type MetroLineResponse struct {
+	ID       string `json:"id"`
+	RegionID string `json:"region_id"`
+	Name     string `json:"name"`
+	Color    string `json:"color"`
+}
+
+type MetroLineRequest struct {
+	RegionID string `json:"region_id"`
+	Name     string `json:"name"`
+	Color    string `json:"color"` // #RRGGBB
+}
+
+type MetroStationResponse struct {
+	ID        string             `json:"id"`
+	Name      string             `json:"name"`
+	RegionID  string             `json:"region_id"`
+	Latitude  float64            `json:"latitude"`
+	Longitude float64            `json:"longitude"`
+	Line      *MetroLineResponse `json:"line"`
+}
+
+type MetroStationRequest struct {
+	Name      string  `json:"name"`
+	RegionID  string  `json:"region_id"`
+	Latitude  float64 `json:"latitude"`
+	Longitude float64 `json:"longitude"`
+	LineID    *string `json:"line_id,omitempty"`
+}
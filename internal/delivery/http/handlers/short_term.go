@@ -0,0 +1,277 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"time"
+
+	"github.com/go-park-mail-ru/2025_2_Avrora/internal/delivery/http/middleware"
+	"github.com/go-park-mail-ru/2025_2_Avrora/internal/delivery/http/response"
+	"github.com/go-park-mail-ru/2025_2_Avrora/internal/domain"
+	"github.com/go-park-mail-ru/2025_2_Avrora/internal/log"
+	"go.uber.org/zap"
+)
+
+type IShortTermUsecase interface {
+	GetCalendar(ctx context.Context, offerID string, from, to *time.Time) (*domain.Calendar, error)
+	SaveTerms(ctx context.Context, userID string, t *domain.ShortTermTerms) error
+	DeleteTerms(ctx context.Context, userID, offerID string) error
+	Block(ctx context.Context, userID string, b *domain.Booking) error
+	Book(ctx context.Context, userID string, b *domain.Booking) error
+	Cancel(ctx context.Context, userID, bookingID string) (*domain.Booking, error)
+	ListOfferBookings(ctx context.Context, userID, offerID string) ([]domain.Booking, error)
+	ListMyBookings(ctx context.Context, userID string, limit, offset int) ([]domain.Booking, error)
+}
+
+type ShortTermHandler struct {
+	shortTermUsecase IShortTermUsecase
+	logger           *log.Logger
+}
+
+func NewShortTermHandler(uc IShortTermUsecase, logger *log.Logger) *ShortTermHandler {
+	return &ShortTermHandler{shortTermUsecase: uc, logger: logger}
+}
+
+func (h *ShortTermHandler) writeError(w http.ResponseWriter, r *http.Request, err error, msg string) {
+	var verr domain.ValidationErrors
+	switch {
+	case errors.As(err, &verr):
+		response.HandleValidationError(w, "проверьте заполнение полей", fieldErrorsMap(verr))
+	case errors.Is(err, domain.ErrInvalidInput):
+		response.HandleError(w, err, http.StatusBadRequest, "невалидные данные")
+	case errors.Is(err, domain.ErrOfferNotFound):
+		response.HandleError(w, nil, http.StatusNotFound, "объявление не найдено")
+	case errors.Is(err, domain.ErrNotShortTerm):
+		response.HandleError(w, nil, http.StatusNotFound, "объявление не сдаётся посуточно")
+	case errors.Is(err, domain.ErrBookingNotFound):
+		response.HandleError(w, nil, http.StatusNotFound, "бронирование не найдено")
+	case errors.Is(err, domain.ErrForbidden):
+		response.HandleError(w, err, http.StatusForbidden, "нет доступа")
+	case errors.Is(err, domain.ErrDatesUnavailable):
+		response.HandleError(w, err, http.StatusConflict, "даты уже заняты")
+	case errors.Is(err, domain.ErrBookingOver):
+		response.HandleError(w, err, http.StatusConflict, "бронирование уже началось или отменено")
+	case errors.Is(err, domain.ErrShortTermHasGuests):
+		response.HandleError(w, err, http.StatusConflict, "есть предстоящие бронирования гостей")
+	default:
+		h.logger.Error(r.Context(), "short-term operation failed", zap.String("path", r.URL.Path), zap.Error(err))
+		response.HandleError(w, nil, http.StatusInternalServerError, msg)
+	}
+}
+
+func toShortTermTermsResponse(t *domain.ShortTermTerms) ShortTermTermsResponse {
+	return ShortTermTermsResponse{
+		OfferID:      t.OfferID,
+		NightlyPrice: t.NightlyPrice,
+		MinNights:    t.MinNights,
+		MaxNights:    t.MaxNights,
+		UpdatedAt:    t.UpdatedAt,
+	}
+}
+
+func toBookingResponse(b *domain.Booking) BookingResponse {
+	return BookingResponse{
+		ID:         b.ID,
+		OfferID:    b.OfferID,
+		Kind:       string(b.Kind),
+		GuestID:    b.GuestID,
+		CheckIn:    b.CheckIn.Format(time.DateOnly),
+		CheckOut:   b.CheckOut.Format(time.DateOnly),
+		Nights:     b.Nights(),
+		TotalPrice: b.TotalPrice,
+		Note:       b.Note,
+		Status:     string(b.Status),
+		CreatedAt:  b.CreatedAt,
+		UpdatedAt:  b.UpdatedAt,
+	}
+}
+
+func toBookingsResponse(bookings []domain.Booking) []BookingResponse {
+	resp := make([]BookingResponse, 0, len(bookings))
+	for i := range bookings {
+		resp = append(resp, toBookingResponse(&bookings[i]))
+	}
+	return resp
+}
+
+// decodeBooking reads the stay; dates that don't parse stay zero and are
+// reported by validation
+func decodeBooking(r *http.Request, b *domain.Booking) error {
+	var req BookingRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		return err
+	}
+	b.CheckIn, _ = time.Parse(time.DateOnly, req.CheckIn)
+	b.CheckOut, _ = time.Parse(time.DateOnly, req.CheckOut)
+	b.Note = req.Note
+	return nil
+}
+
+// GetCalendar — GET /api/v1/offers/calendar/{offer_id}?from=ГГГГ-ММ-ДД&to=ГГГГ-ММ-ДД,
+// two months from today by default
+func (h *ShortTermHandler) GetCalendar(w http.ResponseWriter, r *http.Request) {
+	id, ok := uuidPathParameter(w, r, "/api/v1/offers/calendar/")
+	if !ok {
+		return
+	}
+	var bounds [2]*time.Time
+	for i, name := range []string{"from", "to"} {
+		v := r.URL.Query().Get(name)
+		if v == "" {
+			continue
+		}
+		t, err := time.Parse(time.DateOnly, v)
+		if err != nil {
+			response.HandleError(w, err, http.StatusBadRequest, "некорректный параметр "+name+", ожидается ГГГГ-ММ-ДД")
+			return
+		}
+		bounds[i] = &t
+	}
+
+	calendar, err := h.shortTermUsecase.GetCalendar(r.Context(), id, bounds[0], bounds[1])
+	if err != nil {
+		h.writeError(w, r, err, "ошибка получения календаря")
+		return
+	}
+	days := make([]CalendarDayResponse, 0, len(calendar.Days))
+	for _, d := range calendar.Days {
+		days = append(days, CalendarDayResponse{Date: d.Date.Format(time.DateOnly), Status: string(d.Status)})
+	}
+	response.WriteJSON(w, http.StatusOK, CalendarResponse{Terms: toShortTermTermsResponse(&calendar.Terms), Days: days})
+}
+
+// SaveTerms — PUT /api/v1/offers/shortterm/update/{offer_id}, lets a rent
+// offer out by the night
+func (h *ShortTermHandler) SaveTerms(w http.ResponseWriter, r *http.Request) {
+	id, ok := uuidPathParameter(w, r, "/api/v1/offers/shortterm/update/")
+	if !ok {
+		return
+	}
+	var req ShortTermTermsRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		response.HandleError(w, nil, http.StatusBadRequest, "некорректный JSON")
+		return
+	}
+	terms := domain.ShortTermTerms{OfferID: id, NightlyPrice: req.NightlyPrice, MinNights: req.MinNights, MaxNights: req.MaxNights}
+
+	userID, _ := middleware.GetUserIDFromContext(r.Context())
+	if err := h.shortTermUsecase.SaveTerms(r.Context(), userID, &terms); err != nil {
+		h.writeError(w, r, err, "ошибка сохранения условий посуточной аренды")
+		return
+	}
+	response.WriteJSON(w, http.StatusOK, toShortTermTermsResponse(&terms))
+}
+
+// DeleteTerms — DELETE /api/v1/offers/shortterm/delete/{offer_id}
+func (h *ShortTermHandler) DeleteTerms(w http.ResponseWriter, r *http.Request) {
+	id, ok := uuidPathParameter(w, r, "/api/v1/offers/shortterm/delete/")
+	if !ok {
+		return
+	}
+
+	userID, _ := middleware.GetUserIDFromContext(r.Context())
+	if err := h.shortTermUsecase.DeleteTerms(r.Context(), userID, id); err != nil {
+		h.writeError(w, r, err, "ошибка отключения посуточной аренды")
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// BlockDates — POST /api/v1/offers/calendar/block/{offer_id}, closes nights
+// for guests; cancelling the booking opens them again
+func (h *ShortTermHandler) BlockDates(w http.ResponseWriter, r *http.Request) {
+	id, ok := uuidPathParameter(w, r, "/api/v1/offers/calendar/block/")
+	if !ok {
+		return
+	}
+	booking := domain.Booking{OfferID: id}
+	if err := decodeBooking(r, &booking); err != nil {
+		response.HandleError(w, nil, http.StatusBadRequest, "некорректный JSON")
+		return
+	}
+
+	userID, _ := middleware.GetUserIDFromContext(r.Context())
+	if err := h.shortTermUsecase.Block(r.Context(), userID, &booking); err != nil {
+		h.writeError(w, r, err, "ошибка блокировки дат")
+		return
+	}
+	response.WriteJSON(w, http.StatusCreated, toBookingResponse(&booking))
+}
+
+// CreateBooking — POST /api/v1/offers/bookings/create/{offer_id}
+func (h *ShortTermHandler) CreateBooking(w http.ResponseWriter, r *http.Request) {
+	id, ok := uuidPathParameter(w, r, "/api/v1/offers/bookings/create/")
+	if !ok {
+		return
+	}
+	booking := domain.Booking{OfferID: id}
+	if err := decodeBooking(r, &booking); err != nil {
+		response.HandleError(w, nil, http.StatusBadRequest, "некорректный JSON")
+		return
+	}
+
+	userID, _ := middleware.GetUserIDFromContext(r.Context())
+	if err := h.shortTermUsecase.Book(r.Context(), userID, &booking); err != nil {
+		h.writeError(w, r, err, "ошибка бронирования")
+		return
+	}
+	response.WriteJSON(w, http.StatusCreated, toBookingResponse(&booking))
+}
+
+// CancelBooking — POST /api/v1/offers/bookings/cancel/{booking_id}, by the
+// guest or the owner
+func (h *ShortTermHandler) CancelBooking(w http.ResponseWriter, r *http.Request) {
+	id, ok := uuidPathParameter(w, r, "/api/v1/offers/bookings/cancel/")
+	if !ok {
+		return
+	}
+
+	userID, _ := middleware.GetUserIDFromContext(r.Context())
+	booking, err := h.shortTermUsecase.Cancel(r.Context(), userID, id)
+	if err != nil {
+		h.writeError(w, r, err, "ошибка отмены бронирования")
+		return
+	}
+	response.WriteJSON(w, http.StatusOK, toBookingResponse(booking))
+}
+
+// ListOfferBookings — GET /api/v1/offers/bookings/{offer_id}, current and
+// upcoming bookings and blocked dates for the owner
+func (h *ShortTermHandler) ListOfferBookings(w http.ResponseWriter, r *http.Request) {
+	id, ok := uuidPathParameter(w, r, "/api/v1/offers/bookings/")
+	if !ok {
+		return
+	}
+
+	userID, _ := middleware.GetUserIDFromContext(r.Context())
+	bookings, err := h.shortTermUsecase.ListOfferBookings(r.Context(), userID, id)
+	if err != nil {
+		h.writeError(w, r, err, "ошибка получения бронирований")
+		return
+	}
+	response.WriteJSON(w, http.StatusOK, toBookingsResponse(bookings))
+}
+
+// ListMyBookings — GET /api/v1/bookings/my?limit=20&offset=0
+func (h *ShortTermHandler) ListMyBookings(w http.ResponseWriter, r *http.Request) {
+	limit, err := parseIntQueryParam(r, "limit", 20)
+	if err != nil {
+		response.HandleError(w, err, http.StatusBadRequest, "некорректный limit")
+		return
+	}
+	offset, err := parseIntQueryParam(r, "offset", 0)
+	if err != nil {
+		response.HandleError(w, err, http.StatusBadRequest, "некорректный offset")
+		return
+	}
+
+	userID, _ := middleware.GetUserIDFromContext(r.Context())
+	bookings, err := h.shortTermUsecase.ListMyBookings(r.Context(), userID, limit, offset)
+	if err != nil {
+		h.writeError(w, r, err, "ошибка получения бронирований")
+		return
+	}
+	response.WriteJSON(w, http.StatusOK, toBookingsResponse(bookings))
+}
@@ -0,0 +1,52 @@
+package handlers
+
+import "time"
+
+// ShortTermTermsRequest lets the offer out by the night
+type ShortTermTermsRequest struct {
+	NightlyPrice int64 `json:"nightly_price"`
+	MinNights    int   `json:"min_nights"`
+	MaxNights    *int  `json:"max_nights,omitempty"`
+}
+
+type ShortTermTermsResponse struct {
+	OfferID      string    `json:"offer_id"`
+	NightlyPrice int64     `json:"nightly_price"`
+	MinNights    int       `json:"min_nights"`
+	MaxNights    *int      `json:"max_nights"`
+	UpdatedAt    time.Time `json:"updated_at"`
+}
+
+// CalendarDayResponse is one night; date is ГГГГ-ММ-ДД
+type CalendarDayResponse struct {
+	Date   string `json:"date"`
+	Status string `json:"status"`
+}
+
+type CalendarResponse struct {
+	Terms ShortTermTermsResponse `json:"terms"`
+	Days  []CalendarDayResponse  `json:"days"`
+}
+
+// BookingRequest holds the stay dates as ГГГГ-ММ-ДД; check_out is the day
+// of departure
+type BookingRequest struct {
+	CheckIn  string  `json:"check_in"`
+	CheckOut string  `json:"check_out"`
+	Note     *string `json:"note,omitempty"`
+}
+
+type BookingResponse struct {
+	ID         string    `json:"id"`
+	OfferID    string    `json:"offer_id"`
+	Kind       string    `json:"kind"`
+	GuestID    *string   `json:"guest_id"`
+	CheckIn    string    `json:"check_in"`
+	CheckOut   string    `json:"check_out"`
+	Nights     int       `json:"nights"`
+	TotalPrice *int64    `json:"total_price"`
+	Note       *string   `json:"note"`
+	Status     string    `json:"status"`
+	CreatedAt  time.Time `json:"created_at"`
+	UpdatedAt  time.Time `json:"updated_at"`
+}
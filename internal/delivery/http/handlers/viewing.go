@@ -0,0 +1,387 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/go-park-mail-ru/2025_2_Avrora/internal/delivery/http/middleware"
+	"github.com/go-park-mail-ru/2025_2_Avrora/internal/delivery/http/response"
+	"github.com/go-park-mail-ru/2025_2_Avrora/internal/delivery/http/utils"
+	"github.com/go-park-mail-ru/2025_2_Avrora/internal/domain"
+	"github.com/go-park-mail-ru/2025_2_Avrora/internal/log"
+	"go.uber.org/zap"
+)
+
+const (
+	viewingICSPrefix = "/api/v1/viewings/ics/"
+	viewingFeedPath  = "/api/v1/viewings/feed.ics"
+	// viewingFeedName is what feed links are signed for; the colon keeps it
+	// apart from the names of private files
+	viewingFeedName = "feed:viewings"
+	viewingFeedTTL  = 365 * 24 * time.Hour
+)
+
+type IViewingUsecase interface {
+	ListSlots(ctx context.Context, offerID string) ([]domain.ViewingSlot, error)
+	CreateSlot(ctx context.Context, userID string, s *domain.ViewingSlot) error
+	DeleteSlot(ctx context.Context, userID, slotID string) error
+	Request(ctx context.Context, userID, slotID string, comment *string) (*domain.Viewing, error)
+	Get(ctx context.Context, userID, id string) (*domain.Viewing, error)
+	List(ctx context.Context, userID string, asOwner bool, limit, offset int) ([]domain.Viewing, error)
+	CalendarFeed(ctx context.Context, userID string) ([]domain.Viewing, error)
+	Confirm(ctx context.Context, userID, id string) (*domain.Viewing, error)
+	Reschedule(ctx context.Context, userID, id, slotID string) (*domain.Viewing, error)
+	Cancel(ctx context.Context, userID, id string, reason *string) (*domain.Viewing, error)
+}
+
+type ViewingHandler struct {
+	viewingUsecase IViewingUsecase
+	signer         *utils.URLSigner // signs calendar feed links
+	logger         *log.Logger
+	baseURL        string
+}
+
+func NewViewingHandler(uc IViewingUsecase, signer *utils.URLSigner, logger *log.Logger, baseURL string) *ViewingHandler {
+	return &ViewingHandler{viewingUsecase: uc, signer: signer, logger: logger, baseURL: baseURL}
+}
+
+func (h *ViewingHandler) writeError(w http.ResponseWriter, r *http.Request, err error, msg string) {
+	var verr domain.ValidationErrors
+	switch {
+	case errors.As(err, &verr):
+		response.HandleValidationError(w, "проверьте заполнение полей", fieldErrorsMap(verr))
+	case errors.Is(err, domain.ErrInvalidInput):
+		response.HandleError(w, err, http.StatusBadRequest, "невалидные данные")
+	case errors.Is(err, domain.ErrOfferNotFound):
+		response.HandleError(w, nil, http.StatusNotFound, "объявление не найдено")
+	case errors.Is(err, domain.ErrViewingSlotNotFound):
+		response.HandleError(w, nil, http.StatusNotFound, "время просмотра не найдено")
+	case errors.Is(err, domain.ErrViewingNotFound):
+		response.HandleError(w, nil, http.StatusNotFound, "просмотр не найден")
+	case errors.Is(err, domain.ErrForbidden):
+		response.HandleError(w, err, http.StatusForbidden, "нет доступа")
+	case errors.Is(err, domain.ErrViewingSlotTaken):
+		response.HandleError(w, err, http.StatusConflict, "это время уже занято")
+	case errors.Is(err, domain.ErrViewingSlotOverlap):
+		response.HandleError(w, err, http.StatusConflict, "пересекается с другим временем просмотра")
+	case errors.Is(err, domain.ErrViewingClosed):
+		response.HandleError(w, err, http.StatusConflict, "просмотр уже прошёл, отменён или подтверждён")
+	default:
+		h.logger.Error(r.Context(), "viewing operation failed", zap.String("path", r.URL.Path), zap.Error(err))
+		response.HandleError(w, nil, http.StatusInternalServerError, msg)
+	}
+}
+
+func toViewingSlotResponse(s *domain.ViewingSlot) ViewingSlotResponse {
+	return ViewingSlotResponse{ID: s.ID, OfferID: s.OfferID, StartsAt: s.StartsAt, EndsAt: s.EndsAt, Free: s.Free}
+}
+
+func (h *ViewingHandler) toViewingResponse(v *domain.Viewing) ViewingResponse {
+	return ViewingResponse{
+		ID:           v.ID,
+		OfferID:      v.OfferID,
+		OfferTitle:   v.OfferTitle,
+		OfferAddress: v.OfferAddress,
+		SlotID:       v.SlotID,
+		BuyerID:      v.BuyerID,
+		OwnerID:      v.OwnerID,
+		StartsAt:     v.StartsAt,
+		EndsAt:       v.EndsAt,
+		Status:       string(v.Status),
+		Comment:      v.Comment,
+		CancelReason: v.CancelReason,
+		CancelledBy:  v.CancelledBy,
+		ICSURL:       h.baseURL + viewingICSPrefix + v.ID,
+		CreatedAt:    v.CreatedAt,
+		UpdatedAt:    v.UpdatedAt,
+	}
+}
+
+func (h *ViewingHandler) writeViewing(w http.ResponseWriter, status int, v *domain.Viewing) {
+	response.WriteJSON(w, status, h.toViewingResponse(v))
+}
+
+var viewingICSStatuses = map[domain.ViewingStatus]string{
+	domain.ViewingStatusRequested: utils.ICSStatusTentative,
+	domain.ViewingStatusConfirmed: utils.ICSStatusConfirmed,
+	domain.ViewingStatusCancelled: utils.ICSStatusCancelled,
+}
+
+func (h *ViewingHandler) toICSEvent(v *domain.Viewing) utils.ICSEvent {
+	description := ""
+	if v.Comment != nil {
+		description = *v.Comment
+	}
+	return utils.ICSEvent{
+		UID:         v.ID + "@homa",
+		Summary:     "Просмотр: " + v.OfferTitle,
+		Description: description,
+		Location:    v.OfferAddress,
+		Start:       v.StartsAt,
+		End:         v.EndsAt,
+		Stamp:       v.UpdatedAt,
+		Status:      viewingICSStatuses[v.Status],
+		Sequence:    v.Sequence,
+	}
+}
+
+func writeICS(w http.ResponseWriter, body []byte, attachment string) {
+	w.Header().Set("Content-Type", "text/calendar; charset=utf-8")
+	w.Header().Set("Cache-Control", "private, no-store")
+	if attachment != "" {
+		w.Header().Set("Content-Disposition", fmt.Sprintf(`attachment; filename="%s"`, attachment))
+	}
+	w.WriteHeader(http.StatusOK)
+	w.Write(body)
+}
+
+// ListSlots — GET /api/v1/offers/viewings/slots/{offer_id}, upcoming slots
+func (h *ViewingHandler) ListSlots(w http.ResponseWriter, r *http.Request) {
+	id, ok := uuidPathParameter(w, r, "/api/v1/offers/viewings/slots/")
+	if !ok {
+		return
+	}
+
+	slots, err := h.viewingUsecase.ListSlots(r.Context(), id)
+	if err != nil {
+		h.writeError(w, r, err, "ошибка получения времени просмотров")
+		return
+	}
+	resp := make([]ViewingSlotResponse, 0, len(slots))
+	for i := range slots {
+		resp = append(resp, toViewingSlotResponse(&slots[i]))
+	}
+	response.WriteJSON(w, http.StatusOK, resp)
+}
+
+// CreateSlot — POST /api/v1/offers/viewings/slots/create/{offer_id}
+func (h *ViewingHandler) CreateSlot(w http.ResponseWriter, r *http.Request) {
+	id, ok := uuidPathParameter(w, r, "/api/v1/offers/viewings/slots/create/")
+	if !ok {
+		return
+	}
+	var req ViewingSlotRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		response.HandleError(w, nil, http.StatusBadRequest, "некорректный JSON")
+		return
+	}
+	slot := domain.ViewingSlot{OfferID: id, StartsAt: req.StartsAt, EndsAt: req.EndsAt}
+
+	userID, _ := middleware.GetUserIDFromContext(r.Context())
+	if err := h.viewingUsecase.CreateSlot(r.Context(), userID, &slot); err != nil {
+		h.writeError(w, r, err, "ошибка создания времени просмотра")
+		return
+	}
+	response.WriteJSON(w, http.StatusCreated, toViewingSlotResponse(&slot))
+}
+
+// DeleteSlot — DELETE /api/v1/offers/viewings/slots/delete/{slot_id}
+func (h *ViewingHandler) DeleteSlot(w http.ResponseWriter, r *http.Request) {
+	id, ok := uuidPathParameter(w, r, "/api/v1/offers/viewings/slots/delete/")
+	if !ok {
+		return
+	}
+
+	userID, _ := middleware.GetUserIDFromContext(r.Context())
+	if err := h.viewingUsecase.DeleteSlot(r.Context(), userID, id); err != nil {
+		h.writeError(w, r, err, "ошибка удаления времени просмотра")
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// RequestViewing — POST /api/v1/offers/viewings/request/{slot_id}
+func (h *ViewingHandler) RequestViewing(w http.ResponseWriter, r *http.Request) {
+	id, ok := uuidPathParameter(w, r, "/api/v1/offers/viewings/request/")
+	if !ok {
+		return
+	}
+	var req ViewingRequestRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		response.HandleError(w, nil, http.StatusBadRequest, "некорректный JSON")
+		return
+	}
+
+	userID, _ := middleware.GetUserIDFromContext(r.Context())
+	viewing, err := h.viewingUsecase.Request(r.Context(), userID, id, req.Comment)
+	if err != nil {
+		h.writeError(w, r, err, "ошибка записи на просмотр")
+		return
+	}
+	h.writeViewing(w, http.StatusCreated, viewing)
+}
+
+// ListViewings — GET /api/v1/viewings/my?as=owner&limit=20&offset=0; without
+// as=owner the viewings the user requested
+func (h *ViewingHandler) ListViewings(w http.ResponseWriter, r *http.Request) {
+	limit, err := parseIntQueryParam(r, "limit", 20)
+	if err != nil {
+		response.HandleError(w, err, http.StatusBadRequest, "некорректный limit")
+		return
+	}
+	offset, err := parseIntQueryParam(r, "offset", 0)
+	if err != nil {
+		response.HandleError(w, err, http.StatusBadRequest, "некорректный offset")
+		return
+	}
+	var asOwner bool
+	switch r.URL.Query().Get("as") {
+	case "", "buyer":
+	case "owner":
+		asOwner = true
+	default:
+		response.HandleError(w, nil, http.StatusBadRequest, "параметр as: buyer или owner")
+		return
+	}
+
+	userID, _ := middleware.GetUserIDFromContext(r.Context())
+	viewings, err := h.viewingUsecase.List(r.Context(), userID, asOwner, limit, offset)
+	if err != nil {
+		h.writeError(w, r, err, "ошибка получения просмотров")
+		return
+	}
+	resp := make([]ViewingResponse, 0, len(viewings))
+	for i := range viewings {
+		resp = append(resp, h.toViewingResponse(&viewings[i]))
+	}
+	response.WriteJSON(w, http.StatusOK, resp)
+}
+
+// GetViewing — GET /api/v1/viewings/{id}
+func (h *ViewingHandler) GetViewing(w http.ResponseWriter, r *http.Request) {
+	id, ok := uuidPathParameter(w, r, "/api/v1/viewings/")
+	if !ok {
+		return
+	}
+
+	userID, _ := middleware.GetUserIDFromContext(r.Context())
+	viewing, err := h.viewingUsecase.Get(r.Context(), userID, id)
+	if err != nil {
+		h.writeError(w, r, err, "ошибка получения просмотра")
+		return
+	}
+	h.writeViewing(w, http.StatusOK, viewing)
+}
+
+// ConfirmViewing — POST /api/v1/viewings/confirm/{id}, by the owner
+func (h *ViewingHandler) ConfirmViewing(w http.ResponseWriter, r *http.Request) {
+	id, ok := uuidPathParameter(w, r, "/api/v1/viewings/confirm/")
+	if !ok {
+		return
+	}
+
+	userID, _ := middleware.GetUserIDFromContext(r.Context())
+	viewing, err := h.viewingUsecase.Confirm(r.Context(), userID, id)
+	if err != nil {
+		h.writeError(w, r, err, "ошибка подтверждения просмотра")
+		return
+	}
+	h.writeViewing(w, http.StatusOK, viewing)
+}
+
+// RescheduleViewing — POST /api/v1/viewings/reschedule/{id} {"slot_id": ...},
+// by the owner, to another slot of the offer
+func (h *ViewingHandler) RescheduleViewing(w http.ResponseWriter, r *http.Request) {
+	id, ok := uuidPathParameter(w, r, "/api/v1/viewings/reschedule/")
+	if !ok {
+		return
+	}
+	var req ViewingRescheduleRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		response.HandleError(w, nil, http.StatusBadRequest, "некорректный JSON")
+		return
+	}
+
+	userID, _ := middleware.GetUserIDFromContext(r.Context())
+	viewing, err := h.viewingUsecase.Reschedule(r.Context(), userID, id, req.SlotID)
+	if err != nil {
+		h.writeError(w, r, err, "ошибка переноса просмотра")
+		return
+	}
+	h.writeViewing(w, http.StatusOK, viewing)
+}
+
+// CancelViewing — POST /api/v1/viewings/cancel/{id}, by either side
+func (h *ViewingHandler) CancelViewing(w http.ResponseWriter, r *http.Request) {
+	id, ok := uuidPathParameter(w, r, "/api/v1/viewings/cancel/")
+	if !ok {
+		return
+	}
+	var req ViewingCancelRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		response.HandleError(w, nil, http.StatusBadRequest, "некорректный JSON")
+		return
+	}
+
+	userID, _ := middleware.GetUserIDFromContext(r.Context())
+	viewing, err := h.viewingUsecase.Cancel(r.Context(), userID, id, req.Reason)
+	if err != nil {
+		h.writeError(w, r, err, "ошибка отмены просмотра")
+		return
+	}
+	h.writeViewing(w, http.StatusOK, viewing)
+}
+
+// GetViewingICS — GET /api/v1/viewings/ics/{id}, the viewing as an .ics
+// attachment
+func (h *ViewingHandler) GetViewingICS(w http.ResponseWriter, r *http.Request) {
+	id, ok := uuidPathParameter(w, r, viewingICSPrefix)
+	if !ok {
+		return
+	}
+
+	userID, _ := middleware.GetUserIDFromContext(r.Context())
+	viewing, err := h.viewingUsecase.Get(r.Context(), userID, id)
+	if err != nil {
+		h.writeError(w, r, err, "ошибка получения просмотра")
+		return
+	}
+	body := utils.BuildICS("Просмотр", []utils.ICSEvent{h.toICSEvent(viewing)})
+	writeICS(w, body, "viewing-"+viewing.ID+".ics")
+}
+
+// GetFeedLink — GET /api/v1/viewings/feed/link, a signed link to the user's
+// viewings feed for calendar apps, which can't send the auth token
+func (h *ViewingHandler) GetFeedLink(w http.ResponseWriter, r *http.Request) {
+	userID, _ := middleware.GetUserIDFromContext(r.Context())
+	expiresAt := time.Now().Add(viewingFeedTTL)
+	q := h.signer.SignQuery(viewingFeedName, expiresAt, userID)
+	response.WriteJSON(w, http.StatusOK, ViewingFeedResponse{
+		URL:       fmt.Sprintf("%s%s?%s", h.baseURL, viewingFeedPath, q.Encode()),
+		ExpiresAt: expiresAt,
+	})
+}
+
+// GetFeed — GET /api/v1/viewings/feed.ics?expires=...&uid=...&sig=..., the
+// viewings of both sides of the user from a month ago on
+func (h *ViewingHandler) GetFeed(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	userID, err := h.signer.Verify(viewingFeedName, r.URL.Query(), time.Now())
+	if err == nil && userID == "" {
+		err = utils.ErrSignatureInvalid
+	}
+	if err != nil {
+		h.logger.Warn(ctx, "rejected viewings feed request", zap.Error(err))
+		if errors.Is(err, utils.ErrSignatureExpired) {
+			response.HandleError(w, err, http.StatusForbidden, "срок действия ссылки истёк")
+			return
+		}
+		response.HandleError(w, err, http.StatusForbidden, "недействительная ссылка")
+		return
+	}
+
+	viewings, err := h.viewingUsecase.CalendarFeed(ctx, userID)
+	if err != nil {
+		h.writeError(w, r, err, "ошибка получения календаря просмотров")
+		return
+	}
+	events := make([]utils.ICSEvent, 0, len(viewings))
+	for i := range viewings {
+		events = append(events, h.toICSEvent(&viewings[i]))
+	}
+	writeICS(w, utils.BuildICS("Просмотры Homa", events), "")
+}
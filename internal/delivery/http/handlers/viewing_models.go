@@ -0,0 +1,54 @@
+package handlers
+
+import "time"
+
+// ViewingSlotRequest opens a time for viewings, times in RFC 3339
+type ViewingSlotRequest struct {
+	StartsAt time.Time `json:"starts_at"`
+	EndsAt   time.Time `json:"ends_at"`
+}
+
+type ViewingSlotResponse struct {
+	ID       string    `json:"id"`
+	OfferID  string    `json:"offer_id"`
+	StartsAt time.Time `json:"starts_at"`
+	EndsAt   time.Time `json:"ends_at"`
+	Free     bool      `json:"free"`
+}
+
+type ViewingRequestRequest struct {
+	Comment *string `json:"comment,omitempty"`
+}
+
+type ViewingRescheduleRequest struct {
+	SlotID string `json:"slot_id"`
+}
+
+type ViewingCancelRequest struct {
+	Reason *string `json:"reason,omitempty"`
+}
+
+type ViewingResponse struct {
+	ID           string    `json:"id"`
+	OfferID      string    `json:"offer_id"`
+	OfferTitle   string    `json:"offer_title"`
+	OfferAddress string    `json:"offer_address"`
+	SlotID       *string   `json:"slot_id"`
+	BuyerID      string    `json:"buyer_id"`
+	OwnerID      string    `json:"owner_id"`
+	StartsAt     time.Time `json:"starts_at"`
+	EndsAt       time.Time `json:"ends_at"`
+	Status       string    `json:"status"`
+	Comment      *string   `json:"comment"`
+	CancelReason *string   `json:"cancel_reason"`
+	CancelledBy  *string   `json:"cancelled_by"`
+	ICSURL       string    `json:"ics_url"` // the viewing as an .ics attachment
+	CreatedAt    time.Time `json:"created_at"`
+	UpdatedAt    time.Time `json:"updated_at"`
+}
+
+// ViewingFeedResponse is the link calendar apps subscribe to
+type ViewingFeedResponse struct {
+	URL       string    `json:"url"`
+	ExpiresAt time.Time `json:"expires_at"`
+}
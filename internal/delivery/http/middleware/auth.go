@@ -4,15 +4,14 @@ import (
 	"context"
 	"net/http"
 
+	"github.com/go-park-mail-ru/2025_2_Avrora/internal/ctxkeys"
 	"github.com/go-park-mail-ru/2025_2_Avrora/internal/delivery/http/response"
 	"github.com/go-park-mail-ru/2025_2_Avrora/internal/delivery/http/utils"
 	"github.com/go-park-mail-ru/2025_2_Avrora/internal/log"
 	"go.uber.org/zap"
 )
 
-type contextKey string
-
-const UserContextKey contextKey = "userID"
+const UserContextKey = ctxkeys.UserIDKey
 
 func AuthMiddleware(logger *log.Logger, jwtGen *utils.JwtGenerator) func(http.Handler) http.Handler {
 	return func(next http.Handler) http.Handler {
@@ -45,7 +44,31 @@ func AuthMiddleware(logger *log.Logger, jwtGen *utils.JwtGenerator) func(http.Ha
 	}
 }
 
+// OptionalAuthMiddleware puts the user into the context when a valid token is
+// present but lets anonymous requests through
+func OptionalAuthMiddleware(logger *log.Logger, jwtGen *utils.JwtGenerator) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			const bearerPrefix = "Bearer "
+			authHeader := r.Header.Get("Authorization")
+			if len(authHeader) <= len(bearerPrefix) || authHeader[:len(bearerPrefix)] != bearerPrefix {
+				next.ServeHTTP(w, r)
+				return
+			}
+
+			userID, err := jwtGen.ValidateJWT(authHeader[len(bearerPrefix):])
+			if err != nil {
+				logger.Warn(r.Context(), "ignoring invalid token on optional auth route", zap.Error(err))
+				next.ServeHTTP(w, r)
+				return
+			}
+
+			ctx := context.WithValue(r.Context(), UserContextKey, userID)
+			next.ServeHTTP(w, r.WithContext(ctx))
+		})
+	}
+}
+
 func GetUserIDFromContext(ctx context.Context) (string, bool) {
-	userID, ok := ctx.Value(UserContextKey).(string)
-	return userID, ok
+	return ctxkeys.UserID(ctx)
 }
\ No newline at end of file
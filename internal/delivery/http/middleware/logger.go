@@ -1,9 +1,7 @@
 package middleware
 
 import (
-	"net"
 	"net/http"
-	"strings"
 	"time"
 
 	request_id "github.com/go-park-mail-ru/2025_2_Avrora/internal/delivery/http/middleware/request"
@@ -27,7 +25,7 @@ func LoggerMiddleware(logger *log.Logger) func(http.Handler) http.Handler {
 
 			duration := time.Since(start)
 
-			ip := getClientIP(r)
+			ip := ClientIP(r)
 
 			remoteAddr := r.RemoteAddr
 
@@ -57,19 +55,6 @@ func LoggerMiddleware(logger *log.Logger) func(http.Handler) http.Handler {
 	}
 }
 
-func getClientIP(r *http.Request) string {
-	for _, h := range []string{"X-Forwarded-For", "X-Real-IP"} {
-		if ip := r.Header.Get(h); ip != "" {
-			if idx := strings.Index(ip, ","); idx != -1 {
-				ip = ip[:idx]
-			}
-			return strings.TrimSpace(ip)
-		}
-	}
-	host, _, _ := net.SplitHostPort(r.RemoteAddr)
-	return host
-}
-
 type responseWriter struct {
 	http.ResponseWriter
 	statusCode int
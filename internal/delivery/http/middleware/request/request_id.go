@@ -2,13 +2,12 @@ package request_id
 
 import (
 	"context"
+	"github.com/go-park-mail-ru/2025_2_Avrora/internal/ctxkeys"
 	"github.com/google/uuid"
 	"net/http"
 )
 
-type contextKey string
-
-const RequestIDKey contextKey = "request_id"
+const RequestIDKey = ctxkeys.RequestIDKey
 
 func RequestIDMiddleware(next http.Handler) http.Handler {
 	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
@@ -0,0 +1,46 @@
+package middleware
+
+import (
+	"context"
+	"net/http"
+	"slices"
+
+	"github.com/go-park-mail-ru/2025_2_Avrora/internal/delivery/http/response"
+	"github.com/go-park-mail-ru/2025_2_Avrora/internal/domain"
+	"github.com/go-park-mail-ru/2025_2_Avrora/internal/log"
+	"go.uber.org/zap"
+)
+
+type IUserLookup interface {
+	GetUserByUserID(ctx context.Context, userID string) (*domain.User, error)
+}
+
+// RoleMiddleware lets through only users with one of the allowed roles.
+// Must run after AuthMiddleware. The role is read from the database on every
+// request so that revoking it takes effect immediately.
+func RoleMiddleware(logger *log.Logger, users IUserLookup, allowed ...domain.UserRole) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			userID, ok := GetUserIDFromContext(r.Context())
+			if !ok {
+				response.HandleError(w, nil, http.StatusUnauthorized, "требуется авторизация")
+				return
+			}
+
+			user, err := users.GetUserByUserID(r.Context(), userID)
+			if err != nil {
+				logger.Error(r.Context(), "failed to load user role", zap.String("user_id", userID), zap.Error(err))
+				response.HandleError(w, nil, http.StatusForbidden, "недостаточно прав")
+				return
+			}
+
+			if !slices.Contains(allowed, user.Role) {
+				logger.Warn(r.Context(), "role not allowed", zap.String("user_id", userID), zap.String("role", string(user.Role)))
+				response.HandleError(w, nil, http.StatusForbidden, "недостаточно прав")
+				return
+			}
+
+			next.ServeHTTP(w, r)
+		})
+	}
+}
@@ -27,4 +27,14 @@ func WriteJSON(w http.ResponseWriter, status int, v any) {
 func HandleError(w http.ResponseWriter, err error, status int, userMessage string) {
 	log.Printf("[ERROR] %s: %v", userMessage, err)
 	WriteJSON(w, status, NewErrorResp(userMessage))
-}
\ No newline at end of file
+}
+
+// ValidationErrorResp carries a message per invalid field, keyed by its JSON name
+type ValidationErrorResp struct {
+	Error  string            `json:"error"`
+	Fields map[string]string `json:"fields"`
+}
+
+func HandleValidationError(w http.ResponseWriter, userMessage string, fields map[string]string) {
+	WriteJSON(w, http.StatusUnprocessableEntity, ValidationErrorResp{Error: userMessage, Fields: fields})
+}
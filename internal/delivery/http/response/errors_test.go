@@ -56,3 +56,24 @@ func TestHandleError_Basic(t *testing.T) {
 		t.Errorf("expected error 'ошибка пользователя', got '%s'", resp.Error)
 	}
 }
+
+func TestHandleValidationError(t *testing.T) {
+	rec := httptest.NewRecorder()
+	HandleValidationError(rec, "проверьте поля", map[string]string{"price": "цена должна быть больше нуля"})
+
+	if rec.Code != http.StatusUnprocessableEntity {
+		t.Errorf("expected status %d, got %d", http.StatusUnprocessableEntity, rec.Code)
+	}
+
+	var resp ValidationErrorResp
+	if err := json.NewDecoder(rec.Body).Decode(&resp); err != nil {
+		t.Fatalf("failed to decode response: %v", err)
+	}
+
+	if resp.Error != "проверьте поля" {
+		t.Errorf("expected error 'проверьте поля', got '%s'", resp.Error)
+	}
+	if resp.Fields["price"] != "цена должна быть больше нуля" {
+		t.Errorf("unexpected field errors: %v", resp.Fields)
+	}
+}
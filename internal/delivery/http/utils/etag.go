@@ -0,0 +1,32 @@
+package utils
+
+import (
+	"errors"
+	"strconv"
+	"strings"
+)
+
+var ErrBadIfMatch = errors.New("malformed If-Match header")
+
+// FormatETag renders a row version as a strong entity tag
+func FormatETag(version int) string {
+	return `"` + strconv.Itoa(version) + `"`
+}
+
+// ParseIfMatch reads the version a client expects from an If-Match header.
+// "*" matches any version and gives nil. Weak tags never match under the
+// strong comparison If-Match requires, so they are rejected like lists.
+func ParseIfMatch(header string) (*int, error) {
+	header = strings.TrimSpace(header)
+	if header == "*" {
+		return nil, nil
+	}
+	if len(header) < 2 || header[0] != '"' || header[len(header)-1] != '"' {
+		return nil, ErrBadIfMatch
+	}
+	version, err := strconv.Atoi(header[1 : len(header)-1])
+	if err != nil || version < 0 {
+		return nil, ErrBadIfMatch
+	}
+	return &version, nil
+}
@@ -0,0 +1,21 @@
+package utils
+
+import "testing"
+
+func TestParseIfMatch(t *testing.T) {
+	v, err := ParseIfMatch(FormatETag(7))
+	if err != nil || v == nil || *v != 7 {
+		t.Fatalf("ожидалась версия 7, получили %v, %v", v, err)
+	}
+
+	v, err = ParseIfMatch(" * ")
+	if err != nil || v != nil {
+		t.Errorf("* должен совпадать с любой версией, получили %v, %v", v, err)
+	}
+
+	for _, header := range []string{"", "7", `W/"7"`, `"7", "8"`, `"abc"`, `"-1"`, `"`} {
+		if _, err := ParseIfMatch(header); err != ErrBadIfMatch {
+			t.Errorf("%q: ожидалась ErrBadIfMatch, получили %v", header, err)
+		}
+	}
+}
@@ -0,0 +1,103 @@
+package utils
+
+import (
+	"strconv"
+	"strings"
+	"time"
+	"unicode/utf8"
+)
+
+// Event statuses of RFC 5545
+const (
+	ICSStatusTentative = "TENTATIVE"
+	ICSStatusConfirmed = "CONFIRMED"
+	ICSStatusCancelled = "CANCELLED"
+)
+
+// ICSEvent is one VEVENT of an iCalendar. Calendar clients match updates by
+// UID and take the one with the highest Sequence.
+type ICSEvent struct {
+	UID         string
+	Summary     string
+	Description string
+	Location    string
+	URL         string
+	Start       time.Time
+	End         time.Time
+	Stamp       time.Time // when the event last changed
+	Status      string
+	Sequence    int
+}
+
+const icsTimeLayout = "20060102T150405Z"
+
+// BuildICS renders the events as an iCalendar object named name, with CRLF
+// line ends and long lines folded as RFC 5545 asks
+func BuildICS(name string, events []ICSEvent) []byte {
+	var b strings.Builder
+	line := func(s string) {
+		b.WriteString(foldICSLine(s))
+		b.WriteString("\r\n")
+	}
+
+	line("BEGIN:VCALENDAR")
+	line("VERSION:2.0")
+	line("PRODID:-//Homa//Viewings//RU")
+	line("CALSCALE:GREGORIAN")
+	line("METHOD:PUBLISH")
+	line("X-WR-CALNAME:" + escapeICSText(name))
+	for _, e := range events {
+		line("BEGIN:VEVENT")
+		line("UID:" + e.UID)
+		line("DTSTAMP:" + e.Stamp.UTC().Format(icsTimeLayout))
+		line("DTSTART:" + e.Start.UTC().Format(icsTimeLayout))
+		line("DTEND:" + e.End.UTC().Format(icsTimeLayout))
+		line("SEQUENCE:" + strconv.Itoa(e.Sequence))
+		line("SUMMARY:" + escapeICSText(e.Summary))
+		if e.Description != "" {
+			line("DESCRIPTION:" + escapeICSText(e.Description))
+		}
+		if e.Location != "" {
+			line("LOCATION:" + escapeICSText(e.Location))
+		}
+		if e.URL != "" {
+			line("URL:" + e.URL)
+		}
+		if e.Status != "" {
+			line("STATUS:" + e.Status)
+		}
+		line("END:VEVENT")
+	}
+	line("END:VCALENDAR")
+	return []byte(b.String())
+}
+
+var icsTextEscaper = strings.NewReplacer(`\`, `\\`, ";", `\;`, ",", `\,`, "\r\n", `\n`, "\n", `\n`, "\r", `\n`)
+
+// escapeICSText escapes a TEXT value
+func escapeICSText(s string) string {
+	return icsTextEscaper.Replace(s)
+}
+
+// foldICSLine breaks a content line into lines of at most 75 octets, each
+// continuation starting with a space, without splitting a UTF-8 character
+func foldICSLine(s string) string {
+	const limit = 75
+	if len(s) <= limit {
+		return s
+	}
+	var b strings.Builder
+	width := limit
+	for len(s) > width {
+		cut := width
+		for cut > 0 && !utf8.RuneStart(s[cut]) {
+			cut--
+		}
+		b.WriteString(s[:cut])
+		b.WriteString("\r\n ")
+		s = s[cut:]
+		width = limit - 1 // the leading space counts
+	}
+	b.WriteString(s)
+	return b.String()
+}
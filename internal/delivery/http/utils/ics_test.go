@@ -0,0 +1,88 @@
+package utils
+
+import (
+	"strings"
+	"testing"
+	"time"
+	"unicode/utf8"
+)
+
+func TestBuildICS(t *testing.T) {
+	start := time.Date(2026, 5, 12, 15, 0, 0, 0, time.FixedZone("MSK", 3*60*60))
+	ics := string(BuildICS("Просмотры", []ICSEvent{{
+		UID:         "v1@homa",
+		Summary:     "Просмотр: студия, 28 м²",
+		Description: "Код домофона 12;\nзвонить заранее",
+		Location:    `Bolshaya Dmitrovka, 7`,
+		Start:       start,
+		End:         start.Add(30 * time.Minute),
+		Stamp:       start.Add(-time.Hour),
+		Status:      ICSStatusConfirmed,
+		Sequence:    2,
+	}}))
+
+	if !strings.HasPrefix(ics, "BEGIN:VCALENDAR\r\n") || !strings.HasSuffix(ics, "END:VCALENDAR\r\n") {
+		t.Fatalf("ожидался объект VCALENDAR с CRLF, получили %q", ics)
+	}
+	for _, want := range []string{
+		"UID:v1@homa\r\n",
+		"DTSTART:20260512T120000Z\r\n",
+		"DTEND:20260512T123000Z\r\n",
+		"DTSTAMP:20260512T110000Z\r\n",
+		"SEQUENCE:2\r\n",
+		"STATUS:CONFIRMED\r\n",
+		`SUMMARY:Просмотр: студия\, 28 м²` + "\r\n",
+		`DESCRIPTION:Код домофона 12\;\nзвонить заранее` + "\r\n",
+		`LOCATION:Bolshaya Dmitrovka\, 7` + "\r\n",
+	} {
+		if !strings.Contains(ics, want) {
+			t.Errorf("нет строки %q в\n%s", want, ics)
+		}
+	}
+	if strings.Contains(ics, "URL:") {
+		t.Error("пустой URL не должен попадать в событие")
+	}
+}
+
+func TestBuildICS_Empty(t *testing.T) {
+	ics := string(BuildICS("Просмотры", nil))
+	if strings.Contains(ics, "BEGIN:VEVENT") {
+		t.Errorf("не ожидалось событий, получили %q", ics)
+	}
+}
+
+func TestFoldICSLine(t *testing.T) {
+	line := "DESCRIPTION:" + strings.Repeat("просмотр ", 20)
+	folded := foldICSLine(line)
+
+	parts := strings.Split(folded, "\r\n")
+	if len(parts) < 2 {
+		t.Fatalf("ожидался перенос длинной строки, получили %q", folded)
+	}
+	for i, p := range parts {
+		if len(p) > 75 {
+			t.Errorf("строка %d длиннее 75 октетов: %d", i, len(p))
+		}
+		if i > 0 && !strings.HasPrefix(p, " ") {
+			t.Errorf("продолжение %d должно начинаться с пробела: %q", i, p)
+		}
+		if !utf8.ValidString(p) {
+			t.Errorf("строка %d разрезала символ UTF-8", i)
+		}
+	}
+
+	var unfolded strings.Builder
+	for i, p := range parts {
+		if i > 0 {
+			p = p[1:]
+		}
+		unfolded.WriteString(p)
+	}
+	if unfolded.String() != line {
+		t.Errorf("после склейки строка изменилась: %q", unfolded.String())
+	}
+
+	if short := "SUMMARY:Просмотр"; foldICSLine(short) != short {
+		t.Errorf("короткая строка не должна переноситься")
+	}
+}
@@ -0,0 +1,64 @@
+package utils
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"net/http"
+)
+
+// imageExtensions maps sniffed content types to stored file extensions.
+var imageExtensions = map[string]string{
+	"image/jpeg": ".jpg",
+	"image/png":  ".png",
+	"image/gif":  ".gif",
+	"image/webp": ".webp",
+	"image/bmp":  ".bmp",
+}
+
+// documentExtensions adds the document formats accepted as private files.
+var documentExtensions = map[string]string{
+	"application/pdf": ".pdf",
+}
+
+// ContentHash returns the hex-encoded SHA-256 of data.
+func ContentHash(data []byte) string {
+	sum := sha256.Sum256(data)
+	return hex.EncodeToString(sum[:])
+}
+
+// SniffContentType detects the content type from the data itself; the name
+// and type sent by the client are not trusted.
+func SniffContentType(data []byte) string {
+	return http.DetectContentType(data)
+}
+
+// IsImageContentType reports whether a sniffed content type is one of the
+// image formats accepted for upload.
+func IsImageContentType(contentType string) bool {
+	_, ok := imageExtensions[contentType]
+	return ok
+}
+
+// PrivateFileExtension returns the stored extension of a private file with
+// the sniffed content type; ".bin" for types that are neither an image nor a
+// known document.
+func PrivateFileExtension(contentType string) string {
+	if ext, ok := imageExtensions[contentType]; ok {
+		return ext
+	}
+	if ext, ok := documentExtensions[contentType]; ok {
+		return ext
+	}
+	return ".bin"
+}
+
+// ContentAddressedFilename builds the storage name for a blob: identical
+// uploads always map to the same file. The extension follows the sniffed
+// content type.
+func ContentAddressedFilename(hash, contentType string) string {
+	ext, ok := imageExtensions[contentType]
+	if !ok {
+		ext = ".bin"
+	}
+	return hash + ext
+}
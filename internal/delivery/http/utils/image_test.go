@@ -0,0 +1,79 @@
+package utils
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestContentHash_Deterministic(t *testing.T) {
+	a := ContentHash([]byte("photo"))
+	b := ContentHash([]byte("photo"))
+	if a != b {
+		t.Fatalf("ожидался одинаковый хеш, получили %s и %s", a, b)
+	}
+	if len(a) != 64 {
+		t.Errorf("ожидалась длина 64, получили %d", len(a))
+	}
+	if ContentHash([]byte("other")) == a {
+		t.Error("разные данные не должны давать одинаковый хеш")
+	}
+}
+
+func TestContentAddressedFilename(t *testing.T) {
+	hash := ContentHash([]byte("photo"))
+
+	if got := ContentAddressedFilename(hash, "image/png"); got != hash+".png" {
+		t.Errorf("expected %s.png, got %s", hash, got)
+	}
+	if got := ContentAddressedFilename(hash, "text/html; charset=utf-8"); !strings.HasSuffix(got, ".bin") {
+		t.Errorf("ожидалось расширение .bin для не-картинки, получили %s", got)
+	}
+}
+
+func TestSniffContentType_IgnoresClientName(t *testing.T) {
+	png := []byte("\x89PNG\r\n\x1a\n\x00\x00\x00\rIHDR")
+	if got := SniffContentType(png); got != "image/png" {
+		t.Errorf("ожидался image/png, получили %s", got)
+	}
+
+	html := []byte("<html><body>not an image</body></html>")
+	if got := ContentAddressedFilename(ContentHash(html), SniffContentType(html)); strings.HasSuffix(got, ".jpg") || strings.HasSuffix(got, ".png") {
+		t.Errorf("html не должен сохраняться как картинка, получили %s", got)
+	}
+}
+
+func TestIsImageContentType(t *testing.T) {
+	tests := []struct {
+		contentType string
+		want        bool
+	}{
+		{"image/jpeg", true},
+		{"image/png", true},
+		{"image/webp", true},
+		{"image/svg+xml", false},
+		{"text/html; charset=utf-8", false},
+		{"application/octet-stream", false},
+	}
+	for _, tt := range tests {
+		if got := IsImageContentType(tt.contentType); got != tt.want {
+			t.Errorf("IsImageContentType(%q) = %v, ожидалось %v", tt.contentType, got, tt.want)
+		}
+	}
+}
+
+func TestPrivateFileExtension(t *testing.T) {
+	tests := []struct {
+		contentType string
+		want        string
+	}{
+		{"image/jpeg", ".jpg"},
+		{"application/pdf", ".pdf"},
+		{"text/html; charset=utf-8", ".bin"},
+		{"application/octet-stream", ".bin"},
+	}
+	for _, tt := range tests {
+		if got := PrivateFileExtension(tt.contentType); got != tt.want {
+			t.Errorf("PrivateFileExtension(%q) = %q, ожидалось %q", tt.contentType, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,103 @@
+package utils
+
+import (
+	"encoding/json"
+	"errors"
+
+	"github.com/go-park-mail-ru/2025_2_Avrora/internal/domain"
+	"github.com/google/uuid"
+)
+
+var ErrPatchNotObject = errors.New("merge patch must be a JSON object")
+
+// PatchField describes one field a merge patch may touch
+type PatchField struct {
+	Nullable bool
+	decode   func(raw json.RawMessage) (any, error)
+}
+
+func decodeAs[T any](raw json.RawMessage) (any, error) {
+	var v T
+	if err := json.Unmarshal(raw, &v); err != nil {
+		return nil, err
+	}
+	return v, nil
+}
+
+func PatchString(nullable bool) PatchField {
+	return PatchField{Nullable: nullable, decode: decodeAs[string]}
+}
+
+func PatchInt(nullable bool) PatchField {
+	return PatchField{Nullable: nullable, decode: decodeAs[int]}
+}
+
+func PatchInt64(nullable bool) PatchField {
+	return PatchField{Nullable: nullable, decode: decodeAs[int64]}
+}
+
+func PatchFloat(nullable bool) PatchField {
+	return PatchField{Nullable: nullable, decode: decodeAs[float64]}
+}
+
+// PatchStrings is a list replaced as a whole, as RFC 7396 does with arrays;
+// null empties it
+func PatchStrings() PatchField {
+	return PatchField{Nullable: true, decode: decodeAs[[]string]}
+}
+
+// PatchObject is a nested JSON object merged key by key, where null removes
+// a key; null in place of the object clears it
+func PatchObject() PatchField {
+	return PatchField{Nullable: true, decode: decodeAs[map[string]any]}
+}
+
+func PatchUUID(nullable bool) PatchField {
+	return PatchField{Nullable: nullable, decode: func(raw json.RawMessage) (any, error) {
+		var s string
+		if err := json.Unmarshal(raw, &s); err != nil {
+			return nil, err
+		}
+		if _, err := uuid.Parse(s); err != nil {
+			return nil, err
+		}
+		return s, nil
+	}}
+}
+
+// ParseMergePatch decodes an RFC 7396 merge patch against a whitelist of
+// fields. Unknown fields, wrong types and null for a required field are
+// reported together as domain.ValidationErrors.
+func ParseMergePatch(body []byte, fields map[string]PatchField) (domain.Patch, error) {
+	var raw map[string]json.RawMessage
+	if err := json.Unmarshal(body, &raw); err != nil || raw == nil {
+		return nil, ErrPatchNotObject
+	}
+
+	patch := make(domain.Patch, len(raw))
+	var errs domain.ValidationErrors
+	for name, value := range raw {
+		field, ok := fields[name]
+		switch {
+		case !ok:
+			errs = append(errs, domain.FieldError{Field: name, Message: "поле нельзя изменить"})
+		case string(value) == "null":
+			if !field.Nullable {
+				errs = append(errs, domain.FieldError{Field: name, Message: "поле нельзя очистить"})
+				continue
+			}
+			patch[name] = nil
+		default:
+			v, err := field.decode(value)
+			if err != nil {
+				errs = append(errs, domain.FieldError{Field: name, Message: "неверный формат значения"})
+				continue
+			}
+			patch[name] = v
+		}
+	}
+	if len(errs) > 0 {
+		return nil, errs
+	}
+	return patch, nil
+}
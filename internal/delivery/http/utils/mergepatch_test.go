@@ -0,0 +1,78 @@
+package utils
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/go-park-mail-ru/2025_2_Avrora/internal/domain"
+)
+
+var testPatchFields = map[string]PatchField{
+	"title": PatchString(false),
+	"floor": PatchInt(true),
+	"price": PatchInt64(false),
+	"area":  PatchFloat(false),
+	"urls":  PatchStrings(),
+	"ref":   PatchUUID(true),
+	"attrs": PatchObject(),
+}
+
+func TestParseMergePatch_Values(t *testing.T) {
+	body := `{"title":"Квартира","floor":null,"price":5000000,"area":42.5,"urls":["a","b"],"ref":"2f1b3a3e-7b8c-4a7e-9d2f-1c2b3a4d5e6f","attrs":{"furniture":true,"balcony":null}}`
+	patch, err := ParseMergePatch([]byte(body), testPatchFields)
+	if err != nil {
+		t.Fatalf("не ожидалось ошибки: %v", err)
+	}
+
+	if patch["title"] != "Квартира" {
+		t.Errorf("title: получили %v", patch["title"])
+	}
+	if v, ok := patch["floor"]; !ok || v != nil {
+		t.Errorf("floor должен очищаться, получили %v (есть: %v)", v, ok)
+	}
+	if patch["price"] != int64(5000000) {
+		t.Errorf("price: получили %#v", patch["price"])
+	}
+	if patch["area"] != 42.5 {
+		t.Errorf("area: получили %#v", patch["area"])
+	}
+	if urls, ok := patch["urls"].([]string); !ok || len(urls) != 2 {
+		t.Errorf("urls: получили %#v", patch["urls"])
+	}
+	if attrs, ok := patch["attrs"].(map[string]any); !ok || attrs["furniture"] != true || attrs["balcony"] != nil {
+		t.Errorf("attrs: получили %#v", patch["attrs"])
+	}
+	if patch.Has("missing") {
+		t.Error("отсутствующее поле не должно попадать в патч")
+	}
+}
+
+func TestParseMergePatch_Errors(t *testing.T) {
+	body := `{"title":null,"price":"дорого","unknown":1,"ref":"not-a-uuid","attrs":[1]}`
+	_, err := ParseMergePatch([]byte(body), testPatchFields)
+
+	var verr domain.ValidationErrors
+	if !errors.As(err, &verr) {
+		t.Fatalf("ожидались ошибки полей, получили %v", err)
+	}
+	if !errors.Is(err, domain.ErrInvalidInput) {
+		t.Error("ошибки полей должны соответствовать ErrInvalidInput")
+	}
+	got := make(map[string]bool)
+	for _, fe := range verr {
+		got[fe.Field] = true
+	}
+	for _, field := range []string{"title", "price", "unknown", "ref", "attrs"} {
+		if !got[field] {
+			t.Errorf("нет ошибки для поля %s", field)
+		}
+	}
+}
+
+func TestParseMergePatch_NotObject(t *testing.T) {
+	for _, body := range []string{`[1,2]`, `null`, `"x"`, `{`} {
+		if _, err := ParseMergePatch([]byte(body), testPatchFields); !errors.Is(err, ErrPatchNotObject) {
+			t.Errorf("%s: ожидалась ErrPatchNotObject, получили %v", body, err)
+		}
+	}
+}
@@ -0,0 +1,74 @@
+package utils
+
+import (
+	"bytes"
+	"image"
+	_ "image/gif"
+	_ "image/jpeg"
+	_ "image/png"
+	"math/bits"
+)
+
+const (
+	dhashWidth  = 9 // one extra column: each bit compares neighbours in a row
+	dhashHeight = 8
+)
+
+// PerceptualHash returns the 64-bit difference hash (dHash) of an encoded
+// image. Re-encoded, resized or slightly recoloured copies of a photo get
+// hashes that differ in only a few bits, unlike ContentHash.
+func PerceptualHash(data []byte) (uint64, error) {
+	img, _, err := image.Decode(bytes.NewReader(data))
+	if err != nil {
+		return 0, err
+	}
+	return dHash(img), nil
+}
+
+// HammingDistance is the number of differing bits between two hashes.
+func HammingDistance(a, b uint64) int {
+	return bits.OnesCount64(a ^ b)
+}
+
+// dHash shrinks the image to 9x8 luminance cells by box averaging and sets a
+// bit for every cell that is brighter than its right neighbour.
+func dHash(img image.Image) uint64 {
+	bounds := img.Bounds()
+	w, h := bounds.Dx(), bounds.Dy()
+	if w == 0 || h == 0 {
+		return 0
+	}
+
+	var sum [dhashHeight][dhashWidth]float64
+	var count [dhashHeight][dhashWidth]int
+	for y := 0; y < h; y++ {
+		cy := y * dhashHeight / h
+		for x := 0; x < w; x++ {
+			cx := x * dhashWidth / w
+			r, g, b, _ := img.At(bounds.Min.X+x, bounds.Min.Y+y).RGBA()
+			// ITU-R BT.601 luma
+			sum[cy][cx] += 0.299*float64(r) + 0.587*float64(g) + 0.114*float64(b)
+			count[cy][cx]++
+		}
+	}
+
+	var hash uint64
+	for y := 0; y < dhashHeight; y++ {
+		for x := 0; x < dhashWidth-1; x++ {
+			left := cellMean(sum[y][x], count[y][x])
+			right := cellMean(sum[y][x+1], count[y][x+1])
+			hash <<= 1
+			if left > right {
+				hash |= 1
+			}
+		}
+	}
+	return hash
+}
+
+func cellMean(sum float64, n int) float64 {
+	if n == 0 {
+		return 0
+	}
+	return sum / float64(n)
+}
@@ -0,0 +1,99 @@
+package utils
+
+import (
+	"bytes"
+	"image"
+	"image/color"
+	"image/jpeg"
+	"image/png"
+	"testing"
+)
+
+// gradientImage draws a diagonal gradient with a dark square, so the hash has structure
+func gradientImage(w, h int) image.Image {
+	img := image.NewRGBA(image.Rect(0, 0, w, h))
+	for y := 0; y < h; y++ {
+		for x := 0; x < w; x++ {
+			v := uint8((x*255/w + y*128/h) % 256)
+			if x > w/3 && x < w/2 && y > h/4 && y < h/2 {
+				v = 10
+			}
+			img.Set(x, y, color.RGBA{R: v, G: v / 2, B: 255 - v, A: 255})
+		}
+	}
+	return img
+}
+
+func encodePNG(t *testing.T, img image.Image) []byte {
+	t.Helper()
+	var buf bytes.Buffer
+	if err := png.Encode(&buf, img); err != nil {
+		t.Fatalf("png encode: %v", err)
+	}
+	return buf.Bytes()
+}
+
+func encodeJPEG(t *testing.T, img image.Image, quality int) []byte {
+	t.Helper()
+	var buf bytes.Buffer
+	if err := jpeg.Encode(&buf, img, &jpeg.Options{Quality: quality}); err != nil {
+		t.Fatalf("jpeg encode: %v", err)
+	}
+	return buf.Bytes()
+}
+
+func TestPerceptualHash_SurvivesReencodingAndResize(t *testing.T) {
+	original, err := PerceptualHash(encodePNG(t, gradientImage(320, 240)))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	recompressed, err := PerceptualHash(encodeJPEG(t, gradientImage(320, 240), 40))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if d := HammingDistance(original, recompressed); d > 6 {
+		t.Errorf("пережатая копия слишком далеко: расстояние %d", d)
+	}
+
+	resized, err := PerceptualHash(encodePNG(t, gradientImage(160, 120)))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if d := HammingDistance(original, resized); d > 6 {
+		t.Errorf("уменьшенная копия слишком далеко: расстояние %d", d)
+	}
+}
+
+func TestPerceptualHash_DifferentImages(t *testing.T) {
+	a, _ := PerceptualHash(encodePNG(t, gradientImage(320, 240)))
+
+	// Mirrored picture has the opposite horizontal gradients
+	src := gradientImage(320, 240)
+	mirrored := image.NewRGBA(src.Bounds())
+	for y := 0; y < 240; y++ {
+		for x := 0; x < 320; x++ {
+			mirrored.Set(319-x, y, src.At(x, y))
+		}
+	}
+	b, _ := PerceptualHash(encodePNG(t, mirrored))
+
+	if d := HammingDistance(a, b); d < 20 {
+		t.Errorf("разные изображения слишком близко: расстояние %d", d)
+	}
+}
+
+func TestPerceptualHash_InvalidData(t *testing.T) {
+	if _, err := PerceptualHash([]byte("not an image")); err == nil {
+		t.Error("ожидалась ошибка для нераспознанных данных")
+	}
+}
+
+func TestHammingDistance(t *testing.T) {
+	if d := HammingDistance(0b1011, 0b0010); d != 2 {
+		t.Errorf("expected 2, got %d", d)
+	}
+	if d := HammingDistance(^uint64(0), 0); d != 64 {
+		t.Errorf("expected 64, got %d", d)
+	}
+}
@@ -0,0 +1,21 @@
+package utils
+
+import (
+	"crypto/hkdf"
+	"crypto/sha256"
+)
+
+// DeriveSecret derives an independent key for one purpose from a master
+// secret. A leaked derived key reveals neither the master nor the keys of
+// other purposes.
+func DeriveSecret(master, purpose string) []byte {
+	if master == "" {
+		panic("master secret cannot be empty")
+	}
+	key, err := hkdf.Key(sha256.New, []byte(master), nil, purpose, sha256.Size)
+	if err != nil {
+		// Only reachable with a key length beyond what HKDF-SHA256 can produce
+		panic(err)
+	}
+	return key
+}
@@ -0,0 +1,22 @@
+package utils
+
+import (
+	"bytes"
+	"testing"
+)
+
+func TestDeriveSecret(t *testing.T) {
+	a := DeriveSecret("master", "offer-viewer-key")
+	if !bytes.Equal(a, DeriveSecret("master", "offer-viewer-key")) {
+		t.Error("ожидался одинаковый ключ для одной цели")
+	}
+	if len(a) != 32 {
+		t.Errorf("ожидалась длина 32, получили %d", len(a))
+	}
+	if bytes.Equal(a, DeriveSecret("master", "image-url-signing")) {
+		t.Error("ключи разных целей не должны совпадать")
+	}
+	if bytes.Equal(a, DeriveSecret("other", "offer-viewer-key")) {
+		t.Error("ключи разных секретов не должны совпадать")
+	}
+}
@@ -0,0 +1,63 @@
+package utils
+
+import (
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestURLSigner_SignAndVerify(t *testing.T) {
+	s := NewURLSigner("secret")
+	now := time.Now()
+
+	q := s.SignQuery("passport.pdf", now.Add(time.Minute), "")
+	userID, err := s.Verify("passport.pdf", q, now)
+	if err != nil {
+		t.Fatalf("не ожидалось ошибки: %v", err)
+	}
+	if userID != "" {
+		t.Errorf("ожидалась непривязанная ссылка, получили uid %q", userID)
+	}
+}
+
+func TestURLSigner_UserBinding(t *testing.T) {
+	s := NewURLSigner("secret")
+	now := time.Now()
+
+	q := s.SignQuery("passport.pdf", now.Add(time.Minute), "user_1")
+	userID, err := s.Verify("passport.pdf", q, now)
+	if err != nil {
+		t.Fatalf("не ожидалось ошибки: %v", err)
+	}
+	if userID != "user_1" {
+		t.Errorf("expected user_1, got %q", userID)
+	}
+
+	q.Set("uid", "user_2")
+	if _, err := s.Verify("passport.pdf", q, now); !errors.Is(err, ErrSignatureInvalid) {
+		t.Errorf("подмена uid должна ломать подпись, получили %v", err)
+	}
+}
+
+func TestURLSigner_Expired(t *testing.T) {
+	s := NewURLSigner("secret")
+	now := time.Now()
+
+	q := s.SignQuery("passport.pdf", now.Add(-time.Second), "")
+	if _, err := s.Verify("passport.pdf", q, now); !errors.Is(err, ErrSignatureExpired) {
+		t.Errorf("expected ErrSignatureExpired, got %v", err)
+	}
+}
+
+func TestURLSigner_WrongFileOrSecret(t *testing.T) {
+	s := NewURLSigner("secret")
+	now := time.Now()
+	q := s.SignQuery("passport.pdf", now.Add(time.Minute), "")
+
+	if _, err := s.Verify("other.pdf", q, now); !errors.Is(err, ErrSignatureInvalid) {
+		t.Errorf("подпись не должна подходить к другому файлу, получили %v", err)
+	}
+	if _, err := NewURLSigner("other").Verify("passport.pdf", q, now); !errors.Is(err, ErrSignatureInvalid) {
+		t.Errorf("подпись не должна подходить к другому секрету, получили %v", err)
+	}
+}
@@ -0,0 +1,56 @@
+package domain
+
+import (
+	"encoding/json"
+	"time"
+)
+
+type ChangeEntityType string
+type ChangeAction string
+
+const (
+	ChangeEntityOffer   ChangeEntityType = "offer"
+	ChangeEntityComplex ChangeEntityType = "housing_complex"
+	ChangeEntityProfile ChangeEntityType = "profile"
+
+	ChangeActionCreate ChangeAction = "create"
+	ChangeActionUpdate ChangeAction = "update"
+	ChangeActionDelete ChangeAction = "delete"
+)
+
+func (t ChangeEntityType) Valid() bool {
+	switch t {
+	case ChangeEntityOffer, ChangeEntityComplex, ChangeEntityProfile:
+		return true
+	}
+	return false
+}
+
+// FieldChange is one column of one write. Values are kept as the database
+// rendered them to JSON, null included.
+type FieldChange struct {
+	Field string          `json:"field"`
+	Old   json.RawMessage `json:"old"`
+	New   json.RawMessage `json:"new"`
+}
+
+// ChangeLogEntry records a single write to an offer, complex or profile.
+// Profiles are identified by their user ID.
+type ChangeLogEntry struct {
+	ID         string
+	EntityType ChangeEntityType
+	EntityID   string
+	Action     ChangeAction
+	ActorID    *string // nil for background jobs
+	RequestID  *string
+	Changes    []FieldChange
+	CreatedAt  time.Time
+}
+
+// ChangeLogFilter narrows the change log; unset fields match everything
+type ChangeLogFilter struct {
+	EntityType *ChangeEntityType
+	EntityID   *string
+	ActorID    *string
+	RequestID  *string
+}
@@ -3,6 +3,7 @@ package domain
 import (
 	"errors"
 	"time"
+	"unicode/utf8"
 )
 
 type HousingComplex struct {
@@ -11,12 +12,101 @@ type HousingComplex struct {
 	Description   string
 	YearBuilt     *int      // nullable
 	LocationID    string    // UUID
-	Developer     string
+	DeveloperID   *string   // nullable
+	Developer     string    // name of the developer, read only
 	Address       string
-	StartingPrice *int64	// nullable
+	StartingPrice *int64	// derived from layouts and active sale offers, nullable
 	ImageURLs     []string
+	Buildings     []ComplexBuilding
+	Layouts       []ComplexLayout
+	Amenities     []ComplexAmenity
+	OfferStats    []ComplexOfferStats // filled for the complex card only
 	CreatedAt     time.Time
 	UpdatedAt     time.Time
+	Version       int // bumped by every write, sent as the ETag
+}
+
+// ApplyPatch sets the fields present in the patch
+func (c *HousingComplex) ApplyPatch(p Patch) {
+	patchValue(p, "name", &c.Name)
+	patchString(p, "description", &c.Description)
+	patchPtr(p, "year_built", &c.YearBuilt)
+	patchValue(p, "location_id", &c.LocationID)
+	patchPtr(p, "developer_id", &c.DeveloperID)
+	patchString(p, "address", &c.Address)
+	if p.Has("image_urls") {
+		c.ImageURLs, _ = p["image_urls"].([]string)
+	}
+}
+
+// Validate mirrors the housing_complex table constraints
+func (c *HousingComplex) Validate() ValidationErrors {
+	var errs ValidationErrors
+	switch n := utf8.RuneCountInString(c.Name); {
+	case n == 0:
+		errs.add("name", "обязательное поле")
+	case n > 255:
+		errs.add("name", "не длиннее 255 символов")
+	}
+	if utf8.RuneCountInString(c.Description) > 2000 {
+		errs.add("description", "не длиннее 2000 символов")
+	}
+	if c.YearBuilt != nil && (*c.YearBuilt < 1800 || *c.YearBuilt > 2100) {
+		errs.add("year_built", "год постройки должен быть от 1800 до 2100")
+	}
+	if c.LocationID == "" {
+		errs.add("location_id", "обязательное поле")
+	}
+	if utf8.RuneCountInString(c.Address) > 255 {
+		errs.add("address", "не длиннее 255 символов")
+	}
+	return errs
+}
+
+// ComplexCompletion is whether construction of the complex is finished: all
+// of its buildings are completed or, without buildings, the year built has come
+type ComplexCompletion string
+
+const (
+	ComplexCompleted         ComplexCompletion = "completed"
+	ComplexUnderConstruction ComplexCompletion = "under_construction"
+)
+
+func (c ComplexCompletion) Valid() bool {
+	return c == ComplexCompleted || c == ComplexUnderConstruction
+}
+
+type ComplexSort string
+
+const (
+	ComplexSortNewest    ComplexSort = "newest"
+	ComplexSortPriceAsc  ComplexSort = "price_asc"
+	ComplexSortPriceDesc ComplexSort = "price_desc"
+	ComplexSortDistance  ComplexSort = "distance" // from Lat, Lon
+)
+
+func (s ComplexSort) Valid() bool {
+	switch s {
+	case ComplexSortNewest, ComplexSortPriceAsc, ComplexSortPriceDesc, ComplexSortDistance:
+		return true
+	}
+	return false
+}
+
+// ComplexFilter narrows the complex feed; nil and empty fields match all
+type ComplexFilter struct {
+	RegionID       *string // includes nested regions
+	MetroStationID *string
+	DeveloperID    *string
+	Developer      *string // case-insensitive substring of the name
+	YearBuiltMin   *int
+	YearBuiltMax   *int
+	PriceMin       *int64 // starting price
+	PriceMax       *int64
+	Completion     ComplexCompletion
+	Sort           ComplexSort // empty means newest
+	Lat            *float64    // the point distances are measured from
+	Lon            *float64
 }
 
 type ComplexInFeed struct {
@@ -26,6 +116,7 @@ type ComplexInFeed struct {
 	Address       string
 	Metro         string
 	ImageURL      string
+	DistanceKm    *float64 // from the filter point, when it is given
 	CreatedAt     time.Time
 	UpdatedAt     time.Time
 }
@@ -0,0 +1,157 @@
+package domain
+
+import (
+	"errors"
+	"time"
+	"unicode/utf8"
+)
+
+// ConstructionStage is how far the building of a korpus has got
+type ConstructionStage string
+
+const (
+	ConstructionStagePlanned    ConstructionStage = "planned"
+	ConstructionStageFoundation ConstructionStage = "foundation"
+	ConstructionStageFrame      ConstructionStage = "frame"
+	ConstructionStageFacade     ConstructionStage = "facade"
+	ConstructionStageFinishing  ConstructionStage = "finishing"
+	ConstructionStageCompleted  ConstructionStage = "completed"
+)
+
+func (s ConstructionStage) Valid() bool {
+	switch s {
+	case ConstructionStagePlanned, ConstructionStageFoundation, ConstructionStageFrame,
+		ConstructionStageFacade, ConstructionStageFinishing, ConstructionStageCompleted:
+		return true
+	}
+	return false
+}
+
+// ComplexBuilding is one korpus of a housing complex. Phase is the
+// construction queue it belongs to; the handover deadline is a quarter.
+type ComplexBuilding struct {
+	ID              string
+	ComplexID       string
+	Name            string // "Корпус 1"
+	Phase           int
+	Floors          int
+	Stage           ConstructionStage
+	HandoverYear    *int // nullable, set together with HandoverQuarter
+	HandoverQuarter *int // 1..4
+	CreatedAt       time.Time
+	UpdatedAt       time.Time
+}
+
+// Validate mirrors the complex_building table constraints
+func (b *ComplexBuilding) Validate() ValidationErrors {
+	var errs ValidationErrors
+	switch n := utf8.RuneCountInString(b.Name); {
+	case n == 0:
+		errs.add("name", "обязательное поле")
+	case n > 100:
+		errs.add("name", "не длиннее 100 символов")
+	}
+	if b.Phase < 1 || b.Phase > 100 {
+		errs.add("phase", "очередь должна быть от 1 до 100")
+	}
+	if b.Floors < 1 || b.Floors > 200 {
+		errs.add("floors", "этажность должна быть от 1 до 200")
+	}
+	if !b.Stage.Valid() {
+		errs.add("stage", "неизвестная стадия строительства")
+	}
+	if (b.HandoverYear == nil) != (b.HandoverQuarter == nil) {
+		errs.add("handover", "год и квартал сдачи указываются вместе")
+	}
+	if b.HandoverYear != nil && (*b.HandoverYear < 1800 || *b.HandoverYear > 2100) {
+		errs.add("handover_year", "год сдачи должен быть от 1800 до 2100")
+	}
+	if b.HandoverQuarter != nil && (*b.HandoverQuarter < 1 || *b.HandoverQuarter > 4) {
+		errs.add("handover_quarter", "квартал сдачи должен быть от 1 до 4")
+	}
+	return errs
+}
+
+// ComplexLayout is an apartment layout (планировка) on sale in the complex.
+// A layout without a building is offered in all of them.
+type ComplexLayout struct {
+	ID         string
+	ComplexID  string
+	BuildingID *string // nullable
+	Rooms      int     // 0 is a studio
+	Area       float64
+	PriceMin   *int64  // nullable
+	PriceMax   *int64  // nullable
+	ImageURL   *string // the floor plan, nullable
+	CreatedAt  time.Time
+	UpdatedAt  time.Time
+}
+
+// Validate mirrors the complex_layout table constraints
+func (l *ComplexLayout) Validate() ValidationErrors {
+	var errs ValidationErrors
+	if l.Rooms < 0 || l.Rooms > 20 {
+		errs.add("rooms", "количество комнат должно быть от 0 до 20")
+	}
+	if l.Area <= 0 {
+		errs.add("area", "площадь должна быть положительной")
+	}
+	if l.PriceMin != nil && *l.PriceMin < 0 {
+		errs.add("price_min", "не может быть отрицательной")
+	}
+	if l.PriceMax != nil && *l.PriceMax < 0 {
+		errs.add("price_max", "не может быть отрицательной")
+	}
+	if l.PriceMin != nil && l.PriceMax != nil && *l.PriceMax < *l.PriceMin {
+		errs.add("price_max", "не может быть меньше минимальной цены")
+	}
+	if l.ImageURL != nil && utf8.RuneCountInString(*l.ImageURL) > 1024 {
+		errs.add("image_url", "не длиннее 1024 символов")
+	}
+	return errs
+}
+
+// ComplexAmenity is a feature of the complex territory or infrastructure
+type ComplexAmenity string
+
+const (
+	AmenityParking            ComplexAmenity = "parking"
+	AmenityUndergroundParking ComplexAmenity = "underground_parking"
+	AmenityPlayground         ComplexAmenity = "playground"
+	AmenitySportsGround       ComplexAmenity = "sports_ground"
+	AmenityKindergarten       ComplexAmenity = "kindergarten"
+	AmenitySchool             ComplexAmenity = "school"
+	AmenityFitness            ComplexAmenity = "fitness"
+	AmenityClosedTerritory    ComplexAmenity = "closed_territory"
+	AmenitySecurity           ComplexAmenity = "security"
+	AmenityConcierge          ComplexAmenity = "concierge"
+	AmenityCommercialPremises ComplexAmenity = "commercial_premises"
+	AmenityPark               ComplexAmenity = "park"
+)
+
+func (a ComplexAmenity) Valid() bool {
+	switch a {
+	case AmenityParking, AmenityUndergroundParking, AmenityPlayground, AmenitySportsGround,
+		AmenityKindergarten, AmenitySchool, AmenityFitness, AmenityClosedTerritory,
+		AmenitySecurity, AmenityConcierge, AmenityCommercialPremises, AmenityPark:
+		return true
+	}
+	return false
+}
+
+// ComplexOfferStats aggregates the active offers of one type in a complex.
+// Rooms is nil for all of them together; rooms above MaxRoomsGroup are
+// counted in its group.
+type ComplexOfferStats struct {
+	OfferType      OfferType
+	Rooms          *int
+	Offers         int
+	PriceMin       int64
+	PriceMax       int64
+	AvgPricePerSqm float64
+}
+
+var (
+	ErrBuildingNotFound = errors.New("complex building not found")
+	ErrLayoutNotFound   = errors.New("complex layout not found")
+)
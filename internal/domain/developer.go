@@ -0,0 +1,55 @@
+package domain
+
+import (
+	"errors"
+	"time"
+	"unicode/utf8"
+)
+
+// Developer (застройщик) builds housing complexes. Accounts with the
+// developer role act for one developer and manage its complexes.
+type Developer struct {
+	ID          string
+	Name        string
+	Description string
+	LogoURL     *string // nullable
+	Website     *string // nullable
+	Phone       *string // nullable
+	FoundedYear *int    // nullable
+	Complexes   int     // all complexes of the developer
+	Delivered   int     // completed ones, see ComplexCompletion
+	CreatedAt   time.Time
+	UpdatedAt   time.Time
+}
+
+// Validate mirrors the developer table constraints
+func (d *Developer) Validate() ValidationErrors {
+	var errs ValidationErrors
+	switch n := utf8.RuneCountInString(d.Name); {
+	case n == 0:
+		errs.add("name", "обязательное поле")
+	case n > 255:
+		errs.add("name", "не длиннее 255 символов")
+	}
+	if utf8.RuneCountInString(d.Description) > 5000 {
+		errs.add("description", "не длиннее 5000 символов")
+	}
+	if d.LogoURL != nil && utf8.RuneCountInString(*d.LogoURL) > 1024 {
+		errs.add("logo_url", "не длиннее 1024 символов")
+	}
+	if d.Website != nil && utf8.RuneCountInString(*d.Website) > 255 {
+		errs.add("website", "не длиннее 255 символов")
+	}
+	if d.Phone != nil && utf8.RuneCountInString(*d.Phone) > 20 {
+		errs.add("phone", "не длиннее 20 символов")
+	}
+	if d.FoundedYear != nil && (*d.FoundedYear < 1800 || *d.FoundedYear > 2100) {
+		errs.add("founded_year", "год основания должен быть от 1800 до 2100")
+	}
+	return errs
+}
+
+var (
+	ErrDeveloperNotFound = errors.New("developer not found")
+	ErrDeveloperExists   = errors.New("developer with this name already exists")
+)
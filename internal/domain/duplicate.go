@@ -0,0 +1,35 @@
+package domain
+
+import "time"
+
+type DuplicateFlagStatus string
+
+const (
+	DuplicateFlagOpen      DuplicateFlagStatus = "open"
+	DuplicateFlagDismissed DuplicateFlagStatus = "dismissed"
+	DuplicateFlagConfirmed DuplicateFlagStatus = "confirmed"
+)
+
+// DuplicateFlag records that a photo of OfferID near-matches a photo of
+// another user's MatchedOfferID
+type DuplicateFlag struct {
+	ID              string
+	OfferID         string
+	OfferUserID     string
+	MatchedOfferID  string
+	MatchedUserID   string
+	PhotoURL        string
+	MatchedPhotoURL string
+	Distance        int // Hamming distance between the perceptual hashes
+	Status          DuplicateFlagStatus
+	CreatedAt       time.Time
+}
+
+// DuplicateCluster is a group of offers connected by open duplicate flags
+type DuplicateCluster struct {
+	OfferIDs      []string
+	UserIDs       []string
+	Flags         []DuplicateFlag
+	MinDistance   int
+	LastFlaggedAt time.Time
+}
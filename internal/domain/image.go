@@ -0,0 +1,70 @@
+package domain
+
+import (
+	"errors"
+	"time"
+)
+
+// ImageBlob is a stored image file addressed by the SHA-256 of its contents.
+type ImageBlob struct {
+	Hash              string
+	Filename          string
+	ContentType       string
+	SizeBytes         int64
+	PHash             uint64 // perceptual hash, 0 when unknown
+	RefCount          int
+	UnreferencedSince *time.Time // nullable, set while nothing points at the blob
+	CreatedAt         time.Time
+	UpdatedAt         time.Time
+}
+
+type ImageUploadState string
+
+const (
+	ImageUploadPending  ImageUploadState = "pending"
+	ImageUploadAttached ImageUploadState = "attached"
+	ImageUploadExpired  ImageUploadState = "expired"
+)
+
+// ImageUpload binds an uploaded blob to the user who uploaded it.
+// Pending uploads that are never attached expire automatically.
+type ImageUpload struct {
+	ID         string
+	UserID     string
+	BlobHash   string
+	Filename   string
+	URL        string
+	State      ImageUploadState
+	ExpiresAt  time.Time
+	AttachedAt *time.Time // nullable
+	CreatedAt  time.Time
+	UpdatedAt  time.Time
+}
+
+// PrivateFile is a document in the private bucket, readable only via signed URLs
+type PrivateFile struct {
+	ID           string
+	UserID       string
+	Filename     string
+	OriginalName string
+	ContentType  string
+	SizeBytes    int64
+	CreatedAt    time.Time
+	UpdatedAt    time.Time
+}
+
+// ImageGCReport describes one garbage collector pass over unreferenced blobs.
+type ImageGCReport struct {
+	DryRun     bool
+	Grace      time.Duration
+	StartedAt  time.Time
+	Candidates []ImageBlob
+	Deleted    []string
+	Failed     []string
+	FreedBytes int64
+}
+
+var (
+	ErrImageNotFound = errors.New("image not found")
+	ErrImageNotOwned = errors.New("image was not uploaded by this user or has expired")
+)
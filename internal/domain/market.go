@@ -0,0 +1,84 @@
+package domain
+
+import (
+	"math"
+	"time"
+)
+
+// MarketScope is the area market statistics are aggregated over
+type MarketScope string
+
+const (
+	MarketScopeComplex MarketScope = "complex"
+	MarketScopeMetro   MarketScope = "metro"  // offers whose nearest station it is
+	MarketScopeRegion  MarketScope = "region" // includes nested regions
+)
+
+func (s MarketScope) Valid() bool {
+	return s == MarketScopeComplex || s == MarketScopeMetro || s == MarketScopeRegion
+}
+
+// MaxRoomsGroup collects every offer with this many rooms or more
+const MaxRoomsGroup = 4
+
+// RoomsGroup is the rooms value offers are grouped by in the statistics
+func RoomsGroup(rooms int) int {
+	return min(rooms, MaxRoomsGroup)
+}
+
+// MarketStats is the asking price per square metre of the active offers in
+// one scope. Rooms is nil for all offers together.
+type MarketStats struct {
+	Scope        MarketScope
+	ScopeID      string
+	OfferType    OfferType
+	PropertyType PropertyType
+	Rooms        *int
+	P25          float64
+	Median       float64
+	P75          float64
+	Listings     int
+	RefreshedAt  time.Time
+}
+
+// MarketStatsQuery selects the statistics of one scope; nil filters match all
+type MarketStatsQuery struct {
+	Scope        MarketScope
+	ScopeID      string
+	OfferType    *OfferType
+	PropertyType *PropertyType
+}
+
+// PriceRating places an offer within the interquartile range of its peers
+type PriceRating string
+
+const (
+	PriceRatingBelowMarket PriceRating = "below_market"
+	PriceRatingFair        PriceRating = "fair"
+	PriceRatingAboveMarket PriceRating = "above_market"
+)
+
+// FairPrice is the badge shown on an offer: how its price per square metre
+// compares with similar offers nearby
+type FairPrice struct {
+	Rating      PriceRating
+	PricePerSqm float64
+	DiffPercent float64 // against the peer median, rounded to 0.1
+	Peers       MarketStats
+}
+
+// NewFairPrice rates the offer against its peers
+func NewFairPrice(offer *Offer, peers *MarketStats) *FairPrice {
+	perSqm := float64(offer.Price) / offer.Area
+	fp := &FairPrice{Rating: PriceRatingFair, PricePerSqm: math.Round(perSqm), Peers: *peers}
+	switch {
+	case perSqm < peers.P25:
+		fp.Rating = PriceRatingBelowMarket
+	case perSqm > peers.P75:
+		fp.Rating = PriceRatingAboveMarket
+	}
+	if peers.Median > 0 {
+		fp.DiffPercent = math.Round((perSqm/peers.Median-1)*1000) / 10
+	}
+	return fp
+}
@@ -0,0 +1,110 @@
+package domain
+
+import (
+	"errors"
+	"math"
+	"time"
+)
+
+type ModerationKind string
+type ModerationState string
+type ModerationAction string
+type ViolationSeverity string
+
+const (
+	ModerationKindNew  ModerationKind = "new"
+	ModerationKindEdit ModerationKind = "edit"
+
+	ModerationStatePending  ModerationState = "pending"
+	ModerationStateApproved ModerationState = "approved"
+	ModerationStateRejected ModerationState = "rejected"
+
+	ModerationActionSubmitted    ModerationAction = "submitted"
+	ModerationActionApproved     ModerationAction = "approved"
+	ModerationActionRejected     ModerationAction = "rejected"
+	ModerationActionAutoRejected ModerationAction = "auto_rejected"
+
+	// ViolationBlock rejects the submission without waiting for a moderator
+	ViolationBlock ViolationSeverity = "block"
+	// ViolationReview is shown to the moderator as a hint
+	ViolationReview ViolationSeverity = "review"
+)
+
+// RuleViolation is one finding of the automatic pre-screen
+type RuleViolation struct {
+	Rule     string            `json:"rule"`
+	Severity ViolationSeverity `json:"severity"`
+	Message  string            `json:"message"`
+}
+
+// ModerationItem is one submission of an offer to the moderation queue
+type ModerationItem struct {
+	ID          string
+	OfferID     string
+	OfferUserID string
+	OfferTitle  string
+	Kind        ModerationKind
+	State       ModerationState
+	Violations  []RuleViolation
+	SubmittedAt time.Time
+	DecidedBy   *string // nil while pending and for automatic rejections
+	DecidedAt   *time.Time
+	Reason      *string // rejection reason shown to the owner
+}
+
+// ModerationAuditEntry is one record of the moderation audit trail
+type ModerationAuditEntry struct {
+	ID         string
+	ItemID     *string // nil once the submission is gone
+	OfferID    string
+	Action     ModerationAction
+	ActorID    *string // nil for the automatic pre-screen
+	Reason     *string
+	Violations []RuleViolation
+	CreatedAt  time.Time
+}
+
+// HasBlockingViolation reports whether the pre-screen rejects the submission outright
+func HasBlockingViolation(violations []RuleViolation) bool {
+	for _, v := range violations {
+		if v.Severity == ViolationBlock {
+			return true
+		}
+	}
+	return false
+}
+
+// materialPriceChange is the relative price change that needs a new review
+const materialPriceChange = 0.2
+
+// IsMaterialChange reports whether an edit of a published offer changes what
+// buyers see enough to need another review. Small price corrections and
+// floor or deposit fixes do not.
+func IsMaterialChange(before, after *Offer, addedPhotos int) bool {
+	if addedPhotos > 0 {
+		return true
+	}
+	if before.Title != after.Title ||
+		before.Description != after.Description ||
+		before.Address != after.Address ||
+		before.LocationID != after.LocationID ||
+		before.OfferType != after.OfferType ||
+		before.PropertyType != after.PropertyType ||
+		before.Rooms != after.Rooms ||
+		before.Area != after.Area {
+		return true
+	}
+	if (before.HousingComplexID == nil) != (after.HousingComplexID == nil) ||
+		(before.HousingComplexID != nil && *before.HousingComplexID != *after.HousingComplexID) {
+		return true
+	}
+	if before.Price == 0 {
+		return after.Price != 0
+	}
+	return math.Abs(float64(after.Price-before.Price))/float64(before.Price) > materialPriceChange
+}
+
+var (
+	ErrModerationItemNotFound = errors.New("moderation item not found")
+	ErrModerationDecided      = errors.New("moderation item already decided")
+)
@@ -0,0 +1,29 @@
+package domain
+
+import (
+	"errors"
+	"time"
+)
+
+type NotificationKind string
+
+const (
+	NotificationViewingRequested   NotificationKind = "viewing_requested"
+	NotificationViewingConfirmed   NotificationKind = "viewing_confirmed"
+	NotificationViewingRescheduled NotificationKind = "viewing_rescheduled"
+	NotificationViewingCancelled   NotificationKind = "viewing_cancelled"
+)
+
+// Notification is an in-app message to a user about something they take
+// part in
+type Notification struct {
+	ID        string
+	UserID    string
+	Kind      NotificationKind
+	ViewingID *string // nullable
+	Message   string
+	ReadAt    *time.Time // nullable, unread
+	CreatedAt time.Time
+}
+
+var ErrNotificationNotFound = errors.New("notification not found")
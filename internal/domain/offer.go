@@ -15,12 +15,20 @@ const (
 	OfferTypeSale OfferType = "sale"
 	OfferTypeRent OfferType = "rent"
 
-	PropertyTypeHouse     PropertyType = "house"
-	PropertyTypeApartment PropertyType = "apartment"
+	PropertyTypeHouse      PropertyType = "house"
+	PropertyTypeApartment  PropertyType = "apartment"
+	PropertyTypeRoom       PropertyType = "room"       // a room in a shared apartment
+	PropertyTypeLand       PropertyType = "land"       // a land plot
+	PropertyTypeCommercial PropertyType = "commercial" // office, retail or warehouse
+	PropertyTypeGarage     PropertyType = "garage"     // garage, box or parking space
 
-	OfferStatusActive   OfferStatus = "active"
-	OfferStatusSold     OfferStatus = "sold"
-	OfferStatusArchived OfferStatus = "archived"
+	OfferStatusRejected   OfferStatus = "rejected" // sent back by moderation
+	OfferStatusModeration OfferStatus = "moderation"
+	OfferStatusActive     OfferStatus = "active"
+	OfferStatusPaused     OfferStatus = "paused"
+	OfferStatusSold       OfferStatus = "sold"
+	OfferStatusArchived   OfferStatus = "archived"
+	OfferStatusExpired    OfferStatus = "expired"
 )
 
 type Offer struct {
@@ -37,53 +45,104 @@ type Offer struct {
 	PropertyType     PropertyType
 	OfferType        OfferType
 	Status           OfferStatus
-	Floor            *int     // nullable
-	TotalFloors      *int     // nullable
-	Deposit          *int64   // nullable BIGINT
-	Commission       *int64   // nullable BIGINT
-	RentalPeriod     *string  // nullable
-	LivingArea       *float64 // nullable
-	KitchenArea      *float64 // nullable
+	Floor            *int            // nullable
+	TotalFloors      *int            // nullable
+	Deposit          *int64          // nullable BIGINT
+	Commission       *int64          // nullable BIGINT
+	RentalPeriod     *string         // nullable
+	LivingArea       *float64        // nullable
+	KitchenArea      *float64        // nullable
+	Attributes       OfferAttributes // see OfferAttributeCatalogue
 	Metro            *string
+	MetroStations    []OfferMetro // nearest first, up to MaxOfferMetroStations
 	ImageURLs        []string
+	StatusChangedAt  time.Time
+	PublishedAt      *time.Time // nullable, first time the offer went active
+	ExpiresAt        *time.Time // nullable, set while the offer is live
 	CreatedAt        time.Time
 	UpdatedAt        time.Time
+	Version          int        // bumped by every write, sent as the ETag
+	FairPrice        *FairPrice // comparison with similar offers, only on single-offer reads
+}
+
+// ApplyPatch sets the fields present in the patch. Location and complex are
+// taken as given; resolving them from the address is the caller's job.
+func (o *Offer) ApplyPatch(p Patch) {
+	patchValue(p, "location_id", &o.LocationID)
+	patchPtr(p, "housing_complex_id", &o.HousingComplexID)
+	patchValue(p, "title", &o.Title)
+	patchString(p, "description", &o.Description)
+	patchValue(p, "price", &o.Price)
+	patchValue(p, "area", &o.Area)
+	patchValue(p, "address", &o.Address)
+	patchValue(p, "rooms", &o.Rooms)
+	if v, ok := p["property_type"].(string); ok {
+		o.PropertyType = PropertyType(v)
+	}
+	if v, ok := p["offer_type"].(string); ok {
+		o.OfferType = OfferType(v)
+	}
+	patchPtr(p, "floor", &o.Floor)
+	patchPtr(p, "total_floors", &o.TotalFloors)
+	patchPtr(p, "deposit", &o.Deposit)
+	patchPtr(p, "commission", &o.Commission)
+	patchPtr(p, "rental_period", &o.RentalPeriod)
+	patchPtr(p, "living_area", &o.LivingArea)
+	patchPtr(p, "kitchen_area", &o.KitchenArea)
+	if p.Has("attributes") {
+		// Merged key by key as RFC 7396 does with nested objects; null drops all
+		attrs, ok := p["attributes"].(map[string]any)
+		if ok {
+			o.Attributes = o.Attributes.Merge(attrs)
+		} else {
+			o.Attributes = OfferAttributes{}
+		}
+	}
+	if p.Has("image_urls") {
+		o.ImageURLs, _ = p["image_urls"].([]string)
+	}
 }
 
 type OfferFilter struct {
-	OfferType    *string  `json:"offer_type"`
-	PropertyType *string  `json:"property_type"`
-	Rooms        *int     `json:"rooms"`
-	PriceMin     *int64   `json:"price_min"`
-	PriceMax     *int64   `json:"price_max"`
-	AreaMin      *float64 `json:"area_min"`
-	AreaMax      *float64 `json:"area_max"`
-	Status       *string  `json:"status"`
-	Utug         *bool    `json:"utug"`
-	Address      *string  `json:"address"`
+	OfferType    *string                `json:"offer_type"`
+	PropertyType *string                `json:"property_type"`
+	Rooms        *int                   `json:"rooms"`
+	PriceMin     *int64                 `json:"price_min"`
+	PriceMax     *int64                 `json:"price_max"`
+	AreaMin      *float64               `json:"area_min"`
+	AreaMax      *float64               `json:"area_max"`
+	Status       *string                `json:"status"`
+	Address      *string                `json:"address"`
+	ComplexID    *string                `json:"housing_complex_id"`
+	RegionSlug   *string                `json:"region"`             // the region and everything below it
+	MetroIDs     []string               `json:"metro_ids"`          // near any of the stations
+	MetroWalk    *int                   `json:"metro_walk_minutes"` // at most this walk from them, or from any station
+	Attributes   []OfferAttributeFilter `json:"attributes"`         // all must match
+	ShortTerm    *bool                  `json:"short_term"`         // let by the night, or not
+	CheckIn      *time.Time             `json:"check_in"`           // with CheckOut, free for the whole stay
+	CheckOut     *time.Time             `json:"check_out"`          // the day of departure
+	Sort         OfferSort              `json:"sort"`               // empty means newest
 }
 
 // For feed (simplified + joined data)
 type OfferInFeed struct {
-	ID           string
-	UserID       string
-	OfferType    OfferType
-	PropertyType PropertyType
-	Price        int64
-	Area         float64
-	Rooms        int
-	Floor        int
-	TotalFloors  int
-	Address      string
-	Metro        string
-	ImageURL     string
-	CreatedAt    time.Time
-	UpdatedAt    time.Time
-}
-
-type PricePoint struct {
-	Date  time.Time `json:"date"`
-	Price int64     `json:"price"`
+	ID            string
+	UserID        string
+	OfferType     OfferType
+	PropertyType  PropertyType
+	Price         int64
+	Area          float64
+	Rooms         int
+	Floor         int
+	TotalFloors   int
+	Address       string
+	Metro         string       // name of the nearest station
+	MetroStations []OfferMetro // nearest first, up to MaxOfferMetroStations
+	ImageURL      string
+	CreatedAt     time.Time
+	UpdatedAt     time.Time
+	Status        OfferStatus
+	Stats         *OfferStatsSummary // last 30 days, only in the owner's own list
 }
 
 type OffersInFeed struct {
@@ -0,0 +1,224 @@
+package domain
+
+import (
+	"math"
+	"slices"
+	"sort"
+)
+
+// AttributeKind is the type of an offer attribute value
+type AttributeKind string
+
+const (
+	AttributeKindEnum    AttributeKind = "enum"    // one of Values
+	AttributeKindBool    AttributeKind = "bool"    // true or false
+	AttributeKindInt     AttributeKind = "int"     // whole number within Min..Max
+	AttributeKindDecimal AttributeKind = "decimal" // number within Min..Max
+)
+
+// OfferAttribute describes one characteristic of an offer. Empty
+// PropertyTypes or OfferTypes mean the attribute fits any of them;
+// RequiredFor are the property types an offer can't be published without it.
+type OfferAttribute struct {
+	Key           string
+	Title         string
+	Kind          AttributeKind
+	Values        []string // enum only
+	Min, Max      float64  // int and decimal only
+	PropertyTypes []PropertyType
+	OfferTypes    []OfferType
+	RequiredFor   []PropertyType
+}
+
+// AppliesTo tells whether an offer of the types may carry the attribute
+func (a *OfferAttribute) AppliesTo(offerType OfferType, propertyType PropertyType) bool {
+	return (len(a.OfferTypes) == 0 || slices.Contains(a.OfferTypes, offerType)) &&
+		(len(a.PropertyTypes) == 0 || slices.Contains(a.PropertyTypes, propertyType))
+}
+
+// Numeric tells whether the attribute is filtered by a range
+func (a *OfferAttribute) Numeric() bool {
+	return a.Kind == AttributeKindInt || a.Kind == AttributeKindDecimal
+}
+
+// check returns why the value is not acceptable, or "" if it is. Values
+// are the ones encoding/json decodes into any.
+func (a *OfferAttribute) check(value any) string {
+	switch a.Kind {
+	case AttributeKindEnum:
+		if s, ok := value.(string); !ok || !slices.Contains(a.Values, s) {
+			return "недопустимое значение"
+		}
+	case AttributeKindBool:
+		if _, ok := value.(bool); !ok {
+			return "ожидается true или false"
+		}
+	case AttributeKindInt, AttributeKindDecimal:
+		n, ok := value.(float64)
+		switch {
+		case !ok:
+			return "ожидается число"
+		case a.Kind == AttributeKindInt && n != math.Trunc(n):
+			return "ожидается целое число"
+		case n < a.Min || n > a.Max:
+			return "значение вне допустимого диапазона"
+		}
+	}
+	return ""
+}
+
+var (
+	residentialProperties = []PropertyType{PropertyTypeApartment, PropertyTypeHouse, PropertyTypeRoom}
+	premisesProperties    = []PropertyType{PropertyTypeApartment, PropertyTypeHouse, PropertyTypeRoom, PropertyTypeCommercial}
+	builtProperties       = []PropertyType{PropertyTypeApartment, PropertyTypeHouse, PropertyTypeRoom, PropertyTypeCommercial, PropertyTypeGarage}
+	rentOnly              = []OfferType{OfferTypeRent}
+)
+
+// offerAttributes is the catalogue of attributes. A new attribute needs
+// only an entry here: storage, validation and the feed filter follow it.
+var offerAttributes = []OfferAttribute{
+	{Key: "apartment_rooms", Title: "Комнат в квартире", Kind: AttributeKindInt,
+		Min: 2, Max: 30, PropertyTypes: []PropertyType{PropertyTypeRoom}, RequiredFor: []PropertyType{PropertyTypeRoom}},
+	{Key: "land_category", Title: "Категория земель", Kind: AttributeKindEnum,
+		Values:        []string{"izhs", "snt", "agricultural", "industrial"},
+		PropertyTypes: []PropertyType{PropertyTypeLand}, RequiredFor: []PropertyType{PropertyTypeLand}},
+	{Key: "commercial_type", Title: "Тип помещения", Kind: AttributeKindEnum,
+		Values:        []string{"office", "retail", "warehouse"},
+		PropertyTypes: []PropertyType{PropertyTypeCommercial}, RequiredFor: []PropertyType{PropertyTypeCommercial}},
+	{Key: "garage_type", Title: "Тип машино-места", Kind: AttributeKindEnum,
+		Values:        []string{"garage", "box", "parking_space"},
+		PropertyTypes: []PropertyType{PropertyTypeGarage}, RequiredFor: []PropertyType{PropertyTypeGarage}},
+	{Key: "renovation", Title: "Ремонт", Kind: AttributeKindEnum,
+		Values: []string{"none", "cosmetic", "euro", "designer"}, PropertyTypes: premisesProperties},
+	{Key: "bathroom", Title: "Санузел", Kind: AttributeKindEnum,
+		Values: []string{"combined", "separate", "multiple"}, PropertyTypes: residentialProperties},
+	{Key: "balcony", Title: "Балкон", Kind: AttributeKindEnum,
+		Values:        []string{"none", "balcony", "loggia", "several"},
+		PropertyTypes: []PropertyType{PropertyTypeApartment, PropertyTypeRoom}},
+	{Key: "ceiling_height", Title: "Высота потолков, м", Kind: AttributeKindDecimal,
+		Min: 2, Max: 10, PropertyTypes: builtProperties},
+	{Key: "building_type", Title: "Тип дома", Kind: AttributeKindEnum,
+		Values:        []string{"panel", "brick", "monolith", "monolith_brick", "block", "wood"},
+		PropertyTypes: builtProperties},
+	{Key: "build_year", Title: "Год постройки", Kind: AttributeKindInt,
+		Min: 1800, Max: 2100, PropertyTypes: builtProperties},
+	{Key: "parking", Title: "Парковка", Kind: AttributeKindEnum,
+		Values: []string{"none", "open", "underground", "multilevel"}, PropertyTypes: premisesProperties},
+	{Key: "furniture", Title: "Мебель", Kind: AttributeKindBool, PropertyTypes: premisesProperties},
+	{Key: "appliances", Title: "Бытовая техника", Kind: AttributeKindBool, PropertyTypes: residentialProperties},
+	{Key: "pets_allowed", Title: "Можно с животными", Kind: AttributeKindBool,
+		PropertyTypes: residentialProperties, OfferTypes: rentOnly},
+	{Key: "children_allowed", Title: "Можно с детьми", Kind: AttributeKindBool,
+		PropertyTypes: residentialProperties, OfferTypes: rentOnly},
+}
+
+// OfferAttributeCatalogue lists every attribute in display order
+func OfferAttributeCatalogue() []OfferAttribute {
+	return slices.Clone(offerAttributes)
+}
+
+// LookupOfferAttribute finds an attribute by its key
+func LookupOfferAttribute(key string) (*OfferAttribute, bool) {
+	for i := range offerAttributes {
+		if offerAttributes[i].Key == key {
+			return &offerAttributes[i], true
+		}
+	}
+	return nil, false
+}
+
+// OfferAttributes are the attribute values of an offer by key, stored as a
+// JSON object
+type OfferAttributes map[string]any
+
+// Merge applies a merge patch of attributes: null removes a key
+func (a OfferAttributes) Merge(patch map[string]any) OfferAttributes {
+	merged := make(OfferAttributes, len(a)+len(patch))
+	for k, v := range a {
+		merged[k] = v
+	}
+	for k, v := range patch {
+		if v == nil {
+			delete(merged, k)
+		} else {
+			merged[k] = v
+		}
+	}
+	return merged
+}
+
+// Validate checks the values and, when the types are known, that they fit
+// the offer. Errors are reported as attributes.<key>.
+func (a OfferAttributes) Validate(offerType *OfferType, propertyType *PropertyType) ValidationErrors {
+	var errs ValidationErrors
+	keys := make([]string, 0, len(a))
+	for k := range a {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+
+	for _, key := range keys {
+		field := "attributes." + key
+		attr, ok := LookupOfferAttribute(key)
+		if !ok {
+			errs.add(field, "неизвестная характеристика")
+			continue
+		}
+		if msg := attr.check(a[key]); msg != "" {
+			errs.add(field, msg)
+			continue
+		}
+		if offerType != nil && propertyType != nil && !attr.AppliesTo(*offerType, *propertyType) {
+			errs.add(field, "не применимо к этому типу объявления")
+		}
+	}
+	return errs
+}
+
+// Missing lists the attributes the property type requires that are not set
+func (a OfferAttributes) Missing(propertyType PropertyType) []string {
+	var keys []string
+	for _, attr := range offerAttributes {
+		if _, ok := a[attr.Key]; !ok && slices.Contains(attr.RequiredFor, propertyType) {
+			keys = append(keys, attr.Key)
+		}
+	}
+	return keys
+}
+
+// OfferAttributeFilter narrows the feed by one attribute: enum values match
+// any of Values, bool ones Bool, numeric ones the Min..Max range
+type OfferAttributeFilter struct {
+	Key    string   `json:"key"`
+	Values []string `json:"values,omitempty"`
+	Bool   *bool    `json:"bool,omitempty"`
+	Min    *float64 `json:"min,omitempty"`
+	Max    *float64 `json:"max,omitempty"`
+}
+
+// Valid tells whether the filter fits its attribute
+func (f *OfferAttributeFilter) Valid() bool {
+	attr, ok := LookupOfferAttribute(f.Key)
+	if !ok {
+		return false
+	}
+	switch attr.Kind {
+	case AttributeKindEnum:
+		if len(f.Values) == 0 || f.Bool != nil || f.Min != nil || f.Max != nil {
+			return false
+		}
+		for _, v := range f.Values {
+			if !slices.Contains(attr.Values, v) {
+				return false
+			}
+		}
+		return true
+	case AttributeKindBool:
+		return f.Bool != nil && len(f.Values) == 0 && f.Min == nil && f.Max == nil
+	default:
+		if len(f.Values) > 0 || f.Bool != nil || (f.Min == nil && f.Max == nil) {
+			return false
+		}
+		return f.Min == nil || f.Max == nil || *f.Min <= *f.Max
+	}
+}
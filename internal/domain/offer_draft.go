@@ -0,0 +1,311 @@
+package domain
+
+import (
+	"errors"
+	"time"
+	"unicode/utf8"
+)
+
+// OfferDraft is an offer filled in over several sittings. Any field may be
+// unset until the draft is published.
+type OfferDraft struct {
+	ID        string
+	UserID    string
+	Fields    OfferDraftFields
+	CreatedAt time.Time
+	UpdatedAt time.Time
+}
+
+// OfferDraftFields is the partial offer content; nil means not filled in.
+// It is stored as JSON, image URLs separately.
+type OfferDraftFields struct {
+	LocationID       *string         `json:"location_id,omitempty"`
+	HousingComplexID *string         `json:"housing_complex_id,omitempty"`
+	OfferType        *OfferType      `json:"offer_type,omitempty"`
+	PropertyType     *PropertyType   `json:"property_type,omitempty"`
+	Title            *string         `json:"title,omitempty"`
+	Description      *string         `json:"description,omitempty"`
+	Price            *int64          `json:"price,omitempty"`
+	Area             *float64        `json:"area,omitempty"`
+	Address          *string         `json:"address,omitempty"`
+	Rooms            *int            `json:"rooms,omitempty"`
+	Floor            *int            `json:"floor,omitempty"`
+	TotalFloors      *int            `json:"total_floors,omitempty"`
+	Deposit          *int64          `json:"deposit,omitempty"`
+	Commission       *int64          `json:"commission,omitempty"`
+	RentalPeriod     *string         `json:"rental_period,omitempty"`
+	LivingArea       *float64        `json:"living_area,omitempty"`
+	KitchenArea      *float64        `json:"kitchen_area,omitempty"`
+	Attributes       OfferAttributes `json:"attributes,omitempty"`
+	ImageURLs        []string        `json:"-"`
+}
+
+// Apply copies the fields set in patch and then unsets the cleared ones,
+// named by their JSON names
+func (f *OfferDraftFields) Apply(patch *OfferDraftFields, cleared []string) {
+	if patch.LocationID != nil {
+		f.LocationID = patch.LocationID
+	}
+	if patch.HousingComplexID != nil {
+		f.HousingComplexID = patch.HousingComplexID
+	}
+	if patch.OfferType != nil {
+		f.OfferType = patch.OfferType
+	}
+	if patch.PropertyType != nil {
+		f.PropertyType = patch.PropertyType
+	}
+	if patch.Title != nil {
+		f.Title = patch.Title
+	}
+	if patch.Description != nil {
+		f.Description = patch.Description
+	}
+	if patch.Price != nil {
+		f.Price = patch.Price
+	}
+	if patch.Area != nil {
+		f.Area = patch.Area
+	}
+	if patch.Address != nil {
+		f.Address = patch.Address
+	}
+	if patch.Rooms != nil {
+		f.Rooms = patch.Rooms
+	}
+	if patch.Floor != nil {
+		f.Floor = patch.Floor
+	}
+	if patch.TotalFloors != nil {
+		f.TotalFloors = patch.TotalFloors
+	}
+	if patch.Deposit != nil {
+		f.Deposit = patch.Deposit
+	}
+	if patch.Commission != nil {
+		f.Commission = patch.Commission
+	}
+	if patch.RentalPeriod != nil {
+		f.RentalPeriod = patch.RentalPeriod
+	}
+	if patch.LivingArea != nil {
+		f.LivingArea = patch.LivingArea
+	}
+	if patch.KitchenArea != nil {
+		f.KitchenArea = patch.KitchenArea
+	}
+	if patch.Attributes != nil {
+		f.Attributes = f.Attributes.Merge(patch.Attributes)
+	}
+	if patch.ImageURLs != nil {
+		f.ImageURLs = patch.ImageURLs
+	}
+
+	for _, name := range cleared {
+		switch name {
+		case "location_id":
+			f.LocationID = nil
+		case "housing_complex_id":
+			f.HousingComplexID = nil
+		case "offer_type":
+			f.OfferType = nil
+		case "property_type":
+			f.PropertyType = nil
+		case "title":
+			f.Title = nil
+		case "description":
+			f.Description = nil
+		case "price":
+			f.Price = nil
+		case "area":
+			f.Area = nil
+		case "address":
+			f.Address = nil
+		case "rooms":
+			f.Rooms = nil
+		case "floor":
+			f.Floor = nil
+		case "total_floors":
+			f.TotalFloors = nil
+		case "deposit":
+			f.Deposit = nil
+		case "commission":
+			f.Commission = nil
+		case "rental_period":
+			f.RentalPeriod = nil
+		case "living_area":
+			f.LivingArea = nil
+		case "kitchen_area":
+			f.KitchenArea = nil
+		case "attributes":
+			f.Attributes = nil
+		case "image_urls":
+			f.ImageURLs = nil
+		}
+	}
+}
+
+// Validate checks the fields that are set. With complete it also requires
+// everything a published offer must have.
+func (f *OfferDraftFields) Validate(complete bool) ValidationErrors {
+	var errs ValidationErrors
+	const required = "обязательное поле"
+
+	if f.OfferType == nil {
+		if complete {
+			errs.add("offer_type", required)
+		}
+	} else if *f.OfferType != OfferTypeSale && *f.OfferType != OfferTypeRent {
+		errs.add("offer_type", "тип сделки должен быть sale или rent")
+	}
+
+	if f.PropertyType == nil {
+		if complete {
+			errs.add("property_type", required)
+		}
+	} else if !f.PropertyType.Valid() {
+		errs.add("property_type", "неизвестный тип недвижимости")
+	}
+
+	if f.Title == nil || *f.Title == "" {
+		if complete {
+			errs.add("title", required)
+		}
+	} else if utf8.RuneCountInString(*f.Title) > 255 {
+		errs.add("title", "не длиннее 255 символов")
+	}
+
+	if f.Description != nil && utf8.RuneCountInString(*f.Description) > 5000 {
+		errs.add("description", "не длиннее 5000 символов")
+	}
+
+	if f.Price == nil {
+		if complete {
+			errs.add("price", required)
+		}
+	} else if *f.Price <= 0 {
+		errs.add("price", "цена должна быть больше нуля")
+	}
+
+	if f.Area == nil {
+		if complete {
+			errs.add("area", required)
+		}
+	} else if *f.Area <= 0 {
+		errs.add("area", "площадь должна быть больше нуля")
+	}
+
+	if f.Address == nil || *f.Address == "" {
+		if complete {
+			errs.add("address", required)
+		}
+	} else if utf8.RuneCountInString(*f.Address) > 255 {
+		errs.add("address", "не длиннее 255 символов")
+	} else if complete && f.LocationID == nil {
+		errs.add("address", "адрес не распознан")
+	}
+
+	// Whether rooms are required depends on the property type
+	if f.Rooms != nil && *f.Rooms < 0 {
+		errs.add("rooms", "не может быть отрицательным")
+	}
+
+	if f.Floor != nil && *f.Floor < 0 {
+		errs.add("floor", "не может быть отрицательным")
+	}
+	if f.TotalFloors != nil && *f.TotalFloors < 0 {
+		errs.add("total_floors", "не может быть отрицательным")
+	}
+	if f.Floor != nil && f.TotalFloors != nil && *f.TotalFloors > 0 && *f.Floor > *f.TotalFloors {
+		errs.add("floor", "этаж больше этажности дома")
+	}
+
+	if f.Deposit != nil && *f.Deposit < 0 {
+		errs.add("deposit", "не может быть отрицательным")
+	}
+	if f.Commission != nil && *f.Commission < 0 {
+		errs.add("commission", "не может быть отрицательной")
+	}
+	if f.RentalPeriod != nil && utf8.RuneCountInString(*f.RentalPeriod) > 100 {
+		errs.add("rental_period", "не длиннее 100 символов")
+	}
+
+	for _, part := range []struct {
+		field string
+		value *float64
+	}{{"living_area", f.LivingArea}, {"kitchen_area", f.KitchenArea}} {
+		switch {
+		case part.value == nil:
+		case *part.value < 0:
+			errs.add(part.field, "не может быть отрицательной")
+		case f.Area != nil && *part.value > *f.Area:
+			errs.add(part.field, "больше общей площади")
+		}
+	}
+
+	f.validateForPropertyType(&errs, complete)
+	errs = append(errs, f.Attributes.Validate(f.OfferType, f.PropertyType)...)
+
+	return errs
+}
+
+// ToOffer builds the offer to publish; call it only after a complete Validate
+func (f *OfferDraftFields) ToOffer(userID string) *Offer {
+	offer := &Offer{
+		UserID:           userID,
+		LocationID:       *f.LocationID,
+		HousingComplexID: f.HousingComplexID,
+		Title:            *f.Title,
+		Price:            *f.Price,
+		Area:             *f.Area,
+		Address:          *f.Address,
+		PropertyType:     *f.PropertyType,
+		OfferType:        *f.OfferType,
+		Floor:            f.Floor,
+		TotalFloors:      f.TotalFloors,
+		Deposit:          f.Deposit,
+		Commission:       f.Commission,
+		RentalPeriod:     f.RentalPeriod,
+		LivingArea:       f.LivingArea,
+		KitchenArea:      f.KitchenArea,
+		Attributes:       f.Attributes,
+		ImageURLs:        f.ImageURLs,
+	}
+	if f.Description != nil {
+		offer.Description = *f.Description
+	}
+	// Land, commercial and garages may have no rooms
+	if f.Rooms != nil {
+		offer.Rooms = *f.Rooms
+	}
+	return offer
+}
+
+// Validate checks a whole offer against the same rules a draft must pass to
+// be published
+func (o *Offer) Validate() ValidationErrors {
+	f := OfferDraftFields{
+		OfferType:    &o.OfferType,
+		PropertyType: &o.PropertyType,
+		Title:        &o.Title,
+		Description:  &o.Description,
+		Price:        &o.Price,
+		Area:         &o.Area,
+		Address:      &o.Address,
+		Rooms:        &o.Rooms,
+		Floor:        o.Floor,
+		TotalFloors:  o.TotalFloors,
+		Deposit:      o.Deposit,
+		Commission:   o.Commission,
+		RentalPeriod: o.RentalPeriod,
+		LivingArea:   o.LivingArea,
+		KitchenArea:  o.KitchenArea,
+		Attributes:   o.Attributes,
+	}
+	if o.LocationID != "" {
+		f.LocationID = &o.LocationID
+	}
+	return f.Validate(true)
+}
+
+var ErrDraftNotFound = errors.New("offer draft not found")
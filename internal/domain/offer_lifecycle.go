@@ -0,0 +1,70 @@
+package domain
+
+import (
+	"errors"
+	"slices"
+	"time"
+)
+
+// offerTransitions lists every allowed status change. Moving in and out of
+// moderation from a published state and expiring are done by moderators,
+// material edits and background jobs only. Offers not yet submitted are
+// drafts (OfferDraft) and have no status.
+var offerTransitions = map[OfferStatus][]OfferStatus{
+	OfferStatusRejected:   {OfferStatusModeration, OfferStatusArchived},
+	OfferStatusModeration: {OfferStatusActive, OfferStatusRejected, OfferStatusArchived},
+	OfferStatusActive:     {OfferStatusPaused, OfferStatusSold, OfferStatusArchived, OfferStatusExpired, OfferStatusModeration},
+	OfferStatusPaused:     {OfferStatusActive, OfferStatusSold, OfferStatusArchived, OfferStatusExpired, OfferStatusModeration},
+	OfferStatusSold:       {OfferStatusArchived},
+	OfferStatusExpired:    {OfferStatusActive, OfferStatusArchived},
+	OfferStatusArchived:   {OfferStatusModeration},
+}
+
+// systemTransitions may not be requested by the offer owner
+var systemTransitions = map[OfferStatus][]OfferStatus{
+	OfferStatusModeration: {OfferStatusActive, OfferStatusRejected},
+	OfferStatusActive:     {OfferStatusExpired, OfferStatusModeration},
+	OfferStatusPaused:     {OfferStatusExpired, OfferStatusModeration},
+}
+
+func (s OfferStatus) Valid() bool {
+	_, ok := offerTransitions[s]
+	return ok
+}
+
+// CanTransitionTo reports whether the lifecycle allows moving from s to next
+func (s OfferStatus) CanTransitionTo(next OfferStatus) bool {
+	return slices.Contains(offerTransitions[s], next)
+}
+
+// OwnerCanTransitionTo is CanTransitionTo minus moderator and system-only moves
+func (s OfferStatus) OwnerCanTransitionTo(next OfferStatus) bool {
+	return s.CanTransitionTo(next) && !slices.Contains(systemTransitions[s], next)
+}
+
+// IsLive reports whether the offer counts towards its expiry period
+func (s OfferStatus) IsLive() bool {
+	return s == OfferStatusActive || s == OfferStatusPaused
+}
+
+// IsPublic reports whether anyone may open the offer, not only its owner and
+// moderators
+func (s OfferStatus) IsPublic() bool {
+	return s == OfferStatusActive || s == OfferStatusSold
+}
+
+// OfferStatusChange is one entry of the offer status history
+type OfferStatusChange struct {
+	ID         string
+	OfferID    string
+	FromStatus *OfferStatus // nil on creation
+	ToStatus   OfferStatus
+	ChangedBy  *string // nil for background jobs
+	Reason     *string
+	ChangedAt  time.Time
+}
+
+var (
+	ErrInvalidTransition = errors.New("offer status transition not allowed")
+	ErrStatusConflict    = errors.New("offer status changed concurrently")
+)
@@ -0,0 +1,112 @@
+package domain
+
+import "testing"
+
+var allOfferStatuses = []OfferStatus{
+	OfferStatusRejected,
+	OfferStatusModeration,
+	OfferStatusActive,
+	OfferStatusPaused,
+	OfferStatusSold,
+	OfferStatusExpired,
+	OfferStatusArchived,
+}
+
+func TestOfferStatusValid(t *testing.T) {
+	for _, s := range allOfferStatuses {
+		if !s.Valid() {
+			t.Errorf("статус %q должен быть допустимым", s)
+		}
+	}
+	for _, s := range []OfferStatus{"", "draft", "deleted"} {
+		if s.Valid() {
+			t.Errorf("статус %q не должен быть допустимым", s)
+		}
+	}
+}
+
+func TestOfferStatusTransitions(t *testing.T) {
+	// Every status pair not listed is forbidden for everyone
+	tests := []struct {
+		from, to OfferStatus
+		owner    bool // the owner may request it, not only moderators and jobs
+	}{
+		{OfferStatusRejected, OfferStatusModeration, true},
+		{OfferStatusRejected, OfferStatusArchived, true},
+
+		{OfferStatusModeration, OfferStatusActive, false},
+		{OfferStatusModeration, OfferStatusRejected, false},
+		{OfferStatusModeration, OfferStatusArchived, true},
+
+		{OfferStatusActive, OfferStatusPaused, true},
+		{OfferStatusActive, OfferStatusSold, true},
+		{OfferStatusActive, OfferStatusArchived, true},
+		{OfferStatusActive, OfferStatusExpired, false},
+		{OfferStatusActive, OfferStatusModeration, false},
+
+		{OfferStatusPaused, OfferStatusActive, true},
+		{OfferStatusPaused, OfferStatusSold, true},
+		{OfferStatusPaused, OfferStatusArchived, true},
+		{OfferStatusPaused, OfferStatusExpired, false},
+		{OfferStatusPaused, OfferStatusModeration, false},
+
+		{OfferStatusSold, OfferStatusArchived, true},
+
+		// Renewing an expired offer puts it back on the market
+		{OfferStatusExpired, OfferStatusActive, true},
+		{OfferStatusExpired, OfferStatusArchived, true},
+
+		{OfferStatusArchived, OfferStatusModeration, true},
+	}
+
+	allowed := make(map[[2]OfferStatus]bool)
+	for _, tt := range tests {
+		allowed[[2]OfferStatus{tt.from, tt.to}] = tt.owner
+	}
+
+	for _, from := range allOfferStatuses {
+		for _, to := range allOfferStatuses {
+			owner, ok := allowed[[2]OfferStatus{from, to}]
+			if got := from.CanTransitionTo(to); got != ok {
+				t.Errorf("%s -> %s: CanTransitionTo = %v, ожидалось %v", from, to, got, ok)
+			}
+			if got := from.OwnerCanTransitionTo(to); got != (ok && owner) {
+				t.Errorf("%s -> %s: OwnerCanTransitionTo = %v, ожидалось %v", from, to, got, ok && owner)
+			}
+		}
+	}
+}
+
+func TestOfferStatusTransitions_UnknownStatus(t *testing.T) {
+	for _, s := range allOfferStatuses {
+		if OfferStatus("draft").CanTransitionTo(s) {
+			t.Errorf("из неизвестного статуса не должно быть перехода в %s", s)
+		}
+		if s.CanTransitionTo("draft") {
+			t.Errorf("из %s не должно быть перехода в неизвестный статус", s)
+		}
+	}
+}
+
+func TestOfferStatusIsLive(t *testing.T) {
+	tests := []struct {
+		status       OfferStatus
+		live, public bool
+	}{
+		{OfferStatusRejected, false, false},
+		{OfferStatusModeration, false, false},
+		{OfferStatusActive, true, true},
+		{OfferStatusPaused, true, false},
+		{OfferStatusSold, false, true},
+		{OfferStatusExpired, false, false},
+		{OfferStatusArchived, false, false},
+	}
+	for _, tt := range tests {
+		if got := tt.status.IsLive(); got != tt.live {
+			t.Errorf("%s: IsLive = %v, ожидалось %v", tt.status, got, tt.live)
+		}
+		if got := tt.status.IsPublic(); got != tt.public {
+			t.Errorf("%s: IsPublic = %v, ожидалось %v", tt.status, got, tt.public)
+		}
+	}
+}
@@ -0,0 +1,40 @@
+package domain
+
+// OfferMetro is a station near the offer. Times are estimated from the
+// straight-line distance kept in location_metro.
+type OfferMetro struct {
+	StationID        string
+	Name             string
+	LineName         *string // nullable, stations may have no line
+	LineColor        *string // nullable
+	DistanceMeters   int
+	WalkMinutes      int
+	TransportMinutes int // by ground transport, waiting included
+}
+
+const (
+	// MaxOfferMetroStations is how many nearest stations an offer shows
+	MaxOfferMetroStations = 3
+
+	// WalkMetersPerMinute is about 4.8 km/h
+	WalkMetersPerMinute = 80
+
+	// TransportMetersPerMinute is about 15 km/h, a city bus with stops
+	TransportMetersPerMinute = 250
+	TransportWaitMinutes     = 5
+
+	// MaxMetroWalkMinutes bounds the walk filter of the feed
+	MaxMetroWalkMinutes = 60
+	// MaxMetroStationsInFilter bounds the station set of the feed filter
+	MaxMetroStationsInFilter = 20
+)
+
+// WalkMinutes estimates the walk to a station, rounding up
+func WalkMinutes(distanceMeters int) int {
+	return (distanceMeters + WalkMetersPerMinute - 1) / WalkMetersPerMinute
+}
+
+// TransportMinutes estimates the ride to a station, rounding up
+func TransportMinutes(distanceMeters int) int {
+	return TransportWaitMinutes + (distanceMeters+TransportMetersPerMinute-1)/TransportMetersPerMinute
+}
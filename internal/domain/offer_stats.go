@@ -0,0 +1,65 @@
+package domain
+
+import (
+	"errors"
+	"time"
+)
+
+// Viewer is whoever opened an offer. Guests have no UserID and are told
+// apart by address and browser.
+type Viewer struct {
+	UserID    *string
+	IP        string
+	UserAgent string
+}
+
+// OfferDayStats are the counters of one offer for one day
+type OfferDayStats struct {
+	Day            time.Time
+	Views          int
+	UniqueViewers  int // unique within the day
+	Favorites      int // added that day
+	ContactReveals int
+}
+
+// OfferStatsSummary totals the counters over a period. DailyViewers sums
+// the daily unique viewers, so a viewer coming back on another day counts
+// again. Favorites is the current number of users keeping the offer in
+// favorites.
+type OfferStatsSummary struct {
+	Views          int
+	DailyViewers   int
+	Favorites      int
+	ContactReveals int
+}
+
+// OfferStats is the performance of an offer as shown to its owner
+type OfferStats struct {
+	OfferID string
+	Period  int             // days, ending today
+	Days    []OfferDayStats // only days with activity
+	Summary OfferStatsSummary
+}
+
+// OfferContact is what a buyer gets on revealing the seller contacts
+type OfferContact struct {
+	OfferID   string
+	UserID    string
+	FirstName string
+	LastName  string
+	Phone     string
+}
+
+// OfferSort orders the offer feed
+type OfferSort string
+
+const (
+	OfferSortNewest  OfferSort = "newest"
+	OfferSortPopular OfferSort = "popular" // by activity over the last week
+)
+
+func (s OfferSort) Valid() bool {
+	return s == OfferSortNewest || s == OfferSortPopular
+}
+
+var ErrOfferNotListed = errors.New("offer is not on the market")
@@ -0,0 +1,48 @@
+package domain
+
+// Patch is a partial update in JSON Merge Patch (RFC 7396) form, keyed by
+// JSON field name. Only the listed fields change; a nil value clears one.
+// Values are already decoded to the field's Go type.
+type Patch map[string]any
+
+func (p Patch) Has(field string) bool {
+	_, ok := p[field]
+	return ok
+}
+
+// patchValue copies a non-nullable field; null is left for validation to report
+func patchValue[T any](p Patch, field string, dst *T) {
+	if v, ok := p[field].(T); ok {
+		*dst = v
+	}
+}
+
+// patchPtr copies a nullable field, clearing it on null
+func patchPtr[T any](p Patch, field string, dst **T) {
+	v, ok := p[field]
+	if !ok {
+		return
+	}
+	if v == nil {
+		*dst = nil
+		return
+	}
+	if tv, ok := v.(T); ok {
+		*dst = &tv
+	}
+}
+
+// patchString copies a string field whose null means empty
+func patchString(p Patch, field string, dst *string) {
+	v, ok := p[field]
+	if !ok {
+		return
+	}
+	if v == nil {
+		*dst = ""
+		return
+	}
+	if s, ok := v.(string); ok {
+		*dst = s
+	}
+}
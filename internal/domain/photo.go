@@ -0,0 +1,54 @@
+package domain
+
+import (
+	"errors"
+	"time"
+)
+
+type PhotoRoomTag string
+
+const (
+	PhotoRoomLivingRoom PhotoRoomTag = "living_room"
+	PhotoRoomBedroom    PhotoRoomTag = "bedroom"
+	PhotoRoomKitchen    PhotoRoomTag = "kitchen"
+	PhotoRoomBathroom   PhotoRoomTag = "bathroom"
+	PhotoRoomHallway    PhotoRoomTag = "hallway"
+	PhotoRoomBalcony    PhotoRoomTag = "balcony"
+	PhotoRoomExterior   PhotoRoomTag = "exterior"
+	PhotoRoomView       PhotoRoomTag = "view"
+	PhotoRoomFloorPlan  PhotoRoomTag = "floor_plan"
+	PhotoRoomOther      PhotoRoomTag = "other"
+)
+
+func (t PhotoRoomTag) Valid() bool {
+	switch t {
+	case PhotoRoomLivingRoom, PhotoRoomBedroom, PhotoRoomKitchen, PhotoRoomBathroom, PhotoRoomHallway,
+		PhotoRoomBalcony, PhotoRoomExterior, PhotoRoomView, PhotoRoomFloorPlan, PhotoRoomOther:
+		return true
+	}
+	return false
+}
+
+// Photo belongs either to an offer or to a housing complex (OwnerID)
+type Photo struct {
+	ID        string
+	OwnerID   string
+	URL       string
+	Position  int
+	IsCover   bool
+	Caption   *string       // nullable
+	RoomTag   *PhotoRoomTag // nullable
+	CreatedAt time.Time
+	UpdatedAt time.Time
+}
+
+// PhotoUpdate changes only the non-nil fields
+type PhotoUpdate struct {
+	Caption *string
+	RoomTag *PhotoRoomTag
+	IsCover *bool
+}
+
+var (
+	ErrPhotoNotFound = errors.New("photo not found")
+)
@@ -0,0 +1,96 @@
+package domain
+
+import (
+	"math"
+	"time"
+)
+
+// PriceEvent is one entry of the offer price history. The first one is the
+// listing price and has no OldPrice.
+type PriceEvent struct {
+	Date      time.Time
+	OldPrice  *int64
+	Price     int64
+	Reason    *string // "listed", "price_updated" or a free-form note
+	ChangedBy *string // nil once the user is deleted
+}
+
+// PriceHistory is the price history of an offer with metrics derived from it
+type PriceHistory struct {
+	OfferID       string
+	Events        []PriceEvent
+	InitialPrice  int64
+	CurrentPrice  int64
+	TotalChange   int64   // CurrentPrice - InitialPrice
+	ChangePercent float64 // relative to InitialPrice, rounded to 0.01
+	DaysOnMarket  int
+	Reductions    int
+}
+
+// NewPriceHistory derives the metrics from events sorted by date. The offer
+// is on the market from its first publication until it leaves the live
+// statuses; now closes the period for offers that are still listed.
+func NewPriceHistory(offer *Offer, events []PriceEvent, now time.Time) *PriceHistory {
+	h := &PriceHistory{OfferID: offer.ID, Events: events, CurrentPrice: offer.Price}
+	if len(events) > 0 {
+		h.InitialPrice = events[0].Price
+		h.CurrentPrice = events[len(events)-1].Price
+	} else {
+		h.InitialPrice = offer.Price
+	}
+	for i := 1; i < len(events); i++ {
+		if events[i].Price < events[i-1].Price {
+			h.Reductions++
+		}
+	}
+
+	h.TotalChange = h.CurrentPrice - h.InitialPrice
+	if h.InitialPrice > 0 {
+		h.ChangePercent = math.Round(float64(h.TotalChange)/float64(h.InitialPrice)*10000) / 100
+	}
+
+	if offer.PublishedAt != nil {
+		end := now
+		if !offer.Status.IsLive() && offer.Status != OfferStatusModeration {
+			end = offer.StatusChangedAt
+		}
+		if end.After(*offer.PublishedAt) {
+			h.DaysOnMarket = int(end.Sub(*offer.PublishedAt).Hours() / 24)
+		}
+	}
+	return h
+}
+
+// TrendBucket is the length of one trend period; the values are the
+// date_trunc field names
+type TrendBucket string
+
+const (
+	TrendBucketWeek  TrendBucket = "week"
+	TrendBucketMonth TrendBucket = "month"
+)
+
+func (b TrendBucket) Valid() bool {
+	return b == TrendBucketWeek || b == TrendBucketMonth
+}
+
+// PriceTrendQuery selects the offers and the periods of a trend. Sale and
+// rent prices are never mixed, so OfferType is required.
+type PriceTrendQuery struct {
+	Scope        MarketScope
+	ScopeID      string
+	Bucket       TrendBucket
+	OfferType    OfferType
+	PropertyType *PropertyType
+	From         time.Time
+	To           time.Time
+}
+
+// PriceTrendPoint aggregates the asking price per square metre of the offers
+// listed at the end of the period
+type PriceTrendPoint struct {
+	PeriodStart       time.Time
+	MedianPricePerSqm float64
+	AvgPricePerSqm    float64
+	Offers            int
+}
@@ -2,7 +2,9 @@ package domain
 
 import (
 	"errors"
+	"fmt"
 	"time"
+	"unicode/utf8"
 )
 
 type Profile struct {
@@ -16,15 +18,34 @@ type Profile struct {
 	AvatarURL string
 	CreatedAt time.Time
 	UpdatedAt time.Time
+	Version   int // bumped by every write, sent as the ETag; 0 before the first edit
 }
 
-type ProfileUpdate struct {
-	ID        string
-	FirstName string
-	LastName  string
-	Phone     string
-	Role      string
-	AvatarURL string
+// ApplyPatch sets the fields present in the patch; null clears them
+func (p *Profile) ApplyPatch(patch Patch) {
+	patchString(patch, "first_name", &p.FirstName)
+	patchString(patch, "last_name", &p.LastName)
+	patchString(patch, "phone", &p.Phone)
+	patchString(patch, "avatar_url", &p.AvatarURL)
+}
+
+// Validate mirrors the profile table constraints
+func (p *Profile) Validate() ValidationErrors {
+	var errs ValidationErrors
+	for _, f := range []struct {
+		field, value string
+		max          int
+	}{
+		{"first_name", p.FirstName, 100},
+		{"last_name", p.LastName, 100},
+		{"phone", p.Phone, 20},
+		{"avatar_url", p.AvatarURL, 1024},
+	} {
+		if utf8.RuneCountInString(f.value) > f.max {
+			errs.add(f.field, fmt.Sprintf("не длиннее %d символов", f.max))
+		}
+	}
+	return errs
 }
 
 type ProfileSecurityUpdate struct {
@@ -0,0 +1,94 @@
+package domain
+
+import (
+	"fmt"
+	"slices"
+)
+
+// propertyTypes lists every property type in display order
+var propertyTypes = []PropertyType{
+	PropertyTypeApartment, PropertyTypeRoom, PropertyTypeHouse,
+	PropertyTypeLand, PropertyTypeCommercial, PropertyTypeGarage,
+}
+
+func (t PropertyType) Valid() bool {
+	return slices.Contains(propertyTypes, t)
+}
+
+// propertyRules is what a property type asks of the offer on top of the
+// common rules; the attributes it requires are marked in the catalogue
+type propertyRules struct {
+	roomsRequired bool
+	minRooms      int
+	floorRequired bool     // floor and total floors
+	notApplicable []string // fields the type has no use for, by JSON name
+}
+
+var propertyTypeRules = map[PropertyType]propertyRules{
+	PropertyTypeApartment:  {roomsRequired: true},
+	PropertyTypeHouse:      {roomsRequired: true},
+	PropertyTypeRoom:       {roomsRequired: true, minRooms: 1, floorRequired: true},
+	PropertyTypeLand:       {notApplicable: []string{"rooms", "floor", "total_floors", "living_area", "kitchen_area"}},
+	PropertyTypeCommercial: {notApplicable: []string{"living_area", "kitchen_area"}},
+	PropertyTypeGarage:     {notApplicable: []string{"rooms", "living_area", "kitchen_area"}},
+}
+
+// validateForPropertyType checks the rules of the draft's property type.
+// With complete it also requires the fields and attributes the type needs.
+func (f *OfferDraftFields) validateForPropertyType(errs *ValidationErrors, complete bool) {
+	if f.PropertyType == nil || !f.PropertyType.Valid() {
+		return
+	}
+	rules := propertyTypeRules[*f.PropertyType]
+	const required = "обязательное поле"
+
+	if f.Rooms == nil {
+		if complete && rules.roomsRequired {
+			errs.add("rooms", required)
+		}
+	} else if *f.Rooms >= 0 && *f.Rooms < rules.minRooms {
+		errs.add("rooms", fmt.Sprintf("не меньше %d", rules.minRooms))
+	}
+	if complete && rules.floorRequired {
+		if f.Floor == nil {
+			errs.add("floor", required)
+		}
+		if f.TotalFloors == nil {
+			errs.add("total_floors", required)
+		}
+	}
+	for _, field := range rules.notApplicable {
+		if f.filled(field) {
+			errs.add(field, "не заполняется для этого типа недвижимости")
+		}
+	}
+
+	// A room is sold or let out of a larger apartment
+	if n, ok := f.Attributes["apartment_rooms"].(float64); ok && f.Rooms != nil && float64(*f.Rooms) >= n {
+		errs.add("rooms", "комнат должно быть меньше, чем в квартире")
+	}
+
+	if complete {
+		for _, key := range f.Attributes.Missing(*f.PropertyType) {
+			errs.add("attributes."+key, required)
+		}
+	}
+}
+
+// filled tells whether a numeric field is set. Zero counts as not filled,
+// the way the offer form sends the fields it doesn't show.
+func (f *OfferDraftFields) filled(field string) bool {
+	switch field {
+	case "rooms":
+		return f.Rooms != nil && *f.Rooms != 0
+	case "floor":
+		return f.Floor != nil && *f.Floor != 0
+	case "total_floors":
+		return f.TotalFloors != nil && *f.TotalFloors != 0
+	case "living_area":
+		return f.LivingArea != nil && *f.LivingArea != 0
+	case "kitchen_area":
+		return f.KitchenArea != nil && *f.KitchenArea != 0
+	}
+	return false
+}
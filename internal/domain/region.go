@@ -0,0 +1,118 @@
+package domain
+
+import (
+	"errors"
+	"regexp"
+	"time"
+	"unicode/utf8"
+)
+
+// Region is a node of the country → district → city tree. Level is the
+// depth and follows the parent, so it is never set by clients.
+type Region struct {
+	ID        string
+	Name      string
+	ParentID  *string // nullable, roots have none
+	Level     int
+	Slug      string
+	CreatedAt time.Time
+	UpdatedAt time.Time
+}
+
+// RegionTree is a region with the way down to it and its direct children
+type RegionTree struct {
+	Region
+	Path     []Region // ancestors from the root
+	Children []Region
+}
+
+var regionSlugPattern = regexp.MustCompile(`^[a-z0-9]+(-[a-z0-9]+)*$`)
+
+// Validate mirrors the region table constraints
+func (r *Region) Validate() ValidationErrors {
+	var errs ValidationErrors
+	switch n := utf8.RuneCountInString(r.Name); {
+	case n == 0:
+		errs.add("name", "обязательное поле")
+	case n > 255:
+		errs.add("name", "не длиннее 255 символов")
+	}
+	switch {
+	case len(r.Slug) > 255:
+		errs.add("slug", "не длиннее 255 символов")
+	case !regionSlugPattern.MatchString(r.Slug):
+		errs.add("slug", "латинские буквы в нижнем регистре, цифры и дефисы")
+	}
+	return errs
+}
+
+// MetroLine is a line of the metro in a city region
+type MetroLine struct {
+	ID        string
+	RegionID  string
+	Name      string
+	Color     string // #RRGGBB
+	CreatedAt time.Time
+	UpdatedAt time.Time
+}
+
+var metroLineColorPattern = regexp.MustCompile(`^#[0-9A-Fa-f]{6}$`)
+
+// Validate mirrors the metro_line table constraints
+func (l *MetroLine) Validate() ValidationErrors {
+	var errs ValidationErrors
+	switch n := utf8.RuneCountInString(l.Name); {
+	case n == 0:
+		errs.add("name", "обязательное поле")
+	case n > 100:
+		errs.add("name", "не длиннее 100 символов")
+	}
+	if !metroLineColorPattern.MatchString(l.Color) {
+		errs.add("color", "цвет в формате #RRGGBB")
+	}
+	return errs
+}
+
+// MetroStation is a station placed at its own location
+type MetroStation struct {
+	ID         string
+	Name       string
+	LocationID string
+	RegionID   string
+	Latitude   float64
+	Longitude  float64
+	Line       *MetroLine // nullable
+	CreatedAt  time.Time
+	UpdatedAt  time.Time
+}
+
+// Validate mirrors the metro_station and location table constraints
+func (s *MetroStation) Validate() ValidationErrors {
+	var errs ValidationErrors
+	switch n := utf8.RuneCountInString(s.Name); {
+	case n == 0:
+		errs.add("name", "обязательное поле")
+	case n > 100:
+		errs.add("name", "не длиннее 100 символов")
+	}
+	if s.Latitude < -90 || s.Latitude > 90 {
+		errs.add("latitude", "широта должна быть от -90 до 90")
+	}
+	if s.Longitude < -180 || s.Longitude > 180 {
+		errs.add("longitude", "долгота должна быть от -180 до 180")
+	}
+	return errs
+}
+
+// MetroLinkRadiusMeters bounds the locations a new or moved station is
+// linked to; distances are straight-line
+const MetroLinkRadiusMeters = 3000
+
+var (
+	ErrRegionNotFound       = errors.New("region not found")
+	ErrRegionSlugTaken      = errors.New("region slug already taken")
+	ErrRegionInUse          = errors.New("region has subregions or locations")
+	ErrMetroLineNotFound    = errors.New("metro line not found")
+	ErrMetroLineExists      = errors.New("metro line with this name already exists")
+	ErrMetroStationNotFound = errors.New("metro station not found")
+)
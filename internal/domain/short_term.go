@@ -0,0 +1,193 @@
+package domain
+
+import (
+	"errors"
+	"fmt"
+	"time"
+	"unicode/utf8"
+)
+
+// ShortTermTerms let a rent offer out by the night. An offer is in the
+// short-term mode while it has them.
+type ShortTermTerms struct {
+	OfferID      string
+	NightlyPrice int64
+	MinNights    int
+	MaxNights    *int // nullable, no upper bound
+	CreatedAt    time.Time
+	UpdatedAt    time.Time
+}
+
+const (
+	// MaxStayNights bounds a single stay and the terms
+	MaxStayNights = 365
+	// BookingHorizonDays is how far ahead nights can be booked or blocked
+	BookingHorizonDays = 365
+	// MaxCalendarDays bounds one calendar request
+	MaxCalendarDays = 366
+)
+
+// Validate mirrors the offer_short_term table constraints; only rent offers
+// are let by the night
+func (t *ShortTermTerms) Validate(offerType OfferType) ValidationErrors {
+	var errs ValidationErrors
+	if offerType != OfferTypeRent {
+		errs.add("offer_type", "посуточно сдаются только объявления об аренде")
+	}
+	if t.NightlyPrice <= 0 {
+		errs.add("nightly_price", "цена должна быть больше нуля")
+	}
+	if t.MinNights < 1 || t.MinNights > MaxStayNights {
+		errs.add("min_nights", "от 1 до 365 ночей")
+	}
+	if t.MaxNights != nil && (*t.MaxNights < t.MinNights || *t.MaxNights > MaxStayNights) {
+		errs.add("max_nights", "не меньше минимального срока и не больше 365 ночей")
+	}
+	return errs
+}
+
+// Fits tells whether a stay of the nights is allowed by the terms
+func (t *ShortTermTerms) Fits(nights int) bool {
+	return nights >= t.MinNights && (t.MaxNights == nil || nights <= *t.MaxNights)
+}
+
+// CheckStay reports a stay the terms don't allow
+func (t *ShortTermTerms) CheckStay(b *Booking) ValidationErrors {
+	var errs ValidationErrors
+	if t.Fits(b.Nights()) {
+		return nil
+	}
+	if t.MaxNights != nil {
+		errs.add("check_out", fmt.Sprintf("от %d до %d ночей", t.MinNights, *t.MaxNights))
+	} else {
+		errs.add("check_out", fmt.Sprintf("от %d ночей", t.MinNights))
+	}
+	return errs
+}
+
+type BookingKind string
+type BookingStatus string
+
+const (
+	BookingKindGuest   BookingKind = "guest"   // nights booked by a guest
+	BookingKindBlocked BookingKind = "blocked" // nights the owner closed
+
+	BookingStatusActive    BookingStatus = "active"
+	BookingStatusCancelled BookingStatus = "cancelled"
+)
+
+// Booking takes the nights from CheckIn up to CheckOut; the check-out day is
+// free for the next guest. Dates are UTC midnights.
+type Booking struct {
+	ID         string
+	OfferID    string
+	Kind       BookingKind
+	GuestID    *string // nullable, guest bookings only
+	CheckIn    time.Time
+	CheckOut   time.Time
+	TotalPrice *int64  // nullable, guest bookings only
+	Note       *string // nullable
+	Status     BookingStatus
+	CreatedAt  time.Time
+	UpdatedAt  time.Time
+}
+
+// Nights is the length of the stay
+func (b *Booking) Nights() int {
+	return StayNights(b.CheckIn, b.CheckOut)
+}
+
+// Validate checks the dates and the note
+func (b *Booking) Validate() ValidationErrors {
+	var errs ValidationErrors
+	if b.CheckIn.IsZero() {
+		errs.add("check_in", "обязательное поле")
+	}
+	switch {
+	case b.CheckOut.IsZero():
+		errs.add("check_out", "обязательное поле")
+	case !b.CheckIn.IsZero() && !b.CheckOut.After(b.CheckIn):
+		errs.add("check_out", "дата выезда должна быть позже даты заезда")
+	case b.Nights() > MaxStayNights:
+		errs.add("check_out", "не дольше 365 ночей")
+	}
+	if b.Note != nil && utf8.RuneCountInString(*b.Note) > 500 {
+		errs.add("note", "не длиннее 500 символов")
+	}
+	return errs
+}
+
+// ValidateFrom checks the booking and that it starts from today and within
+// the booking horizon
+func (b *Booking) ValidateFrom(today time.Time) ValidationErrors {
+	errs := b.Validate()
+	if b.CheckIn.IsZero() {
+		return errs
+	}
+	if b.CheckIn.Before(today) {
+		errs.add("check_in", "дата заезда уже прошла")
+	} else if b.CheckIn.After(today.AddDate(0, 0, BookingHorizonDays)) {
+		errs.add("check_in", "бронирование открыто на год вперёд")
+	}
+	return errs
+}
+
+// StayNights counts the nights between two dates
+func StayNights(checkIn, checkOut time.Time) int {
+	return int(checkOut.Sub(checkIn).Hours() / 24)
+}
+
+// Day truncates a time to its UTC date
+func Day(t time.Time) time.Time {
+	y, m, d := t.UTC().Date()
+	return time.Date(y, m, d, 0, 0, 0, 0, time.UTC)
+}
+
+type CalendarDayStatus string
+
+const (
+	CalendarDayFree    CalendarDayStatus = "free"
+	CalendarDayBooked  CalendarDayStatus = "booked"
+	CalendarDayBlocked CalendarDayStatus = "blocked"
+)
+
+// CalendarDay is the state of one night of an offer
+type CalendarDay struct {
+	Date   time.Time
+	Status CalendarDayStatus
+}
+
+// Calendar is the availability of a short-term offer by night
+type Calendar struct {
+	Terms ShortTermTerms
+	Days  []CalendarDay
+}
+
+// BuildCalendar lays the active bookings over the nights from from up to to
+func BuildCalendar(from, to time.Time, bookings []Booking) []CalendarDay {
+	days := make([]CalendarDay, 0, StayNights(from, to))
+	for d := from; d.Before(to); d = d.AddDate(0, 0, 1) {
+		day := CalendarDay{Date: d, Status: CalendarDayFree}
+		for i := range bookings {
+			b := &bookings[i]
+			if b.Status != BookingStatusActive || d.Before(b.CheckIn) || !d.Before(b.CheckOut) {
+				continue
+			}
+			day.Status = CalendarDayBooked
+			if b.Kind == BookingKindBlocked {
+				day.Status = CalendarDayBlocked
+			}
+			break
+		}
+		days = append(days, day)
+	}
+	return days
+}
+
+var (
+	ErrNotShortTerm       = errors.New("offer is not let by the night")
+	ErrDatesUnavailable   = errors.New("dates are already taken")
+	ErrBookingNotFound    = errors.New("booking not found")
+	ErrBookingOver        = errors.New("booking is already over or cancelled")
+	ErrShortTermHasGuests = errors.New("offer has upcoming guest bookings")
+)
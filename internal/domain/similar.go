@@ -0,0 +1,130 @@
+package domain
+
+import (
+	"cmp"
+	"math"
+	"slices"
+	"strings"
+	"time"
+)
+
+const (
+	// SimilarMaxDistanceKm is how far a similar offer may be when it shares
+	// neither the complex, the station nor the region with the target
+	SimilarMaxDistanceKm = 20
+
+	earthRadiusKm = 6371
+)
+
+// SimilarityFeatures are what an offer is compared on when recommending
+// offers like it. Candidates are already of the target's offer type, active
+// and within half to double its price.
+type SimilarityFeatures struct {
+	OfferID          string
+	PropertyType     PropertyType
+	HousingComplexID *string
+	MetroStationID   *string // the nearest station
+	RegionID         *string
+	Rooms            int
+	Area             float64
+	Price            int64
+	Lat, Lon         *float64 // nil when the location has no coordinates
+	CreatedAt        time.Time
+}
+
+// HaversineKm is the great-circle distance between two points in kilometres
+func HaversineKm(lat1, lon1, lat2, lon2 float64) float64 {
+	rad := func(deg float64) float64 { return deg * math.Pi / 180 }
+	dLat := rad(lat2 - lat1)
+	dLon := rad(lon2 - lon1)
+	h := math.Pow(math.Sin(dLat/2), 2) + math.Cos(rad(lat1))*math.Cos(rad(lat2))*math.Pow(math.Sin(dLon/2), 2)
+	return 2 * earthRadiusKm * math.Asin(math.Sqrt(h))
+}
+
+// distanceKm is the distance between the offers; false when either has no
+// coordinates
+func (f *SimilarityFeatures) distanceKm(other *SimilarityFeatures) (float64, bool) {
+	if f.Lat == nil || f.Lon == nil || other.Lat == nil || other.Lon == nil {
+		return 0, false
+	}
+	return HaversineKm(*f.Lat, *f.Lon, *other.Lat, *other.Lon), true
+}
+
+// IsNear reports whether the candidate is in the same area as the offer:
+// same complex, nearest station or region, or within SimilarMaxDistanceKm
+func (f *SimilarityFeatures) IsNear(c *SimilarityFeatures) bool {
+	if sameID(f.HousingComplexID, c.HousingComplexID) ||
+		sameID(f.MetroStationID, c.MetroStationID) ||
+		sameID(f.RegionID, c.RegionID) {
+		return true
+	}
+	km, ok := f.distanceKm(c)
+	return ok && km <= SimilarMaxDistanceKm
+}
+
+// SimilarityScore rates how much the candidate is like the offer, higher is
+// closer. The same complex weighs most, then the station, rooms, price and
+// distance; area and property type break ties.
+func (f *SimilarityFeatures) SimilarityScore(c *SimilarityFeatures) float64 {
+	var score float64
+	if sameID(f.HousingComplexID, c.HousingComplexID) {
+		score += 3
+	}
+	if sameID(f.MetroStationID, c.MetroStationID) {
+		score += 2
+	}
+	switch c.Rooms - f.Rooms {
+	case 0:
+		score += 2
+	case -1, 1:
+		score += 1
+	}
+	if c.PropertyType == f.PropertyType {
+		score += 1
+	}
+	// Full points for an equal value, none from 30% off
+	if f.Area > 0 {
+		score += 1.5 * max(0, 1-math.Abs(c.Area-f.Area)/f.Area/0.3)
+	}
+	if f.Price > 0 {
+		score += 2 * max(0, 1-math.Abs(float64(c.Price-f.Price))/float64(f.Price)/0.3)
+	}
+	if km, ok := f.distanceKm(c); ok {
+		score += 2 * max(0, 1-km/10)
+	}
+	return score
+}
+
+// RankSimilar keeps the candidates near the offer and orders them best
+// match first; equal scores go newest first
+func (f *SimilarityFeatures) RankSimilar(candidates []SimilarityFeatures) []SimilarityFeatures {
+	type scored struct {
+		SimilarityFeatures
+		score float64
+	}
+	ranked := make([]scored, 0, len(candidates))
+	for i := range candidates {
+		c := &candidates[i]
+		if c.OfferID == f.OfferID || !f.IsNear(c) {
+			continue
+		}
+		ranked = append(ranked, scored{*c, f.SimilarityScore(c)})
+	}
+	slices.SortStableFunc(ranked, func(a, b scored) int {
+		return cmp.Or(
+			cmp.Compare(b.score, a.score),
+			b.CreatedAt.Compare(a.CreatedAt),
+			strings.Compare(a.OfferID, b.OfferID),
+		)
+	})
+
+	result := make([]SimilarityFeatures, len(ranked))
+	for i := range ranked {
+		result[i] = ranked[i].SimilarityFeatures
+	}
+	return result
+}
+
+func sameID(a, b *string) bool {
+	return a != nil && b != nil && *a == *b
+}
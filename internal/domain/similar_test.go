@@ -0,0 +1,169 @@
+package domain
+
+import (
+	"math"
+	"slices"
+	"testing"
+	"time"
+)
+
+// kmNorth is the latitude shift that moves a point km kilometres north
+func kmNorth(km float64) float64 {
+	return km / (earthRadiusKm * math.Pi / 180)
+}
+
+func similarTarget() SimilarityFeatures {
+	lat, lon := 55.7558, 37.6173
+	region := "moscow"
+	return SimilarityFeatures{
+		OfferID:      "target",
+		PropertyType: PropertyTypeApartment,
+		RegionID:     &region,
+		Rooms:        2,
+		Area:         50,
+		Price:        10_000_000,
+		Lat:          &lat,
+		Lon:          &lon,
+	}
+}
+
+func TestHaversineKm(t *testing.T) {
+	tests := []struct {
+		name                   string
+		lat1, lon1, lat2, lon2 float64
+		want                   float64
+	}{
+		{"та же точка", 55.7558, 37.6173, 55.7558, 37.6173, 0},
+		{"градус широты", 0, 0, 1, 0, 111.19},
+		{"градус долготы на экваторе", 0, 0, 0, 1, 111.19},
+		{"Москва — Санкт-Петербург", 55.7558, 37.6173, 59.9343, 30.3351, 633.0},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := HaversineKm(tt.lat1, tt.lon1, tt.lat2, tt.lon2)
+			if math.Abs(got-tt.want) > 0.5 {
+				t.Errorf("ожидалось %.2f км, получили %.2f", tt.want, got)
+			}
+		})
+	}
+}
+
+func TestSimilarityScore(t *testing.T) {
+	complexID, stationID := "complex", "station"
+	tests := []struct {
+		name   string
+		modify func(c *SimilarityFeatures)
+		want   float64
+	}{
+		// rooms 2 + type 1 + area 1.5 + price 2 + distance 2
+		{"такое же объявление рядом", func(c *SimilarityFeatures) {}, 8.5},
+		{"тот же комплекс и станция", func(c *SimilarityFeatures) {
+			c.HousingComplexID, c.MetroStationID = &complexID, &stationID
+		}, 13.5},
+		{"на комнату больше", func(c *SimilarityFeatures) { c.Rooms = 3 }, 7.5},
+		{"на комнату меньше", func(c *SimilarityFeatures) { c.Rooms = 1 }, 7.5},
+		{"на две комнаты больше", func(c *SimilarityFeatures) { c.Rooms = 4 }, 6.5},
+		{"другой тип недвижимости", func(c *SimilarityFeatures) { c.PropertyType = PropertyTypeHouse }, 7.5},
+		{"площадь на 15% больше", func(c *SimilarityFeatures) { c.Area = 57.5 }, 7.75},
+		{"цена на 15% ниже", func(c *SimilarityFeatures) { c.Price = 8_500_000 }, 7.5},
+		{"цена на 15% выше", func(c *SimilarityFeatures) { c.Price = 11_500_000 }, 7.5},
+		{"цена дальше 30%", func(c *SimilarityFeatures) { c.Price = 14_000_000 }, 6.5},
+		{"в 5 км", func(c *SimilarityFeatures) {
+			lat := *c.Lat + kmNorth(5)
+			c.Lat = &lat
+		}, 7.5},
+		{"дальше 10 км", func(c *SimilarityFeatures) {
+			lat := *c.Lat + kmNorth(12)
+			c.Lat = &lat
+		}, 6.5},
+		{"без координат", func(c *SimilarityFeatures) { c.Lat, c.Lon = nil, nil }, 6.5},
+	}
+
+	target := similarTarget()
+	target.HousingComplexID, target.MetroStationID = &complexID, &stationID
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c := similarTarget()
+			c.OfferID = "candidate"
+			tt.modify(&c)
+			if got := target.SimilarityScore(&c); math.Abs(got-tt.want) > 0.01 {
+				t.Errorf("ожидался балл %.2f, получили %.2f", tt.want, got)
+			}
+		})
+	}
+}
+
+func TestIsNear(t *testing.T) {
+	complexID, otherRegion := "complex", "other"
+	tests := []struct {
+		name   string
+		modify func(c *SimilarityFeatures)
+		want   bool
+	}{
+		{"тот же регион", func(c *SimilarityFeatures) {}, true},
+		{"другой регион в 15 км", func(c *SimilarityFeatures) {
+			lat := *c.Lat + kmNorth(15)
+			c.Lat, c.RegionID = &lat, &otherRegion
+		}, true},
+		{"другой регион в 25 км", func(c *SimilarityFeatures) {
+			lat := *c.Lat + kmNorth(25)
+			c.Lat, c.RegionID = &lat, &otherRegion
+		}, false},
+		{"другой регион без координат", func(c *SimilarityFeatures) {
+			c.Lat, c.Lon, c.RegionID = nil, nil, &otherRegion
+		}, false},
+		{"тот же комплекс далеко", func(c *SimilarityFeatures) {
+			lat := *c.Lat + kmNorth(25)
+			c.Lat, c.RegionID, c.HousingComplexID = &lat, &otherRegion, &complexID
+		}, true},
+	}
+
+	target := similarTarget()
+	target.HousingComplexID = &complexID
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c := similarTarget()
+			c.OfferID = "candidate"
+			tt.modify(&c)
+			if got := target.IsNear(&c); got != tt.want {
+				t.Errorf("ожидалось %v, получили %v", tt.want, got)
+			}
+		})
+	}
+}
+
+func TestRankSimilar(t *testing.T) {
+	target := similarTarget()
+	now := time.Now()
+	otherRegion := "other"
+
+	candidate := func(id string, created time.Time, modify func(c *SimilarityFeatures)) SimilarityFeatures {
+		c := similarTarget()
+		c.OfferID, c.CreatedAt = id, created
+		modify(&c)
+		return c
+	}
+	candidates := []SimilarityFeatures{
+		candidate("rooms-off", now, func(c *SimilarityFeatures) { c.Rooms = 3 }),
+		candidate("far", now, func(c *SimilarityFeatures) {
+			lat := *c.Lat + kmNorth(30)
+			c.Lat, c.RegionID = &lat, &otherRegion
+		}),
+		candidate("same-old", now.Add(-time.Hour), func(c *SimilarityFeatures) {}),
+		candidate("target", now, func(c *SimilarityFeatures) {}),
+		candidate("same-new-b", now, func(c *SimilarityFeatures) {}),
+		candidate("same-new-a", now, func(c *SimilarityFeatures) {}),
+		candidate("price-off", now, func(c *SimilarityFeatures) { c.Price = 14_000_000 }),
+	}
+
+	var got []string
+	for _, c := range target.RankSimilar(candidates) {
+		got = append(got, c.OfferID)
+	}
+	// Best match first; equal scores newest first, then by id. The offer
+	// itself and those out of the area are left out.
+	want := []string{"same-new-a", "same-new-b", "same-old", "rooms-off", "price-off"}
+	if !slices.Equal(got, want) {
+		t.Errorf("ожидался порядок %v, получили %v", want, got)
+	}
+}
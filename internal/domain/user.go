@@ -11,6 +11,13 @@ const (
 	UserRoleUser    UserRole = "user"
 	UserRoleOwner   UserRole = "owner"
 	UserRoleRealtor UserRole = "realtor"
+
+	// Granted by admins together with a link to the developer the account acts for
+	UserRoleDeveloper UserRole = "developer"
+
+	// Staff roles are granted manually, never through the API
+	UserRoleModerator UserRole = "moderator"
+	UserRoleAdmin     UserRole = "admin"
 )
 
 type User struct {
@@ -29,4 +36,5 @@ type UserEmailUpdate struct {
 var (
 	ErrUserNotFound       = errors.New("user not found")
 	ErrInvalidCredentials = errors.New("invalid email or password")
+	ErrForbidden          = errors.New("access denied")
 )
\ No newline at end of file
@@ -0,0 +1,29 @@
+package domain
+
+import "strings"
+
+// FieldError describes why one input field was rejected. Field is the JSON name.
+type FieldError struct {
+	Field   string
+	Message string
+}
+
+// ValidationErrors reports every invalid field at once. It matches
+// ErrInvalidInput with errors.Is.
+type ValidationErrors []FieldError
+
+func (e ValidationErrors) Error() string {
+	fields := make([]string, len(e))
+	for i, fe := range e {
+		fields[i] = fe.Field
+	}
+	return "invalid fields: " + strings.Join(fields, ", ")
+}
+
+func (e ValidationErrors) Unwrap() error {
+	return ErrInvalidInput
+}
+
+func (e *ValidationErrors) add(field, message string) {
+	*e = append(*e, FieldError{Field: field, Message: message})
+}
@@ -0,0 +1,7 @@
+package domain
+
+import "errors"
+
+// ErrVersionMismatch means the resource changed after the client read it,
+// so the write was not applied
+var ErrVersionMismatch = errors.New("resource version mismatch")
@@ -0,0 +1,101 @@
+package domain
+
+import (
+	"errors"
+	"time"
+	"unicode/utf8"
+)
+
+// ViewingSlot is a time the owner is ready to show the offer
+type ViewingSlot struct {
+	ID        string
+	OfferID   string
+	StartsAt  time.Time
+	EndsAt    time.Time
+	Free      bool // no viewing holds it
+	CreatedAt time.Time
+}
+
+const (
+	MinViewingDuration = 15 * time.Minute
+	MaxViewingDuration = 3 * time.Hour
+	// ViewingHorizon is how far ahead slots can be opened
+	ViewingHorizon = 60 * 24 * time.Hour
+)
+
+// Validate checks the slot is ahead of now, within the horizon and of a
+// sensible length
+func (s *ViewingSlot) Validate(now time.Time) ValidationErrors {
+	var errs ValidationErrors
+	switch {
+	case s.StartsAt.IsZero():
+		errs.add("starts_at", "обязательное поле")
+	case !s.StartsAt.After(now):
+		errs.add("starts_at", "время уже прошло")
+	case s.StartsAt.After(now.Add(ViewingHorizon)):
+		errs.add("starts_at", "не дальше 60 дней вперёд")
+	}
+	switch d := s.EndsAt.Sub(s.StartsAt); {
+	case s.EndsAt.IsZero():
+		errs.add("ends_at", "обязательное поле")
+	case d < MinViewingDuration || d > MaxViewingDuration:
+		errs.add("ends_at", "просмотр длится от 15 минут до 3 часов")
+	}
+	return errs
+}
+
+type ViewingStatus string
+
+const (
+	ViewingStatusRequested ViewingStatus = "requested" // waits for the owner
+	ViewingStatusConfirmed ViewingStatus = "confirmed"
+	ViewingStatusCancelled ViewingStatus = "cancelled"
+)
+
+// Viewing is a buyer's visit to the offer. It keeps the time of its slot;
+// Sequence grows with every change of the time or status.
+type Viewing struct {
+	ID           string
+	OfferID      string
+	SlotID       *string // nullable, the slot may be removed later
+	BuyerID      string
+	OwnerID      string
+	OfferTitle   string
+	OfferAddress string
+	StartsAt     time.Time
+	EndsAt       time.Time
+	Status       ViewingStatus
+	Comment      *string // nullable, from the buyer
+	CancelReason *string // nullable
+	CancelledBy  *string // nullable
+	Sequence     int
+	CreatedAt    time.Time
+	UpdatedAt    time.Time
+}
+
+// Party tells whether the user is the buyer or the owner of the viewing
+func (v *Viewing) Party(userID string) bool {
+	return userID == v.BuyerID || userID == v.OwnerID
+}
+
+// Open tells whether the viewing can still be confirmed, moved or cancelled
+func (v *Viewing) Open(now time.Time) bool {
+	return v.Status != ViewingStatusCancelled && v.StartsAt.After(now)
+}
+
+// ValidateViewingText checks the buyer comment or cancel reason
+func ValidateViewingText(field string, text *string) ValidationErrors {
+	var errs ValidationErrors
+	if text != nil && utf8.RuneCountInString(*text) > 500 {
+		errs.add(field, "не длиннее 500 символов")
+	}
+	return errs
+}
+
+var (
+	ErrViewingSlotNotFound = errors.New("viewing slot not found")
+	ErrViewingSlotTaken    = errors.New("viewing time is already taken")
+	ErrViewingSlotOverlap  = errors.New("viewing slot overlaps another one")
+	ErrViewingNotFound     = errors.New("viewing not found")
+	ErrViewingClosed       = errors.New("viewing can no longer be changed")
+)
@@ -0,0 +1,25 @@
+package storage
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+)
+
+// LocalStorage gives direct access to the file server's storage directory
+type LocalStorage struct {
+	dir string
+}
+
+func NewLocalStorage(dir string) *LocalStorage {
+	return &LocalStorage{dir: dir}
+}
+
+// Remove deletes a stored file; a missing file is not an error
+func (s *LocalStorage) Remove(filename string) error {
+	err := os.Remove(filepath.Join(s.dir, filepath.Base(filename)))
+	if err != nil && !errors.Is(err, os.ErrNotExist) {
+		return err
+	}
+	return nil
+}
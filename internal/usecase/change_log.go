@@ -0,0 +1,36 @@
+package usecase
+
+import (
+	"context"
+
+	"github.com/go-park-mail-ru/2025_2_Avrora/internal/domain"
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+)
+
+// List searches the change log. At least one of entity, actor or request
+// must be given: the whole log is too large to page through.
+func (uc *changeLogUsecase) List(ctx context.Context, f *domain.ChangeLogFilter, limit, offset int) ([]domain.ChangeLogEntry, error) {
+	if f == nil || (f.EntityID == nil && f.ActorID == nil && f.RequestID == nil) {
+		uc.log.Warn(ctx, "change log query without a filter")
+		return nil, domain.ErrInvalidInput
+	}
+	if f.EntityType != nil && !f.EntityType.Valid() {
+		uc.log.Warn(ctx, "unknown change log entity type", zap.String("entity_type", string(*f.EntityType)))
+		return nil, domain.ErrInvalidInput
+	}
+	for _, id := range []*string{f.EntityID, f.ActorID} {
+		if id == nil {
+			continue
+		}
+		if _, err := uuid.Parse(*id); err != nil {
+			uc.log.Warn(ctx, "malformed ID in change log filter", zap.String("id", *id))
+			return nil, domain.ErrInvalidInput
+		}
+	}
+	if limit < 1 || limit > 100 || offset < 0 {
+		uc.log.Warn(ctx, "invalid change log paging", zap.Int("limit", limit), zap.Int("offset", offset))
+		return nil, domain.ErrInvalidInput
+	}
+	return uc.changeLogRepo.List(ctx, f, limit, offset)
+}
@@ -0,0 +1,21 @@
+package usecase
+
+import (
+	"context"
+
+	"github.com/go-park-mail-ru/2025_2_Avrora/internal/domain"
+	"github.com/go-park-mail-ru/2025_2_Avrora/internal/log"
+)
+
+type IChangeLogRepository interface {
+	List(ctx context.Context, f *domain.ChangeLogFilter, limit, offset int) ([]domain.ChangeLogEntry, error)
+}
+
+type changeLogUsecase struct {
+	changeLogRepo IChangeLogRepository
+	log           *log.Logger
+}
+
+func NewChangeLogUsecase(repo IChangeLogRepository, log *log.Logger) *changeLogUsecase {
+	return &changeLogUsecase{changeLogRepo: repo, log: log}
+}
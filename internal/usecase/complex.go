@@ -2,26 +2,185 @@ package usecase
 
 import (
 	"context"
+	"errors"
 
 	"github.com/go-park-mail-ru/2025_2_Avrora/internal/domain"
+	"github.com/google/uuid"
+	"go.uber.org/zap"
 )
 
+// GetByID returns the complex card with the stats of its active offers
 func (u *housingComplexUsecase) GetByID(ctx context.Context, id string) (*domain.HousingComplex, error) {
-	return u.complexRepo.GetByID(ctx, id)
+	complex, err := u.complexRepo.GetByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if complex.OfferStats, err = u.complexRepo.OfferStats(ctx, id); err != nil {
+		return nil, err
+	}
+	return complex, nil
+}
+
+// List returns a page of the complexes matching the filter
+func (u *housingComplexUsecase) List(ctx context.Context, f *domain.ComplexFilter, page, limit int) (*domain.ComplexesInFeed, error) {
+	if f == nil {
+		f = &domain.ComplexFilter{}
+	}
+	if err := u.checkFilter(ctx, f); err != nil {
+		return nil, err
+	}
+	return u.complexRepo.List(ctx, f, page, limit)
+}
+
+// checkAccess loads the complex and checks the user may manage it
+func (u *housingComplexUsecase) checkAccess(ctx context.Context, userID, complexID string) error {
+	complex, err := u.complexRepo.GetByID(ctx, complexID)
+	if err != nil {
+		return err
+	}
+	return u.access.CheckComplexAccess(ctx, userID, complex.DeveloperID)
+}
+
+func (u *housingComplexUsecase) checkFilter(ctx context.Context, f *domain.ComplexFilter) error {
+	for _, id := range []*string{f.RegionID, f.MetroStationID, f.DeveloperID} {
+		if id == nil {
+			continue
+		}
+		if _, err := uuid.Parse(*id); err != nil {
+			u.log.Warn(ctx, "malformed ID in complex filter", zap.String("id", *id))
+			return domain.ErrInvalidInput
+		}
+	}
+	if f.Completion != "" && !f.Completion.Valid() {
+		u.log.Warn(ctx, "invalid completion in complex filter", zap.String("completion", string(f.Completion)))
+		return domain.ErrInvalidInput
+	}
+	if f.Sort == "" {
+		f.Sort = domain.ComplexSortNewest
+	}
+	if !f.Sort.Valid() {
+		u.log.Warn(ctx, "invalid sort in complex filter", zap.String("sort", string(f.Sort)))
+		return domain.ErrInvalidInput
+	}
+	if (f.Lat == nil) != (f.Lon == nil) ||
+		f.Lat != nil && (*f.Lat < -90 || *f.Lat > 90 || *f.Lon < -180 || *f.Lon > 180) {
+		u.log.Warn(ctx, "invalid point in complex filter")
+		return domain.ErrInvalidInput
+	}
+	if f.Sort == domain.ComplexSortDistance && f.Lat == nil {
+		u.log.Warn(ctx, "distance sort without a point")
+		return domain.ErrInvalidInput
+	}
+	if f.YearBuiltMin != nil && f.YearBuiltMax != nil && *f.YearBuiltMin > *f.YearBuiltMax ||
+		f.PriceMin != nil && f.PriceMax != nil && *f.PriceMin > *f.PriceMax {
+		u.log.Warn(ctx, "empty range in complex filter")
+		return domain.ErrInvalidInput
+	}
+	return nil
 }
 
-func (u *housingComplexUsecase) List(ctx context.Context, page, limit int) (*domain.ComplexesInFeed, error) {
-	return u.complexRepo.List(ctx, page, limit)
+// Create adds a complex; developer accounts create it for their developer
+// unless another one is given
+func (u *housingComplexUsecase) Create(ctx context.Context, userID string, complex *domain.HousingComplex) error {
+	if complex.DeveloperID == nil {
+		developerID, err := u.access.MemberDeveloperID(ctx, userID)
+		if err != nil && !errors.Is(err, domain.ErrDeveloperNotFound) {
+			return err
+		}
+		if err == nil {
+			complex.DeveloperID = &developerID
+		}
+	}
+	if err := u.access.CheckComplexAccess(ctx, userID, complex.DeveloperID); err != nil {
+		return err
+	}
+	if err := u.images.CheckOwned(ctx, userID, complex.ImageURLs); err != nil {
+		return err
+	}
+	return u.complexRepo.Create(ctx, userID, complex)
 }
 
-func (u *housingComplexUsecase) Create(ctx context.Context, complex *domain.HousingComplex) error {
-	return u.complexRepo.Create(ctx, complex)
+// Update applies a merge patch to the complex and returns the result.
+// version is the one the client edited; nil skips the check.
+func (u *housingComplexUsecase) Update(ctx context.Context, userID, id string, patch domain.Patch, version *int) (*domain.HousingComplex, error) {
+	if id == "" || len(patch) == 0 {
+		u.log.Warn(ctx, "empty complex patch", zap.String("complex_id", id))
+		return nil, domain.ErrInvalidInput
+	}
+
+	existing, err := u.complexRepo.GetByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if err := matchVersion(existing.Version, version); err != nil {
+		return nil, err
+	}
+	if err := u.access.CheckComplexAccess(ctx, userID, existing.DeveloperID); err != nil {
+		return nil, err
+	}
+	updated := *existing
+	updated.ApplyPatch(patch)
+	if errs := updated.Validate(); len(errs) > 0 {
+		u.log.Warn(ctx, "invalid complex patch", zap.String("complex_id", id), zap.Error(errs))
+		return nil, errs
+	}
+	// Handing the complex over needs access to the new developer as well
+	if _, ok := patch["developer_id"]; ok && updated.DeveloperID != nil {
+		if err := u.access.CheckComplexAccess(ctx, userID, updated.DeveloperID); err != nil {
+			return nil, err
+		}
+	}
+	added := newImageURLs(existing.ImageURLs, updated.ImageURLs)
+	if err := u.images.CheckOwned(ctx, userID, added); err != nil {
+		return nil, err
+	}
+
+	if err := u.complexRepo.Update(ctx, userID, id, patch, version); err != nil {
+		return nil, err
+	}
+	return u.complexRepo.GetByID(ctx, id)
 }
 
-func (u *housingComplexUsecase) Update(ctx context.Context, complex *domain.HousingComplex) error {
-	return u.complexRepo.Update(ctx, complex)
+// Delete removes the complex if it is still at the version the client saw
+func (u *housingComplexUsecase) Delete(ctx context.Context, userID, id string, version *int) error {
+	existing, err := u.complexRepo.GetByID(ctx, id)
+	if err != nil {
+		return err
+	}
+	if err := matchVersion(existing.Version, version); err != nil {
+		return err
+	}
+	if err := u.access.CheckComplexAccess(ctx, userID, existing.DeveloperID); err != nil {
+		return err
+	}
+	return u.complexRepo.Delete(ctx, id, version)
 }
 
-func (u *housingComplexUsecase) Delete(ctx context.Context, id string) error {
-	return u.complexRepo.Delete(ctx, id)
-}
\ No newline at end of file
+// ListOffers returns the active offers of the complex matching the feed filters
+func (u *housingComplexUsecase) ListOffers(ctx context.Context, complexID string, f *domain.OfferFilter, limit, offset int) ([]domain.OfferInFeed, error) {
+	if f == nil {
+		return nil, domain.ErrInvalidInput
+	}
+	if limit < 1 || limit > 100 || offset < 0 {
+		u.log.Warn(ctx, "invalid complex offers paging", zap.Int("limit", limit), zap.Int("offset", offset))
+		return nil, domain.ErrInvalidInput
+	}
+	if err := validateOfferFilter(ctx, u.log, f); err != nil {
+		return nil, err
+	}
+	if _, err := u.complexRepo.GetByID(ctx, complexID); err != nil {
+		return nil, err
+	}
+
+	active := string(domain.OfferStatusActive)
+	f.Status = &active
+	f.ComplexID = &complexID
+	offers, err := u.offerRepo.FilterOffers(ctx, f, limit, offset)
+	if err != nil {
+		return nil, err
+	}
+	if offers == nil {
+		offers = []domain.OfferInFeed{}
+	}
+	return offers, nil
+}
@@ -0,0 +1,144 @@
+package usecase
+
+import (
+	"context"
+
+	"github.com/go-park-mail-ru/2025_2_Avrora/internal/domain"
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+)
+
+func (u *housingComplexUsecase) AddBuilding(ctx context.Context, userID string, building *domain.ComplexBuilding) error {
+	if err := u.checkBuilding(ctx, building); err != nil {
+		return err
+	}
+	if err := u.checkAccess(ctx, userID, building.ComplexID); err != nil {
+		return err
+	}
+	return u.complexRepo.AddBuilding(ctx, building)
+}
+
+// UpdateBuilding replaces every field of the building
+func (u *housingComplexUsecase) UpdateBuilding(ctx context.Context, userID string, building *domain.ComplexBuilding) error {
+	if err := u.checkBuilding(ctx, building); err != nil {
+		return err
+	}
+	if err := u.checkAccess(ctx, userID, building.ComplexID); err != nil {
+		return err
+	}
+	return u.complexRepo.UpdateBuilding(ctx, building)
+}
+
+func (u *housingComplexUsecase) DeleteBuilding(ctx context.Context, userID, complexID, buildingID string) error {
+	if err := u.checkAccess(ctx, userID, complexID); err != nil {
+		return err
+	}
+	return u.complexRepo.DeleteBuilding(ctx, complexID, buildingID)
+}
+
+func (u *housingComplexUsecase) checkBuilding(ctx context.Context, building *domain.ComplexBuilding) error {
+	if building == nil || building.ComplexID == "" {
+		return domain.ErrInvalidInput
+	}
+	if building.Stage == "" {
+		building.Stage = domain.ConstructionStagePlanned
+	}
+	if building.Phase == 0 {
+		building.Phase = 1
+	}
+	if errs := building.Validate(); len(errs) > 0 {
+		u.log.Warn(ctx, "invalid complex building", zap.String("complex_id", building.ComplexID), zap.Error(errs))
+		return errs
+	}
+	return nil
+}
+
+// AddLayout adds a layout; its floor plan must be an image the user uploaded
+func (u *housingComplexUsecase) AddLayout(ctx context.Context, userID string, layout *domain.ComplexLayout) error {
+	if err := u.checkLayout(ctx, layout); err != nil {
+		return err
+	}
+	if err := u.checkAccess(ctx, userID, layout.ComplexID); err != nil {
+		return err
+	}
+	urls := layoutImageURLs(layout)
+	if err := u.images.CheckOwned(ctx, userID, urls); err != nil {
+		return err
+	}
+	return u.complexRepo.AddLayout(ctx, userID, layout)
+}
+
+// UpdateLayout replaces every field of the layout
+func (u *housingComplexUsecase) UpdateLayout(ctx context.Context, userID string, layout *domain.ComplexLayout) error {
+	if err := u.checkLayout(ctx, layout); err != nil {
+		return err
+	}
+	if err := u.checkAccess(ctx, userID, layout.ComplexID); err != nil {
+		return err
+	}
+	existing, err := u.complexRepo.GetLayout(ctx, layout.ComplexID, layout.ID)
+	if err != nil {
+		return err
+	}
+	added := newImageURLs(layoutImageURLs(existing), layoutImageURLs(layout))
+	if err := u.images.CheckOwned(ctx, userID, added); err != nil {
+		return err
+	}
+	return u.complexRepo.UpdateLayout(ctx, userID, layout)
+}
+
+func (u *housingComplexUsecase) DeleteLayout(ctx context.Context, userID, complexID, layoutID string) error {
+	if err := u.checkAccess(ctx, userID, complexID); err != nil {
+		return err
+	}
+	return u.complexRepo.DeleteLayout(ctx, complexID, layoutID)
+}
+
+// checkLayout validates the layout and that its building is in the same complex
+func (u *housingComplexUsecase) checkLayout(ctx context.Context, layout *domain.ComplexLayout) error {
+	if layout == nil || layout.ComplexID == "" {
+		return domain.ErrInvalidInput
+	}
+	if errs := layout.Validate(); len(errs) > 0 {
+		u.log.Warn(ctx, "invalid complex layout", zap.String("complex_id", layout.ComplexID), zap.Error(errs))
+		return errs
+	}
+	if layout.BuildingID == nil {
+		return nil
+	}
+	if _, err := uuid.Parse(*layout.BuildingID); err != nil {
+		u.log.Warn(ctx, "malformed building ID", zap.String("building_id", *layout.BuildingID))
+		return domain.ErrInvalidInput
+	}
+	_, err := u.complexRepo.GetBuilding(ctx, layout.ComplexID, *layout.BuildingID)
+	return err
+}
+
+func layoutImageURLs(layout *domain.ComplexLayout) []string {
+	if layout.ImageURL == nil {
+		return nil
+	}
+	return []string{*layout.ImageURL}
+}
+
+// SetAmenities replaces the amenities of the complex; duplicates are ignored
+func (u *housingComplexUsecase) SetAmenities(ctx context.Context, userID, complexID string, amenities []domain.ComplexAmenity) ([]domain.ComplexAmenity, error) {
+	for _, a := range amenities {
+		if !a.Valid() {
+			u.log.Warn(ctx, "unknown complex amenity", zap.String("amenity", string(a)))
+			return nil, domain.ErrInvalidInput
+		}
+	}
+	if err := u.checkAccess(ctx, userID, complexID); err != nil {
+		return nil, err
+	}
+	if err := u.complexRepo.SetAmenities(ctx, complexID, amenities); err != nil {
+		return nil, err
+	}
+
+	complex, err := u.complexRepo.GetByID(ctx, complexID)
+	if err != nil {
+		return nil, err
+	}
+	return complex.Amenities, nil
+}
@@ -8,23 +8,48 @@ import (
 
 type IComplexRepository interface {
 	GetByID(ctx context.Context, id string) (*domain.HousingComplex, error)
-	List(ctx context.Context, page, limit int) (*domain.ComplexesInFeed, error)
-	Create(ctx context.Context, complex *domain.HousingComplex) error
-	Update(ctx context.Context, complex *domain.HousingComplex) error
-	Delete(ctx context.Context, id string) error
+	List(ctx context.Context, f *domain.ComplexFilter, page, limit int) (*domain.ComplexesInFeed, error)
+	Create(ctx context.Context, userID string, complex *domain.HousingComplex) error
+	Update(ctx context.Context, userID, id string, patch domain.Patch, version *int) error
+	Delete(ctx context.Context, id string, version *int) error
+
+	GetBuilding(ctx context.Context, complexID, buildingID string) (*domain.ComplexBuilding, error)
+	AddBuilding(ctx context.Context, building *domain.ComplexBuilding) error
+	UpdateBuilding(ctx context.Context, building *domain.ComplexBuilding) error
+	DeleteBuilding(ctx context.Context, complexID, buildingID string) error
+	GetLayout(ctx context.Context, complexID, layoutID string) (*domain.ComplexLayout, error)
+	AddLayout(ctx context.Context, userID string, layout *domain.ComplexLayout) error
+	UpdateLayout(ctx context.Context, userID string, layout *domain.ComplexLayout) error
+	DeleteLayout(ctx context.Context, complexID, layoutID string) error
+	SetAmenities(ctx context.Context, complexID string, amenities []domain.ComplexAmenity) error
+	OfferStats(ctx context.Context, complexID string) ([]domain.ComplexOfferStats, error)
+}
+
+// IComplexOfferRepository lists the offers linked to a complex
+type IComplexOfferRepository interface {
+	FilterOffers(ctx context.Context, f *domain.OfferFilter, limit, offset int) ([]domain.OfferInFeed, error)
 }
 
 type housingComplexUsecase struct {
 	complexRepo IComplexRepository
+	offerRepo   IComplexOfferRepository
+	access      IComplexAccess
+	images      IImageOwnership
 	log         *log.Logger
 }
 
 func NewHousingComplexUsecase(
 	complexRepo IComplexRepository,
+	offerRepo IComplexOfferRepository,
+	access IComplexAccess,
+	images IImageOwnership,
 	log *log.Logger,
 ) *housingComplexUsecase {
 	return &housingComplexUsecase{
 		complexRepo: complexRepo,
+		offerRepo:   offerRepo,
+		access:      access,
+		images:      images,
 		log:         log,
 	}
 }
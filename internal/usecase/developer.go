@@ -0,0 +1,131 @@
+package usecase
+
+import (
+	"context"
+	"errors"
+
+	"github.com/go-park-mail-ru/2025_2_Avrora/internal/domain"
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+)
+
+// GetByID returns the developer page; its complexes and delivered projects
+// are read through the complex feed filtered by developer_id
+func (u *developerUsecase) GetByID(ctx context.Context, id string) (*domain.Developer, error) {
+	return u.developerRepo.GetByID(ctx, id)
+}
+
+// List returns developers by name; query narrows them to names containing it
+func (u *developerUsecase) List(ctx context.Context, query *string, limit, offset int) ([]domain.Developer, error) {
+	if limit < 1 || limit > 100 || offset < 0 {
+		u.log.Warn(ctx, "invalid developers paging", zap.Int("limit", limit), zap.Int("offset", offset))
+		return nil, domain.ErrInvalidInput
+	}
+	developers, err := u.developerRepo.List(ctx, query, limit, offset)
+	if err != nil {
+		return nil, err
+	}
+	if developers == nil {
+		developers = []domain.Developer{}
+	}
+	return developers, nil
+}
+
+// Create adds a developer; the logo must be an image the user uploaded
+func (u *developerUsecase) Create(ctx context.Context, userID string, d *domain.Developer) error {
+	if errs := d.Validate(); len(errs) > 0 {
+		u.log.Warn(ctx, "invalid developer", zap.Error(errs))
+		return errs
+	}
+	urls := developerImageURLs(d)
+	if err := u.images.CheckOwned(ctx, userID, urls); err != nil {
+		return err
+	}
+	return u.developerRepo.Create(ctx, userID, d)
+}
+
+// Update replaces the profile of the developer; allowed to its accounts and admins
+func (u *developerUsecase) Update(ctx context.Context, userID string, d *domain.Developer) error {
+	if errs := d.Validate(); len(errs) > 0 {
+		u.log.Warn(ctx, "invalid developer", zap.String("developer_id", d.ID), zap.Error(errs))
+		return errs
+	}
+	existing, err := u.developerRepo.GetByID(ctx, d.ID)
+	if err != nil {
+		return err
+	}
+	if err := u.CheckComplexAccess(ctx, userID, &existing.ID); err != nil {
+		return err
+	}
+	added := newImageURLs(developerImageURLs(existing), developerImageURLs(d))
+	if err := u.images.CheckOwned(ctx, userID, added); err != nil {
+		return err
+	}
+	if err := u.developerRepo.Update(ctx, userID, d); err != nil {
+		return err
+	}
+	d.Complexes, d.Delivered, d.CreatedAt = existing.Complexes, existing.Delivered, existing.CreatedAt
+	return nil
+}
+
+func developerImageURLs(d *domain.Developer) []string {
+	if d.LogoURL == nil {
+		return nil
+	}
+	return []string{*d.LogoURL}
+}
+
+// AddMember links the account to the developer and grants it the developer role
+func (u *developerUsecase) AddMember(ctx context.Context, developerID, userID string) error {
+	if _, err := uuid.Parse(userID); err != nil {
+		u.log.Warn(ctx, "malformed member ID", zap.String("user_id", userID))
+		return domain.ErrInvalidInput
+	}
+	if _, err := u.developerRepo.GetByID(ctx, developerID); err != nil {
+		return err
+	}
+	if _, err := u.users.GetUserByUserID(ctx, userID); err != nil {
+		return err
+	}
+	return u.developerRepo.AddMember(ctx, developerID, userID)
+}
+
+// RemoveMember unlinks the account; it goes back to the user role
+func (u *developerUsecase) RemoveMember(ctx context.Context, developerID, userID string) error {
+	return u.developerRepo.RemoveMember(ctx, developerID, userID)
+}
+
+// MemberDeveloperID returns the developer the account acts for
+func (u *developerUsecase) MemberDeveloperID(ctx context.Context, userID string) (string, error) {
+	return u.developerRepo.MemberDeveloperID(ctx, userID)
+}
+
+// CheckComplexAccess lets admins manage any complex and developer accounts
+// only the complexes of their developer. Complexes without a developer stay
+// open to every signed in user.
+func (u *developerUsecase) CheckComplexAccess(ctx context.Context, userID string, developerID *string) error {
+	if developerID == nil {
+		return nil
+	}
+	if _, err := uuid.Parse(*developerID); err != nil {
+		u.log.Warn(ctx, "malformed developer ID", zap.String("developer_id", *developerID))
+		return domain.ErrInvalidInput
+	}
+	if _, err := u.developerRepo.GetByID(ctx, *developerID); err != nil {
+		return err
+	}
+
+	user, err := u.users.GetUserByUserID(ctx, userID)
+	if err != nil {
+		return err
+	}
+	if user.Role == domain.UserRoleAdmin {
+		return nil
+	}
+	memberOf, err := u.developerRepo.MemberDeveloperID(ctx, userID)
+	if errors.Is(err, domain.ErrDeveloperNotFound) || err == nil && memberOf != *developerID {
+		u.log.Warn(ctx, "complex of another developer", zap.String("user_id", userID), zap.String("developer_id", *developerID))
+		return domain.ErrForbidden
+	}
+	return err
+}
@@ -0,0 +1,40 @@
+package usecase
+
+import (
+	"context"
+
+	"github.com/go-park-mail-ru/2025_2_Avrora/internal/domain"
+	"github.com/go-park-mail-ru/2025_2_Avrora/internal/log"
+)
+
+type IDeveloperRepository interface {
+	GetByID(ctx context.Context, id string) (*domain.Developer, error)
+	List(ctx context.Context, query *string, limit, offset int) ([]domain.Developer, error)
+	Create(ctx context.Context, userID string, d *domain.Developer) error
+	Update(ctx context.Context, userID string, d *domain.Developer) error
+	MemberDeveloperID(ctx context.Context, userID string) (string, error)
+	AddMember(ctx context.Context, developerID, userID string) error
+	RemoveMember(ctx context.Context, developerID, userID string) error
+}
+
+// IUserLookup reads the account behind a user ID
+type IUserLookup interface {
+	GetUserByUserID(ctx context.Context, userID string) (*domain.User, error)
+}
+
+// IComplexAccess decides who may manage a complex of a developer
+type IComplexAccess interface {
+	CheckComplexAccess(ctx context.Context, userID string, developerID *string) error
+	MemberDeveloperID(ctx context.Context, userID string) (string, error)
+}
+
+type developerUsecase struct {
+	developerRepo IDeveloperRepository
+	users         IUserLookup
+	images        IImageOwnership
+	log           *log.Logger
+}
+
+func NewDeveloperUsecase(developerRepo IDeveloperRepository, users IUserLookup, images IImageOwnership, log *log.Logger) *developerUsecase {
+	return &developerUsecase{developerRepo: developerRepo, users: users, images: images, log: log}
+}
@@ -0,0 +1,171 @@
+package usecase
+
+import (
+	"context"
+	"time"
+
+	"github.com/go-park-mail-ru/2025_2_Avrora/internal/domain"
+	"go.uber.org/zap"
+)
+
+func (uc *imageUsecase) RegisterBlob(ctx context.Context, blob *domain.ImageBlob) error {
+	if blob == nil || blob.Hash == "" || blob.Filename == "" {
+		return domain.ErrInvalidInput
+	}
+	return uc.imageRepo.RegisterBlob(ctx, blob)
+}
+
+// RegisterUpload records the blob and opens a pending upload owned by userID
+func (uc *imageUsecase) RegisterUpload(ctx context.Context, userID, url string, blob *domain.ImageBlob) (*domain.ImageUpload, error) {
+	if userID == "" || url == "" {
+		uc.log.Warn(ctx, "empty user or url in RegisterUpload")
+		return nil, domain.ErrInvalidInput
+	}
+	if err := uc.RegisterBlob(ctx, blob); err != nil {
+		return nil, err
+	}
+
+	upload := &domain.ImageUpload{
+		UserID:    userID,
+		BlobHash:  blob.Hash,
+		Filename:  blob.Filename,
+		URL:       url,
+		ExpiresAt: time.Now().UTC().Add(uc.uploadTTL),
+	}
+	if err := uc.imageRepo.CreateUpload(ctx, upload); err != nil {
+		return nil, err
+	}
+	return upload, nil
+}
+
+// CheckOwned fails with domain.ErrImageNotOwned if any url lacks a live upload by userID
+func (uc *imageUsecase) CheckOwned(ctx context.Context, userID string, urls []string) error {
+	if len(urls) == 0 {
+		return nil
+	}
+	if userID == "" {
+		return domain.ErrImageNotOwned
+	}
+
+	unowned, err := uc.imageRepo.ListUnowned(ctx, userID, urls)
+	if err != nil {
+		return err
+	}
+	if len(unowned) > 0 {
+		uc.log.Warn(ctx, "images not owned by user", zap.String("user_id", userID), zap.Strings("urls", unowned))
+		return domain.ErrImageNotOwned
+	}
+	return nil
+}
+
+// ExpireUploads expires pending uploads whose deadline has passed
+func (uc *imageUsecase) ExpireUploads(ctx context.Context) error {
+	n, err := uc.imageRepo.ExpireUploads(ctx, time.Now().UTC())
+	if err != nil {
+		return err
+	}
+	if n > 0 {
+		uc.log.Info(ctx, "expired image uploads", zap.Int64("count", n))
+	}
+	return nil
+}
+
+func (uc *imageUsecase) RegisterPrivateFile(ctx context.Context, f *domain.PrivateFile) error {
+	if f == nil || f.UserID == "" || f.Filename == "" {
+		return domain.ErrInvalidInput
+	}
+	return uc.imageRepo.CreatePrivateFile(ctx, f)
+}
+
+// GetOwnedPrivateFile returns the private file only if userID uploaded it
+func (uc *imageUsecase) GetOwnedPrivateFile(ctx context.Context, userID, filename string) (*domain.PrivateFile, error) {
+	if userID == "" || filename == "" {
+		return nil, domain.ErrInvalidInput
+	}
+
+	f, err := uc.imageRepo.GetPrivateFile(ctx, filename)
+	if err != nil {
+		return nil, err
+	}
+	if f.UserID != userID {
+		uc.log.Warn(ctx, "private file requested by non-owner", zap.String("user_id", userID), zap.String("filename", filename))
+		return nil, domain.ErrImageNotOwned
+	}
+	return f, nil
+}
+
+// newImageURLs returns the urls from next that are not already in current
+func newImageURLs(current, next []string) []string {
+	seen := make(map[string]struct{}, len(current))
+	for _, u := range current {
+		seen[u] = struct{}{}
+	}
+
+	var added []string
+	for _, u := range next {
+		if _, ok := seen[u]; !ok {
+			added = append(added, u)
+		}
+	}
+	return added
+}
+
+// CollectGarbage removes blobs that have stayed unreferenced longer than grace.
+// With dryRun the report lists the candidates without touching anything.
+func (uc *imageUsecase) CollectGarbage(ctx context.Context, grace time.Duration, dryRun bool) (*domain.ImageGCReport, error) {
+	if grace < 0 {
+		return nil, domain.ErrInvalidInput
+	}
+
+	report := &domain.ImageGCReport{
+		DryRun:    dryRun,
+		Grace:     grace,
+		StartedAt: time.Now().UTC(),
+	}
+
+	candidates, err := uc.imageRepo.ListUnreferenced(ctx, report.StartedAt.Add(-grace))
+	if err != nil {
+		uc.log.Error(ctx, "failed to list gc candidates", zap.Error(err))
+		return nil, err
+	}
+	report.Candidates = candidates
+
+	if dryRun {
+		for _, b := range candidates {
+			report.FreedBytes += b.SizeBytes
+		}
+		uc.log.Info(ctx, "image gc dry run finished", zap.Int("candidates", len(candidates)))
+		return report, nil
+	}
+
+	remove := func(filename string) error {
+		if err := uc.storage.Remove(filename); err != nil {
+			uc.log.Error(ctx, "failed to remove image file", zap.String("filename", filename), zap.Error(err))
+			return err
+		}
+		return nil
+	}
+
+	for _, b := range candidates {
+		// The file goes while the blob row is locked, so no upload or
+		// reference can revive the blob halfway through
+		deleted, err := uc.imageRepo.DeleteUnreferenced(ctx, b.Hash, remove)
+		if err != nil {
+			report.Failed = append(report.Failed, b.Filename)
+			continue
+		}
+		if !deleted {
+			// Got referenced again between listing and deletion
+			continue
+		}
+		report.Deleted = append(report.Deleted, b.Filename)
+		report.FreedBytes += b.SizeBytes
+	}
+
+	uc.log.Info(ctx, "image gc finished",
+		zap.Int("candidates", len(candidates)),
+		zap.Int("deleted", len(report.Deleted)),
+		zap.Int("failed", len(report.Failed)),
+		zap.Int64("freed_bytes", report.FreedBytes))
+	return report, nil
+}
@@ -0,0 +1,43 @@
+package usecase
+
+import (
+	"context"
+	"time"
+
+	"github.com/go-park-mail-ru/2025_2_Avrora/internal/domain"
+	"github.com/go-park-mail-ru/2025_2_Avrora/internal/log"
+)
+
+type IImageRepository interface {
+	RegisterBlob(ctx context.Context, blob *domain.ImageBlob) error
+	ListUnreferenced(ctx context.Context, olderThan time.Time) ([]domain.ImageBlob, error)
+	DeleteUnreferenced(ctx context.Context, hash string, remove func(filename string) error) (bool, error)
+	CreateUpload(ctx context.Context, upload *domain.ImageUpload) error
+	ListUnowned(ctx context.Context, userID string, urls []string) ([]string, error)
+	ExpireUploads(ctx context.Context, now time.Time) (int64, error)
+	CreatePrivateFile(ctx context.Context, f *domain.PrivateFile) error
+	GetPrivateFile(ctx context.Context, filename string) (*domain.PrivateFile, error)
+}
+
+// IBlobStorage is the place image files physically live in
+type IBlobStorage interface {
+	Remove(filename string) error
+}
+
+// IImageOwnership is used by offer, complex and profile writes to make sure
+// every referenced image was uploaded by the caller. The repositories attach
+// the uploads in the transaction of the write.
+type IImageOwnership interface {
+	CheckOwned(ctx context.Context, userID string, urls []string) error
+}
+
+type imageUsecase struct {
+	imageRepo IImageRepository
+	storage   IBlobStorage
+	uploadTTL time.Duration
+	log       *log.Logger
+}
+
+func NewImageUsecase(repo IImageRepository, storage IBlobStorage, uploadTTL time.Duration, log *log.Logger) *imageUsecase {
+	return &imageUsecase{imageRepo: repo, storage: storage, uploadTTL: uploadTTL, log: log}
+}
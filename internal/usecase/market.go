@@ -0,0 +1,50 @@
+package usecase
+
+import (
+	"context"
+
+	"github.com/go-park-mail-ru/2025_2_Avrora/internal/domain"
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+)
+
+// Stats returns the price statistics of a complex, metro station or region,
+// for all offers and by rooms
+func (uc *marketUsecase) Stats(ctx context.Context, q *domain.MarketStatsQuery) ([]domain.MarketStats, error) {
+	if q == nil || !q.Scope.Valid() {
+		uc.log.Warn(ctx, "invalid market stats scope")
+		return nil, domain.ErrInvalidInput
+	}
+	if _, err := uuid.Parse(q.ScopeID); err != nil {
+		uc.log.Warn(ctx, "malformed market stats scope ID", zap.String("scope_id", q.ScopeID))
+		return nil, domain.ErrInvalidInput
+	}
+	if ot := q.OfferType; ot != nil && *ot != domain.OfferTypeSale && *ot != domain.OfferTypeRent {
+		uc.log.Warn(ctx, "invalid offer type in market stats", zap.String("offer_type", string(*ot)))
+		return nil, domain.ErrInvalidInput
+	}
+	if pt := q.PropertyType; pt != nil && !pt.Valid() {
+		uc.log.Warn(ctx, "invalid property type in market stats", zap.String("property_type", string(*pt)))
+		return nil, domain.ErrInvalidInput
+	}
+	return uc.marketRepo.List(ctx, q)
+}
+
+// FairPrice rates an active offer against offers with the same deal,
+// property type and rooms nearby. It returns nil when the offer is not on
+// the market or there are too few peers to judge.
+func (uc *marketUsecase) FairPrice(ctx context.Context, offer *domain.Offer) (*domain.FairPrice, error) {
+	if offer.Status != domain.OfferStatusActive || offer.Area <= 0 {
+		return nil, nil
+	}
+	peers, err := uc.marketRepo.PeerStats(ctx, offer, minPriceSamples)
+	if err != nil || peers == nil {
+		return nil, err
+	}
+	return domain.NewFairPrice(offer, peers), nil
+}
+
+// RefreshStats is run periodically to recompute the statistics
+func (uc *marketUsecase) RefreshStats(ctx context.Context) error {
+	return uc.marketRepo.Refresh(ctx)
+}
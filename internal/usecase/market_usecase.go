@@ -0,0 +1,23 @@
+package usecase
+
+import (
+	"context"
+
+	"github.com/go-park-mail-ru/2025_2_Avrora/internal/domain"
+	"github.com/go-park-mail-ru/2025_2_Avrora/internal/log"
+)
+
+type IMarketRepository interface {
+	Refresh(ctx context.Context) error
+	List(ctx context.Context, q *domain.MarketStatsQuery) ([]domain.MarketStats, error)
+	PeerStats(ctx context.Context, offer *domain.Offer, minListings int) (*domain.MarketStats, error)
+}
+
+type marketUsecase struct {
+	marketRepo IMarketRepository
+	log        *log.Logger
+}
+
+func NewMarketUsecase(repo IMarketRepository, log *log.Logger) *marketUsecase {
+	return &marketUsecase{marketRepo: repo, log: log}
+}
@@ -0,0 +1,266 @@
+package usecase
+
+import (
+	"context"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/go-park-mail-ru/2025_2_Avrora/internal/domain"
+	"go.uber.org/zap"
+)
+
+// maxDuplicateFlagsScanned bounds how many open flags are grouped per request
+const maxDuplicateFlagsScanned = 5000
+
+// Submit pre-screens an offer that has just entered the moderation status and
+// puts it into the queue. Blocking findings reject it right away, which is
+// reflected in offer.Status.
+func (uc *moderationUsecase) Submit(ctx context.Context, offer *domain.Offer, kind domain.ModerationKind) error {
+	item := &domain.ModerationItem{
+		OfferID:     offer.ID,
+		OfferUserID: offer.UserID,
+		OfferTitle:  offer.Title,
+		Kind:        kind,
+		State:       domain.ModerationStatePending,
+		Violations:  uc.prescreen(ctx, offer),
+		SubmittedAt: time.Now().UTC(),
+	}
+	if err := uc.moderationRepo.Submit(ctx, item); err != nil {
+		return err
+	}
+	if !domain.HasBlockingViolation(item.Violations) {
+		return nil
+	}
+
+	var msgs []string
+	for _, v := range item.Violations {
+		if v.Severity == domain.ViolationBlock {
+			msgs = append(msgs, v.Message)
+		}
+	}
+	reason := strings.Join(msgs, "; ")
+	uc.log.Info(ctx, "offer rejected by pre-screen", zap.String("offer_id", offer.ID), zap.String("reason", reason))
+	if err := uc.decide(ctx, item, domain.ModerationActionAutoRejected, nil, &reason); err != nil {
+		return err
+	}
+	offer.Status = domain.OfferStatusRejected
+	offer.StatusChangedAt = *item.DecidedAt
+	return nil
+}
+
+// prescreen runs every rule; a rule that fails to run is skipped so the
+// offer still reaches a moderator
+func (uc *moderationUsecase) prescreen(ctx context.Context, offer *domain.Offer) []domain.RuleViolation {
+	violations := []domain.RuleViolation{}
+	for _, rule := range uc.rules {
+		v, err := rule.Check(ctx, offer)
+		if err != nil {
+			uc.log.Warn(ctx, "moderation rule failed", zap.String("offer_id", offer.ID), zap.Error(err))
+			continue
+		}
+		if v != nil {
+			violations = append(violations, *v)
+		}
+	}
+	return violations
+}
+
+// Queue returns pending submissions, oldest first
+func (uc *moderationUsecase) Queue(ctx context.Context, limit, offset int) ([]domain.ModerationItem, error) {
+	if limit < 1 || limit > 100 || offset < 0 {
+		uc.log.Warn(ctx, "invalid moderation queue paging", zap.Int("limit", limit), zap.Int("offset", offset))
+		return nil, domain.ErrInvalidInput
+	}
+	return uc.moderationRepo.ListPending(ctx, limit, offset)
+}
+
+// Approve publishes the offer and starts its listing period
+func (uc *moderationUsecase) Approve(ctx context.Context, moderatorID, itemID string) (*domain.ModerationItem, error) {
+	item, err := uc.pendingItem(ctx, itemID)
+	if err != nil {
+		return nil, err
+	}
+	if err := uc.decide(ctx, item, domain.ModerationActionApproved, &moderatorID, nil); err != nil {
+		return nil, err
+	}
+	return item, nil
+}
+
+// Reject sends the offer back to its owner, who sees the reason
+func (uc *moderationUsecase) Reject(ctx context.Context, moderatorID, itemID, reason string) (*domain.ModerationItem, error) {
+	reason = strings.TrimSpace(reason)
+	if reason == "" || len([]rune(reason)) > maxStatusReasonLength {
+		uc.log.Warn(ctx, "invalid rejection reason", zap.String("item_id", itemID))
+		return nil, domain.ErrInvalidInput
+	}
+	item, err := uc.pendingItem(ctx, itemID)
+	if err != nil {
+		return nil, err
+	}
+	if err := uc.decide(ctx, item, domain.ModerationActionRejected, &moderatorID, &reason); err != nil {
+		return nil, err
+	}
+	return item, nil
+}
+
+func (uc *moderationUsecase) pendingItem(ctx context.Context, itemID string) (*domain.ModerationItem, error) {
+	if itemID == "" {
+		return nil, domain.ErrInvalidInput
+	}
+	item, err := uc.moderationRepo.GetItem(ctx, itemID)
+	if err != nil {
+		return nil, err
+	}
+	if item.State != domain.ModerationStatePending {
+		return nil, domain.ErrModerationDecided
+	}
+	return item, nil
+}
+
+// decide closes the submission and moves the offer out of moderation.
+// moderatorID is nil for the automatic pre-screen.
+func (uc *moderationUsecase) decide(ctx context.Context, item *domain.ModerationItem, action domain.ModerationAction, moderatorID, reason *string) error {
+	now := time.Now().UTC()
+	from := domain.OfferStatusModeration
+	change := &domain.OfferStatusChange{
+		OfferID:    item.OfferID,
+		FromStatus: &from,
+		ToStatus:   domain.OfferStatusRejected,
+		ChangedBy:  moderatorID,
+		Reason:     reason,
+		ChangedAt:  now,
+	}
+
+	var expiresAt *time.Time
+	item.State = domain.ModerationStateRejected
+	if action == domain.ModerationActionApproved {
+		t := now.Add(uc.offerTTL)
+		expiresAt = &t
+		change.ToStatus = domain.OfferStatusActive
+		item.State = domain.ModerationStateApproved
+	}
+	item.DecidedBy = moderatorID
+	item.DecidedAt = &now
+	item.Reason = reason
+
+	if err := uc.moderationRepo.Decide(ctx, item, action, change, expiresAt); err != nil {
+		return err
+	}
+	uc.log.Info(ctx, "moderation decision",
+		zap.String("item_id", item.ID),
+		zap.String("offer_id", item.OfferID),
+		zap.String("action", string(action)))
+	return nil
+}
+
+// OfferModeration returns the latest submission of the user's offer,
+// including the rejection reason
+func (uc *moderationUsecase) OfferModeration(ctx context.Context, userID, offerID string) (*domain.ModerationItem, error) {
+	if offerID == "" {
+		return nil, domain.ErrInvalidInput
+	}
+	offer, err := uc.offerRepo.GetByID(ctx, offerID)
+	if err != nil {
+		return nil, err
+	}
+	if offer.UserID != userID {
+		return nil, domain.ErrForbidden
+	}
+	return uc.moderationRepo.GetLatestForOffer(ctx, offerID)
+}
+
+// Audit returns every submission and decision for the offer
+func (uc *moderationUsecase) Audit(ctx context.Context, offerID string) ([]domain.ModerationAuditEntry, error) {
+	if offerID == "" {
+		return nil, domain.ErrInvalidInput
+	}
+	return uc.moderationRepo.ListAudit(ctx, offerID)
+}
+
+// ListDuplicateClusters groups offers linked by open duplicate flags: if A
+// matches B and B matches C, all three land in one cluster
+func (uc *moderationUsecase) ListDuplicateClusters(ctx context.Context, limit int) ([]domain.DuplicateCluster, error) {
+	if limit < 1 || limit > 100 {
+		uc.log.Warn(ctx, "invalid duplicate cluster limit", zap.Int("limit", limit))
+		return nil, domain.ErrInvalidInput
+	}
+
+	flags, err := uc.moderationRepo.ListOpenDuplicateFlags(ctx, maxDuplicateFlagsScanned)
+	if err != nil {
+		return nil, err
+	}
+
+	clusters := buildDuplicateClusters(flags)
+	if len(clusters) > limit {
+		clusters = clusters[:limit]
+	}
+	return clusters, nil
+}
+
+func buildDuplicateClusters(flags []domain.DuplicateFlag) []domain.DuplicateCluster {
+	parent := make(map[string]string)
+	var find func(string) string
+	find = func(id string) string {
+		if _, ok := parent[id]; !ok {
+			parent[id] = id
+		}
+		if parent[id] != id {
+			parent[id] = find(parent[id])
+		}
+		return parent[id]
+	}
+
+	owners := make(map[string]string)
+	for _, f := range flags {
+		owners[f.OfferID] = f.OfferUserID
+		owners[f.MatchedOfferID] = f.MatchedUserID
+		if a, b := find(f.OfferID), find(f.MatchedOfferID); a != b {
+			parent[a] = b
+		}
+	}
+
+	byRoot := make(map[string]*domain.DuplicateCluster)
+	for _, f := range flags {
+		root := find(f.OfferID)
+		c, ok := byRoot[root]
+		if !ok {
+			c = &domain.DuplicateCluster{MinDistance: f.Distance}
+			byRoot[root] = c
+		}
+		c.Flags = append(c.Flags, f)
+		if f.Distance < c.MinDistance {
+			c.MinDistance = f.Distance
+		}
+		if f.CreatedAt.After(c.LastFlaggedAt) {
+			c.LastFlaggedAt = f.CreatedAt
+		}
+	}
+
+	for offerID := range owners {
+		c := byRoot[find(offerID)]
+		c.OfferIDs = append(c.OfferIDs, offerID)
+	}
+
+	clusters := make([]domain.DuplicateCluster, 0, len(byRoot))
+	for _, c := range byRoot {
+		sort.Strings(c.OfferIDs)
+		seen := make(map[string]bool)
+		for _, id := range c.OfferIDs {
+			if u := owners[id]; !seen[u] {
+				seen[u] = true
+				c.UserIDs = append(c.UserIDs, u)
+			}
+		}
+		clusters = append(clusters, *c)
+	}
+
+	// Biggest and most recently active clusters first
+	sort.Slice(clusters, func(i, j int) bool {
+		if len(clusters[i].OfferIDs) != len(clusters[j].OfferIDs) {
+			return len(clusters[i].OfferIDs) > len(clusters[j].OfferIDs)
+		}
+		return clusters[i].LastFlaggedAt.After(clusters[j].LastFlaggedAt)
+	})
+	return clusters
+}
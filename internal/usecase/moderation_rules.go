@@ -0,0 +1,169 @@
+package usecase
+
+import (
+	"context"
+	"fmt"
+	"regexp"
+	"strings"
+
+	"github.com/go-park-mail-ru/2025_2_Avrora/internal/domain"
+)
+
+// ModerationRule is one check of the automatic pre-screen. Check returns nil
+// when the offer passes.
+type ModerationRule interface {
+	Check(ctx context.Context, offer *domain.Offer) (*domain.RuleViolation, error)
+}
+
+// IPrescreenRepository provides the market and duplicate data the rules look at
+type IPrescreenRepository interface {
+	MedianPricePerSqmInComplex(ctx context.Context, offer *domain.Offer) (float64, int, error)
+	MedianPricePerSqmInRegion(ctx context.Context, offer *domain.Offer) (float64, int, error)
+	CountOpenDuplicateFlags(ctx context.Context, offerID string) (int, error)
+}
+
+// defaultBannedWords are matched as word prefixes, case-insensitively
+var defaultBannedWords = []string{"казино", "букмекер", "наркот", "закладк", "эскорт", "интим", "обнал"}
+
+// DefaultModerationRules is the pre-screen used in production
+func DefaultModerationRules(stats IPrescreenRepository) []ModerationRule {
+	return []ModerationRule{
+		newBannedWordsRule(defaultBannedWords),
+		contactInfoRule{},
+		missingPhotosRule{},
+		priceOutlierRule{stats: stats},
+		duplicatePhotosRule{stats: stats},
+	}
+}
+
+type bannedWordsRule struct {
+	pattern *regexp.Regexp
+}
+
+func newBannedWordsRule(words []string) bannedWordsRule {
+	quoted := make([]string, len(words))
+	for i, w := range words {
+		quoted[i] = regexp.QuoteMeta(w)
+	}
+	return bannedWordsRule{pattern: regexp.MustCompile(`(?i)(?:^|[^\p{L}])(` + strings.Join(quoted, "|") + `)`)}
+}
+
+func (r bannedWordsRule) Check(_ context.Context, offer *domain.Offer) (*domain.RuleViolation, error) {
+	m := r.pattern.FindStringSubmatch(offer.Title + "\n" + offer.Description)
+	if m == nil {
+		return nil, nil
+	}
+	return &domain.RuleViolation{
+		Rule:     "banned_words",
+		Severity: domain.ViolationBlock,
+		Message:  fmt.Sprintf("недопустимое слово в тексте объявления: %q", m[1]),
+	}, nil
+}
+
+var contactPatterns = []*regexp.Regexp{
+	regexp.MustCompile(`(?:\+7|(?:^|[^\d])8)[\s\-(]*\d{3}[\s\-)]*\d{3}[\s\-]*\d{2}[\s\-]*\d{2}`), // phone
+	regexp.MustCompile(`[\w.+\-]+@[\w\-]+\.[\w.\-]+`),                                            // email
+	regexp.MustCompile(`(?i)https?://|www\.|t\.me/`),                                             // links
+	regexp.MustCompile(`(?i)telegram|whatsapp|viber|телеграм|ватсап|вотсап|вайбер`),              // messengers
+}
+
+// contactInfoRule keeps contacts out of the text: buyers reach the seller
+// through the platform
+type contactInfoRule struct{}
+
+func (contactInfoRule) Check(_ context.Context, offer *domain.Offer) (*domain.RuleViolation, error) {
+	text := offer.Title + "\n" + offer.Description
+	for _, p := range contactPatterns {
+		if p.MatchString(text) {
+			return &domain.RuleViolation{
+				Rule:     "contact_info",
+				Severity: domain.ViolationBlock,
+				Message:  "контактные данные и ссылки в тексте объявления запрещены",
+			}, nil
+		}
+	}
+	return nil, nil
+}
+
+// missingPhotosRule only flags: photos may still be added after creation
+type missingPhotosRule struct{}
+
+func (missingPhotosRule) Check(_ context.Context, offer *domain.Offer) (*domain.RuleViolation, error) {
+	if len(offer.ImageURLs) > 0 {
+		return nil, nil
+	}
+	return &domain.RuleViolation{
+		Rule:     "missing_photos",
+		Severity: domain.ViolationReview,
+		Message:  "у объявления нет фотографий",
+	}, nil
+}
+
+const (
+	// Fewer comparable listings than this say nothing about the market
+	minPriceSamples  = 5
+	priceOutlierLow  = 0.3
+	priceOutlierHigh = 3.0
+)
+
+// priceOutlierRule compares the price per square metre with the median of
+// similar listings in the same complex, or in the region when the complex
+// has too few of them
+type priceOutlierRule struct {
+	stats IPrescreenRepository
+}
+
+func (r priceOutlierRule) Check(ctx context.Context, offer *domain.Offer) (*domain.RuleViolation, error) {
+	if offer.Area <= 0 {
+		return nil, nil
+	}
+
+	scope := "жилом комплексе"
+	var median float64
+	var samples int
+	if offer.HousingComplexID != nil {
+		var err error
+		if median, samples, err = r.stats.MedianPricePerSqmInComplex(ctx, offer); err != nil {
+			return nil, err
+		}
+	}
+	if samples < minPriceSamples {
+		var err error
+		if median, samples, err = r.stats.MedianPricePerSqmInRegion(ctx, offer); err != nil {
+			return nil, err
+		}
+		scope = "регионе"
+	}
+	if samples < minPriceSamples || median <= 0 {
+		return nil, nil
+	}
+
+	ratio := float64(offer.Price) / offer.Area / median
+	var msg string
+	switch {
+	case ratio < priceOutlierLow:
+		msg = fmt.Sprintf("цена за м² в %.1f раза ниже медианы в %s", 1/ratio, scope)
+	case ratio > priceOutlierHigh:
+		msg = fmt.Sprintf("цена за м² в %.1f раза выше медианы в %s", ratio, scope)
+	default:
+		return nil, nil
+	}
+	return &domain.RuleViolation{Rule: "price_outlier", Severity: domain.ViolationReview, Message: msg}, nil
+}
+
+// duplicatePhotosRule surfaces the photo duplicate check to the moderator
+type duplicatePhotosRule struct {
+	stats IPrescreenRepository
+}
+
+func (r duplicatePhotosRule) Check(ctx context.Context, offer *domain.Offer) (*domain.RuleViolation, error) {
+	flags, err := r.stats.CountOpenDuplicateFlags(ctx, offer.ID)
+	if err != nil || flags == 0 {
+		return nil, err
+	}
+	return &domain.RuleViolation{
+		Rule:     "duplicate_photos",
+		Severity: domain.ViolationReview,
+		Message:  fmt.Sprintf("фотографии совпадают с чужими объявлениями: %d", flags),
+	}, nil
+}
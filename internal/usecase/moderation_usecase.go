@@ -0,0 +1,40 @@
+package usecase
+
+import (
+	"context"
+	"time"
+
+	"github.com/go-park-mail-ru/2025_2_Avrora/internal/domain"
+	"github.com/go-park-mail-ru/2025_2_Avrora/internal/log"
+)
+
+// Photos whose dHashes differ in at most this many of 64 bits are treated as
+// the same picture (re-encoded, resized or lightly edited)
+const duplicatePhotoMaxDistance = 6
+
+type IModerationRepository interface {
+	ListOpenDuplicateFlags(ctx context.Context, limit int) ([]domain.DuplicateFlag, error)
+	Submit(ctx context.Context, item *domain.ModerationItem) error
+	ListPending(ctx context.Context, limit, offset int) ([]domain.ModerationItem, error)
+	GetItem(ctx context.Context, id string) (*domain.ModerationItem, error)
+	GetLatestForOffer(ctx context.Context, offerID string) (*domain.ModerationItem, error)
+	Decide(ctx context.Context, item *domain.ModerationItem, action domain.ModerationAction, change *domain.OfferStatusChange, expiresAt *time.Time) error
+	ListAudit(ctx context.Context, offerID string) ([]domain.ModerationAuditEntry, error)
+}
+
+// IModerationOfferReader gives moderation read access to offers
+type IModerationOfferReader interface {
+	GetByID(ctx context.Context, id string) (*domain.Offer, error)
+}
+
+type moderationUsecase struct {
+	moderationRepo IModerationRepository
+	offerRepo      IModerationOfferReader
+	rules          []ModerationRule
+	offerTTL       time.Duration // listing period started on approval
+	log            *log.Logger
+}
+
+func NewModerationUsecase(repo IModerationRepository, offerRepo IModerationOfferReader, rules []ModerationRule, offerTTL time.Duration, log *log.Logger) *moderationUsecase {
+	return &moderationUsecase{moderationRepo: repo, offerRepo: offerRepo, rules: rules, offerTTL: offerTTL, log: log}
+}
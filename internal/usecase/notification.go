@@ -0,0 +1,42 @@
+package usecase
+
+import (
+	"context"
+
+	"github.com/go-park-mail-ru/2025_2_Avrora/internal/domain"
+	"go.uber.org/zap"
+)
+
+// Notify stores a notification for its user
+func (u *notificationUsecase) Notify(ctx context.Context, n *domain.Notification) error {
+	return u.notificationRepo.Create(ctx, n)
+}
+
+// List returns the notifications of the user, newest first, and how many
+// of all of them are unread
+func (u *notificationUsecase) List(ctx context.Context, userID string, unreadOnly bool, limit, offset int) ([]domain.Notification, int, error) {
+	if limit < 1 || limit > 100 || offset < 0 {
+		u.log.Warn(ctx, "invalid notifications paging", zap.Int("limit", limit), zap.Int("offset", offset))
+		return nil, 0, domain.ErrInvalidInput
+	}
+	notifications, err := u.notificationRepo.List(ctx, userID, unreadOnly, limit, offset)
+	if err != nil {
+		return nil, 0, err
+	}
+	if notifications == nil {
+		notifications = []domain.Notification{}
+	}
+	unread, err := u.notificationRepo.CountUnread(ctx, userID)
+	if err != nil {
+		return nil, 0, err
+	}
+	return notifications, unread, nil
+}
+
+func (u *notificationUsecase) MarkRead(ctx context.Context, userID, id string) error {
+	return u.notificationRepo.MarkRead(ctx, userID, id)
+}
+
+func (u *notificationUsecase) MarkAllRead(ctx context.Context, userID string) error {
+	return u.notificationRepo.MarkAllRead(ctx, userID)
+}
@@ -0,0 +1,25 @@
+package usecase
+
+import (
+	"context"
+
+	"github.com/go-park-mail-ru/2025_2_Avrora/internal/domain"
+	"github.com/go-park-mail-ru/2025_2_Avrora/internal/log"
+)
+
+type INotificationRepository interface {
+	Create(ctx context.Context, n *domain.Notification) error
+	List(ctx context.Context, userID string, unreadOnly bool, limit, offset int) ([]domain.Notification, error)
+	CountUnread(ctx context.Context, userID string) (int, error)
+	MarkRead(ctx context.Context, userID, id string) error
+	MarkAllRead(ctx context.Context, userID string) error
+}
+
+type notificationUsecase struct {
+	notificationRepo INotificationRepository
+	log              *log.Logger
+}
+
+func NewNotificationUsecase(notificationRepo INotificationRepository, log *log.Logger) *notificationUsecase {
+	return &notificationUsecase{notificationRepo: notificationRepo, log: log}
+}
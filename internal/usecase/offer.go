@@ -5,6 +5,7 @@ import (
 	"fmt"
 
 	"github.com/go-park-mail-ru/2025_2_Avrora/internal/domain"
+	"github.com/go-park-mail-ru/2025_2_Avrora/internal/log"
 	"github.com/google/uuid"
 	"go.uber.org/zap"
 )
@@ -12,7 +13,7 @@ import (
 // === FEED METHODS ===
 
 // ListOffersInFeed returns paginated offers for the main feed
-func (uc *offerUsecase) ListOffersInFeed(ctx context.Context, page, limit int) (*domain.OffersInFeed, error) {
+func (uc *offerUsecase) ListOffersInFeed(ctx context.Context, page, limit int, sort domain.OfferSort) (*domain.OffersInFeed, error) {
 	if page < 1 {
 		uc.log.Warn(ctx, "invalid page in feed", zap.Int("page", page))
 		return nil, domain.ErrInvalidInput
@@ -21,8 +22,14 @@ func (uc *offerUsecase) ListOffersInFeed(ctx context.Context, page, limit int) (
 		uc.log.Warn(ctx, "invalid limit in feed", zap.Int("limit", limit))
 		return nil, domain.ErrInvalidInput
 	}
+	if sort == "" {
+		sort = domain.OfferSortNewest
+	} else if !sort.Valid() {
+		uc.log.Warn(ctx, "invalid sort in feed", zap.String("sort", string(sort)))
+		return nil, domain.ErrInvalidInput
+	}
 
-	offers, err := uc.offerRepo.List(ctx, page, limit)
+	offers, err := uc.offerRepo.List(ctx, page, limit, sort)
 	if err != nil {
 		uc.log.Error(ctx, "failed to list offers for feed", zap.Error(err))
 		return nil, err
@@ -31,8 +38,14 @@ func (uc *offerUsecase) ListOffersInFeed(ctx context.Context, page, limit int) (
 	return offers, nil
 }
 
-// ListOffersInFeedByUserID returns paginated offers for a specific user (e.g., "my offers")
+// ListOffersInFeedByUserID returns paginated active offers of a specific user
 func (uc *offerUsecase) ListOffersInFeedByUserID(ctx context.Context, userID string, page, limit int) (*domain.OffersInFeed, error) {
+	return uc.listUserOffers(ctx, userID, page, limit, false)
+}
+
+// listUserOffers lists the offers of the user; withUnlisted adds those off
+// the market, for the owner
+func (uc *offerUsecase) listUserOffers(ctx context.Context, userID string, page, limit int, withUnlisted bool) (*domain.OffersInFeed, error) {
 	if userID == "" {
 		uc.log.Warn(ctx, "empty user ID in ListOffersInFeedByUserID")
 		return nil, domain.ErrInvalidInput
@@ -46,7 +59,7 @@ func (uc *offerUsecase) ListOffersInFeedByUserID(ctx context.Context, userID str
 		return nil, domain.ErrInvalidInput
 	}
 
-	offers, err := uc.offerRepo.ListByUserID(ctx, userID, page, limit)
+	offers, err := uc.offerRepo.ListByUserID(ctx, userID, page, limit, withUnlisted)
 	if err != nil {
 		uc.log.Error(ctx, "failed to list user offers for feed", zap.String("user_id", userID), zap.Error(err))
 		return nil, err
@@ -55,6 +68,30 @@ func (uc *offerUsecase) ListOffersInFeedByUserID(ctx context.Context, userID str
 	return offers, nil
 }
 
+// ListMyOffers is ListOffersInFeedByUserID for the owner: offers in every
+// status, so those to renew or resubmit are found, with the last month's
+// statistics of every offer
+func (uc *offerUsecase) ListMyOffers(ctx context.Context, userID string, page, limit int) (*domain.OffersInFeed, error) {
+	offers, err := uc.listUserOffers(ctx, userID, page, limit, true)
+	if err != nil {
+		return nil, err
+	}
+
+	ids := make([]string, len(offers.Offers))
+	for i, o := range offers.Offers {
+		ids[i] = o.ID
+	}
+	summaries, err := uc.activity.Summaries(ctx, ids)
+	if err != nil {
+		return nil, err
+	}
+	for i := range offers.Offers {
+		s := summaries[offers.Offers[i].ID]
+		offers.Offers[i].Stats = &s
+	}
+	return offers, nil
+}
+
 // func (uc *offerUsecase) buildOffersInFeed(
 // 	ctx context.Context,
 // 	offers []*domain.Offer,
@@ -133,12 +170,82 @@ func (uc *offerUsecase) Get(ctx context.Context, id string) (*domain.Offer, erro
 		return nil, err
 	}
 
+	// The badge is decoration: the offer is still shown without it
+	if offer.FairPrice, err = uc.pricing.FairPrice(ctx, offer); err != nil {
+		uc.log.Warn(ctx, "failed to rate offer price", zap.String("offer_id", id), zap.Error(err))
+	}
+
 	return offer, nil
 }
 
+// View is Get for the offer page: the opening counts towards the offer
+// statistics. Offers that are not public are hidden from everyone but the
+// owner and moderators.
+func (uc *offerUsecase) View(ctx context.Context, id string, viewer domain.Viewer) (*domain.Offer, error) {
+	offer, err := uc.Get(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if !offer.Status.IsPublic() {
+		if !uc.canSeeUnlisted(ctx, offer, viewer) {
+			return nil, domain.ErrOfferNotFound
+		}
+		return offer, nil
+	}
+	if err := uc.activity.RecordView(ctx, offer, viewer); err != nil {
+		uc.log.Warn(ctx, "failed to record offer view", zap.String("offer_id", id), zap.Error(err))
+	}
+	return offer, nil
+}
+
+// canSeeUnlisted reports whether the viewer may open an offer that is not
+// public. The role is read on every call so that revoking it takes effect
+// immediately.
+func (uc *offerUsecase) canSeeUnlisted(ctx context.Context, offer *domain.Offer, viewer domain.Viewer) bool {
+	if viewer.UserID == nil {
+		return false
+	}
+	if *viewer.UserID == offer.UserID {
+		return true
+	}
+	user, err := uc.users.GetUserByUserID(ctx, *viewer.UserID)
+	if err != nil {
+		uc.log.Warn(ctx, "failed to load viewer role", zap.String("user_id", *viewer.UserID), zap.Error(err))
+		return false
+	}
+	return user.Role == domain.UserRoleModerator || user.Role == domain.UserRoleAdmin
+}
+
+// SimilarOffers recommends active offers like the given one, best match first
+func (uc *offerUsecase) SimilarOffers(ctx context.Context, id string, limit, offset int) ([]domain.OfferInFeed, error) {
+	if _, err := uuid.Parse(id); err != nil {
+		uc.log.Warn(ctx, "malformed offer ID", zap.String("offer_id", id))
+		return nil, domain.ErrInvalidInput
+	}
+	if limit < 1 || limit > 100 || offset < 0 {
+		uc.log.Warn(ctx, "invalid similar offers paging", zap.Int("limit", limit), zap.Int("offset", offset))
+		return nil, domain.ErrInvalidInput
+	}
+
+	offers, err := uc.offerRepo.ListSimilar(ctx, id, limit, offset)
+	if err != nil {
+		return nil, err
+	}
+	if offers == nil {
+		offers = []domain.OfferInFeed{}
+	}
+	return offers, nil
+}
+
 // === CORE CRUD (unchanged) ===
 
 func (uc *offerUsecase) Create(ctx context.Context, offer *domain.Offer) error {
+	return uc.create(ctx, offer, "")
+}
+
+// create validates and stores a new offer and sends it to moderation. A
+// non-empty draftID is deleted in the same transaction as the offer is created.
+func (uc *offerUsecase) create(ctx context.Context, offer *domain.Offer, draftID string) error {
 	if offer == nil || offer.Title == "" {
 		uc.log.Warn(ctx, "empty offer title")
 		return domain.ErrInvalidInput
@@ -150,20 +257,153 @@ func (uc *offerUsecase) Create(ctx context.Context, offer *domain.Offer) error {
 		uc.log.Warn(ctx, "invalid offer fields")
 		return domain.ErrInvalidInput
 	}
+	// Each property type has its own required fields and attributes
+	if errs := offer.Validate(); len(errs) > 0 {
+		uc.log.Warn(ctx, "invalid offer", zap.String("property_type", string(offer.PropertyType)), zap.Error(errs))
+		return errs
+	}
+	if offer.Attributes == nil {
+		offer.Attributes = domain.OfferAttributes{}
+	}
+	if err := uc.images.CheckOwned(ctx, offer.UserID, offer.ImageURLs); err != nil {
+		return err
+	}
 	offer.ID = uuid.NewString()
-	return uc.offerRepo.Create(ctx, offer)
+	// New offers go live only after a moderator approves them
+	offer.Status = domain.OfferStatusModeration
+	offer.ExpiresAt = nil
+	var err error
+	if draftID == "" {
+		err = uc.offerRepo.Create(ctx, offer)
+	} else {
+		err = uc.offerRepo.CreateFromDraft(ctx, offer, draftID)
+	}
+	if err != nil {
+		return err
+	}
+	uc.flagDuplicatePhotos(ctx, offer.ID)
+	return uc.moderation.Submit(ctx, offer, domain.ModerationKindNew)
 }
 
-func (uc *offerUsecase) Update(ctx context.Context, offer *domain.Offer) error {
-	if offer == nil || offer.ID == "" || offer.Title == "" {
-		return domain.ErrInvalidInput
+// Update applies a merge patch to the user's offer and returns the result.
+// Fields missing from the patch keep their values. version is the one the
+// client edited; nil skips the check.
+func (uc *offerUsecase) Update(ctx context.Context, userID, id string, patch domain.Patch, version *int) (*domain.Offer, error) {
+	if id == "" || len(patch) == 0 {
+		uc.log.Warn(ctx, "empty offer patch", zap.String("offer_id", id))
+		return nil, domain.ErrInvalidInput
+	}
+
+	existing, err := uc.offerRepo.GetByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if existing.UserID != userID {
+		return nil, domain.ErrForbidden
+	}
+	if err := matchVersion(existing.Version, version); err != nil {
+		return nil, err
+	}
+
+	// Status is changed only through ChangeStatus, so the patch never carries it
+	updated := *existing
+	updated.ApplyPatch(patch)
+	if updated.LocationID != existing.LocationID && !patch.Has("housing_complex_id") {
+		// The offer has moved away from the complex it was in, unless the
+		// patch names the complex at the new place
+		patch["housing_complex_id"] = nil
+		updated.HousingComplexID = nil
+	}
+	if errs := updated.Validate(); len(errs) > 0 {
+		uc.log.Warn(ctx, "invalid offer patch", zap.String("offer_id", id), zap.Error(errs))
+		return nil, errs
+	}
+	if patch.Has("attributes") {
+		// The column takes the merged attributes, not the patch of them
+		patch["attributes"] = updated.Attributes
+	}
+	// Photos the offer already has were validated when they were added
+	added := newImageURLs(existing.ImageURLs, updated.ImageURLs)
+	if err := uc.images.CheckOwned(ctx, userID, added); err != nil {
+		return nil, err
+	}
+
+	// A published offer is taken down until the edit is reviewed, together
+	// with the edit so that it is never live unreviewed
+	material := domain.IsMaterialChange(existing, &updated, len(added))
+	var change *domain.OfferStatusChange
+	if material {
+		change = resubmitChange(existing, "sent to moderation after edit")
+	}
+
+	if err := uc.offerRepo.Update(ctx, userID, id, patch, version, change); err != nil {
+		return nil, err
+	}
+	if len(added) > 0 {
+		uc.flagDuplicatePhotos(ctx, id)
+	}
+
+	// A pending submission is re-screened with the new content
+	if material && (change != nil || existing.Status == domain.OfferStatusModeration) {
+		updated.Status = domain.OfferStatusModeration
+		if err := uc.moderation.Submit(ctx, &updated, moderationKind(existing)); err != nil {
+			return nil, err
+		}
+	}
+
+	// Read back for the timestamps and any status moderation has set
+	return uc.offerRepo.GetByID(ctx, id)
+}
+
+// moderationKind tells a first publication from a change to a published offer
+func moderationKind(offer *domain.Offer) domain.ModerationKind {
+	if offer.PublishedAt != nil {
+		return domain.ModerationKindEdit
 	}
-	return uc.offerRepo.Update(ctx, offer)
+	return domain.ModerationKindNew
 }
 
-func (uc *offerUsecase) Delete(ctx context.Context, id string) error {
+// flagDuplicatePhotos queues the offer for moderator review when its photos
+// near-match other users' photos. Detection never blocks publishing.
+func (uc *offerUsecase) flagDuplicatePhotos(ctx context.Context, offerID string) {
+	flagDuplicateOfferPhotos(ctx, uc.offerRepo, uc.log, offerID)
+}
+
+// flagDuplicateOfferPhotos is flagDuplicatePhotos for every path that adds
+// offer photos, the single photo endpoints included
+func flagDuplicateOfferPhotos(ctx context.Context, repo IOfferRepository, l *log.Logger, offerID string) {
+	flagged, err := repo.FlagDuplicatePhotos(ctx, offerID, duplicatePhotoMaxDistance)
+	if err != nil {
+		l.Warn(ctx, "duplicate photo check failed", zap.String("offer_id", offerID), zap.Error(err))
+		return
+	}
+	if flagged > 0 {
+		l.Info(ctx, "offer flagged as possible duplicate", zap.String("offer_id", offerID), zap.Int64("flags", flagged))
+	}
+}
+
+// Delete archives the offer; hard removes the row for good. Like Update it
+// only applies to the version the client saw unless version is nil.
+func (uc *offerUsecase) Delete(ctx context.Context, userID, id string, hard bool, version *int) error {
 	if id == "" {
 		return domain.ErrInvalidInput
 	}
-	return uc.offerRepo.Delete(ctx, id)
+	offer, err := uc.offerRepo.GetByID(ctx, id)
+	if err != nil {
+		return err
+	}
+	if offer.UserID != userID {
+		return domain.ErrForbidden
+	}
+	if err := matchVersion(offer.Version, version); err != nil {
+		return err
+	}
+	if hard {
+		return uc.offerRepo.Delete(ctx, id, version)
+	}
+	if offer.Status == domain.OfferStatusArchived {
+		return nil
+	}
+	_, err = uc.changeStatus(ctx, offer, domain.OfferStatusArchived, &userID, nil, version)
+	return err
 }
@@ -0,0 +1,125 @@
+package usecase
+
+import (
+	"context"
+
+	"github.com/go-park-mail-ru/2025_2_Avrora/internal/domain"
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+)
+
+// CreateDraft starts a draft from whatever part of the offer is filled in
+func (uc *offerUsecase) CreateDraft(ctx context.Context, userID string, fields *domain.OfferDraftFields) (*domain.OfferDraft, error) {
+	if userID == "" || fields == nil {
+		return nil, domain.ErrInvalidInput
+	}
+	if errs := fields.Validate(false); len(errs) > 0 {
+		uc.log.Warn(ctx, "invalid draft fields", zap.Error(errs))
+		return nil, errs
+	}
+	fields.ImageURLs = uniqueStrings(fields.ImageURLs)
+	if err := uc.images.CheckOwned(ctx, userID, fields.ImageURLs); err != nil {
+		return nil, err
+	}
+
+	draft := &domain.OfferDraft{ID: uuid.NewString(), UserID: userID, Fields: *fields}
+	if err := uc.drafts.Create(ctx, draft); err != nil {
+		return nil, err
+	}
+	return draft, nil
+}
+
+// SaveDraft autosaves a change to the draft: fields set in patch overwrite,
+// cleared fields are unset. Only the resulting set fields are validated.
+func (uc *offerUsecase) SaveDraft(ctx context.Context, userID, draftID string, patch *domain.OfferDraftFields, cleared []string) (*domain.OfferDraft, error) {
+	if patch == nil {
+		return nil, domain.ErrInvalidInput
+	}
+	draft, err := uc.ownDraft(ctx, userID, draftID)
+	if err != nil {
+		return nil, err
+	}
+
+	before := draft.Fields.ImageURLs
+	draft.Fields.Apply(patch, cleared)
+	if errs := draft.Fields.Validate(false); len(errs) > 0 {
+		uc.log.Warn(ctx, "invalid draft fields", zap.String("draft_id", draftID), zap.Error(errs))
+		return nil, errs
+	}
+	draft.Fields.ImageURLs = uniqueStrings(draft.Fields.ImageURLs)
+	added := newImageURLs(before, draft.Fields.ImageURLs)
+	if err := uc.images.CheckOwned(ctx, userID, added); err != nil {
+		return nil, err
+	}
+
+	if err := uc.drafts.Update(ctx, draft); err != nil {
+		return nil, err
+	}
+	return draft, nil
+}
+
+func (uc *offerUsecase) GetDraft(ctx context.Context, userID, draftID string) (*domain.OfferDraft, error) {
+	return uc.ownDraft(ctx, userID, draftID)
+}
+
+func (uc *offerUsecase) ListDrafts(ctx context.Context, userID string, page, limit int) ([]domain.OfferDraft, error) {
+	if userID == "" || page < 1 || limit < 1 || limit > 100 {
+		uc.log.Warn(ctx, "invalid draft list request", zap.Int("page", page), zap.Int("limit", limit))
+		return nil, domain.ErrInvalidInput
+	}
+	return uc.drafts.ListByUserID(ctx, userID, limit, (page-1)*limit)
+}
+
+func (uc *offerUsecase) DeleteDraft(ctx context.Context, userID, draftID string) error {
+	if _, err := uc.ownDraft(ctx, userID, draftID); err != nil {
+		return err
+	}
+	return uc.drafts.Delete(ctx, draftID)
+}
+
+// PublishDraft validates the draft in full and turns it into an offer, which
+// enters the lifecycle in moderation like any new offer. The draft is gone
+// once the offer exists.
+func (uc *offerUsecase) PublishDraft(ctx context.Context, userID, draftID string) (*domain.Offer, error) {
+	draft, err := uc.ownDraft(ctx, userID, draftID)
+	if err != nil {
+		return nil, err
+	}
+	if errs := draft.Fields.Validate(true); len(errs) > 0 {
+		return nil, errs
+	}
+
+	offer := draft.Fields.ToOffer(userID)
+	if err := uc.create(ctx, offer, draftID); err != nil {
+		return nil, err
+	}
+	return offer, nil
+}
+
+func (uc *offerUsecase) ownDraft(ctx context.Context, userID, draftID string) (*domain.OfferDraft, error) {
+	if draftID == "" {
+		return nil, domain.ErrInvalidInput
+	}
+	draft, err := uc.drafts.GetByID(ctx, draftID)
+	if err != nil {
+		return nil, err
+	}
+	if draft.UserID != userID {
+		return nil, domain.ErrForbidden
+	}
+	return draft, nil
+}
+
+// uniqueStrings drops repeated values, keeping the first occurrence
+func uniqueStrings(values []string) []string {
+	seen := make(map[string]struct{}, len(values))
+	result := make([]string, 0, len(values))
+	for _, v := range values {
+		if _, ok := seen[v]; ok {
+			continue
+		}
+		seen[v] = struct{}{}
+		result = append(result, v)
+	}
+	return result
+}
@@ -0,0 +1,169 @@
+package usecase
+
+import (
+	"context"
+	"time"
+
+	"github.com/go-park-mail-ru/2025_2_Avrora/internal/domain"
+	"go.uber.org/zap"
+)
+
+const maxStatusReasonLength = 1000
+
+// ChangeStatus moves the user's offer to another lifecycle state
+func (uc *offerUsecase) ChangeStatus(ctx context.Context, userID, offerID string, to domain.OfferStatus, reason *string) (*domain.Offer, error) {
+	if offerID == "" || !to.Valid() {
+		uc.log.Warn(ctx, "invalid status change request", zap.String("offer_id", offerID), zap.String("status", string(to)))
+		return nil, domain.ErrInvalidInput
+	}
+	if reason != nil && len([]rune(*reason)) > maxStatusReasonLength {
+		return nil, domain.ErrInvalidInput
+	}
+
+	offer, err := uc.offerRepo.GetByID(ctx, offerID)
+	if err != nil {
+		return nil, err
+	}
+	if offer.UserID != userID {
+		return nil, domain.ErrForbidden
+	}
+	if !offer.Status.OwnerCanTransitionTo(to) {
+		uc.log.Warn(ctx, "offer status transition rejected",
+			zap.String("offer_id", offerID),
+			zap.String("from", string(offer.Status)),
+			zap.String("to", string(to)))
+		return nil, domain.ErrInvalidTransition
+	}
+
+	offer, err = uc.changeStatus(ctx, offer, to, &userID, reason, nil)
+	if err != nil {
+		return nil, err
+	}
+	if to != domain.OfferStatusModeration {
+		return offer, nil
+	}
+	if err := uc.moderation.Submit(ctx, offer, moderationKind(offer)); err != nil {
+		return nil, err
+	}
+	return offer, nil
+}
+
+// changeStatus applies a transition already authorised by the caller.
+// changedBy is nil for background jobs. A non-nil version makes the change
+// conditional on the offer still being at it.
+func (uc *offerUsecase) changeStatus(ctx context.Context, offer *domain.Offer, to domain.OfferStatus, changedBy, reason *string, version *int) (*domain.Offer, error) {
+	if !offer.Status.CanTransitionTo(to) {
+		return nil, domain.ErrInvalidTransition
+	}
+
+	now := time.Now().UTC()
+	from := offer.Status
+
+	// Pausing keeps the clock running; going active from any other state starts a new period
+	var expiresAt *time.Time
+	switch {
+	case to == domain.OfferStatusPaused || (from == domain.OfferStatusPaused && to == domain.OfferStatusActive):
+		expiresAt = offer.ExpiresAt
+	case to == domain.OfferStatusActive:
+		t := now.Add(uc.offerTTL)
+		expiresAt = &t
+	}
+
+	change := &domain.OfferStatusChange{
+		OfferID:    offer.ID,
+		FromStatus: &from,
+		ToStatus:   to,
+		ChangedBy:  changedBy,
+		Reason:     reason,
+		ChangedAt:  now,
+	}
+	if err := uc.offerRepo.ChangeStatus(ctx, change, expiresAt, version); err != nil {
+		return nil, err
+	}
+
+	offer.Version++
+	offer.Status = to
+	offer.StatusChangedAt = now
+	offer.ExpiresAt = expiresAt
+	if to == domain.OfferStatusActive && offer.PublishedAt == nil {
+		offer.PublishedAt = &now
+	}
+	uc.log.Info(ctx, "offer status changed",
+		zap.String("offer_id", offer.ID),
+		zap.String("from", string(from)),
+		zap.String("to", string(to)))
+	return offer, nil
+}
+
+// resubmitChange is the status change that takes a live offer down until a
+// material edit of it is reviewed; nil for offers that are not live
+func resubmitChange(offer *domain.Offer, reason string) *domain.OfferStatusChange {
+	if !offer.Status.IsLive() {
+		return nil
+	}
+	from := offer.Status
+	return &domain.OfferStatusChange{
+		OfferID:    offer.ID,
+		FromStatus: &from,
+		ToStatus:   domain.OfferStatusModeration,
+		Reason:     &reason,
+		ChangedAt:  time.Now().UTC(),
+	}
+}
+
+// Renew starts a new listing period for a live offer or re-activates an expired one
+func (uc *offerUsecase) Renew(ctx context.Context, userID, offerID string) (*domain.Offer, error) {
+	if offerID == "" {
+		return nil, domain.ErrInvalidInput
+	}
+
+	offer, err := uc.offerRepo.GetByID(ctx, offerID)
+	if err != nil {
+		return nil, err
+	}
+	if offer.UserID != userID {
+		return nil, domain.ErrForbidden
+	}
+
+	switch {
+	case offer.Status == domain.OfferStatusExpired:
+		return uc.changeStatus(ctx, offer, domain.OfferStatusActive, &userID, nil, nil)
+	case offer.Status.IsLive():
+		expiresAt := time.Now().UTC().Add(uc.offerTTL)
+		if err := uc.offerRepo.Renew(ctx, offerID, expiresAt); err != nil {
+			return nil, err
+		}
+		offer.ExpiresAt = &expiresAt
+		offer.Version++
+		return offer, nil
+	default:
+		return nil, domain.ErrInvalidTransition
+	}
+}
+
+// StatusHistory returns the lifecycle of the user's offer with a timestamp per transition
+func (uc *offerUsecase) StatusHistory(ctx context.Context, userID, offerID string) ([]domain.OfferStatusChange, error) {
+	if offerID == "" {
+		return nil, domain.ErrInvalidInput
+	}
+	offer, err := uc.offerRepo.GetByID(ctx, offerID)
+	if err != nil {
+		return nil, err
+	}
+	if offer.UserID != userID {
+		return nil, domain.ErrForbidden
+	}
+	return uc.offerRepo.ListStatusHistory(ctx, offerID)
+}
+
+// ExpireOffers is run periodically to end listings nobody renewed
+func (uc *offerUsecase) ExpireOffers(ctx context.Context) error {
+	expired, err := uc.offerRepo.ExpireOffers(ctx, time.Now().UTC())
+	if err != nil {
+		return err
+	}
+	if expired > 0 {
+		uc.log.Info(ctx, "expired offers", zap.Int64("count", expired))
+	}
+	return nil
+}
@@ -0,0 +1,79 @@
+package usecase
+
+import (
+	"context"
+	"time"
+
+	"github.com/go-park-mail-ru/2025_2_Avrora/internal/domain"
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+)
+
+const (
+	// Trend length when the caller gives no start date
+	defaultTrendPeriods = 12
+	// Two years of weeks; longer series are not worth computing on request
+	maxTrendPeriods = 104
+)
+
+// GetOfferPriceHistory returns the annotated price history of the offer with
+// its derived metrics
+func (uc *offerUsecase) GetOfferPriceHistory(ctx context.Context, id string) (*domain.PriceHistory, error) {
+	if _, err := uuid.Parse(id); err != nil {
+		uc.log.Warn(ctx, "malformed offer ID", zap.String("offer_id", id))
+		return nil, domain.ErrInvalidInput
+	}
+
+	offer, err := uc.offerRepo.GetByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	events, err := uc.offerRepo.ListPriceEvents(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	return domain.NewPriceHistory(offer, events, time.Now().UTC()), nil
+}
+
+// PriceTrend returns the price per square metre series of a complex, metro
+// station or region. Missing bounds default to the last defaultTrendPeriods
+// periods up to now.
+func (uc *offerUsecase) PriceTrend(ctx context.Context, q *domain.PriceTrendQuery) ([]domain.PriceTrendPoint, error) {
+	if q == nil || !q.Scope.Valid() || !q.Bucket.Valid() {
+		uc.log.Warn(ctx, "invalid price trend scope or bucket")
+		return nil, domain.ErrInvalidInput
+	}
+	if _, err := uuid.Parse(q.ScopeID); err != nil {
+		uc.log.Warn(ctx, "malformed price trend scope ID", zap.String("scope_id", q.ScopeID))
+		return nil, domain.ErrInvalidInput
+	}
+	if q.OfferType != domain.OfferTypeSale && q.OfferType != domain.OfferTypeRent {
+		uc.log.Warn(ctx, "invalid offer type in price trend", zap.String("offer_type", string(q.OfferType)))
+		return nil, domain.ErrInvalidInput
+	}
+	if pt := q.PropertyType; pt != nil && !pt.Valid() {
+		uc.log.Warn(ctx, "invalid property type in price trend", zap.String("property_type", string(*pt)))
+		return nil, domain.ErrInvalidInput
+	}
+
+	if q.To.IsZero() {
+		q.To = time.Now().UTC()
+	}
+	if q.From.IsZero() {
+		q.From = trendPeriodsBack(q.Bucket, q.To, defaultTrendPeriods-1)
+	}
+	if q.From.After(q.To) || q.From.Before(trendPeriodsBack(q.Bucket, q.To, maxTrendPeriods-1)) {
+		uc.log.Warn(ctx, "invalid price trend range", zap.Time("from", q.From), zap.Time("to", q.To))
+		return nil, domain.ErrInvalidInput
+	}
+
+	return uc.offerRepo.PriceTrend(ctx, q)
+}
+
+// trendPeriodsBack moves t back by n buckets
+func trendPeriodsBack(bucket domain.TrendBucket, t time.Time, n int) time.Time {
+	if bucket == domain.TrendBucketMonth {
+		return t.AddDate(0, -n, 0)
+	}
+	return t.AddDate(0, 0, -7*n)
+}
@@ -0,0 +1,193 @@
+package usecase
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"regexp"
+	"time"
+
+	"github.com/go-park-mail-ru/2025_2_Avrora/internal/domain"
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+)
+
+const (
+	// Period of the statistics shown in the owner's offer list
+	summaryStatsDays = 30
+	maxStatsDays     = 365
+)
+
+// botUserAgent matches crawlers, link previews and scripted clients. Their
+// requests are served but not counted.
+var botUserAgent = regexp.MustCompile(`(?i)bot|crawl|spider|slurp|preview|facebookexternalhit|headless|phantomjs|` +
+	`curl|wget|python|java/|go-http-client|okhttp|axios|node-fetch|scrapy|httpclient|postman`)
+
+func isBot(userAgent string) bool {
+	return userAgent == "" || botUserAgent.MatchString(userAgent)
+}
+
+// viewerKey identifies a viewer for unique counting without storing who
+// they are. Viewers are only told apart within a day, so the key is an HMAC
+// under a secret of that day: without the secret a guest's address cannot
+// be recovered by hashing candidates, and keys of different days do not link.
+func (uc *offerStatsUsecase) viewerKey(v domain.Viewer) string {
+	day := hmac.New(sha256.New, uc.viewerSecret)
+	day.Write([]byte("day:" + time.Now().UTC().Format(time.DateOnly)))
+
+	mac := hmac.New(sha256.New, day.Sum(nil))
+	if v.UserID != nil {
+		mac.Write([]byte("user:" + *v.UserID))
+	} else {
+		mac.Write([]byte("guest:" + v.IP + "\x00" + v.UserAgent))
+	}
+	return hex.EncodeToString(mac.Sum(nil))
+}
+
+// countable reports whether activity of the viewer on the offer goes into
+// its statistics: owners looking at their own listing and bots do not
+func countable(offer *domain.Offer, v domain.Viewer) bool {
+	if offer.Status != domain.OfferStatusActive || isBot(v.UserAgent) {
+		return false
+	}
+	return v.UserID == nil || *v.UserID != offer.UserID
+}
+
+// RecordView counts an opening of the offer page
+func (uc *offerStatsUsecase) RecordView(ctx context.Context, offer *domain.Offer, viewer domain.Viewer) error {
+	if !countable(offer, viewer) {
+		return nil
+	}
+	return uc.statsRepo.RecordView(ctx, offer.ID, uc.viewerKey(viewer))
+}
+
+// Summaries returns the last month's totals of the offers, zero for offers
+// without activity
+func (uc *offerStatsUsecase) Summaries(ctx context.Context, offerIDs []string) (map[string]domain.OfferStatsSummary, error) {
+	if len(offerIDs) == 0 {
+		return map[string]domain.OfferStatsSummary{}, nil
+	}
+	return uc.statsRepo.Summaries(ctx, offerIDs, summaryStatsDays)
+}
+
+// OfferStats returns the daily statistics of the user's offer for the last
+// days days
+func (uc *offerStatsUsecase) OfferStats(ctx context.Context, userID, offerID string, days int) (*domain.OfferStats, error) {
+	if _, err := uuid.Parse(offerID); err != nil {
+		uc.log.Warn(ctx, "malformed offer ID", zap.String("offer_id", offerID))
+		return nil, domain.ErrInvalidInput
+	}
+	if days < 1 || days > maxStatsDays {
+		uc.log.Warn(ctx, "invalid offer stats period", zap.Int("days", days))
+		return nil, domain.ErrInvalidInput
+	}
+
+	offer, err := uc.offerRepo.GetByID(ctx, offerID)
+	if err != nil {
+		return nil, err
+	}
+	if offer.UserID != userID {
+		return nil, domain.ErrForbidden
+	}
+
+	daily, err := uc.statsRepo.DailyStats(ctx, offerID, days)
+	if err != nil {
+		return nil, err
+	}
+	favorites, err := uc.statsRepo.CountFavorites(ctx, offerID)
+	if err != nil {
+		return nil, err
+	}
+
+	stats := &domain.OfferStats{
+		OfferID: offerID,
+		Period:  days,
+		Days:    daily,
+		Summary: domain.OfferStatsSummary{Favorites: favorites},
+	}
+	for _, d := range daily {
+		stats.Summary.Views += d.Views
+		stats.Summary.DailyViewers += d.UniqueViewers
+		stats.Summary.ContactReveals += d.ContactReveals
+	}
+	return stats, nil
+}
+
+// AddFavorite puts an offer on the market into the user's favorites
+func (uc *offerStatsUsecase) AddFavorite(ctx context.Context, userID, offerID string) error {
+	if _, err := uuid.Parse(offerID); err != nil {
+		uc.log.Warn(ctx, "malformed offer ID", zap.String("offer_id", offerID))
+		return domain.ErrInvalidInput
+	}
+	offer, err := uc.offerRepo.GetByID(ctx, offerID)
+	if err != nil {
+		return err
+	}
+	if offer.Status != domain.OfferStatusActive {
+		return domain.ErrOfferNotListed
+	}
+	return uc.statsRepo.AddFavorite(ctx, userID, offerID, uc.viewerKey(domain.Viewer{UserID: &userID}))
+}
+
+func (uc *offerStatsUsecase) RemoveFavorite(ctx context.Context, userID, offerID string) error {
+	if _, err := uuid.Parse(offerID); err != nil {
+		uc.log.Warn(ctx, "malformed offer ID", zap.String("offer_id", offerID))
+		return domain.ErrInvalidInput
+	}
+	return uc.statsRepo.RemoveFavorite(ctx, userID, offerID)
+}
+
+func (uc *offerStatsUsecase) ListFavorites(ctx context.Context, userID string, limit, offset int) ([]domain.OfferInFeed, error) {
+	if limit < 1 || limit > 100 || offset < 0 {
+		uc.log.Warn(ctx, "invalid favorites paging", zap.Int("limit", limit), zap.Int("offset", offset))
+		return nil, domain.ErrInvalidInput
+	}
+	offers, err := uc.statsRepo.ListFavorites(ctx, userID, limit, offset)
+	if err != nil {
+		return nil, err
+	}
+	if offers == nil {
+		offers = []domain.OfferInFeed{}
+	}
+	return offers, nil
+}
+
+// RevealContact returns the seller contacts of an offer on the market and
+// counts the reveal
+func (uc *offerStatsUsecase) RevealContact(ctx context.Context, viewer domain.Viewer, offerID string) (*domain.OfferContact, error) {
+	if _, err := uuid.Parse(offerID); err != nil {
+		uc.log.Warn(ctx, "malformed offer ID", zap.String("offer_id", offerID))
+		return nil, domain.ErrInvalidInput
+	}
+	offer, err := uc.offerRepo.GetByID(ctx, offerID)
+	if err != nil {
+		return nil, err
+	}
+	if offer.Status != domain.OfferStatusActive {
+		return nil, domain.ErrOfferNotListed
+	}
+
+	contact, err := uc.statsRepo.GetContact(ctx, offerID)
+	if err != nil {
+		return nil, err
+	}
+	if countable(offer, viewer) {
+		if err := uc.statsRepo.RecordContactReveal(ctx, offerID, uc.viewerKey(viewer)); err != nil {
+			uc.log.Warn(ctx, "failed to count contact reveal", zap.String("offer_id", offerID), zap.Error(err))
+		}
+	}
+	return contact, nil
+}
+
+// PurgeViewers is run periodically to forget who viewed offers on past days
+func (uc *offerStatsUsecase) PurgeViewers(ctx context.Context) error {
+	purged, err := uc.statsRepo.PurgeViewers(ctx)
+	if err != nil {
+		return err
+	}
+	if purged > 0 {
+		uc.log.Info(ctx, "purged offer viewers", zap.Int64("count", purged))
+	}
+	return nil
+}
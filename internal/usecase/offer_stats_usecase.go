@@ -0,0 +1,35 @@
+package usecase
+
+import (
+	"context"
+
+	"github.com/go-park-mail-ru/2025_2_Avrora/internal/domain"
+	"github.com/go-park-mail-ru/2025_2_Avrora/internal/log"
+)
+
+type IOfferStatsRepository interface {
+	RecordView(ctx context.Context, offerID, viewerKey string) error
+	AddFavorite(ctx context.Context, userID, offerID, viewerKey string) error
+	RemoveFavorite(ctx context.Context, userID, offerID string) error
+	CountFavorites(ctx context.Context, offerID string) (int, error)
+	ListFavorites(ctx context.Context, userID string, limit, offset int) ([]domain.OfferInFeed, error)
+	GetContact(ctx context.Context, offerID string) (*domain.OfferContact, error)
+	RecordContactReveal(ctx context.Context, offerID, viewerKey string) error
+	DailyStats(ctx context.Context, offerID string, days int) ([]domain.OfferDayStats, error)
+	Summaries(ctx context.Context, offerIDs []string, days int) (map[string]domain.OfferStatsSummary, error)
+	PurgeViewers(ctx context.Context) (int64, error)
+}
+
+type offerStatsUsecase struct {
+	statsRepo    IOfferStatsRepository
+	offerRepo    IOfferRepository
+	viewerSecret []byte // keys the viewer keys; see viewerKey
+	log          *log.Logger
+}
+
+func NewOfferStatsUsecase(statsRepo IOfferStatsRepository, offerRepo IOfferRepository, viewerSecret []byte, log *log.Logger) *offerStatsUsecase {
+	if len(viewerSecret) == 0 {
+		panic("viewer key secret cannot be empty")
+	}
+	return &offerStatsUsecase{statsRepo: statsRepo, offerRepo: offerRepo, viewerSecret: viewerSecret, log: log}
+}
@@ -2,6 +2,9 @@ package usecase
 
 import (
 	"context"
+	"time"
+
+	"github.com/google/uuid"
 	"go.uber.org/zap"
 
 	"github.com/go-park-mail-ru/2025_2_Avrora/internal/domain"
@@ -9,30 +12,80 @@ import (
 )
 
 type IOfferRepository interface {
-	List(ctx context.Context, page, limit int) (*domain.OffersInFeed, error)
+	List(ctx context.Context, page, limit int, sort domain.OfferSort) (*domain.OffersInFeed, error)
 	Create(ctx context.Context, offer *domain.Offer) error
-	Update(ctx context.Context, offer *domain.Offer) error
-	Delete(ctx context.Context, id string) error
+	CreateFromDraft(ctx context.Context, offer *domain.Offer, draftID string) error
+	Update(ctx context.Context, userID, id string, patch domain.Patch, version *int, change *domain.OfferStatusChange) error
+	Delete(ctx context.Context, id string, version *int) error
 	CountAll(ctx context.Context) (int, error)
-	ListByUserID(ctx context.Context, userID string, page, limit int) (*domain.OffersInFeed, error)
+	ListByUserID(ctx context.Context, userID string, page, limit int, withUnlisted bool) (*domain.OffersInFeed, error)
 	GetByID(ctx context.Context, id string) (*domain.Offer, error)
 	FilterOffers(ctx context.Context, f *domain.OfferFilter, limit, offset int) ([]domain.OfferInFeed, error)
-	GetOfferPriceHistory(ctx context.Context, id string) ([]domain.PricePoint, error)
+	ListSimilar(ctx context.Context, offerID string, limit, offset int) ([]domain.OfferInFeed, error)
+	ListPriceEvents(ctx context.Context, offerID string) ([]domain.PriceEvent, error)
+	PriceTrend(ctx context.Context, q *domain.PriceTrendQuery) ([]domain.PriceTrendPoint, error)
+	FlagDuplicatePhotos(ctx context.Context, offerID string, maxDistance int) (int64, error)
+	ChangeStatus(ctx context.Context, change *domain.OfferStatusChange, expiresAt *time.Time, version *int) error
+	Renew(ctx context.Context, offerID string, expiresAt time.Time) error
+	ExpireOffers(ctx context.Context, now time.Time) (int64, error)
+	ListStatusHistory(ctx context.Context, offerID string) ([]domain.OfferStatusChange, error)
+}
+
+type IOfferDraftRepository interface {
+	Create(ctx context.Context, draft *domain.OfferDraft) error
+	Update(ctx context.Context, draft *domain.OfferDraft) error
+	GetByID(ctx context.Context, id string) (*domain.OfferDraft, error)
+	ListByUserID(ctx context.Context, userID string, limit, offset int) ([]domain.OfferDraft, error)
+	Delete(ctx context.Context, id string) error
+}
+
+// IOfferModeration queues offers that have entered the moderation status
+type IOfferModeration interface {
+	Submit(ctx context.Context, offer *domain.Offer, kind domain.ModerationKind) error
+}
+
+// IOfferPricing compares an offer price with the market
+type IOfferPricing interface {
+	FairPrice(ctx context.Context, offer *domain.Offer) (*domain.FairPrice, error)
+}
+
+// IOfferActivity counts views of offers and reports the counters to owners
+type IOfferActivity interface {
+	RecordView(ctx context.Context, offer *domain.Offer, viewer domain.Viewer) error
+	Summaries(ctx context.Context, offerIDs []string) (map[string]domain.OfferStatsSummary, error)
 }
 
 type offerUsecase struct {
-	offerRepo IOfferRepository
-	log       *log.Logger
+	offerRepo  IOfferRepository
+	drafts     IOfferDraftRepository
+	images     IImageOwnership
+	moderation IOfferModeration
+	pricing    IOfferPricing
+	activity   IOfferActivity
+	users      IUserLookup
+	offerTTL   time.Duration // how long a listing stays active without renewal
+	log        *log.Logger
 }
 
-func NewOfferUsecase(repo IOfferRepository, log *log.Logger) *offerUsecase {
-	return &offerUsecase{offerRepo: repo, log: log}
+func NewOfferUsecase(repo IOfferRepository, drafts IOfferDraftRepository, images IImageOwnership, moderation IOfferModeration, pricing IOfferPricing, activity IOfferActivity, users IUserLookup, offerTTL time.Duration, log *log.Logger) *offerUsecase {
+	return &offerUsecase{offerRepo: repo, drafts: drafts, images: images, moderation: moderation, pricing: pricing, activity: activity, users: users, offerTTL: offerTTL, log: log}
 }
 
 func (uc *offerUsecase) FilterOffers(ctx context.Context, f *domain.OfferFilter, limit, offset int) ([]domain.OfferInFeed, error) {
 	if f == nil {
 		return nil, domain.ErrInvalidInput
 	}
+	// Drafts, archived and other non-public offers never show up in search
+	if f.Status == nil {
+		active := string(domain.OfferStatusActive)
+		f.Status = &active
+	} else if s := domain.OfferStatus(*f.Status); s != domain.OfferStatusActive && s != domain.OfferStatusSold {
+		uc.log.Warn(ctx, "non-public status in offer filter", zap.String("status", *f.Status))
+		return nil, domain.ErrInvalidInput
+	}
+	if err := validateOfferFilter(ctx, uc.log, f); err != nil {
+		return nil, err
+	}
 	offers, err := uc.offerRepo.FilterOffers(ctx, f, limit, offset)
 	if err != nil {
 		uc.log.Error(ctx, "failed to filter offers", zap.Error(err))
@@ -41,6 +94,83 @@ func (uc *offerUsecase) FilterOffers(ctx context.Context, f *domain.OfferFilter,
 	return offers, nil
 }
 
-func (uc *offerUsecase) GetOfferPriceHistory(ctx context.Context, id string) ([]domain.PricePoint, error) {
-	return uc.offerRepo.GetOfferPriceHistory(ctx, id)
+// validateOfferFilter checks the parts of a feed filter shared by every feed:
+// the offer feed and the offers of a complex or a region
+func validateOfferFilter(ctx context.Context, l *log.Logger, f *domain.OfferFilter) error {
+	if f.Sort != "" && !f.Sort.Valid() {
+		l.Warn(ctx, "invalid sort in offer filter", zap.String("sort", string(f.Sort)))
+		return domain.ErrInvalidInput
+	}
+	if err := checkTypeFilter(ctx, l, f); err != nil {
+		return err
+	}
+	if err := checkMetroFilter(ctx, l, f); err != nil {
+		return err
+	}
+	if err := checkAttributeFilter(ctx, l, f); err != nil {
+		return err
+	}
+	return checkStayFilter(ctx, l, f)
+}
+
+// checkMetroFilter validates the station set and the walk of a feed filter
+func checkMetroFilter(ctx context.Context, l *log.Logger, f *domain.OfferFilter) error {
+	if len(f.MetroIDs) > domain.MaxMetroStationsInFilter {
+		l.Warn(ctx, "too many stations in offer filter", zap.Int("count", len(f.MetroIDs)))
+		return domain.ErrInvalidInput
+	}
+	for _, id := range f.MetroIDs {
+		if _, err := uuid.Parse(id); err != nil {
+			l.Warn(ctx, "malformed station ID in offer filter", zap.String("id", id))
+			return domain.ErrInvalidInput
+		}
+	}
+	if f.MetroWalk != nil && (*f.MetroWalk < 1 || *f.MetroWalk > domain.MaxMetroWalkMinutes) {
+		l.Warn(ctx, "invalid metro walk in offer filter", zap.Int("minutes", *f.MetroWalk))
+		return domain.ErrInvalidInput
+	}
+	return nil
+}
+
+// checkTypeFilter validates the deal and property types of a feed filter
+func checkTypeFilter(ctx context.Context, l *log.Logger, f *domain.OfferFilter) error {
+	if ot := f.OfferType; ot != nil && *ot != string(domain.OfferTypeSale) && *ot != string(domain.OfferTypeRent) {
+		l.Warn(ctx, "invalid offer type in offer filter", zap.String("offer_type", *ot))
+		return domain.ErrInvalidInput
+	}
+	if pt := f.PropertyType; pt != nil && !domain.PropertyType(*pt).Valid() {
+		l.Warn(ctx, "invalid property type in offer filter", zap.String("property_type", *pt))
+		return domain.ErrInvalidInput
+	}
+	return nil
+}
+
+// checkAttributeFilter validates the attribute filters of a feed filter
+func checkAttributeFilter(ctx context.Context, l *log.Logger, f *domain.OfferFilter) error {
+	for _, af := range f.Attributes {
+		if !af.Valid() {
+			l.Warn(ctx, "invalid attribute in offer filter", zap.String("key", af.Key))
+			return domain.ErrInvalidInput
+		}
+	}
+	return nil
+}
+
+// checkStayFilter validates the stay dates of a feed filter: both or none,
+// departure after arrival and no longer than a stay may be
+func checkStayFilter(ctx context.Context, l *log.Logger, f *domain.OfferFilter) error {
+	if f.CheckIn == nil && f.CheckOut == nil {
+		return nil
+	}
+	if f.CheckIn == nil || f.CheckOut == nil {
+		l.Warn(ctx, "only one stay date in offer filter")
+		return domain.ErrInvalidInput
+	}
+	in, out := domain.Day(*f.CheckIn), domain.Day(*f.CheckOut)
+	if !out.After(in) || domain.StayNights(in, out) > domain.MaxStayNights {
+		l.Warn(ctx, "invalid stay in offer filter", zap.Time("check_in", in), zap.Time("check_out", out))
+		return domain.ErrInvalidInput
+	}
+	f.CheckIn, f.CheckOut = &in, &out
+	return nil
 }
\ No newline at end of file
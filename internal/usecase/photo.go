@@ -0,0 +1,102 @@
+package usecase
+
+import (
+	"context"
+	"unicode/utf8"
+
+	"github.com/go-park-mail-ru/2025_2_Avrora/internal/domain"
+	"go.uber.org/zap"
+)
+
+const maxPhotoCaptionLength = 500
+
+func (uc *photoUsecase) List(ctx context.Context, ownerID string) ([]domain.Photo, error) {
+	if ownerID == "" {
+		return nil, domain.ErrInvalidInput
+	}
+	return uc.photoRepo.List(ctx, ownerID)
+}
+
+func (uc *photoUsecase) Add(ctx context.Context, userID string, photo *domain.Photo) error {
+	if photo == nil || photo.OwnerID == "" || photo.URL == "" {
+		return domain.ErrInvalidInput
+	}
+	if err := validatePhotoMeta(photo.Caption, photo.RoomTag); err != nil {
+		uc.log.Warn(ctx, "invalid photo metadata", zap.String("owner_id", photo.OwnerID))
+		return err
+	}
+	if err := uc.canEdit(ctx, userID, photo.OwnerID); err != nil {
+		return err
+	}
+	urls := []string{photo.URL}
+	if err := uc.images.CheckOwned(ctx, userID, urls); err != nil {
+		return err
+	}
+	var change *domain.OfferStatusChange
+	if uc.review != nil {
+		var err error
+		if change, err = uc.review(ctx, photo.OwnerID); err != nil {
+			return err
+		}
+	}
+	if err := uc.photoRepo.Add(ctx, userID, photo, change); err != nil {
+		return err
+	}
+	if uc.added != nil {
+		return uc.added(ctx, photo.OwnerID)
+	}
+	return nil
+}
+
+// Update changes caption, room tag or cover flag of a single photo
+func (uc *photoUsecase) Update(ctx context.Context, userID, ownerID, photoID string, upd *domain.PhotoUpdate) (*domain.Photo, error) {
+	if ownerID == "" || photoID == "" || upd == nil {
+		return nil, domain.ErrInvalidInput
+	}
+	if err := validatePhotoMeta(upd.Caption, upd.RoomTag); err != nil {
+		uc.log.Warn(ctx, "invalid photo metadata", zap.String("photo_id", photoID))
+		return nil, err
+	}
+	if err := uc.canEdit(ctx, userID, ownerID); err != nil {
+		return nil, err
+	}
+	if err := uc.photoRepo.Update(ctx, ownerID, photoID, upd); err != nil {
+		return nil, err
+	}
+	return uc.photoRepo.Get(ctx, ownerID, photoID)
+}
+
+func (uc *photoUsecase) Delete(ctx context.Context, userID, ownerID, photoID string) error {
+	if ownerID == "" || photoID == "" {
+		return domain.ErrInvalidInput
+	}
+	if err := uc.canEdit(ctx, userID, ownerID); err != nil {
+		return err
+	}
+	return uc.photoRepo.Delete(ctx, ownerID, photoID)
+}
+
+// Reorder expects the ids of all listing photos in the desired order
+func (uc *photoUsecase) Reorder(ctx context.Context, userID, ownerID string, photoIDs []string) ([]domain.Photo, error) {
+	if ownerID == "" || len(photoIDs) == 0 {
+		return nil, domain.ErrInvalidInput
+	}
+	if err := uc.canEdit(ctx, userID, ownerID); err != nil {
+		return nil, err
+	}
+	if err := uc.photoRepo.Reorder(ctx, ownerID, photoIDs); err != nil {
+		return nil, err
+	}
+	return uc.photoRepo.List(ctx, ownerID)
+}
+
+// Empty caption or tag means "clear the value", so only non-empty ones are checked
+func validatePhotoMeta(caption *string, roomTag *domain.PhotoRoomTag) error {
+	if caption != nil && utf8.RuneCountInString(*caption) > maxPhotoCaptionLength {
+		return domain.ErrInvalidInput
+	}
+	if roomTag != nil && *roomTag != "" && !roomTag.Valid() {
+		return domain.ErrInvalidInput
+	}
+	return nil
+}
@@ -0,0 +1,86 @@
+package usecase
+
+import (
+	"context"
+
+	"github.com/go-park-mail-ru/2025_2_Avrora/internal/domain"
+	"github.com/go-park-mail-ru/2025_2_Avrora/internal/log"
+)
+
+type IPhotoRepository interface {
+	List(ctx context.Context, ownerID string) ([]domain.Photo, error)
+	Get(ctx context.Context, ownerID, photoID string) (*domain.Photo, error)
+	Add(ctx context.Context, userID string, photo *domain.Photo, change *domain.OfferStatusChange) error
+	Update(ctx context.Context, ownerID, photoID string, upd *domain.PhotoUpdate) error
+	Delete(ctx context.Context, ownerID, photoID string) error
+	Reorder(ctx context.Context, ownerID string, photoIDs []string) error
+}
+
+// photoAccessFunc decides whether the user may edit photos of the listing
+type photoAccessFunc func(ctx context.Context, userID, ownerID string) error
+
+// photoReviewFunc returns the status change a new photo puts the listing
+// through, made in the same transaction as adding it; nil for none
+type photoReviewFunc func(ctx context.Context, ownerID string) (*domain.OfferStatusChange, error)
+
+// photoAddedFunc runs the listing's checks on a newly added photo; nil for none
+type photoAddedFunc func(ctx context.Context, ownerID string) error
+
+type photoUsecase struct {
+	photoRepo IPhotoRepository
+	canEdit   photoAccessFunc
+	review    photoReviewFunc
+	added     photoAddedFunc
+	images    IImageOwnership
+	log       *log.Logger
+}
+
+// NewOfferPhotoUsecase manages offer photos; only the offer author may change
+// them. Added photos go through duplicate detection and moderation like those
+// of offer writes.
+func NewOfferPhotoUsecase(photoRepo IPhotoRepository, offerRepo IOfferRepository, moderation IOfferModeration, images IImageOwnership, log *log.Logger) *photoUsecase {
+	canEdit := func(ctx context.Context, userID, offerID string) error {
+		offer, err := offerRepo.GetByID(ctx, offerID)
+		if err != nil {
+			return err
+		}
+		if offer.UserID != userID {
+			return domain.ErrForbidden
+		}
+		return nil
+	}
+	// A new photo is a material edit: a published offer is taken down until
+	// it is reviewed
+	review := func(ctx context.Context, offerID string) (*domain.OfferStatusChange, error) {
+		offer, err := offerRepo.GetByID(ctx, offerID)
+		if err != nil {
+			return nil, err
+		}
+		return resubmitChange(offer, "sent to moderation after photo added"), nil
+	}
+	added := func(ctx context.Context, offerID string) error {
+		flagDuplicateOfferPhotos(ctx, offerRepo, log, offerID)
+		offer, err := offerRepo.GetByID(ctx, offerID)
+		if err != nil {
+			return err
+		}
+		if offer.Status != domain.OfferStatusModeration {
+			return nil
+		}
+		return moderation.Submit(ctx, offer, moderationKind(offer))
+	}
+	return &photoUsecase{photoRepo: photoRepo, canEdit: canEdit, review: review, added: added, images: images, log: log}
+}
+
+// NewComplexPhotoUsecase manages housing complex photos; those of a
+// developer's complex only its accounts and admins may change
+func NewComplexPhotoUsecase(photoRepo IPhotoRepository, complexRepo IComplexRepository, access IComplexAccess, images IImageOwnership, log *log.Logger) *photoUsecase {
+	canEdit := func(ctx context.Context, userID, complexID string) error {
+		complex, err := complexRepo.GetByID(ctx, complexID)
+		if err != nil {
+			return err
+		}
+		return access.CheckComplexAccess(ctx, userID, complex.DeveloperID)
+	}
+	return &photoUsecase{photoRepo: photoRepo, canEdit: canEdit, images: images, log: log}
+}
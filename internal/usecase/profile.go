@@ -33,28 +33,49 @@ func (uc *profileUsecase) GetProfileByID(ctx context.Context, userID string) (*d
 		AvatarURL: profile.AvatarURL,
 		CreatedAt: profile.CreatedAt,
 		UpdatedAt: profile.UpdatedAt,
+		Version:   profile.Version,
 	}, nil
 }
 
-func (uc *profileUsecase) UpdateProfile(ctx context.Context, userID string, upd *domain.ProfileUpdate) error {
+// UpdateProfile applies a merge patch to the profile; omitted fields are
+// kept. version is the one the client edited; nil skips the check.
+func (uc *profileUsecase) UpdateProfile(ctx context.Context, userID string, patch domain.Patch, version *int) error {
 	if userID == "" {
 		uc.log.Warn(ctx, "empty user ID in UpdateProfileByID")
 		return domain.ErrInvalidInput
 	}
-	if upd == nil {
-		uc.log.Warn(ctx, "nil update payload", zap.String("user_id", userID))
+	if len(patch) == 0 {
+		uc.log.Warn(ctx, "empty update payload", zap.String("user_id", userID))
 		return domain.ErrInvalidInput
 	}
 
-	_, _, err := uc.profileRepo.GetByUserID(ctx, userID)
+	existing, _, err := uc.profileRepo.GetByUserID(ctx, userID)
 	if err != nil {
 		if errors.Is(err, domain.ErrProfileNotFound) {
 			return ErrUserNotFound
 		}
 		return err
 	}
+	if err := matchVersion(existing.Version, version); err != nil {
+		return err
+	}
+
+	updated := *existing
+	updated.ApplyPatch(patch)
+	if errs := updated.Validate(); len(errs) > 0 {
+		uc.log.Warn(ctx, "invalid profile patch", zap.String("user_id", userID), zap.Error(errs))
+		return errs
+	}
+
+	var newAvatar []string
+	if updated.AvatarURL != "" && updated.AvatarURL != existing.AvatarURL {
+		newAvatar = []string{updated.AvatarURL}
+	}
+	if err := uc.images.CheckOwned(ctx, userID, newAvatar); err != nil {
+		return err
+	}
 
-	return uc.profileRepo.Update(ctx, userID, upd)
+	return uc.profileRepo.Update(ctx, userID, patch, version)
 }
 
 func (uc *profileUsecase) UpdateProfileSecurityByID(ctx context.Context, userID string, oldPassword, newPassword string) error {
@@ -9,7 +9,7 @@ import (
 
 type IProfileRepository interface {
 	GetByUserID(ctx context.Context, userID string) (*domain.Profile, string, error)
-	Update(ctx context.Context, userID string, upd *domain.ProfileUpdate) error
+	Update(ctx context.Context, userID string, patch domain.Patch, version *int) error
 	UpdateSecurity(ctx context.Context, userID string, passwordHash string) error
 	UpdateEmail(ctx context.Context, userID string, email string) error
 	GetUserByUserID(ctx context.Context, userID string) (*domain.User, error)
@@ -18,13 +18,15 @@ type IProfileRepository interface {
 type profileUsecase struct {
 	profileRepo IProfileRepository
 	passwordHasher IPasswordHasher
+	images IImageOwnership
 	log  *log.Logger
 }
 
-func NewProfileUsecase(profileRepo IProfileRepository, ph IPasswordHasher, log *log.Logger) *profileUsecase {
+func NewProfileUsecase(profileRepo IProfileRepository, ph IPasswordHasher, images IImageOwnership, log *log.Logger) *profileUsecase {
 	return &profileUsecase{
 		profileRepo: profileRepo, 
 		passwordHasher: ph, 
+		images: images,
 		log: log,
 	}
 }
\ No newline at end of file
@@ -0,0 +1,212 @@
+package usecase
+
+import (
+	"context"
+
+	"github.com/go-park-mail-ru/2025_2_Avrora/internal/domain"
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+)
+
+// Roots returns the top level regions
+func (u *regionUsecase) Roots(ctx context.Context) ([]domain.Region, error) {
+	return u.regionRepo.ListRegionChildren(ctx, nil)
+}
+
+// Tree returns the region with its ancestors and direct children
+func (u *regionUsecase) Tree(ctx context.Context, slug string) (*domain.RegionTree, error) {
+	region, err := u.regionRepo.GetRegionBySlug(ctx, slug)
+	if err != nil {
+		return nil, err
+	}
+	tree := &domain.RegionTree{Region: *region}
+	if tree.Path, err = u.regionRepo.ListRegionAncestors(ctx, region.ID); err != nil {
+		return nil, err
+	}
+	if tree.Children, err = u.regionRepo.ListRegionChildren(ctx, &region.ID); err != nil {
+		return nil, err
+	}
+	return tree, nil
+}
+
+// regionIDBySlug resolves an optional slug; nil stays nil
+func (u *regionUsecase) regionIDBySlug(ctx context.Context, slug *string) (*string, error) {
+	if slug == nil {
+		return nil, nil
+	}
+	region, err := u.regionRepo.GetRegionBySlug(ctx, *slug)
+	if err != nil {
+		return nil, err
+	}
+	return &region.ID, nil
+}
+
+// ListMetroLines returns the lines of the region and the regions below it
+func (u *regionUsecase) ListMetroLines(ctx context.Context, regionSlug *string) ([]domain.MetroLine, error) {
+	regionID, err := u.regionIDBySlug(ctx, regionSlug)
+	if err != nil {
+		return nil, err
+	}
+	return u.regionRepo.ListMetroLines(ctx, regionID)
+}
+
+// ListMetroStations returns the stations of the region, optionally of one line
+func (u *regionUsecase) ListMetroStations(ctx context.Context, regionSlug, lineID *string) ([]domain.MetroStation, error) {
+	if lineID != nil {
+		if _, err := uuid.Parse(*lineID); err != nil {
+			u.log.Warn(ctx, "malformed metro line ID", zap.String("line_id", *lineID))
+			return nil, domain.ErrInvalidInput
+		}
+	}
+	regionID, err := u.regionIDBySlug(ctx, regionSlug)
+	if err != nil {
+		return nil, err
+	}
+	return u.regionRepo.ListMetroStations(ctx, regionID, lineID)
+}
+
+// ListOffers returns the active offers of the region and the regions below
+// it matching the feed filters
+func (u *regionUsecase) ListOffers(ctx context.Context, slug string, f *domain.OfferFilter, limit, offset int) ([]domain.OfferInFeed, error) {
+	if f == nil {
+		return nil, domain.ErrInvalidInput
+	}
+	if limit < 1 || limit > 100 || offset < 0 {
+		u.log.Warn(ctx, "invalid region offers paging", zap.Int("limit", limit), zap.Int("offset", offset))
+		return nil, domain.ErrInvalidInput
+	}
+	if err := validateOfferFilter(ctx, u.log, f); err != nil {
+		return nil, err
+	}
+	if _, err := u.regionRepo.GetRegionBySlug(ctx, slug); err != nil {
+		return nil, err
+	}
+
+	active := string(domain.OfferStatusActive)
+	f.Status = &active
+	f.RegionSlug = &slug
+	offers, err := u.offerRepo.FilterOffers(ctx, f, limit, offset)
+	if err != nil {
+		return nil, err
+	}
+	if offers == nil {
+		offers = []domain.OfferInFeed{}
+	}
+	return offers, nil
+}
+
+// checkRegion validates the region and that its parent exists
+func (u *regionUsecase) checkRegion(ctx context.Context, region *domain.Region) error {
+	if errs := region.Validate(); len(errs) > 0 {
+		u.log.Warn(ctx, "invalid region", zap.String("slug", region.Slug), zap.Error(errs))
+		return errs
+	}
+	if region.ParentID == nil {
+		return nil
+	}
+	if _, err := uuid.Parse(*region.ParentID); err != nil {
+		u.log.Warn(ctx, "malformed parent region ID", zap.String("parent_id", *region.ParentID))
+		return domain.ErrInvalidInput
+	}
+	_, err := u.regionRepo.GetRegion(ctx, *region.ParentID)
+	return err
+}
+
+func (u *regionUsecase) CreateRegion(ctx context.Context, region *domain.Region) error {
+	if err := u.checkRegion(ctx, region); err != nil {
+		return err
+	}
+	return u.regionRepo.CreateRegion(ctx, region)
+}
+
+// UpdateRegion replaces name, slug and parent; a region can't move below itself
+func (u *regionUsecase) UpdateRegion(ctx context.Context, region *domain.Region) error {
+	if err := u.checkRegion(ctx, region); err != nil {
+		return err
+	}
+	return u.regionRepo.UpdateRegion(ctx, region)
+}
+
+// DeleteRegion removes a region without subregions, locations or metro lines
+func (u *regionUsecase) DeleteRegion(ctx context.Context, id string) error {
+	return u.regionRepo.DeleteRegion(ctx, id)
+}
+
+func (u *regionUsecase) checkMetroLine(ctx context.Context, line *domain.MetroLine) error {
+	if errs := line.Validate(); len(errs) > 0 {
+		u.log.Warn(ctx, "invalid metro line", zap.Error(errs))
+		return errs
+	}
+	if _, err := uuid.Parse(line.RegionID); err != nil {
+		u.log.Warn(ctx, "malformed metro line region", zap.String("region_id", line.RegionID))
+		return domain.ErrInvalidInput
+	}
+	_, err := u.regionRepo.GetRegion(ctx, line.RegionID)
+	return err
+}
+
+func (u *regionUsecase) CreateMetroLine(ctx context.Context, line *domain.MetroLine) error {
+	if err := u.checkMetroLine(ctx, line); err != nil {
+		return err
+	}
+	return u.regionRepo.CreateMetroLine(ctx, line)
+}
+
+func (u *regionUsecase) UpdateMetroLine(ctx context.Context, line *domain.MetroLine) error {
+	if err := u.checkMetroLine(ctx, line); err != nil {
+		return err
+	}
+	return u.regionRepo.UpdateMetroLine(ctx, line)
+}
+
+func (u *regionUsecase) DeleteMetroLine(ctx context.Context, id string) error {
+	return u.regionRepo.DeleteMetroLine(ctx, id)
+}
+
+// checkMetroStation validates the station and fills in its line
+func (u *regionUsecase) checkMetroStation(ctx context.Context, station *domain.MetroStation) error {
+	if errs := station.Validate(); len(errs) > 0 {
+		u.log.Warn(ctx, "invalid metro station", zap.Error(errs))
+		return errs
+	}
+	if _, err := uuid.Parse(station.RegionID); err != nil {
+		u.log.Warn(ctx, "malformed metro station region", zap.String("region_id", station.RegionID))
+		return domain.ErrInvalidInput
+	}
+	if _, err := u.regionRepo.GetRegion(ctx, station.RegionID); err != nil {
+		return err
+	}
+	if station.Line == nil {
+		return nil
+	}
+	if _, err := uuid.Parse(station.Line.ID); err != nil {
+		u.log.Warn(ctx, "malformed metro line ID", zap.String("line_id", station.Line.ID))
+		return domain.ErrInvalidInput
+	}
+	line, err := u.regionRepo.GetMetroLine(ctx, station.Line.ID)
+	if err != nil {
+		return err
+	}
+	station.Line = line
+	return nil
+}
+
+// CreateMetroStation adds the station and links it to the locations around
+func (u *regionUsecase) CreateMetroStation(ctx context.Context, station *domain.MetroStation) error {
+	if err := u.checkMetroStation(ctx, station); err != nil {
+		return err
+	}
+	return u.regionRepo.CreateMetroStation(ctx, station)
+}
+
+// UpdateMetroStation replaces the station; moving it links it anew
+func (u *regionUsecase) UpdateMetroStation(ctx context.Context, station *domain.MetroStation) error {
+	if err := u.checkMetroStation(ctx, station); err != nil {
+		return err
+	}
+	return u.regionRepo.UpdateMetroStation(ctx, station)
+}
+
+func (u *regionUsecase) DeleteMetroStation(ctx context.Context, id string) error {
+	return u.regionRepo.DeleteMetroStation(ctx, id)
+}
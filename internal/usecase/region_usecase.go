@@ -0,0 +1,45 @@
+package usecase
+
+import (
+	"context"
+
+	"github.com/go-park-mail-ru/2025_2_Avrora/internal/domain"
+	"github.com/go-park-mail-ru/2025_2_Avrora/internal/log"
+)
+
+type IRegionRepository interface {
+	GetRegion(ctx context.Context, id string) (*domain.Region, error)
+	GetRegionBySlug(ctx context.Context, slug string) (*domain.Region, error)
+	ListRegionChildren(ctx context.Context, parentID *string) ([]domain.Region, error)
+	ListRegionAncestors(ctx context.Context, id string) ([]domain.Region, error)
+	CreateRegion(ctx context.Context, region *domain.Region) error
+	UpdateRegion(ctx context.Context, region *domain.Region) error
+	DeleteRegion(ctx context.Context, id string) error
+
+	ListMetroLines(ctx context.Context, regionID *string) ([]domain.MetroLine, error)
+	GetMetroLine(ctx context.Context, id string) (*domain.MetroLine, error)
+	CreateMetroLine(ctx context.Context, line *domain.MetroLine) error
+	UpdateMetroLine(ctx context.Context, line *domain.MetroLine) error
+	DeleteMetroLine(ctx context.Context, id string) error
+
+	ListMetroStations(ctx context.Context, regionID, lineID *string) ([]domain.MetroStation, error)
+	GetMetroStation(ctx context.Context, id string) (*domain.MetroStation, error)
+	CreateMetroStation(ctx context.Context, station *domain.MetroStation) error
+	UpdateMetroStation(ctx context.Context, station *domain.MetroStation) error
+	DeleteMetroStation(ctx context.Context, id string) error
+}
+
+// IRegionOfferRepository lists the offers of a region
+type IRegionOfferRepository interface {
+	FilterOffers(ctx context.Context, f *domain.OfferFilter, limit, offset int) ([]domain.OfferInFeed, error)
+}
+
+type regionUsecase struct {
+	regionRepo IRegionRepository
+	offerRepo  IRegionOfferRepository
+	log        *log.Logger
+}
+
+func NewRegionUsecase(regionRepo IRegionRepository, offerRepo IRegionOfferRepository, log *log.Logger) *regionUsecase {
+	return &regionUsecase{regionRepo: regionRepo, offerRepo: offerRepo, log: log}
+}
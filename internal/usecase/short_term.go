@@ -0,0 +1,202 @@
+package usecase
+
+import (
+	"context"
+	"time"
+
+	"github.com/go-park-mail-ru/2025_2_Avrora/internal/domain"
+	"go.uber.org/zap"
+)
+
+// defaultCalendarDays is the span of a calendar asked for without an end
+const defaultCalendarDays = 60
+
+// GetCalendar returns the terms and the state of each night from from up to
+// to; missing bounds mean today and two months on
+func (u *shortTermUsecase) GetCalendar(ctx context.Context, offerID string, from, to *time.Time) (*domain.Calendar, error) {
+	start := domain.Day(time.Now())
+	if from != nil {
+		start = domain.Day(*from)
+	}
+	end := start.AddDate(0, 0, defaultCalendarDays)
+	if to != nil {
+		end = domain.Day(*to)
+	}
+	if !end.After(start) || domain.StayNights(start, end) > domain.MaxCalendarDays {
+		u.log.Warn(ctx, "invalid calendar range", zap.Time("from", start), zap.Time("to", end))
+		return nil, domain.ErrInvalidInput
+	}
+
+	if _, err := u.offerRepo.GetByID(ctx, offerID); err != nil {
+		return nil, err
+	}
+	terms, err := u.shortTermRepo.GetTerms(ctx, offerID)
+	if err != nil {
+		return nil, err
+	}
+	bookings, err := u.shortTermRepo.ListOfferBookings(ctx, offerID, start, end)
+	if err != nil {
+		return nil, err
+	}
+	return &domain.Calendar{Terms: *terms, Days: domain.BuildCalendar(start, end, bookings)}, nil
+}
+
+// SaveTerms lets the rent offer out by the night or changes its terms
+func (u *shortTermUsecase) SaveTerms(ctx context.Context, userID string, t *domain.ShortTermTerms) error {
+	offer, err := u.ownOffer(ctx, userID, t.OfferID)
+	if err != nil {
+		return err
+	}
+	if errs := t.Validate(offer.OfferType); len(errs) > 0 {
+		u.log.Warn(ctx, "invalid short-term terms", zap.String("offer_id", t.OfferID), zap.Error(errs))
+		return errs
+	}
+	return u.shortTermRepo.SaveTerms(ctx, t)
+}
+
+// DeleteTerms takes the offer out of the short-term mode; refused while
+// guests are still to come
+func (u *shortTermUsecase) DeleteTerms(ctx context.Context, userID, offerID string) error {
+	if _, err := u.ownOffer(ctx, userID, offerID); err != nil {
+		return err
+	}
+	return u.shortTermRepo.DeleteTerms(ctx, offerID)
+}
+
+// Block closes the nights for guests; only the owner may
+func (u *shortTermUsecase) Block(ctx context.Context, userID string, b *domain.Booking) error {
+	if _, err := u.ownOffer(ctx, userID, b.OfferID); err != nil {
+		return err
+	}
+	if _, err := u.shortTermRepo.GetTerms(ctx, b.OfferID); err != nil {
+		return err
+	}
+	b.Kind, b.GuestID, b.TotalPrice = domain.BookingKindBlocked, nil, nil
+	if errs := u.checkStay(b); len(errs) > 0 {
+		u.log.Warn(ctx, "invalid blocked dates", zap.String("offer_id", b.OfferID), zap.Error(errs))
+		return errs
+	}
+	return u.shortTermRepo.CreateBooking(ctx, b)
+}
+
+// Book takes the nights for the user at the nightly price of the offer
+func (u *shortTermUsecase) Book(ctx context.Context, userID string, b *domain.Booking) error {
+	offer, err := u.offerRepo.GetByID(ctx, b.OfferID)
+	if err != nil {
+		return err
+	}
+	if offer.Status != domain.OfferStatusActive {
+		// Offers out of the feed are not shown to guests
+		return domain.ErrOfferNotFound
+	}
+	if offer.UserID == userID {
+		u.log.Warn(ctx, "owner booking own offer", zap.String("offer_id", b.OfferID))
+		return domain.ErrForbidden
+	}
+	terms, err := u.shortTermRepo.GetTerms(ctx, b.OfferID)
+	if err != nil {
+		return err
+	}
+
+	b.Kind, b.GuestID = domain.BookingKindGuest, &userID
+	errs := u.checkStay(b)
+	if len(errs) == 0 {
+		errs = terms.CheckStay(b)
+	}
+	if len(errs) > 0 {
+		u.log.Warn(ctx, "invalid booking", zap.String("offer_id", b.OfferID), zap.Error(errs))
+		return errs
+	}
+	total := terms.NightlyPrice * int64(b.Nights())
+	b.TotalPrice = &total
+	return u.shortTermRepo.CreateBooking(ctx, b)
+}
+
+// checkStay truncates the dates to days and checks them against today
+func (u *shortTermUsecase) checkStay(b *domain.Booking) domain.ValidationErrors {
+	if !b.CheckIn.IsZero() {
+		b.CheckIn = domain.Day(b.CheckIn)
+	}
+	if !b.CheckOut.IsZero() {
+		b.CheckOut = domain.Day(b.CheckOut)
+	}
+	return b.ValidateFrom(domain.Day(time.Now()))
+}
+
+// Cancel frees the nights. Guests cancel their stays before check-in, owners
+// any stay not yet begun and their blocked dates not yet passed.
+func (u *shortTermUsecase) Cancel(ctx context.Context, userID, bookingID string) (*domain.Booking, error) {
+	b, err := u.shortTermRepo.GetBooking(ctx, bookingID)
+	if err != nil {
+		return nil, err
+	}
+	isGuest := b.GuestID != nil && *b.GuestID == userID
+	if !isGuest {
+		if _, err := u.ownOffer(ctx, userID, b.OfferID); err != nil {
+			return nil, err
+		}
+	}
+
+	today := domain.Day(time.Now())
+	over := b.Status != domain.BookingStatusActive
+	if b.Kind == domain.BookingKindBlocked {
+		over = over || !b.CheckOut.After(today)
+	} else {
+		over = over || b.CheckIn.Before(today)
+	}
+	if over {
+		u.log.Warn(ctx, "booking cannot be cancelled", zap.String("id", bookingID), zap.String("status", string(b.Status)))
+		return nil, domain.ErrBookingOver
+	}
+	if err := u.shortTermRepo.CancelBooking(ctx, b); err != nil {
+		return nil, err
+	}
+	return b, nil
+}
+
+// ListOfferBookings returns the owner the current and upcoming bookings and
+// blocked dates of the offer
+func (u *shortTermUsecase) ListOfferBookings(ctx context.Context, userID, offerID string) ([]domain.Booking, error) {
+	if _, err := u.ownOffer(ctx, userID, offerID); err != nil {
+		return nil, err
+	}
+	today := domain.Day(time.Now())
+	horizon := today.AddDate(0, 0, domain.BookingHorizonDays+domain.MaxStayNights)
+	bookings, err := u.shortTermRepo.ListOfferBookings(ctx, offerID, today, horizon)
+	if err != nil {
+		return nil, err
+	}
+	if bookings == nil {
+		bookings = []domain.Booking{}
+	}
+	return bookings, nil
+}
+
+// ListMyBookings returns the stays the user booked, latest first
+func (u *shortTermUsecase) ListMyBookings(ctx context.Context, userID string, limit, offset int) ([]domain.Booking, error) {
+	if limit < 1 || limit > 100 || offset < 0 {
+		u.log.Warn(ctx, "invalid bookings paging", zap.Int("limit", limit), zap.Int("offset", offset))
+		return nil, domain.ErrInvalidInput
+	}
+	bookings, err := u.shortTermRepo.ListGuestBookings(ctx, userID, limit, offset)
+	if err != nil {
+		return nil, err
+	}
+	if bookings == nil {
+		bookings = []domain.Booking{}
+	}
+	return bookings, nil
+}
+
+// ownOffer returns the offer if the user owns it
+func (u *shortTermUsecase) ownOffer(ctx context.Context, userID, offerID string) (*domain.Offer, error) {
+	offer, err := u.offerRepo.GetByID(ctx, offerID)
+	if err != nil {
+		return nil, err
+	}
+	if offer.UserID != userID {
+		u.log.Warn(ctx, "short-term access to another user's offer", zap.String("offer_id", offerID), zap.String("user_id", userID))
+		return nil, domain.ErrForbidden
+	}
+	return offer, nil
+}
@@ -0,0 +1,30 @@
+package usecase
+
+import (
+	"context"
+	"time"
+
+	"github.com/go-park-mail-ru/2025_2_Avrora/internal/domain"
+	"github.com/go-park-mail-ru/2025_2_Avrora/internal/log"
+)
+
+type IShortTermRepository interface {
+	GetTerms(ctx context.Context, offerID string) (*domain.ShortTermTerms, error)
+	SaveTerms(ctx context.Context, t *domain.ShortTermTerms) error
+	DeleteTerms(ctx context.Context, offerID string) error
+	GetBooking(ctx context.Context, id string) (*domain.Booking, error)
+	ListOfferBookings(ctx context.Context, offerID string, from, to time.Time) ([]domain.Booking, error)
+	ListGuestBookings(ctx context.Context, guestID string, limit, offset int) ([]domain.Booking, error)
+	CreateBooking(ctx context.Context, b *domain.Booking) error
+	CancelBooking(ctx context.Context, b *domain.Booking) error
+}
+
+type shortTermUsecase struct {
+	shortTermRepo IShortTermRepository
+	offerRepo     IOfferRepository
+	log           *log.Logger
+}
+
+func NewShortTermUsecase(shortTermRepo IShortTermRepository, offerRepo IOfferRepository, log *log.Logger) *shortTermUsecase {
+	return &shortTermUsecase{shortTermRepo: shortTermRepo, offerRepo: offerRepo, log: log}
+}
@@ -0,0 +1,13 @@
+package usecase
+
+import "github.com/go-park-mail-ru/2025_2_Avrora/internal/domain"
+
+// matchVersion fails early when the client's If-Match version is already
+// stale. The repository repeats the check atomically with the write; nil
+// expected matches any version.
+func matchVersion(current int, expected *int) error {
+	if expected != nil && *expected != current {
+		return domain.ErrVersionMismatch
+	}
+	return nil
+}
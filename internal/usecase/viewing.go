@@ -0,0 +1,250 @@
+package usecase
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/go-park-mail-ru/2025_2_Avrora/internal/domain"
+	"go.uber.org/zap"
+)
+
+const (
+	// calendarFeedPast is how long past viewings stay in the calendar feed
+	calendarFeedPast = 30 * 24 * time.Hour
+	// calendarFeedLimit bounds the viewings in one feed
+	calendarFeedLimit = 500
+)
+
+// viewingTimeZone is the time of the notification texts
+var viewingTimeZone = time.FixedZone("MSK", 3*60*60)
+
+// ListSlots returns the upcoming slots of the offer, free or not
+func (u *viewingUsecase) ListSlots(ctx context.Context, offerID string) ([]domain.ViewingSlot, error) {
+	if _, err := u.offerRepo.GetByID(ctx, offerID); err != nil {
+		return nil, err
+	}
+	slots, err := u.viewingRepo.ListSlots(ctx, offerID, time.Now())
+	if err != nil {
+		return nil, err
+	}
+	if slots == nil {
+		slots = []domain.ViewingSlot{}
+	}
+	return slots, nil
+}
+
+// CreateSlot opens a time for viewings of the user's offer
+func (u *viewingUsecase) CreateSlot(ctx context.Context, userID string, s *domain.ViewingSlot) error {
+	if _, err := u.ownOffer(ctx, userID, s.OfferID); err != nil {
+		return err
+	}
+	if errs := s.Validate(time.Now()); len(errs) > 0 {
+		u.log.Warn(ctx, "invalid viewing slot", zap.String("offer_id", s.OfferID), zap.Error(errs))
+		return errs
+	}
+	return u.viewingRepo.CreateSlot(ctx, s)
+}
+
+// DeleteSlot closes a slot no viewing holds
+func (u *viewingUsecase) DeleteSlot(ctx context.Context, userID, slotID string) error {
+	slot, err := u.viewingRepo.GetSlot(ctx, slotID)
+	if err != nil {
+		return err
+	}
+	if _, err := u.ownOffer(ctx, userID, slot.OfferID); err != nil {
+		return err
+	}
+	return u.viewingRepo.DeleteSlot(ctx, slotID)
+}
+
+// Request books the slot for the user; the owner is notified and confirms
+func (u *viewingUsecase) Request(ctx context.Context, userID, slotID string, comment *string) (*domain.Viewing, error) {
+	if errs := domain.ValidateViewingText("comment", comment); len(errs) > 0 {
+		return nil, errs
+	}
+	slot, err := u.viewingRepo.GetSlot(ctx, slotID)
+	if err != nil {
+		return nil, err
+	}
+	offer, err := u.offerRepo.GetByID(ctx, slot.OfferID)
+	if err != nil {
+		return nil, err
+	}
+	if offer.Status != domain.OfferStatusActive {
+		// Offers out of the feed are not shown to buyers
+		return nil, domain.ErrOfferNotFound
+	}
+	if offer.UserID == userID {
+		u.log.Warn(ctx, "owner requesting viewing of own offer", zap.String("offer_id", offer.ID))
+		return nil, domain.ErrForbidden
+	}
+	if !slot.StartsAt.After(time.Now()) {
+		return nil, domain.ErrViewingClosed
+	}
+
+	id, err := u.viewingRepo.CreateViewing(ctx, slotID, userID, comment)
+	if err != nil {
+		return nil, err
+	}
+	v, err := u.viewingRepo.GetViewing(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	u.notify(ctx, v, v.OwnerID, domain.NotificationViewingRequested,
+		fmt.Sprintf("Новая заявка на просмотр «%s» %s", v.OfferTitle, formatViewingTime(v.StartsAt)))
+	return v, nil
+}
+
+// Get returns the viewing to one of its sides
+func (u *viewingUsecase) Get(ctx context.Context, userID, id string) (*domain.Viewing, error) {
+	v, err := u.viewingRepo.GetViewing(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if !v.Party(userID) {
+		// Viewings of others don't exist for the user
+		return nil, domain.ErrViewingNotFound
+	}
+	return v, nil
+}
+
+// List returns the viewings the user requested, or with asOwner the ones of
+// the user's offers
+func (u *viewingUsecase) List(ctx context.Context, userID string, asOwner bool, limit, offset int) ([]domain.Viewing, error) {
+	if limit < 1 || limit > 100 || offset < 0 {
+		u.log.Warn(ctx, "invalid viewings paging", zap.Int("limit", limit), zap.Int("offset", offset))
+		return nil, domain.ErrInvalidInput
+	}
+	viewings, err := u.viewingRepo.ListViewings(ctx, userID, asOwner, limit, offset)
+	if err != nil {
+		return nil, err
+	}
+	if viewings == nil {
+		viewings = []domain.Viewing{}
+	}
+	return viewings, nil
+}
+
+// CalendarFeed returns the viewings of both sides of the user from a month
+// ago on, for the iCalendar feed
+func (u *viewingUsecase) CalendarFeed(ctx context.Context, userID string) ([]domain.Viewing, error) {
+	return u.viewingRepo.ListCalendarViewings(ctx, userID, time.Now().Add(-calendarFeedPast), calendarFeedLimit)
+}
+
+// Confirm accepts a requested viewing; only the owner may
+func (u *viewingUsecase) Confirm(ctx context.Context, userID, id string) (*domain.Viewing, error) {
+	v, err := u.ownViewing(ctx, userID, id)
+	if err != nil {
+		return nil, err
+	}
+	if err := u.viewingRepo.ConfirmViewing(ctx, id); err != nil {
+		return nil, err
+	}
+	if v, err = u.viewingRepo.GetViewing(ctx, id); err != nil {
+		return nil, err
+	}
+	u.notify(ctx, v, v.BuyerID, domain.NotificationViewingConfirmed,
+		fmt.Sprintf("Просмотр «%s» подтверждён: %s", v.OfferTitle, formatViewingTime(v.StartsAt)))
+	return v, nil
+}
+
+// Reschedule moves the viewing to another slot of the offer; the owner
+// proposes the time, so the viewing is confirmed
+func (u *viewingUsecase) Reschedule(ctx context.Context, userID, id, slotID string) (*domain.Viewing, error) {
+	v, err := u.ownViewing(ctx, userID, id)
+	if err != nil {
+		return nil, err
+	}
+	if !v.Open(time.Now()) {
+		return nil, domain.ErrViewingClosed
+	}
+	slot, err := u.viewingRepo.GetSlot(ctx, slotID)
+	if err != nil {
+		return nil, err
+	}
+	if slot.OfferID != v.OfferID || !slot.StartsAt.After(time.Now()) {
+		u.log.Warn(ctx, "unsuitable slot to reschedule to", zap.String("id", id), zap.String("slot_id", slotID))
+		return nil, domain.ErrInvalidInput
+	}
+	if err := u.viewingRepo.RescheduleViewing(ctx, id, slotID); err != nil {
+		return nil, err
+	}
+	if v, err = u.viewingRepo.GetViewing(ctx, id); err != nil {
+		return nil, err
+	}
+	u.notify(ctx, v, v.BuyerID, domain.NotificationViewingRescheduled,
+		fmt.Sprintf("Просмотр «%s» перенесён на %s", v.OfferTitle, formatViewingTime(v.StartsAt)))
+	return v, nil
+}
+
+// Cancel calls the viewing off on behalf of either side; the other one is
+// notified
+func (u *viewingUsecase) Cancel(ctx context.Context, userID, id string, reason *string) (*domain.Viewing, error) {
+	if errs := domain.ValidateViewingText("reason", reason); len(errs) > 0 {
+		return nil, errs
+	}
+	v, err := u.Get(ctx, userID, id)
+	if err != nil {
+		return nil, err
+	}
+	if !v.Open(time.Now()) {
+		return nil, domain.ErrViewingClosed
+	}
+	if err := u.viewingRepo.CancelViewing(ctx, id, userID, reason); err != nil {
+		return nil, err
+	}
+	if v, err = u.viewingRepo.GetViewing(ctx, id); err != nil {
+		return nil, err
+	}
+
+	to, who := v.OwnerID, "покупатель"
+	if userID == v.OwnerID {
+		to, who = v.BuyerID, "владелец"
+	}
+	msg := fmt.Sprintf("Просмотр «%s» %s отменён: %s", v.OfferTitle, formatViewingTime(v.StartsAt), who)
+	if reason != nil && *reason != "" {
+		msg += ", причина: " + *reason
+	}
+	u.notify(ctx, v, to, domain.NotificationViewingCancelled, msg)
+	return v, nil
+}
+
+// ownViewing returns the viewing if the user owns its offer
+func (u *viewingUsecase) ownViewing(ctx context.Context, userID, id string) (*domain.Viewing, error) {
+	v, err := u.Get(ctx, userID, id)
+	if err != nil {
+		return nil, err
+	}
+	if v.OwnerID != userID {
+		u.log.Warn(ctx, "viewing change by the buyer", zap.String("id", id), zap.String("user_id", userID))
+		return nil, domain.ErrForbidden
+	}
+	return v, nil
+}
+
+// ownOffer returns the offer if the user owns it
+func (u *viewingUsecase) ownOffer(ctx context.Context, userID, offerID string) (*domain.Offer, error) {
+	offer, err := u.offerRepo.GetByID(ctx, offerID)
+	if err != nil {
+		return nil, err
+	}
+	if offer.UserID != userID {
+		u.log.Warn(ctx, "viewing slots of another user's offer", zap.String("offer_id", offerID), zap.String("user_id", userID))
+		return nil, domain.ErrForbidden
+	}
+	return offer, nil
+}
+
+// notify tells the user about the change. The change is already made, so a
+// failed notification is logged rather than returned.
+func (u *viewingUsecase) notify(ctx context.Context, v *domain.Viewing, userID string, kind domain.NotificationKind, msg string) {
+	n := domain.Notification{UserID: userID, Kind: kind, ViewingID: &v.ID, Message: msg}
+	if err := u.notifier.Notify(ctx, &n); err != nil {
+		u.log.Error(ctx, "failed to notify about viewing", zap.String("id", v.ID), zap.String("kind", string(kind)), zap.Error(err))
+	}
+}
+
+func formatViewingTime(t time.Time) string {
+	return t.In(viewingTimeZone).Format("02.01.2006 в 15:04") + " (МСК)"
+}
@@ -0,0 +1,39 @@
+package usecase
+
+import (
+	"context"
+	"time"
+
+	"github.com/go-park-mail-ru/2025_2_Avrora/internal/domain"
+	"github.com/go-park-mail-ru/2025_2_Avrora/internal/log"
+)
+
+type IViewingRepository interface {
+	CreateSlot(ctx context.Context, s *domain.ViewingSlot) error
+	GetSlot(ctx context.Context, id string) (*domain.ViewingSlot, error)
+	ListSlots(ctx context.Context, offerID string, since time.Time) ([]domain.ViewingSlot, error)
+	DeleteSlot(ctx context.Context, id string) error
+	CreateViewing(ctx context.Context, slotID, buyerID string, comment *string) (string, error)
+	GetViewing(ctx context.Context, id string) (*domain.Viewing, error)
+	ListViewings(ctx context.Context, userID string, asOwner bool, limit, offset int) ([]domain.Viewing, error)
+	ListCalendarViewings(ctx context.Context, userID string, since time.Time, limit int) ([]domain.Viewing, error)
+	ConfirmViewing(ctx context.Context, id string) error
+	RescheduleViewing(ctx context.Context, id, slotID string) error
+	CancelViewing(ctx context.Context, id, cancelledBy string, reason *string) error
+}
+
+// INotifier delivers notifications to users
+type INotifier interface {
+	Notify(ctx context.Context, n *domain.Notification) error
+}
+
+type viewingUsecase struct {
+	viewingRepo IViewingRepository
+	offerRepo   IOfferRepository
+	notifier    INotifier
+	log         *log.Logger
+}
+
+func NewViewingUsecase(viewingRepo IViewingRepository, offerRepo IOfferRepository, notifier INotifier, log *log.Logger) *viewingUsecase {
+	return &viewingUsecase{viewingRepo: viewingRepo, offerRepo: offerRepo, notifier: notifier, log: log}
+}
@@ -26,6 +26,7 @@ type UploadRequest struct {
 	Data          []byte                 `protobuf:"bytes,1,opt,name=data,proto3" json:"data,omitempty"`
 	Filename      string                 `protobuf:"bytes,2,opt,name=filename,proto3" json:"filename,omitempty"`                          // e.g., "avatar.jpg" — you’ll generate this as UUID+ext
 	ContentType   string                 `protobuf:"bytes,3,opt,name=content_type,json=contentType,proto3" json:"content_type,omitempty"` // e.g., "image/jpeg"
+	Bucket        string                 `protobuf:"bytes,4,opt,name=bucket,proto3" json:"bucket,omitempty"`                              // "public" (default) or "private"
 	unknownFields protoimpl.UnknownFields
 	sizeCache     protoimpl.SizeCache
 }
@@ -81,9 +82,17 @@ func (x *UploadRequest) GetContentType() string {
 	return ""
 }
 
+func (x *UploadRequest) GetBucket() string {
+	if x != nil {
+		return x.Bucket
+	}
+	return ""
+}
+
 type UploadResponse struct {
 	state         protoimpl.MessageState `protogen:"open.v1"`
-	Url           string                 `protobuf:"bytes,1,opt,name=url,proto3" json:"url,omitempty"` // e.g., "/api/v1/image/abc123.jpg"
+	Url           string                 `protobuf:"bytes,1,opt,name=url,proto3" json:"url,omitempty"`      // e.g., "/api/v1/image/abc123.jpg"
+	Phash         uint64                 `protobuf:"varint,2,opt,name=phash,proto3" json:"phash,omitempty"` // perceptual hash of the image, 0 if it could not be decoded
 	unknownFields protoimpl.UnknownFields
 	sizeCache     protoimpl.SizeCache
 }
@@ -125,9 +134,17 @@ func (x *UploadResponse) GetUrl() string {
 	return ""
 }
 
+func (x *UploadResponse) GetPhash() uint64 {
+	if x != nil {
+		return x.Phash
+	}
+	return 0
+}
+
 type GetRequest struct {
 	state         protoimpl.MessageState `protogen:"open.v1"`
 	Filename      string                 `protobuf:"bytes,1,opt,name=filename,proto3" json:"filename,omitempty"` // use filename directly (since you control it, no need for ID)
+	Bucket        string                 `protobuf:"bytes,2,opt,name=bucket,proto3" json:"bucket,omitempty"`     // "public" (default) or "private"
 	unknownFields protoimpl.UnknownFields
 	sizeCache     protoimpl.SizeCache
 }
@@ -169,6 +186,13 @@ func (x *GetRequest) GetFilename() string {
 	return ""
 }
 
+func (x *GetRequest) GetBucket() string {
+	if x != nil {
+		return x.Bucket
+	}
+	return ""
+}
+
 // Stream for efficiency (avoids loading large files into memory at once)
 type GetResponse struct {
 	state         protoimpl.MessageState `protogen:"open.v1"`
@@ -219,16 +243,19 @@ var File_proto_fileserver_filserver_proto protoreflect.FileDescriptor
 const file_proto_fileserver_filserver_proto_rawDesc = "" +
 	"\n" +
 	" proto/fileserver/filserver.proto\x12\n" +
-	"fileserver\"b\n" +
+	"fileserver\"z\n" +
 	"\rUploadRequest\x12\x12\n" +
 	"\x04data\x18\x01 \x01(\fR\x04data\x12\x1a\n" +
 	"\bfilename\x18\x02 \x01(\tR\bfilename\x12!\n" +
-	"\fcontent_type\x18\x03 \x01(\tR\vcontentType\"\"\n" +
+	"\fcontent_type\x18\x03 \x01(\tR\vcontentType\x12\x16\n" +
+	"\x06bucket\x18\x04 \x01(\tR\x06bucket\"8\n" +
 	"\x0eUploadResponse\x12\x10\n" +
-	"\x03url\x18\x01 \x01(\tR\x03url\"(\n" +
+	"\x03url\x18\x01 \x01(\tR\x03url\x12\x14\n" +
+	"\x05phash\x18\x02 \x01(\x04R\x05phash\"@\n" +
 	"\n" +
 	"GetRequest\x12\x1a\n" +
-	"\bfilename\x18\x01 \x01(\tR\bfilename\"#\n" +
+	"\bfilename\x18\x01 \x01(\tR\bfilename\x12\x16\n" +
+	"\x06bucket\x18\x02 \x01(\tR\x06bucket\"#\n" +
 	"\vGetResponse\x12\x14\n" +
 	"\x05chunk\x18\x01 \x01(\fR\x05chunk2\x87\x01\n" +
 	"\n" +